@@ -6,19 +6,77 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 
 	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/notify"
 	"github.com/kaznasho/yarmarok/service"
 	"github.com/kaznasho/yarmarok/storage"
 	"github.com/kaznasho/yarmarok/web"
 )
 
-const ProjectIDEnvVar = "GCP_PROJECT"
+const (
+	ProjectIDEnvVar = "GCP_PROJECT"
 
-// ErrEmptyProjectID is returned when the project id is empty.
-var ErrEmptyProjectID = errors.New("empty project id")
+	// PhoneDefaultRegionEnvVar is the region of phones given without the international prefix.
+	PhoneDefaultRegionEnvVar = "PHONE_DEFAULT_REGION"
+	// PhoneAllowedRegionsEnvVar is a comma separated list of regions of accepted phones,
+	// phones of any region are accepted if it's set empty.
+	PhoneAllowedRegionsEnvVar = "PHONE_ALLOWED_REGIONS"
+	// AllowedScriptsEnvVar is a comma separated list of Unicode scripts
+	// letters of which are accepted in names and notes, e.g. "Latin,Cyrillic".
+	AllowedScriptsEnvVar = "ALLOWED_SCRIPTS"
+	// ValidationLocaleEnvVar is the locale of validation messages.
+	ValidationLocaleEnvVar = "VALIDATION_LOCALE"
+
+	// NotifierEnvVar is the provider winners are notified through:
+	// "sms", "telegram" or "log". Winners are not notified if it's not set.
+	NotifierEnvVar = "NOTIFIER"
+	// SMSGatewayURLEnvVar is the URL of the SMS gateway messages are posted to.
+	SMSGatewayURLEnvVar = "SMS_GATEWAY_URL"
+	// SMSGatewayTokenEnvVar is the bearer token of the SMS gateway.
+	SMSGatewayTokenEnvVar = "SMS_GATEWAY_TOKEN"
+	// SMSSenderEnvVar is the sender name of text messages.
+	SMSSenderEnvVar = "SMS_SENDER"
+	// TelegramBotTokenEnvVar is the token of the Telegram bot.
+	TelegramBotTokenEnvVar = "TELEGRAM_BOT_TOKEN"
+
+	// RetentionPeriodEnvVar is how long participants of archived raffles
+	// are kept before being anonymized, e.g. "8760h". They are kept forever if it's not set.
+	RetentionPeriodEnvVar = "RETENTION_PERIOD"
+
+	// EncryptionKeyringEnvVar is the keyring of phone encryption in JSON
+	// with keys wrapped by the Cloud KMS key KMSKeyNameEnvVar.
+	EncryptionKeyringEnvVar = "ENCRYPTION_KEYRING"
+	// KMSKeyNameEnvVar is the resource name of the Cloud KMS key wrapping the keyring.
+	KMSKeyNameEnvVar = "KMS_KEY_NAME"
+	// EncryptionKeyringFileEnvVar is the path to a keyring file with plaintext keys
+	// for local development. Phones are not encrypted if no keyring is set.
+	EncryptionKeyringFileEnvVar = "ENCRYPTION_KEYRING_FILE"
+
+	// SchedulerAudienceEnvVar is the audience of OIDC tokens Cloud Scheduler jobs are sent with.
+	SchedulerAudienceEnvVar = "SCHEDULER_AUDIENCE"
+	// SchedulerServiceAccountEnvVar is the email of the service account Cloud Scheduler jobs run as.
+	// Scheduler endpoints reject all requests if it's not set.
+	SchedulerServiceAccountEnvVar = "SCHEDULER_SERVICE_ACCOUNT"
+)
+
+// notifierTimeout limits sending a single message.
+const notifierTimeout = 10 * time.Second
+
+var (
+	// ErrEmptyProjectID is returned when the project id is empty.
+	ErrEmptyProjectID = errors.New("empty project id")
+
+	// ErrUnknownNotifier is returned when the notifier is not supported.
+	ErrUnknownNotifier = errors.New("unknown notifier")
+
+	// ErrEmptyKMSKeyName is returned when the keyring is set without the KMS key.
+	ErrEmptyKMSKeyName = errors.New("empty kms key name")
+)
 
 // Entrypoint is the entry point for the cloud function.
 func Entrypoint(w http.ResponseWriter, r *http.Request) {
@@ -41,6 +99,10 @@ func LoadRouter(log *logger.Logger) (*web.Router, error) {
 		return nil, fmt.Errorf("%w: %s is not set", ErrEmptyProjectID, ProjectIDEnvVar)
 	}
 
+	if err := service.SetValidationConfig(LoadValidationConfig()); err != nil {
+		return nil, err
+	}
+
 	firestoreClient, err := firestore.NewClient(context.Background(), projectID)
 	if err != nil {
 		return nil, err
@@ -48,7 +110,162 @@ func LoadRouter(log *logger.Logger) (*web.Router, error) {
 
 	organizerStorage := storage.NewFirestoreOrganizerStorage(firestoreClient)
 
+	routerOptions := make([]web.RouterOption, 0)
+
+	if account := os.Getenv(SchedulerServiceAccountEnvVar); account != "" {
+		routerOptions = append(routerOptions, web.WithSchedulerAuth(os.Getenv(SchedulerAudienceEnvVar), account))
+	}
+
+	keyProvider, err := LoadKeyProvider(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	if keyProvider != nil {
+		cipher, err := storage.NewFieldCipher(context.Background(), keyProvider)
+		if err != nil {
+			return nil, err
+		}
+
+		organizerStorage.SetFieldCipher(cipher)
+		routerOptions = append(routerOptions, web.WithKeyRotator(organizerStorage))
+	}
+
 	organizerService := service.NewOrganizerManager(organizerStorage)
+	organizerService.Milestones().Subscribe(logMilestone(log))
+
+	notifier, err := LoadNotifier(log)
+	if err != nil {
+		return nil, err
+	}
+
+	if notifier != nil {
+		organizerService.NotifyWinners(notifier)
+	}
+
+	retention, err := LoadRetentionPeriod()
+	if err != nil {
+		return nil, err
+	}
+
+	organizerService.SetRetentionPeriod(retention)
+
+	idempotencyStorage := storage.NewFirestoreIdempotencyStorage(firestoreClient)
+
+	routerOptions = append(routerOptions, web.WithIdempotencyStorage(idempotencyStorage, web.DefaultIdempotencyTTL))
+
+	return web.NewRouter(organizerService, log, routerOptions...)
+}
+
+// logMilestone logs milestones of fundraising goals.
+func logMilestone(log *logger.Logger) service.MilestoneHook {
+	entry := log.WithField("component", "milestones")
+
+	return func(e service.MilestoneEvent) {
+		entry.WithFields(logger.Fields{
+			"raffle_id": e.RaffleID,
+			"prize_id":  e.PrizeID,
+			"percent":   e.Percent,
+			"raised":    e.Raised,
+		}).Info("Goal milestone reached")
+	}
+}
+
+// LoadNotifier loads the notifier of winners from the environment.
+// It returns nil if winners are not notified.
+func LoadNotifier(log *logger.Logger) (service.Notifier, error) {
+	client := &http.Client{Timeout: notifierTimeout}
+
+	switch name := os.Getenv(NotifierEnvVar); name {
+	case "":
+		return nil, nil
+	case "sms":
+		return notify.NewSMSGateway(
+			os.Getenv(SMSGatewayURLEnvVar),
+			os.Getenv(SMSGatewayTokenEnvVar),
+			os.Getenv(SMSSenderEnvVar),
+			client,
+		), nil
+	case "telegram":
+		return notify.NewTelegram(os.Getenv(TelegramBotTokenEnvVar), client), nil
+	case "log":
+		return notify.NewLog(log), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownNotifier, name)
+	}
+}
+
+// LoadKeyProvider loads the provider of phone encryption keys from the environment.
+// It returns nil if phones are not encrypted.
+func LoadKeyProvider(ctx context.Context) (storage.KeyProvider, error) {
+	if keyring := os.Getenv(EncryptionKeyringEnvVar); keyring != "" {
+		name := os.Getenv(KMSKeyNameEnvVar)
+		if name == "" {
+			return nil, fmt.Errorf("%w: %s is not set", ErrEmptyKMSKeyName, KMSKeyNameEnvVar)
+		}
+
+		kms, err := storage.NewCloudKMS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return storage.NewKMSKeyProvider([]byte(keyring), kms), nil
+	}
+
+	if path := os.Getenv(EncryptionKeyringFileEnvVar); path != "" {
+		return storage.NewLocalKeyProvider(path), nil
+	}
+
+	return nil, nil
+}
+
+// LoadRetentionPeriod loads the retention period of participants of archived raffles
+// from the environment. It returns zero if participants are kept forever.
+func LoadRetentionPeriod() (time.Duration, error) {
+	period := os.Getenv(RetentionPeriodEnvVar)
+	if period == "" {
+		return 0, nil
+	}
+
+	retention, err := time.ParseDuration(period)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", RetentionPeriodEnvVar, err)
+	}
+
+	return retention, nil
+}
+
+// LoadValidationConfig loads the validation rules from the environment.
+// Rules which aren't set keep their default values.
+func LoadValidationConfig() service.ValidationConfig {
+	config := service.DefaultValidationConfig()
+
+	if region := os.Getenv(PhoneDefaultRegionEnvVar); region != "" {
+		config.DefaultRegion = region
+	}
+
+	if regions, ok := os.LookupEnv(PhoneAllowedRegionsEnvVar); ok {
+		config.AllowedRegions = splitList(regions)
+	}
+
+	if scripts := os.Getenv(AllowedScriptsEnvVar); scripts != "" {
+		config.Chars.Scripts = splitList(scripts)
+	}
+
+	if locale := os.Getenv(ValidationLocaleEnvVar); locale != "" {
+		config.Locale = locale
+	}
+
+	return config
+}
+
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
 
-	return web.NewRouter(organizerService, log)
+	return items
 }
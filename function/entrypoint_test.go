@@ -2,13 +2,16 @@ package function
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kaznasho/yarmarok/logger"
 	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/storage"
 	"github.com/kaznasho/yarmarok/testinfra"
 	fsemulator "github.com/kaznasho/yarmarok/testinfra/firestore"
 	"github.com/kaznasho/yarmarok/web"
@@ -50,6 +53,104 @@ func TestLoadRouter(t *testing.T) {
 
 }
 
+func TestLoadValidationConfig(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		assert.Equal(t, service.DefaultValidationConfig(), LoadValidationConfig())
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv(PhoneDefaultRegionEnvVar, "PL")
+		t.Setenv(PhoneAllowedRegionsEnvVar, "PL, UA,")
+		t.Setenv(AllowedScriptsEnvVar, "Latin,Cyrillic")
+		t.Setenv(ValidationLocaleEnvVar, service.LocaleUkrainian)
+
+		config := LoadValidationConfig()
+		assert.Equal(t, "PL", config.DefaultRegion)
+		assert.Equal(t, []string{"PL", "UA"}, config.AllowedRegions)
+		assert.Equal(t, []string{"Latin", "Cyrillic"}, config.Chars.Scripts)
+		assert.Equal(t, service.LocaleUkrainian, config.Locale)
+	})
+
+	t.Run("any_region", func(t *testing.T) {
+		t.Setenv(PhoneAllowedRegionsEnvVar, "")
+
+		assert.Empty(t, LoadValidationConfig().AllowedRegions)
+	})
+}
+
+func TestLoadNotifier(t *testing.T) {
+	log := logger.NewNoOpLogger()
+
+	t.Run("disabled", func(t *testing.T) {
+		notifier, err := LoadNotifier(log)
+		require.NoError(t, err)
+		assert.Nil(t, notifier)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		for _, channel := range []string{"sms", "telegram", "log"} {
+			t.Setenv(NotifierEnvVar, channel)
+
+			notifier, err := LoadNotifier(log)
+			require.NoError(t, err)
+			assert.Equal(t, channel, notifier.Channel())
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Setenv(NotifierEnvVar, "pigeon")
+
+		_, err := LoadNotifier(log)
+		assert.ErrorIs(t, err, ErrUnknownNotifier)
+	})
+}
+
+func TestLoadRetentionPeriod(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		retention, err := LoadRetentionPeriod()
+		require.NoError(t, err)
+		assert.Zero(t, retention)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv(RetentionPeriodEnvVar, "8760h")
+
+		retention, err := LoadRetentionPeriod()
+		require.NoError(t, err)
+		assert.Equal(t, 365*24*time.Hour, retention)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv(RetentionPeriodEnvVar, "a year")
+
+		_, err := LoadRetentionPeriod()
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadKeyProvider(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		provider, err := LoadKeyProvider(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, provider)
+	})
+
+	t.Run("local", func(t *testing.T) {
+		t.Setenv(EncryptionKeyringFileEnvVar, "keyring.json")
+
+		provider, err := LoadKeyProvider(context.Background())
+		require.NoError(t, err)
+		assert.IsType(t, &storage.LocalKeyProvider{}, provider)
+	})
+
+	t.Run("no_kms_key", func(t *testing.T) {
+		t.Setenv(EncryptionKeyringEnvVar, `{"primary": "1"}`)
+
+		_, err := LoadKeyProvider(context.Background())
+		assert.ErrorIs(t, err, ErrEmptyKMSKeyName)
+	})
+}
+
 func TestEntrypoint(t *testing.T) {
 	testinfra.SkipIfNotIntegrationRun(t)
 
@@ -21,6 +21,62 @@ variable "billing_account" {
   nullable = false
 }
 
+variable "phone_default_region" {
+  default = "UA"
+}
+
+# Comma separated, phones of any region are accepted if empty.
+variable "phone_allowed_regions" {
+  default = "UA"
+}
+
+# Comma separated Unicode scripts, e.g. "Latin,Cyrillic".
+# Only English and Ukrainian letters are accepted if empty.
+variable "allowed_scripts" {
+  default = ""
+}
+
+variable "validation_locale" {
+  default = "en"
+}
+
+# Provider winners are notified through: "sms", "telegram" or "log".
+# Winners are not notified if empty.
+variable "notifier" {
+  default = ""
+}
+
+variable "sms_gateway_url" {
+  default = ""
+}
+
+variable "sms_gateway_token" {
+  default   = ""
+  sensitive = true
+}
+
+variable "sms_sender" {
+  default = ""
+}
+
+variable "telegram_bot_token" {
+  default   = ""
+  sensitive = true
+}
+
+# How long participants of archived raffles are kept before being anonymized,
+# e.g. "8760h". They are kept forever if empty.
+variable "retention_period" {
+  default = ""
+}
+
+# Keyring of phone encryption in JSON with keys wrapped by the "phones" KMS key:
+# {"primary": "<id>", "keys": {"<id>": "<base64>"}, "indexKey": "<base64>"}.
+# Phones are not encrypted if empty.
+variable "encryption_keyring" {
+  default = ""
+}
+
 provider "google" {
   project = var.project
   region  = var.region
@@ -68,6 +124,37 @@ resource "google_project_service" "cloudfunctions" {
   ]
 }
 
+resource "google_project_service" "cloudkms" {
+  project = google_project.project.project_id
+  service = "cloudkms.googleapis.com"
+
+  depends_on = [ 
+    google_project.project,
+    time_sleep.wait_30_seconds 
+  ]
+}
+
+resource "google_kms_key_ring" "keyring" {
+  project  = google_project.project.project_id
+  name     = "yarmarok"
+  location = var.region
+
+  depends_on = [google_project_service.cloudkms]
+}
+
+# Wraps the keys of phone encryption
+resource "google_kms_crypto_key" "phones" {
+  name            = "phones"
+  key_ring        = google_kms_key_ring.keyring.id
+  rotation_period = "7776000s"
+}
+
+resource "google_kms_crypto_key_iam_member" "function-decrypter" {
+  crypto_key_id = google_kms_crypto_key.phones.id
+  role          = "roles/cloudkms.cryptoKeyDecrypter"
+  member        = "serviceAccount:${google_project.project.project_id}@appspot.gserviceaccount.com"
+}
+
 resource "google_firestore_database" "database" {
   project     = google_project.project.project_id
   name        = "(default)"
@@ -77,6 +164,98 @@ resource "google_firestore_database" "database" {
   depends_on = [google_project_service.firestore]
 }
 
+# Removes stored responses to requests with idempotency keys once they expire
+resource "google_firestore_field" "idempotency-keys-ttl" {
+  project    = google_project.project.project_id
+  database   = google_firestore_database.database.name
+  collection = "idempotency_keys"
+  field      = "ExpiresAt"
+
+  ttl_config {}
+
+  depends_on = [google_firestore_database.database]
+}
+
+# Lets the scheduler query due prizes of all raffles at once
+resource "google_firestore_field" "prizes-due-at" {
+  project    = google_project.project.project_id
+  database   = google_firestore_database.database.name
+  collection = "prizes"
+  field      = "DueAt"
+
+  index_config {
+    indexes {
+      order = "ASCENDING"
+    }
+    indexes {
+      order       = "ASCENDING"
+      query_scope = "COLLECTION_GROUP"
+    }
+  }
+
+  depends_on = [google_firestore_database.database]
+}
+
+# Lets the scheduler query closed raffles of all organizers at once
+resource "google_firestore_field" "raffles-close-due-at" {
+  project    = google_project.project.project_id
+  database   = google_firestore_database.database.name
+  collection = "raffles"
+  field      = "CloseDueAt"
+
+  index_config {
+    indexes {
+      order = "ASCENDING"
+    }
+    indexes {
+      order       = "ASCENDING"
+      query_scope = "COLLECTION_GROUP"
+    }
+  }
+
+  depends_on = [google_firestore_database.database]
+}
+
+# Lets the scheduler query raffles to be anonymized of all organizers at once
+resource "google_firestore_field" "raffles-retained-since" {
+  project    = google_project.project.project_id
+  database   = google_firestore_database.database.name
+  collection = "raffles"
+  field      = "RetainedSince"
+
+  index_config {
+    indexes {
+      order = "ASCENDING"
+    }
+    indexes {
+      order       = "ASCENDING"
+      query_scope = "COLLECTION_GROUP"
+    }
+  }
+
+  depends_on = [google_firestore_database.database]
+}
+
+# Lets the scheduler query due webhook deliveries of all organizers at once
+resource "google_firestore_field" "deliveries-next-attempt-at" {
+  project    = google_project.project.project_id
+  database   = google_firestore_database.database.name
+  collection = "deliveries"
+  field      = "NextAttemptAt"
+
+  index_config {
+    indexes {
+      order = "ASCENDING"
+    }
+    indexes {
+      order       = "ASCENDING"
+      query_scope = "COLLECTION_GROUP"
+    }
+  }
+
+  depends_on = [google_firestore_database.database]
+}
+
 resource "random_id" "default" {
   byte_length = 8
 }
@@ -120,7 +299,21 @@ resource "google_cloudfunctions_function" "function" {
   max_instances         = 4
   min_instances         = 0
   environment_variables = {
-    "GCP_PROJECT" = google_project.project.project_id
+    "GCP_PROJECT"               = google_project.project.project_id
+    "PHONE_DEFAULT_REGION"      = var.phone_default_region
+    "PHONE_ALLOWED_REGIONS"     = var.phone_allowed_regions
+    "ALLOWED_SCRIPTS"           = var.allowed_scripts
+    "VALIDATION_LOCALE"         = var.validation_locale
+    "NOTIFIER"                  = var.notifier
+    "SMS_GATEWAY_URL"           = var.sms_gateway_url
+    "SMS_GATEWAY_TOKEN"         = var.sms_gateway_token
+    "SMS_SENDER"                = var.sms_sender
+    "TELEGRAM_BOT_TOKEN"        = var.telegram_bot_token
+    "RETENTION_PERIOD"          = var.retention_period
+    "ENCRYPTION_KEYRING"        = var.encryption_keyring
+    "KMS_KEY_NAME"              = google_kms_crypto_key.phones.id
+    "SCHEDULER_AUDIENCE"        = local.scheduler_audience
+    "SCHEDULER_SERVICE_ACCOUNT" = google_service_account.scheduler.email
   }
   depends_on = [
     google_project.project,
@@ -141,4 +334,140 @@ resource "google_cloudfunctions_function_iam_member" "invoker" {
     google_project.project,
     google_cloudfunctions_function.function,
   ]
-}
\ No newline at end of file
+}
+resource "google_project_service" "cloudscheduler" {
+  project = google_project.project.project_id
+  service = "cloudscheduler.googleapis.com"
+
+  depends_on = [ 
+    google_project.project,
+    time_sleep.wait_30_seconds 
+  ]
+}
+
+# Cloud Scheduler jobs run as this account and are sent with its OIDC tokens,
+# which the function verifies on the scheduler endpoints
+resource "google_service_account" "scheduler" {
+  project      = google_project.project.project_id
+  account_id   = "scheduler"
+  display_name = "Cloud Scheduler jobs"
+}
+
+locals {
+  scheduler_audience = "${var.project}-scheduler"
+}
+
+# Plays prizes which draw time has come
+resource "google_cloud_scheduler_job" "play-due-prizes" {
+  project  = google_project.project.project_id
+  region   = var.region
+  name     = "play-due-prizes"
+  schedule = "* * * * *"
+
+  http_target {
+    http_method = "POST"
+    uri         = "${google_cloudfunctions_function.function.https_trigger_url}/api/scheduler/play-due"
+
+    oidc_token {
+      service_account_email = google_service_account.scheduler.email
+      audience              = local.scheduler_audience
+    }
+  }
+
+  depends_on = [
+    google_project_service.cloudscheduler,
+    google_cloudfunctions_function.function,
+  ]
+}
+
+# Publishes closed raffles and retries failed webhook deliveries
+resource "google_cloud_scheduler_job" "deliver-webhooks" {
+  project  = google_project.project.project_id
+  region   = var.region
+  name     = "deliver-webhooks"
+  schedule = "* * * * *"
+
+  http_target {
+    http_method = "POST"
+    uri         = "${google_cloudfunctions_function.function.https_trigger_url}/api/scheduler/webhooks"
+
+    oidc_token {
+      service_account_email = google_service_account.scheduler.email
+      audience              = local.scheduler_audience
+    }
+  }
+
+  depends_on = [
+    google_project_service.cloudscheduler,
+    google_cloudfunctions_function.function,
+  ]
+}
+
+# Notifies winners queued when prizes were played
+resource "google_cloud_scheduler_job" "send-notifications" {
+  project  = google_project.project.project_id
+  region   = var.region
+  name     = "send-notifications"
+  schedule = "* * * * *"
+
+  http_target {
+    http_method = "POST"
+    uri         = "${google_cloudfunctions_function.function.https_trigger_url}/api/scheduler/notifications"
+
+    oidc_token {
+      service_account_email = google_service_account.scheduler.email
+      audience              = local.scheduler_audience
+    }
+  }
+
+  depends_on = [
+    google_project_service.cloudscheduler,
+    google_cloudfunctions_function.function,
+  ]
+}
+
+# Anonymizes participants of raffles archived longer than the retention period
+resource "google_cloud_scheduler_job" "anonymize-expired" {
+  project  = google_project.project.project_id
+  region   = var.region
+  name     = "anonymize-expired"
+  schedule = "0 3 * * *"
+
+  http_target {
+    http_method = "POST"
+    uri         = "${google_cloudfunctions_function.function.https_trigger_url}/api/scheduler/anonymize"
+
+    oidc_token {
+      service_account_email = google_service_account.scheduler.email
+      audience              = local.scheduler_audience
+    }
+  }
+
+  depends_on = [
+    google_project_service.cloudscheduler,
+    google_cloudfunctions_function.function,
+  ]
+}
+
+# Re-encrypts phones encrypted with previous keys
+resource "google_cloud_scheduler_job" "rotate-keys" {
+  project  = google_project.project.project_id
+  region   = var.region
+  name     = "rotate-keys"
+  schedule = "30 3 * * *"
+
+  http_target {
+    http_method = "POST"
+    uri         = "${google_cloudfunctions_function.function.https_trigger_url}/api/scheduler/rotate-keys"
+
+    oidc_token {
+      service_account_email = google_service_account.scheduler.email
+      audience              = local.scheduler_audience
+    }
+  }
+
+  depends_on = [
+    google_project_service.cloudscheduler,
+    google_cloudfunctions_function.function,
+  ]
+}
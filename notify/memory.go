@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/service"
+)
+
+var (
+	_ service.Notifier = (*Memory)(nil)
+	_ service.Notifier = (*Log)(nil)
+)
+
+// Memory is a fake notifier which keeps the messages in memory.
+type Memory struct {
+	mu       sync.Mutex
+	messages []service.Message
+	// Err is returned by Notify if it's set.
+	Err error
+}
+
+// Channel returns "memory".
+func (n *Memory) Channel() string {
+	return "memory"
+}
+
+// Notify records the message and returns its index as the ID.
+func (n *Memory) Notify(m *service.Message) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.Err != nil {
+		return "", n.Err
+	}
+
+	n.messages = append(n.messages, *m)
+
+	return strconv.Itoa(len(n.messages) - 1), nil
+}
+
+// Messages returns the recorded messages.
+func (n *Memory) Messages() []service.Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]service.Message(nil), n.messages...)
+}
+
+// Log is a fake notifier which logs the messages instead of sending them.
+// It's meant for local runs.
+type Log struct {
+	entry *logger.Entry
+}
+
+// NewLog creates a new Log notifier.
+func NewLog(log *logger.Logger) *Log {
+	return &Log{
+		entry: log.WithField("component", "notifier"),
+	}
+}
+
+// Channel returns "log".
+func (n *Log) Channel() string {
+	return "log"
+}
+
+// Notify logs the message. The phone of the recipient is not logged.
+func (n *Log) Notify(m *service.Message) (string, error) {
+	n.entry.WithFields(logger.Fields{
+		"participant_id": m.Recipient.ID,
+		"text":           m.Text,
+	}).Info("Message to participant")
+
+	return "", nil
+}
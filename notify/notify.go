@@ -0,0 +1,66 @@
+// Package notify provides notifiers sending messages to participants
+// through messaging providers.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ErrUnexpectedResponse is returned when a provider doesn't accept a message.
+var ErrUnexpectedResponse = errors.New("unexpected provider response")
+
+// maxErrorBodyLength limits the response body included in errors.
+const maxErrorBodyLength = 512
+
+// postJSON posts the body encoded to JSON with the headers and decodes
+// the response into out if the provider responds with 2xx.
+func postJSON(client *http.Client, url string, header http.Header, body, out any) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(encoded))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", redactURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		return fmt.Errorf("%w: status %d: %s", ErrUnexpectedResponse, resp.StatusCode, text)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// redactURL strips the request URL from transport errors,
+// since URLs of some providers include credentials, e.g. Telegram bot tokens.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+
+	return err
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+// provider is a fake messaging provider recording the requests.
+type provider struct {
+	*httptest.Server
+	requests []*http.Request
+	bodies   []map[string]any
+}
+
+func newProvider(t *testing.T, status int, response string) *provider {
+	p := &provider{}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+
+		p.requests = append(p.requests, req)
+		p.bodies = append(p.bodies, body)
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+var winner = service.Participant{
+	ID:             "participant_1",
+	Name:           "Olena",
+	Phone:          "0501234567",
+	TelegramChatID: "123456789",
+	ContactAllowed: true,
+}
+
+func TestSMSGateway(t *testing.T) {
+	t.Run("sent", func(t *testing.T) {
+		p := newProvider(t, http.StatusOK, `{"id":"sms_1"}`)
+
+		id, err := NewSMSGateway(p.URL, "token", "Yarmarok", http.DefaultClient).
+			Notify(&service.Message{Recipient: winner, Text: "You won!"})
+		require.NoError(t, err)
+		assert.Equal(t, "sms_1", id)
+
+		require.Len(t, p.requests, 1)
+		assert.Equal(t, "Bearer token", p.requests[0].Header.Get("Authorization"))
+		assert.Equal(t, map[string]any{"to": "+380501234567", "from": "Yarmarok", "text": "You won!"}, p.bodies[0])
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		p := newProvider(t, http.StatusPaymentRequired, `{"error":"no funds"}`)
+
+		_, err := NewSMSGateway(p.URL, "token", "", http.DefaultClient).
+			Notify(&service.Message{Recipient: winner, Text: "You won!"})
+		assert.ErrorIs(t, err, ErrUnexpectedResponse)
+		assert.ErrorContains(t, err, "no funds")
+	})
+
+	t.Run("no_phone", func(t *testing.T) {
+		_, err := NewSMSGateway("http://localhost", "token", "", http.DefaultClient).
+			Notify(&service.Message{Recipient: service.Participant{ID: "participant_2"}})
+		assert.ErrorIs(t, err, service.ErrNoAddress)
+	})
+}
+
+func TestTelegram(t *testing.T) {
+	newTelegram := func(p *provider) *Telegram {
+		telegram := NewTelegram("bot_token", http.DefaultClient)
+		telegram.apiURL = p.URL
+
+		return telegram
+	}
+
+	t.Run("sent", func(t *testing.T) {
+		p := newProvider(t, http.StatusOK, `{"ok":true,"result":{"message_id":42}}`)
+
+		id, err := newTelegram(p).Notify(&service.Message{Recipient: winner, Text: "You won!"})
+		require.NoError(t, err)
+		assert.Equal(t, "42", id)
+
+		require.Len(t, p.requests, 1)
+		assert.Equal(t, "/botbot_token/sendMessage", p.requests[0].URL.Path)
+		assert.Equal(t, map[string]any{"chat_id": "123456789", "text": "You won!"}, p.bodies[0])
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		p := newProvider(t, http.StatusForbidden, `{"ok":false,"description":"Forbidden: bot was blocked by the user"}`)
+
+		_, err := newTelegram(p).Notify(&service.Message{Recipient: winner, Text: "You won!"})
+		assert.ErrorIs(t, err, ErrUnexpectedResponse)
+		assert.ErrorContains(t, err, "bot was blocked")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		p := newProvider(t, http.StatusOK, "")
+		telegram := newTelegram(p)
+		p.Close()
+
+		_, err := telegram.Notify(&service.Message{Recipient: winner, Text: "You won!"})
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "bot_token")
+	})
+
+	t.Run("no_chat", func(t *testing.T) {
+		_, err := NewTelegram("bot_token", http.DefaultClient).
+			Notify(&service.Message{Recipient: service.Participant{ID: "participant_2", Phone: "0501234567"}})
+		assert.ErrorIs(t, err, service.ErrNoAddress)
+	})
+}
+
+func TestMemory(t *testing.T) {
+	n := &Memory{}
+
+	id, err := n.Notify(&service.Message{Recipient: winner, Text: "You won!"})
+	require.NoError(t, err)
+	assert.Equal(t, "0", id)
+	assert.Equal(t, []service.Message{{Recipient: winner, Text: "You won!"}}, n.Messages())
+
+	n.Err = errors.New("unavailable")
+	_, err = n.Notify(&service.Message{Recipient: winner})
+	assert.Error(t, err)
+	assert.Len(t, n.Messages(), 1)
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"net/http"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+var _ service.Notifier = (*SMSGateway)(nil)
+
+// SMSGateway sends text messages through an HTTP SMS gateway.
+// It posts {"to", "from", "text"} JSON with a bearer token
+// and reads the message ID from the "id" field of the response.
+type SMSGateway struct {
+	url    string
+	token  string
+	sender string
+	client *http.Client
+}
+
+// NewSMSGateway creates a new SMSGateway sending messages
+// on behalf of the sender name.
+func NewSMSGateway(url, token, sender string, client *http.Client) *SMSGateway {
+	return &SMSGateway{
+		url:    url,
+		token:  token,
+		sender: sender,
+		client: client,
+	}
+}
+
+type smsRequest struct {
+	To   string `json:"to"`
+	From string `json:"from,omitempty"`
+	Text string `json:"text"`
+}
+
+type smsResponse struct {
+	ID string `json:"id"`
+}
+
+// Channel returns "sms".
+func (g *SMSGateway) Channel() string {
+	return "sms"
+}
+
+// Notify sends the message to the phone of the recipient.
+func (g *SMSGateway) Notify(m *service.Message) (string, error) {
+	if m.Recipient.Phone == "" {
+		return "", service.ErrNoAddress
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+g.token)
+
+	var resp smsResponse
+
+	err := postJSON(g.client, g.url, header, smsRequest{
+		To:   service.NormalizePhone(m.Recipient.Phone),
+		From: g.sender,
+		Text: m.Text,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ID, nil
+}
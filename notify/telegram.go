@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+// TelegramAPIURL is the URL of the Telegram Bot API.
+const TelegramAPIURL = "https://api.telegram.org"
+
+var _ service.Notifier = (*Telegram)(nil)
+
+// Telegram sends messages through a Telegram bot
+// to participants which have a chat with it.
+type Telegram struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// NewTelegram creates a new Telegram notifier of the bot with the token.
+func NewTelegram(token string, client *http.Client) *Telegram {
+	return &Telegram{
+		apiURL: TelegramAPIURL,
+		token:  token,
+		client: client,
+	}
+}
+
+type telegramRequest struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+	Result      struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"result"`
+}
+
+// Channel returns "telegram".
+func (t *Telegram) Channel() string {
+	return "telegram"
+}
+
+// Notify sends the message to the chat of the recipient with the bot.
+func (t *Telegram) Notify(m *service.Message) (string, error) {
+	if m.Recipient.TelegramChatID == "" {
+		return "", service.ErrNoAddress
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.apiURL, t.token)
+
+	var resp telegramResponse
+
+	err := postJSON(t.client, url, nil, telegramRequest{
+		ChatID: m.Recipient.TelegramChatID,
+		Text:   m.Text,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if !resp.OK {
+		return "", fmt.Errorf("%w: %s", ErrUnexpectedResponse, resp.Description)
+	}
+
+	return strconv.FormatInt(resp.Result.MessageID, 10), nil
+}
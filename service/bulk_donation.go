@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBulkDonationRejected is reported for valid donations of an atomic bulk
+// request which are not created because other donations are invalid.
+var ErrBulkDonationRejected = errors.New("bulk donation rejected")
+
+// BulkDonationRequest is a request for creating donations
+// to several prizes of a raffle at once.
+type BulkDonationRequest struct {
+	// Donations are limited by the number of writes in a single Firestore batch.
+	Donations []PrizeDonationRequest `json:"donations" validate:"required,min=1,max=500"`
+	// Atomic makes the whole request fail if any of the donations is invalid.
+	// Otherwise, valid donations are created and failures are reported per donation.
+	Atomic bool `json:"atomic"`
+}
+
+func (r *BulkDonationRequest) Validate() error {
+	return validateStruct(r)
+}
+
+// PrizeDonationRequest is a request for creating a donation to a prize.
+type PrizeDonationRequest struct {
+	PrizeID string `json:"prizeId" validate:"required"`
+	DonationRequest
+}
+
+func (r *PrizeDonationRequest) Validate() error {
+	return validateStruct(r)
+}
+
+// BulkDonationResult is a result of a bulk request.
+// Results are in the order of the requested donations.
+type BulkDonationResult struct {
+	Donations []BulkDonationItemResult `json:"donations"`
+}
+
+// BulkDonationItemResult is a result of creating a single donation.
+type BulkDonationItemResult struct {
+	PrizeID string `json:"prizeId"`
+	ID      string `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CreateDonations creates donations to prizes of the raffle in a single batch.
+func (rm *RaffleManager) CreateDonations(id string, r *BulkDonationRequest) (*BulkDonationResult, error) {
+	raffle, err := rm.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get raffle: %w", err)
+	}
+
+	if raffle.IsClosed(timeNow()) {
+		return nil, ErrRaffleClosed
+	}
+
+	var result *BulkDonationResult
+
+	err = rm.milestoneTracker(id).track(func() (err error) {
+		result, err = rm.prizeManager(id).createDonations(r, raffle.BaseCurrency())
+		return err
+	})
+
+	return result, err
+}
+
+// createDonations validates all the donations before writing any of them,
+// so that valid ones are written at once.
+func (pm *PrizeManager) createDonations(r *BulkDonationRequest, baseCurrency string) (*BulkDonationResult, error) {
+	if err := r.Validate(); err != nil {
+		return nil, errors.Join(err, ErrInvalidRequest)
+	}
+
+	prizes, err := pm.prizeStorage.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all prizes: %w", err)
+	}
+
+	participants, err := pm.participantStorage.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all participants: %w", err)
+	}
+
+	prizesByID := make(map[string]*Prize, len(prizes))
+	for i := range prizes {
+		prizesByID[prizes[i].ID] = &prizes[i]
+	}
+
+	participantIDs := make(map[string]bool, len(participants))
+	for _, p := range participants {
+		participantIDs[p.ID] = true
+	}
+
+	result := &BulkDonationResult{Donations: make([]BulkDonationItemResult, len(r.Donations))}
+	donations := make(map[string][]Donation)
+	created := make([]*BulkDonationItemResult, 0, len(r.Donations))
+	rejected := false
+
+	for i := range r.Donations {
+		item := &r.Donations[i]
+		itemResult := &result.Donations[i]
+		itemResult.PrizeID = item.PrizeID
+
+		if err := validateDonation(item, prizesByID, participantIDs); err != nil {
+			itemResult.Error = err.Error()
+			rejected = true
+			continue
+		}
+
+		donation, err := toDonation(&item.DonationRequest, baseCurrency)
+		if err != nil {
+			itemResult.Error = err.Error()
+			rejected = true
+			continue
+		}
+		donations[item.PrizeID] = append(donations[item.PrizeID], *donation)
+
+		itemResult.ID = donation.ID
+		created = append(created, itemResult)
+	}
+
+	if rejected && r.Atomic {
+		for _, itemResult := range created {
+			itemResult.ID = ""
+			itemResult.Error = ErrBulkDonationRejected.Error()
+		}
+
+		return result, nil
+	}
+
+	if len(donations) == 0 {
+		return result, nil
+	}
+
+	if err := pm.prizeStorage.CreateDonations(donations); err != nil {
+		return nil, fmt.Errorf("create donations: %w", err)
+	}
+
+	for _, itemResult := range created {
+		for _, donation := range donations[itemResult.PrizeID] {
+			if donation.ID == itemResult.ID {
+				pm.events.publish(EventDonationCreated, DonationCreated{PrizeID: itemResult.PrizeID, Donation: donation})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func validateDonation(r *PrizeDonationRequest, prizes map[string]*Prize, participants map[string]bool) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	prize, ok := prizes[r.PrizeID]
+	if !ok {
+		return fmt.Errorf("prize %q: %w", r.PrizeID, ErrNotFound)
+	}
+
+	if prize.IsPlayed() {
+		return ErrEditPlayedPrizeDonations
+	}
+
+	if !participants[r.ParticipantID] {
+		return fmt.Errorf("participant %q: %w", r.ParticipantID, ErrNotFound)
+	}
+
+	return nil
+}
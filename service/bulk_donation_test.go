@@ -0,0 +1,147 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateDonations(t *testing.T) {
+	now := time.Now().UTC()
+	setTimeNowMock(now)
+	setUUIDMock("donation_id")
+
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	participantStorage := NewMockParticipantStorage(ctrl)
+
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(participantStorage).AnyTimes()
+
+	prizes := []Prize{
+		{ID: "prize_1"},
+		{ID: "prize_2"},
+		{ID: "played", PlayResult: dummyPlayResult()},
+	}
+	participants := []Participant{{ID: "olena"}}
+
+	donation := func(prizeID string, amount int) PrizeDonationRequest {
+		return PrizeDonationRequest{
+			PrizeID:         prizeID,
+			DonationRequest: DonationRequest{Amount: amount, ParticipantID: "olena"},
+		}
+	}
+
+	rm := NewRaffleManager(raffleStorage)
+
+	t.Run("across_prizes", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		prizeStorage.EXPECT().GetAll().Return(prizes, nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil)
+		prizeStorage.EXPECT().CreateDonations(map[string][]Donation{
+			"prize_1": {
+				{ID: "donation_id", ParticipantID: "olena", Amount: 100, Method: PaymentCash, CreatedAt: now},
+				{ID: "donation_id", ParticipantID: "olena", Amount: 50, Method: PaymentCash, CreatedAt: now},
+			},
+			"prize_2": {{ID: "donation_id", ParticipantID: "olena", Amount: 150, Method: PaymentCash, CreatedAt: now}},
+		}).Return(nil)
+
+		result, err := rm.CreateDonations("raffle_1", &BulkDonationRequest{
+			Donations: []PrizeDonationRequest{donation("prize_1", 100), donation("prize_2", 150), donation("prize_1", 50)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []BulkDonationItemResult{
+			{PrizeID: "prize_1", ID: "donation_id"},
+			{PrizeID: "prize_2", ID: "donation_id"},
+			{PrizeID: "prize_1", ID: "donation_id"},
+		}, result.Donations)
+	})
+
+	t.Run("partial_failure", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		prizeStorage.EXPECT().GetAll().Return(prizes, nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil)
+		prizeStorage.EXPECT().CreateDonations(gomock.Len(1)).Return(nil)
+
+		unknownParticipant := donation("prize_2", 100)
+		unknownParticipant.ParticipantID = "taras"
+
+		result, err := rm.CreateDonations("raffle_1", &BulkDonationRequest{
+			Donations: []PrizeDonationRequest{
+				donation("prize_1", 100),
+				donation("played", 100),
+				donation("unknown", 100),
+				donation("prize_2", 0),
+				unknownParticipant,
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Donations, 5)
+
+		assert.Equal(t, "donation_id", result.Donations[0].ID)
+		assert.Empty(t, result.Donations[0].Error)
+
+		for _, r := range result.Donations[1:] {
+			assert.Empty(t, r.ID)
+			assert.NotEmpty(t, r.Error)
+		}
+
+		assert.Equal(t, ErrEditPlayedPrizeDonations.Error(), result.Donations[1].Error)
+		assert.Contains(t, result.Donations[2].Error, ErrNotFound.Error())
+		assert.Equal(t, "amount is required if Original is not given", result.Donations[3].Error)
+		assert.Contains(t, result.Donations[4].Error, ErrNotFound.Error())
+	})
+
+	t.Run("atomic_rejected", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		prizeStorage.EXPECT().GetAll().Return(prizes, nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil)
+
+		result, err := rm.CreateDonations("raffle_1", &BulkDonationRequest{
+			Donations: []PrizeDonationRequest{donation("prize_1", 100), donation("played", 100)},
+			Atomic:    true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []BulkDonationItemResult{
+			{PrizeID: "prize_1", Error: ErrBulkDonationRejected.Error()},
+			{PrizeID: "played", Error: ErrEditPlayedPrizeDonations.Error()},
+		}, result.Donations)
+	})
+
+	t.Run("storage_error", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		prizeStorage.EXPECT().GetAll().Return(prizes, nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil)
+		prizeStorage.EXPECT().CreateDonations(gomock.Any()).Return(assert.AnError)
+
+		result, err := rm.CreateDonations("raffle_1", &BulkDonationRequest{
+			Donations: []PrizeDonationRequest{donation("prize_1", 100)},
+		})
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("raffle_closed", func(t *testing.T) {
+		closedAt := now.Add(-time.Hour)
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1", EndsAt: &closedAt}, nil)
+
+		result, err := rm.CreateDonations("raffle_1", &BulkDonationRequest{
+			Donations: []PrizeDonationRequest{donation("prize_1", 100)},
+		})
+		require.ErrorIs(t, err, ErrRaffleClosed)
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid_request", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+
+		result, err := rm.CreateDonations("raffle_1", &BulkDonationRequest{})
+		require.ErrorIs(t, err, ErrInvalidRequest)
+		assert.Nil(t, result)
+	})
+}
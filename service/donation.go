@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -11,6 +12,7 @@ type DonationService interface {
 	Get(id string) (*Donation, error)
 	List() ([]Donation, error)
 	Edit(id string, d *DonationRequest) error
+	Patch(id string, p *MergePatch) error
 	Delete(id string) error
 }
 
@@ -22,21 +24,87 @@ type DonationStorage interface {
 	Get(id string) (*Donation, error)
 	GetAll() ([]Donation, error)
 	Update(*Donation) error
+	UpdateFields(d *Donation, fields []string) error
 	Delete(id string) error
 }
 
 // Donation represents a donation of the application.
 type Donation struct {
-	ID            string    `json:"id"`
-	ParticipantID string    `json:"participantId"`
-	Amount        int       `json:"amount"`
-	CreatedAt     time.Time `json:"createdAt"`
+	ID            string `json:"id"`
+	ParticipantID string `json:"participantId"`
+	// Amount is in whole units of the base currency of the raffle,
+	// rounded half up from Base if the donation is given as Original.
+	Amount int `json:"amount"`
+	// Base is the exact amount in minor units of the base currency
+	// if the donation is given as Original. Tickets are counted from it.
+	Base *Money `json:"base,omitempty"`
+	// Original is the amount paid if it's given in minor units or in another currency.
+	Original *Money `json:"original,omitempty"`
+	// ExchangeRate converts the original amount to the base currency.
+	ExchangeRate ExchangeRate `json:"exchangeRate,omitempty"`
+	// Method is the way the donation was paid.
+	Method PaymentMethod `json:"method,omitempty"`
+	// EnteredBy is the authenticated user who entered the donation.
+	EnteredBy string `json:"enteredBy,omitempty"`
+	// PaymentID refers to the payment the donation is allocated from.
+	PaymentID string `json:"paymentId,omitempty"`
+	// ShiftID refers to the closed shift which locks the donation.
+	ShiftID   string    `json:"shiftId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the version of the donation, see Versioned.
+	UpdatedAt time.Time `json:"updatedAt" firestore:"-"`
 }
 
 // DonationRequest is a request for creating/updating a donation.
 type DonationRequest struct {
-	Amount        int    `json:"amount"`
-	ParticipantID string `json:"participantId"`
+	Precondition
+
+	// Amount is in whole units of the base currency of the raffle.
+	// It's computed from Original if that is given.
+	Amount int `json:"amount" validate:"required_without=Original,omitempty,gt=0"`
+	// Original is an amount paid in another currency or in minor units.
+	Original *Money `json:"original,omitempty"`
+	// ExchangeRate is required if Original is in another currency than the base one.
+	ExchangeRate  ExchangeRate `json:"exchangeRate,omitempty"`
+	ParticipantID string       `json:"participantId" validate:"required"`
+	// Method defaults to cash.
+	Method PaymentMethod `json:"method" validate:"omitempty,oneof=cash card bank_transfer"`
+	// EnteredBy is set from the authenticated user rather than the request body.
+	EnteredBy string `json:"-"`
+}
+
+func (d *DonationRequest) Validate() error {
+	if err := validateStruct(d); err != nil {
+		return err
+	}
+
+	if d.ExchangeRate != "" {
+		return d.ExchangeRate.Validate()
+	}
+
+	return nil
+}
+
+// baseAmount returns the amount in minor units of the base currency
+// along with the rate it's converted at. It's nil if Original isn't given,
+// since the amount is then given in whole units.
+func (d *DonationRequest) baseAmount(baseCurrency string) (*Money, ExchangeRate, error) {
+	switch {
+	case d.Original == nil:
+		return nil, "", nil
+	case d.Original.Currency == baseCurrency:
+		base, err := d.Original.Convert("1", baseCurrency)
+		return &base, "", err
+	case d.ExchangeRate == "":
+		return nil, "", fmt.Errorf("%w: %s", ErrMissingExchangeRate, d.Original.Currency)
+	}
+
+	base, err := d.Original.Convert(d.ExchangeRate, baseCurrency)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &base, d.ExchangeRate, nil
 }
 
 var (
@@ -49,6 +117,10 @@ var _ DonationService = (*DonationManager)(nil)
 // DonationManager is an implementation of DonationService.
 type DonationManager struct {
 	donationStorage DonationStorage
+
+	// baseCurrency is the currency of the raffle amounts are converted to,
+	// DefaultCurrency if it's not set.
+	baseCurrency string
 }
 
 // NewDonationManager creates a new DonationManager.
@@ -60,7 +132,14 @@ func NewDonationManager(ds DonationStorage) *DonationManager {
 
 // Create creates a new Donation.
 func (dm *DonationManager) Create(d *DonationRequest) (string, error) {
-	donation := toDonation(d)
+	if err := d.Validate(); err != nil {
+		return "", errors.Join(err, ErrInvalidRequest)
+	}
+
+	donation, err := toDonation(d, currencyOrDefault(dm.baseCurrency))
+	if err != nil {
+		return "", errors.Join(err, ErrInvalidRequest)
+	}
 
 	if err := dm.donationStorage.Create(donation); err != nil {
 		return "", err
@@ -71,13 +150,22 @@ func (dm *DonationManager) Create(d *DonationRequest) (string, error) {
 
 // Edit updates a Donation.
 func (dm *DonationManager) Edit(id string, d *DonationRequest) error {
+	if err := d.Validate(); err != nil {
+		return errors.Join(err, ErrInvalidRequest)
+	}
+
 	donation, err := dm.donationStorage.Get(id)
 	if err != nil {
 		return err
 	}
 
-	donation.Amount = d.Amount
-	donation.ParticipantID = d.ParticipantID
+	if err := d.checkVersion(donation); err != nil {
+		return err
+	}
+
+	if err := dm.edit(donation, d); err != nil {
+		return err
+	}
 
 	if err := dm.donationStorage.Update(donation); err != nil {
 		return err
@@ -86,6 +174,27 @@ func (dm *DonationManager) Edit(id string, d *DonationRequest) error {
 	return nil
 }
 
+// edit applies the request to the donation.
+func (dm *DonationManager) edit(donation *Donation, d *DonationRequest) error {
+	if err := checkDonationEditable(donation); err != nil {
+		return err
+	}
+
+	base, rate, err := d.baseAmount(currencyOrDefault(dm.baseCurrency))
+	if err != nil {
+		return errors.Join(err, ErrInvalidRequest)
+	}
+
+	donation.Amount = wholeAmount(d.Amount, base)
+	donation.Base = base
+	donation.Original = d.Original
+	donation.ExchangeRate = rate
+	donation.ParticipantID = d.ParticipantID
+	donation.Method = methodOrDefault(d.Method)
+
+	return nil
+}
+
 // List returns a Donation list.
 func (dm *DonationManager) List() ([]Donation, error) {
 	donations, err := dm.donationStorage.GetAll()
@@ -108,6 +217,15 @@ func (dm *DonationManager) Get(id string) (*Donation, error) {
 
 // Delete deletes a Donation.
 func (dm *DonationManager) Delete(id string) error {
+	donation, err := dm.donationStorage.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkDonationEditable(donation); err != nil {
+		return err
+	}
+
 	if err := dm.donationStorage.Delete(id); err != nil {
 		return err
 	}
@@ -115,11 +233,64 @@ func (dm *DonationManager) Delete(id string) error {
 	return nil
 }
 
-func toDonation(d *DonationRequest) *Donation {
+func toDonation(d *DonationRequest, baseCurrency string) (*Donation, error) {
+	base, rate, err := d.baseAmount(baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Donation{
 		ID:            stringUUID(),
-		Amount:        d.Amount,
+		Amount:        wholeAmount(d.Amount, base),
+		Base:          base,
+		Original:      d.Original,
+		ExchangeRate:  rate,
 		ParticipantID: d.ParticipantID,
+		Method:        methodOrDefault(d.Method),
+		EnteredBy:     d.EnteredBy,
 		CreatedAt:     timeNow(),
+	}, nil
+}
+
+// wholeAmount returns the amount in whole units of the base currency,
+// rounded from the base amount if it's given.
+func wholeAmount(amount int, base *Money) int {
+	if base == nil {
+		return amount
+	}
+
+	return base.Whole()
+}
+
+// fineAmount returns the amount in fine units of the base currency,
+// which is exact for donations given as Original. See fineUnits.
+func (d *Donation) fineAmount() int {
+	if d.Base != nil {
+		return d.Base.fine()
+	}
+
+	return d.Amount * fineUnits
+}
+
+// checkDonationEditable returns an error if the donation
+// can't be changed on its own.
+func checkDonationEditable(d *Donation) error {
+	if d.ShiftID != "" {
+		return ErrShiftClosed
+	}
+
+	if d.PaymentID != "" {
+		return ErrDonationBelongsToPayment
 	}
+
+	return nil
+}
+
+// methodOrDefault treats donations without a method as cash ones.
+func methodOrDefault(m PaymentMethod) PaymentMethod {
+	if m == "" {
+		return PaymentCash
+	}
+
+	return m
 }
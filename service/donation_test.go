@@ -33,6 +33,11 @@ func TestDonationManagerCreateDonation(t *testing.T) {
 		_, err := donationManager.Create(&DonationRequest{Amount: 777, ParticipantID: stringUUID()})
 		require.ErrorIs(t, err, ErrDonationAlreadyExists)
 	})
+
+	t.Run("Add invalid donation", func(t *testing.T) {
+		_, err := manager.Create(&DonationRequest{Amount: -100, ParticipantID: stringUUID()})
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
 }
 
 func TestDonationManagerEditDonation(t *testing.T) {
@@ -44,7 +49,7 @@ func TestDonationManagerEditDonation(t *testing.T) {
 
 	t.Run("Edit donation", func(t *testing.T) {
 		donationRequest := &DonationRequest{Amount: 999, ParticipantID: "participant_test_id"}
-		donation := &Donation{ParticipantID: "participant_test_id", Amount: 999}
+		donation := &Donation{ParticipantID: "participant_test_id", Amount: 999, Method: PaymentCash}
 		storageMock.EXPECT().Get(testID).Return(&Donation{}, nil)
 		storageMock.EXPECT().Update(donation).Return(nil)
 
@@ -58,6 +63,25 @@ func TestDonationManagerEditDonation(t *testing.T) {
 		err := manager.Edit(testID, &DonationRequest{Amount: 999, ParticipantID: "participant_test_id"})
 		require.ErrorIs(t, err, ErrNotFound)
 	})
+
+	t.Run("Edit payment donation", func(t *testing.T) {
+		storageMock.EXPECT().Get(testID).Return(&Donation{ID: testID, PaymentID: "payment_id"}, nil)
+
+		err := manager.Edit(testID, &DonationRequest{Amount: 999, ParticipantID: "participant_test_id"})
+		require.ErrorIs(t, err, ErrDonationBelongsToPayment)
+	})
+
+	t.Run("Edit locked donation", func(t *testing.T) {
+		storageMock.EXPECT().Get(testID).Return(&Donation{ID: testID, ShiftID: "shift_id"}, nil)
+
+		err := manager.Edit(testID, &DonationRequest{Amount: 999, ParticipantID: "participant_test_id"})
+		require.ErrorIs(t, err, ErrShiftClosed)
+	})
+
+	t.Run("Edit with invalid request", func(t *testing.T) {
+		err := manager.Edit(testID, &DonationRequest{Amount: -100, ParticipantID: "participant_test_id"})
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
 }
 
 func TestDonationManagerListDonations(t *testing.T) {
@@ -118,6 +142,7 @@ func TestDonationManagerDeleteDonation(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		id := "donation_id"
+		storageMock.EXPECT().Get(id).Return(&Donation{ID: id}, nil)
 		storageMock.EXPECT().Delete(id).Return(nil)
 
 		err := manager.Delete(id)
@@ -126,9 +151,25 @@ func TestDonationManagerDeleteDonation(t *testing.T) {
 
 	t.Run("Error", func(t *testing.T) {
 		id := "donation_id"
-		storageMock.EXPECT().Delete(id).Return(ErrNotFound)
+		storageMock.EXPECT().Get(id).Return(nil, ErrNotFound)
 
 		err := manager.Delete(id)
 		require.ErrorIs(t, err, ErrNotFound)
 	})
+
+	t.Run("Payment donation", func(t *testing.T) {
+		id := "donation_id"
+		storageMock.EXPECT().Get(id).Return(&Donation{ID: id, PaymentID: "payment_id"}, nil)
+
+		err := manager.Delete(id)
+		require.ErrorIs(t, err, ErrDonationBelongsToPayment)
+	})
+
+	t.Run("Locked donation", func(t *testing.T) {
+		id := "donation_id"
+		storageMock.EXPECT().Get(id).Return(&Donation{ID: id, ShiftID: "shift_id"}, nil)
+
+		err := manager.Delete(id)
+		require.ErrorIs(t, err, ErrShiftClosed)
+	})
 }
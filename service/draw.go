@@ -0,0 +1,315 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DrawStrategy defines how the chances of participants
+// to win a prize are calculated.
+type DrawStrategy string
+
+const (
+	// DrawByTickets gives a chance for each full TicketCost donated.
+	// The remainder of donations is discarded. It is the default strategy.
+	DrawByTickets DrawStrategy = "tickets"
+
+	// DrawByAmount gives chances proportional to the donated amount.
+	// Any donation makes a participant eligible.
+	DrawByAmount DrawStrategy = "amount"
+
+	// DrawWithCarryOver works like DrawByTickets, but the remainders
+	// of donations to the carry-over prizes created earlier are added up
+	// to the donations to this prize. A remainder is carried only
+	// into the next such prize the participant donated to, so it's used once.
+	DrawWithCarryOver DrawStrategy = "carry_over"
+
+	// DrawEqual gives an equal chance to each participant
+	// with at least one ticket.
+	DrawEqual DrawStrategy = "equal"
+)
+
+// WeightFunc returns the weight of a participant's chance to win.
+type WeightFunc func(PlayParticipant) int
+
+// drawWeights maps draw strategies to weight functions.
+// New strategies are plugged in by registering them here.
+var drawWeights = map[DrawStrategy]func(*Prize) WeightFunc{
+	DrawByTickets:     func(*Prize) WeightFunc { return ticketsWeight },
+	DrawWithCarryOver: func(*Prize) WeightFunc { return ticketsWeight },
+	DrawEqual:         func(*Prize) WeightFunc { return equalWeight },
+	DrawByAmount:      amountWeight,
+}
+
+func ticketsWeight(p PlayParticipant) int {
+	return p.TotalTicketsNumber
+}
+
+func equalWeight(PlayParticipant) int {
+	return 1
+}
+
+// amountWeight weighs participants by the donated amount,
+// limited to the cost of the maximum number of tickets if it is set.
+func amountWeight(prize *Prize) WeightFunc {
+	return func(p PlayParticipant) int {
+		limit := prize.MaxTickets * prize.TicketCost
+		if limit > 0 && p.TotalDonation > limit {
+			return limit
+		}
+
+		return p.TotalDonation
+	}
+}
+
+// strategy returns the draw strategy of the prize.
+func (p *Prize) strategy() DrawStrategy {
+	if p.DrawStrategy == "" {
+		return DrawByTickets
+	}
+
+	return p.DrawStrategy
+}
+
+// weightFunc returns the weight function of the prize draw strategy.
+func (p *Prize) weightFunc() WeightFunc {
+	weight, ok := drawWeights[p.strategy()]
+	if !ok {
+		return ticketsWeight
+	}
+
+	return weight(p)
+}
+
+// ticketCounter counts tickets of participants for a prize.
+type ticketCounter struct {
+	ticketCost int
+	maxTickets int
+
+	// anyAmount makes any donation eligible even if it is less than ticketCost.
+	anyAmount bool
+
+	// carry holds amounts in fine units carried over from other prizes
+	// by participant ID. They are added only to donations of participants
+	// who donated to the prize.
+	carry map[string]int
+}
+
+// count counts donations, total amount and total tickets count for each participant.
+// Tickets are counted from exact amounts, so converted donations
+// which add up to the ticket cost make a ticket.
+func (tc ticketCounter) count(donations []Donation, participants []Participant) []PlayParticipant {
+	donationsMap := make(map[string][]Donation)
+
+	for _, d := range donations {
+		donationsMap[d.ParticipantID] = append(donationsMap[d.ParticipantID], d)
+	}
+
+	result := make([]PlayParticipant, 0, len(donationsMap))
+
+	for _, participant := range participants {
+		donations := donationsMap[participant.ID]
+		totalDonation := countTotalDonation(donations)
+		fineDonation := countFineDonation(donations)
+
+		carried := 0
+		if fineDonation > 0 {
+			carried = tc.carry[participant.ID]
+		}
+
+		ticketsNumber := (fineDonation + carried) / (tc.ticketCost * fineUnits)
+		if tc.maxTickets > 0 && ticketsNumber > tc.maxTickets {
+			ticketsNumber = tc.maxTickets
+		}
+
+		eligible := ticketsNumber > 0 || (tc.anyAmount && totalDonation > 0)
+		if !eligible {
+			continue
+		}
+
+		result = append(result, PlayParticipant{
+			Participant:        participant,
+			TotalDonation:      totalDonation,
+			TotalTicketsNumber: ticketsNumber,
+			Donations:          donations,
+		})
+	}
+
+	return result
+}
+
+// ticketCounter creates a ticketCounter for the prize draw strategy.
+func (pm *PrizeManager) ticketCounter(prize *Prize) (ticketCounter, error) {
+	counter := ticketCounter{
+		ticketCost: prize.TicketCost,
+		maxTickets: prize.MaxTickets,
+		anyAmount:  prize.strategy() == DrawByAmount,
+	}
+
+	if prize.strategy() == DrawWithCarryOver {
+		carry, err := pm.carriedRemainders(prize)
+		if err != nil {
+			return ticketCounter{}, fmt.Errorf("count carried remainders: %w", err)
+		}
+
+		counter.carry = carry
+	}
+
+	return counter, nil
+}
+
+// carriedRemainders calculates remainders in fine units of donations of each participant
+// that didn't make up a full ticket in the carry-over prizes created before the given one.
+// The remainder of a prize is added to the donations to the next carry-over prize
+// the participant donated to, where it's turned into tickets or carried further,
+// so remainders of participants who didn't donate to a prize pass it unused.
+// Prizes drawn with other strategies discard their remainders, so they neither
+// take nor pass carried amounts. Raffle donations count for prizes using them.
+func (pm *PrizeManager) carriedRemainders(prize *Prize) (map[string]int, error) {
+	prizes, err := pm.prizeStorage.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all prizes: %w", err)
+	}
+
+	sort.SliceStable(prizes, func(i, j int) bool {
+		return prizes[i].CreatedAt.Before(prizes[j].CreatedAt)
+	})
+
+	carry := make(map[string]int)
+
+	for _, earlier := range prizes {
+		if earlier.ID == prize.ID || !earlier.CreatedAt.Before(prize.CreatedAt) || earlier.strategy() != DrawWithCarryOver {
+			continue
+		}
+
+		donations, err := pm.prizeDonations(&earlier)
+		if err != nil {
+			return nil, fmt.Errorf("get donations of prize %q: %w", earlier.ID, err)
+		}
+
+		totals := make(map[string]int)
+		for _, d := range donations {
+			totals[d.ParticipantID] += d.fineAmount()
+		}
+
+		for participantID, total := range totals {
+			carry[participantID] = (total + carry[participantID]) % (earlier.TicketCost * fineUnits)
+		}
+	}
+
+	return carry, nil
+}
+
+// ExcludedParticipant is a participant left out of a draw by the draw policy.
+type ExcludedParticipant struct {
+	ParticipantID string `json:"participantId"`
+	PrizesWon     int    `json:"prizesWon"`
+}
+
+// draw draws a winner of the prize among the participants allowed
+// by the draw policy. Excluded participants stay in the pool,
+// so they can take part in later draws if the policy allows.
+func (pm *PrizeManager) draw(prize *Prize) (*PrizePlayResult, error) {
+	return pm.drawAmong(prize, pm.prizeStorage.GetAll)
+}
+
+// drawAmong works like draw, but takes the prizes of the raffle
+// to count previous wins from the given source.
+func (pm *PrizeManager) drawAmong(prize *Prize, prizes func() ([]Prize, error)) (*PrizePlayResult, error) {
+	participants, err := pm.prepareParticipants(prize)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := pm.prizesLimit(prize)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit == 0 {
+		result := prize.Play(participants, pm.randomizer)
+		result.Excluded = nil
+
+		return result, nil
+	}
+
+	all, err := prizes()
+	if err != nil {
+		return nil, fmt.Errorf("get prizes to count wins: %w", err)
+	}
+
+	wins := otherPrizesWins(prize, all)
+
+	eligible := make([]PlayParticipant, 0, len(participants))
+	left := make([]PlayParticipant, 0)
+	excluded := make([]ExcludedParticipant, 0)
+
+	for _, p := range participants {
+		if won := wins[p.Participant.ID]; won >= limit {
+			left = append(left, p)
+			excluded = append(excluded, ExcludedParticipant{
+				ParticipantID: p.Participant.ID,
+				PrizesWon:     won,
+			})
+
+			continue
+		}
+
+		eligible = append(eligible, p)
+	}
+
+	if len(eligible) == 0 {
+		return nil, fmt.Errorf("%w: all of them are excluded by draw policy", ErrNoParticipants)
+	}
+
+	result := prize.Play(eligible, pm.randomizer)
+	result.PlayParticipants = append(result.PlayParticipants, left...)
+	result.Excluded = excluded
+
+	return result, nil
+}
+
+// prizesLimit returns the number of prizes a participant can win
+// before being excluded from the draw of the prize. Zero means no limit.
+func (pm *PrizeManager) prizesLimit(prize *Prize) (int, error) {
+	limit := 0
+	if prize.ExcludeWinners {
+		limit = 1
+	}
+
+	raffle, err := pm.raffle()
+	if err != nil {
+		return 0, fmt.Errorf("get raffle: %w", err)
+	}
+
+	if raffle == nil {
+		return limit, nil
+	}
+
+	if raffleLimit := raffle.prizesLimit(); raffleLimit > 0 && (limit == 0 || raffleLimit < limit) {
+		limit = raffleLimit
+	}
+
+	return limit, nil
+}
+
+// otherPrizesWins counts prizes other than the given one won by each participant.
+// Forfeited prizes are not counted.
+func otherPrizesWins(prize *Prize, prizes []Prize) map[string]int {
+	wins := make(map[string]int)
+	for _, p := range prizes {
+		if p.ID == prize.ID || p.PlayResult == nil {
+			continue
+		}
+
+		for _, winner := range p.PlayResult.Winners {
+			if winner.Claim != nil && winner.Claim.Status == ClaimForfeited {
+				continue
+			}
+
+			wins[winner.Participant.ID]++
+		}
+	}
+
+	return wins
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGenerateWeightedWinner(t *testing.T) {
+	participants := []PlayParticipant{
+		{Participant: Participant{ID: "p1"}, TotalDonation: 150, TotalTicketsNumber: 1},
+		{Participant: Participant{ID: "p2"}, TotalDonation: 0, TotalTicketsNumber: 0},
+		{Participant: Participant{ID: "p3"}, TotalDonation: 300, TotalTicketsNumber: 3},
+	}
+
+	testCases := map[string]struct {
+		weight   WeightFunc
+		point    uint
+		expected string
+		total    uint
+	}{
+		"first_ticket":       {weight: ticketsWeight, point: 0, expected: "p1", total: 4},
+		"skip_zero_weight":   {weight: ticketsWeight, point: 1, expected: "p3", total: 4},
+		"last_ticket":        {weight: ticketsWeight, point: 3, expected: "p3", total: 4},
+		"amount_first":       {weight: amountWeight(&Prize{}), point: 149, expected: "p1", total: 450},
+		"amount_second":      {weight: amountWeight(&Prize{}), point: 150, expected: "p3", total: 450},
+		"equal_last":         {weight: equalWeight, point: 2, expected: "p3", total: 3},
+		"equal_zero_tickets": {weight: equalWeight, point: 1, expected: "p2", total: 3},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var r Randomizer = func(n uint) uint {
+				require.Equal(t, tc.total, n)
+				return tc.point
+			}
+
+			assert.Equal(t, tc.expected, r.GenerateWeightedWinner(participants, tc.weight))
+		})
+	}
+}
+
+func TestAmountWeightCap(t *testing.T) {
+	weight := amountWeight(&Prize{TicketCost: 100, MaxTickets: 2})
+
+	assert.Equal(t, 150, weight(PlayParticipant{TotalDonation: 150}))
+	assert.Equal(t, 200, weight(PlayParticipant{TotalDonation: 1000}))
+}
+
+func TestTicketCounter(t *testing.T) {
+	participants := []Participant{{ID: "p1"}, {ID: "p2"}, {ID: "p3"}}
+	donations := []Donation{
+		{ID: "dn1", ParticipantID: "p1", Amount: 50},
+		{ID: "dn2", ParticipantID: "p2", Amount: 1000},
+		{ID: "dn3", ParticipantID: "p3", Amount: 180},
+	}
+
+	tickets := func(result []PlayParticipant) map[string]int {
+		m := make(map[string]int)
+		for _, p := range result {
+			m[p.Participant.ID] = p.TotalTicketsNumber
+		}
+		return m
+	}
+
+	t.Run("tickets", func(t *testing.T) {
+		result := ticketCounter{ticketCost: 100}.count(donations, participants)
+		assert.Equal(t, map[string]int{"p2": 10, "p3": 1}, tickets(result))
+	})
+
+	t.Run("max_tickets", func(t *testing.T) {
+		result := ticketCounter{ticketCost: 100, maxTickets: 3}.count(donations, participants)
+		assert.Equal(t, map[string]int{"p2": 3, "p3": 1}, tickets(result))
+	})
+
+	t.Run("any_amount", func(t *testing.T) {
+		result := ticketCounter{ticketCost: 100, anyAmount: true}.count(donations, participants)
+		assert.Equal(t, map[string]int{"p1": 0, "p2": 10, "p3": 1}, tickets(result))
+	})
+
+	t.Run("carry_over", func(t *testing.T) {
+		carry := map[string]int{"p1": 50 * fineUnits, "p3": 30 * fineUnits}
+		result := ticketCounter{ticketCost: 100, carry: carry}.count(donations, participants)
+		assert.Equal(t, map[string]int{"p1": 1, "p2": 10, "p3": 2}, tickets(result))
+	})
+
+	t.Run("converted_donations", func(t *testing.T) {
+		converted := []Donation{
+			{ParticipantID: "p1", Amount: 1, Base: &Money{Amount: 60, Currency: "UAH"}},
+			{ParticipantID: "p1", Amount: 1, Base: &Money{Amount: 50, Currency: "UAH"}},
+			{ParticipantID: "p2", Amount: 13, Base: &Money{Amount: 1250, Currency: "UAH"}},
+		}
+
+		// p1 donated 1.10 rather than 2 and p2 donated 12.50 rather than 13.
+		result := ticketCounter{ticketCost: 1, anyAmount: true}.count(converted, participants)
+		assert.Equal(t, map[string]int{"p1": 1, "p2": 12}, tickets(result))
+	})
+
+	t.Run("carry_without_donation", func(t *testing.T) {
+		carry := map[string]int{"p1": 150 * fineUnits}
+		result := ticketCounter{ticketCost: 100, carry: carry}.count(donations[1:], participants)
+		assert.Equal(t, map[string]int{"p2": 10, "p3": 1}, tickets(result))
+	})
+}
+
+func TestCarryOverUsedOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	manager := NewPrizeManager(prizeStorage, NewMockParticipantStorage(ctrl))
+
+	now := time.Now().UTC()
+	first := Prize{ID: "first", TicketCost: 100, CreatedAt: now.Add(-2 * time.Hour), DrawStrategy: DrawWithCarryOver}
+	second := Prize{ID: "second", TicketCost: 50, CreatedAt: now.Add(-time.Hour), DrawStrategy: DrawWithCarryOver}
+	third := Prize{ID: "third", TicketCost: 50, CreatedAt: now, DrawStrategy: DrawWithCarryOver}
+	prizes := []Prize{first, second, third}
+
+	participants := []Participant{{ID: "p1"}, {ID: "p2"}}
+	donations := map[string][]Donation{
+		// Both leave 90 over.
+		first.ID: {{ParticipantID: "p1", Amount: 190}, {ParticipantID: "p2", Amount: 190}},
+		// p1 uses the leftover here, p2 doesn't donate.
+		second.ID: {{ParticipantID: "p1", Amount: 10}},
+		third.ID:  {{ParticipantID: "p1", Amount: 10}, {ParticipantID: "p2", Amount: 10}},
+	}
+
+	storages := make(map[string]*MockDonationStorage)
+	for _, p := range prizes {
+		storages[p.ID] = NewMockDonationStorage(ctrl)
+		storages[p.ID].EXPECT().GetAll().Return(donations[p.ID], nil).AnyTimes()
+		prizeStorage.EXPECT().DonationStorage(p.ID).Return(storages[p.ID]).AnyTimes()
+	}
+	prizeStorage.EXPECT().GetAll().Return(prizes, nil).AnyTimes()
+
+	tickets := func(prize *Prize) map[string]int {
+		counter, err := manager.ticketCounter(prize)
+		require.NoError(t, err)
+
+		m := make(map[string]int)
+		for _, p := range counter.count(donations[prize.ID], participants) {
+			m[p.Participant.ID] = p.TotalTicketsNumber
+		}
+		return m
+	}
+
+	// p1: (10 + 90) / 50 = 2, p2 has no donations to the second prize.
+	assert.Equal(t, map[string]int{"p1": 2}, tickets(&second))
+	// p1: the leftover is used up, 10 / 50 = 0; p2: (10 + 90) / 50 = 2.
+	assert.Equal(t, map[string]int{"p2": 2}, tickets(&third))
+}
+
+func TestCarriedRemainders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	manager := NewPrizeManager(prizeStorage, NewMockParticipantStorage(ctrl))
+	manager.raffleID = "raffle_1"
+	manager.raffleStorage = raffleStorage
+
+	now := time.Now().UTC()
+	first := Prize{ID: "first", TicketCost: 100, CreatedAt: now.Add(-3 * time.Hour), DrawStrategy: DrawWithCarryOver}
+	tickets := Prize{ID: "tickets", TicketCost: 30, CreatedAt: now.Add(-2 * time.Hour)}
+	amount := Prize{ID: "amount", TicketCost: 70, CreatedAt: now.Add(-90 * time.Minute), DrawStrategy: DrawByAmount}
+	second := Prize{ID: "second", TicketCost: 30, CreatedAt: now.Add(-time.Hour), DrawStrategy: DrawWithCarryOver, UseRaffleTickets: true}
+	current := Prize{ID: "current", TicketCost: 100, CreatedAt: now, DrawStrategy: DrawWithCarryOver}
+	later := Prize{ID: "later", TicketCost: 100, CreatedAt: now.Add(time.Hour), DrawStrategy: DrawWithCarryOver}
+
+	firstDonations := NewMockDonationStorage(ctrl)
+	secondDonations := NewMockDonationStorage(ctrl)
+	raffleDonations := NewMockDonationStorage(ctrl)
+
+	// Prizes drawn with other strategies are not read at all.
+	prizeStorage.EXPECT().GetAll().Return([]Prize{later, current, second, amount, tickets, first}, nil)
+	prizeStorage.EXPECT().DonationStorage(first.ID).Return(firstDonations)
+	prizeStorage.EXPECT().DonationStorage(second.ID).Return(secondDonations)
+	raffleStorage.EXPECT().DonationStorage("raffle_1").Return(raffleDonations)
+
+	firstDonations.EXPECT().GetAll().Return([]Donation{
+		{ParticipantID: "p1", Amount: 120},
+		{ParticipantID: "p1", Amount: 30},
+		{ParticipantID: "p2", Amount: 270},
+	}, nil)
+	secondDonations.EXPECT().GetAll().Return([]Donation{
+		{ParticipantID: "p3", Amount: 40},
+	}, nil)
+	raffleDonations.EXPECT().GetAll().Return([]Donation{
+		{ParticipantID: "p1", Amount: 10},
+	}, nil)
+
+	carry, err := manager.carriedRemainders(&current)
+	require.NoError(t, err)
+
+	// p1: (150 % 100) = 50 passes the tickets and amount prizes,
+	// then (10 donated to the raffle + 50) % 30 = 0
+	// p2: 270 % 100 = 70, didn't donate to the second prize
+	// p3: 40 % 30 = 10
+	assert.Equal(t, map[string]int{"p1": 0, "p2": 70 * fineUnits, "p3": 10 * fineUnits}, carry)
+
+	t.Run("error", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(nil, assert.AnError)
+
+		carry, err := manager.carriedRemainders(&current)
+		require.ErrorIs(t, err, assert.AnError)
+		require.Nil(t, carry)
+	})
+}
+
+func BenchmarkGenerateWinner(b *testing.B) {
+	participants := make([]PlayParticipant, 1000)
+	for i := range participants {
+		participants[i] = PlayParticipant{TotalTicketsNumber: 10_000}
+	}
+
+	r := NewSimpleRandomizer()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r.GenerateWinner(participants, 1)
+	}
+}
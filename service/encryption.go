@@ -0,0 +1,21 @@
+package service
+
+// KeyRotation is a result of re-encrypting personal data encrypted at rest.
+type KeyRotation struct {
+	// Key is the ID of the key the data is encrypted with now.
+	Key string `json:"key"`
+	// Participants and Prizes are the numbers of re-encrypted documents.
+	Participants int `json:"participants"`
+	Prizes       int `json:"prizes"`
+	// Deliveries is the number of webhook deliveries
+	// which payloads had contacts of winners removed.
+	Deliveries int `json:"deliveries"`
+}
+
+// KeyRotator re-encrypts personal data encrypted at rest with the current key,
+// so that previous keys can be retired. Data written before the encryption
+// was enabled gets encrypted as well, and phones are removed from
+// webhook payloads which are kept in plaintext.
+type KeyRotator interface {
+	RotateKeys() (*KeyRotation, error)
+}
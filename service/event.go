@@ -0,0 +1,149 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType is a type of raffle events webhooks can subscribe to.
+type EventType string
+
+const (
+	EventDonationCreated EventType = "donation.created"
+	EventPaymentCreated  EventType = "payment.created"
+	EventPrizePlayed     EventType = "prize.played"
+	EventRaffleClosed    EventType = "raffle.closed"
+)
+
+// Event is something that happened in a raffle of an organizer.
+type Event struct {
+	ID          string    `json:"id"`
+	Type        EventType `json:"type"`
+	OrganizerID string    `json:"organizerId"`
+	RaffleID    string    `json:"raffleId"`
+	// Data is DonationCreated, Payment, PrizePlayed or Raffle
+	// depending on the type.
+	Data      any       `json:"data"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DonationCreated is the data of donation.created events.
+type DonationCreated struct {
+	// PrizeID is empty for donations made to the whole raffle.
+	PrizeID  string   `json:"prizeId,omitempty"`
+	Donation Donation `json:"donation"`
+}
+
+// PrizePlayed is the data of prize.played events.
+type PrizePlayed struct {
+	PrizeID   string          `json:"prizeId"`
+	PrizeName string          `json:"prizeName"`
+	Winner    PlayParticipant `json:"winner"`
+}
+
+// EventHook is called for every event.
+type EventHook func(Event)
+
+// EventHooks are hooks subscribed to raffle events.
+type EventHooks struct {
+	mu    sync.RWMutex
+	hooks []EventHook
+}
+
+// Subscribe subscribes the hook to raffle events.
+func (h *EventHooks) Subscribe(hook EventHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hooks = append(h.hooks, hook)
+}
+
+func (h *EventHooks) subscribed() bool {
+	if h == nil {
+		return false
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.hooks) > 0
+}
+
+func (h *EventHooks) emit(e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, hook := range h.hooks {
+		hook(e)
+	}
+}
+
+// raffleEvents publishes events of a raffle of an organizer.
+type raffleEvents struct {
+	organizerID string
+	raffleID    string
+	hooks       *EventHooks
+}
+
+// publish emits an event of the type with the data.
+// It's a no-op for nil raffleEvents.
+func (e *raffleEvents) publish(eventType EventType, data any) {
+	if e == nil {
+		return
+	}
+
+	e.hooks.emit(Event{
+		ID:          stringUUID(),
+		Type:        eventType,
+		OrganizerID: e.organizerID,
+		RaffleID:    e.raffleID,
+		Data:        data,
+		CreatedAt:   timeNow(),
+	})
+}
+
+// EventDonationService is a DonationService
+// that publishes donation.created events.
+type EventDonationService struct {
+	DonationService
+	prizeID string
+	events  *raffleEvents
+}
+
+// Create creates a donation and publishes it.
+// Events are best effort: the donation is created
+// even if it can't be read back to be published.
+func (s *EventDonationService) Create(d *DonationRequest) (string, error) {
+	id, err := s.DonationService.Create(d)
+	if err != nil {
+		return "", err
+	}
+
+	if donation, err := s.DonationService.Get(id); err == nil {
+		s.events.publish(EventDonationCreated, DonationCreated{PrizeID: s.prizeID, Donation: *donation})
+	}
+
+	return id, nil
+}
+
+// EventPaymentService is a PaymentService
+// that publishes payment.created events.
+type EventPaymentService struct {
+	PaymentService
+	events *raffleEvents
+}
+
+// Create creates a payment and publishes it.
+// Events are best effort like in EventDonationService.
+func (s *EventPaymentService) Create(r *PaymentRequest) (string, error) {
+	id, err := s.PaymentService.Create(r)
+	if err != nil {
+		return "", err
+	}
+
+	if payment, err := s.PaymentService.Get(id); err == nil {
+		s.events.publish(EventPaymentCreated, *payment)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GoalMilestones are percentages of a goal a milestone event is emitted at.
+var GoalMilestones = []int{25, 50, 75, 100}
+
+// Goal is an amount to be raised by a raffle or a prize.
+type Goal struct {
+	Amount   int    `json:"amount" validate:"gte=1"`
+	Currency string `json:"currency,omitempty" validate:"omitempty,iso4217"`
+}
+
+// GoalProgress is the progress of raising a goal amount.
+type GoalProgress struct {
+	Raised  int     `json:"raised"`
+	Percent float64 `json:"percent"`
+	Reached bool    `json:"reached"`
+}
+
+// Progress returns the progress of the goal given the raised amount.
+func (g *Goal) Progress(raised int) *GoalProgress {
+	return &GoalProgress{
+		Raised:  raised,
+		Percent: float64(raised) * 100 / float64(g.Amount),
+		Reached: raised >= g.Amount,
+	}
+}
+
+// milestones returns milestones passed by raising from the amount before to the amount after.
+func (g *Goal) milestones(before, after int) []int {
+	passed := make([]int, 0)
+
+	for _, percent := range GoalMilestones {
+		threshold := g.Amount * percent
+		if before*100 < threshold && after*100 >= threshold {
+			passed = append(passed, percent)
+		}
+	}
+
+	return passed
+}
+
+// MilestoneEvent is emitted when donations pass a milestone of a goal.
+type MilestoneEvent struct {
+	RaffleID string `json:"raffleId"`
+	// PrizeID is empty for goals of the whole raffle.
+	PrizeID   string    `json:"prizeId,omitempty"`
+	Percent   int       `json:"percent"`
+	Goal      Goal      `json:"goal"`
+	Raised    int       `json:"raised"`
+	ReachedAt time.Time `json:"reachedAt"`
+}
+
+// GoalReached reports whether the milestone is the goal being reached.
+func (e *MilestoneEvent) GoalReached() bool {
+	return e.Percent >= 100
+}
+
+// MilestoneHook is called for every milestone event.
+type MilestoneHook func(MilestoneEvent)
+
+// MilestoneHooks are hooks subscribed to milestone events.
+type MilestoneHooks struct {
+	mu    sync.RWMutex
+	hooks []MilestoneHook
+}
+
+// Subscribe subscribes the hook to milestone events.
+func (h *MilestoneHooks) Subscribe(hook MilestoneHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hooks = append(h.hooks, hook)
+}
+
+func (h *MilestoneHooks) subscribed() bool {
+	if h == nil {
+		return false
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.hooks) > 0
+}
+
+func (h *MilestoneHooks) emit(e MilestoneEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, hook := range h.hooks {
+		hook(e)
+	}
+}
+
+// raffleProgress fills in the progress of the raffle goal.
+func (rm *RaffleManager) raffleProgress(r *Raffle) error {
+	if r.Goal == nil {
+		return nil
+	}
+
+	counters, err := rm.raffleStorage.StatsStorage(r.ID).Get()
+	if err != nil {
+		return fmt.Errorf("get counters: %w", err)
+	}
+
+	r.Progress = r.Goal.Progress(counters.Raised)
+
+	return nil
+}
+
+// prizeProgress fills in the progress of the prize goal.
+func prizeProgress(p *Prize) {
+	if p.Goal != nil {
+		p.Progress = p.Goal.Progress(p.TotalDonated)
+	}
+}
+
+// milestoneTracker emits milestone events for goals of a raffle
+// passed by donations written within track.
+type milestoneTracker struct {
+	raffleID      string
+	raffleStorage RaffleStorage
+	hooks         *MilestoneHooks
+}
+
+// raisedAmounts are amounts raised towards goals keyed by prize ID,
+// the amount raised towards the raffle goal is keyed by an empty ID.
+type raisedAmounts map[string]int
+
+// track writes donations and emits milestones passed by them.
+// Milestones are best effort: no events are emitted if amounts can't be read,
+// and concurrent writes may make a milestone emitted twice or missed.
+func (t *milestoneTracker) track(write func() error) error {
+	if t == nil || !t.hooks.subscribed() {
+		return write()
+	}
+
+	goals, before, err := t.raised()
+	if err != nil {
+		return write()
+	}
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	_, after, err := t.raised()
+	if err != nil {
+		return nil
+	}
+
+	now := timeNow()
+	for prizeID, goal := range goals {
+		for _, percent := range goal.milestones(before[prizeID], after[prizeID]) {
+			t.hooks.emit(MilestoneEvent{
+				RaffleID:  t.raffleID,
+				PrizeID:   prizeID,
+				Percent:   percent,
+				Goal:      goal,
+				Raised:    after[prizeID],
+				ReachedAt: now,
+			})
+		}
+	}
+
+	return nil
+}
+
+// raised returns goals of the raffle and its prizes along with amounts raised towards them.
+func (t *milestoneTracker) raised() (map[string]Goal, raisedAmounts, error) {
+	goals := make(map[string]Goal)
+	raised := make(raisedAmounts)
+
+	raffle, err := t.raffleStorage.Get(t.raffleID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get raffle: %w", err)
+	}
+
+	if raffle.Goal != nil {
+		counters, err := t.raffleStorage.StatsStorage(t.raffleID).Get()
+		if err != nil {
+			return nil, nil, fmt.Errorf("get counters: %w", err)
+		}
+
+		goals[""] = *raffle.Goal
+		raised[""] = counters.Raised
+	}
+
+	prizes, err := t.raffleStorage.PrizeStorage(t.raffleID).GetAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("get all prizes: %w", err)
+	}
+
+	for _, prize := range prizes {
+		if prize.Goal != nil {
+			goals[prize.ID] = *prize.Goal
+			raised[prize.ID] = prize.TotalDonated
+		}
+	}
+
+	return goals, raised, nil
+}
+
+// MilestoneDonationService is a DonationService
+// that emits milestones passed by donations.
+type MilestoneDonationService struct {
+	DonationService
+	tracker *milestoneTracker
+}
+
+// Create creates a donation and emits milestones passed by it.
+func (m *MilestoneDonationService) Create(d *DonationRequest) (string, error) {
+	var id string
+
+	err := m.tracker.track(func() (err error) {
+		id, err = m.DonationService.Create(d)
+		return err
+	})
+
+	return id, err
+}
+
+// Edit edits a donation and emits milestones passed by it.
+func (m *MilestoneDonationService) Edit(id string, d *DonationRequest) error {
+	return m.tracker.track(func() error {
+		return m.DonationService.Edit(id, d)
+	})
+}
+
+// Patch patches a donation and emits milestones passed by it.
+func (m *MilestoneDonationService) Patch(id string, p *MergePatch) error {
+	return m.tracker.track(func() error {
+		return m.DonationService.Patch(id, p)
+	})
+}
+
+// MilestonePaymentService is a PaymentService
+// that emits milestones passed by payments.
+type MilestonePaymentService struct {
+	PaymentService
+	tracker *milestoneTracker
+}
+
+// Create creates a payment and emits milestones passed by it.
+func (m *MilestonePaymentService) Create(r *PaymentRequest) (string, error) {
+	var id string
+
+	err := m.tracker.track(func() (err error) {
+		id, err = m.PaymentService.Create(r)
+		return err
+	})
+
+	return id, err
+}
+
+// Edit edits a payment and emits milestones passed by it.
+func (m *MilestonePaymentService) Edit(id string, r *PaymentRequest) error {
+	return m.tracker.track(func() error {
+		return m.PaymentService.Edit(id, r)
+	})
+}
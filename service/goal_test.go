@@ -0,0 +1,131 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGoalProgress(t *testing.T) {
+	goal := &Goal{Amount: 2000, Currency: "UAH"}
+
+	assert.Equal(t, &GoalProgress{Raised: 500, Percent: 25}, goal.Progress(500))
+	assert.Equal(t, &GoalProgress{Raised: 3000, Percent: 150, Reached: true}, goal.Progress(3000))
+
+	assert.Equal(t, []int{25, 50}, goal.milestones(400, 1000))
+	assert.Equal(t, []int{100}, goal.milestones(1999, 2500))
+	assert.Empty(t, goal.milestones(500, 900))
+	assert.Empty(t, goal.milestones(2500, 1000), "removed donations pass no milestones")
+}
+
+func TestGoalValidation(t *testing.T) {
+	request := func(goal *Goal) *RaffleRequest {
+		return &RaffleRequest{Name: "Raffle", Goal: goal}
+	}
+
+	assert.NoError(t, request(nil).Validate())
+	assert.NoError(t, request(&Goal{Amount: 100}).Validate())
+	assert.NoError(t, request(&Goal{Amount: 100, Currency: "UAH"}).Validate())
+	assert.Error(t, request(&Goal{Amount: 0, Currency: "UAH"}).Validate())
+	assert.Error(t, request(&Goal{Amount: 100, Currency: "HRN"}).Validate())
+}
+
+func TestGoalProgressInResponses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	statsStorage := NewMockStatsStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+
+	raffleStorage.EXPECT().StatsStorage("raffle_1").Return(statsStorage).AnyTimes()
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(nil).AnyTimes()
+
+	rm := NewRaffleManager(raffleStorage)
+
+	t.Run("raffle", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1", Goal: &Goal{Amount: 1000}}, nil)
+		statsStorage.EXPECT().Get().Return(&RaffleCounters{Raised: 250}, nil)
+
+		raffle, err := rm.Get("raffle_1")
+		require.NoError(t, err)
+		assert.Equal(t, &GoalProgress{Raised: 250, Percent: 25}, raffle.Progress)
+	})
+
+	t.Run("raffle_without_goal", func(t *testing.T) {
+		raffleStorage.EXPECT().GetAll().Return([]Raffle{{ID: "raffle_2"}}, nil)
+
+		raffles, err := rm.List()
+		require.NoError(t, err)
+		assert.Nil(t, raffles[0].Progress)
+	})
+
+	t.Run("prizes", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return([]Prize{
+			{ID: "prize_1", TotalDonated: 300, Goal: &Goal{Amount: 200}},
+			{ID: "prize_2", TotalDonated: 300},
+		}, nil)
+
+		prizes, err := rm.PrizeService("raffle_1").List()
+		require.NoError(t, err)
+		assert.Equal(t, &GoalProgress{Raised: 300, Percent: 150, Reached: true}, prizes[0].Progress)
+		assert.Nil(t, prizes[1].Progress)
+	})
+}
+
+func TestMilestones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	storage := NewMockOrganizerStorage(ctrl)
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	statsStorage := NewMockStatsStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	donationStorage := NewMockDonationStorage(ctrl)
+	webhookStorage := NewMockWebhookStorage(ctrl)
+
+	storage.EXPECT().RaffleStorage("organizer_1").Return(raffleStorage).AnyTimes()
+	storage.EXPECT().WebhookStorage("organizer_1").Return(webhookStorage).AnyTimes()
+	storage.EXPECT().DeliveryStorage("organizer_1").Return(nil).AnyTimes()
+	webhookStorage.EXPECT().GetAll().Return(nil, nil).AnyTimes()
+	raffleStorage.EXPECT().StatsStorage("raffle_1").Return(statsStorage).AnyTimes()
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().DonationStorage("raffle_1").Return(donationStorage).AnyTimes()
+
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	setTimeNowMock(now)
+	setUUIDMock("donation_1")
+
+	om := NewOrganizerManager(storage)
+
+	var events []MilestoneEvent
+	om.Milestones().Subscribe(func(e MilestoneEvent) {
+		events = append(events, e)
+	})
+
+	raffle := &Raffle{ID: "raffle_1", Goal: &Goal{Amount: 1000, Currency: "UAH"}}
+	prizes := []Prize{{ID: "prize_1", TotalDonated: 100, Goal: &Goal{Amount: 100}}}
+
+	raffleStorage.EXPECT().Get("raffle_1").Return(raffle, nil).Times(3)
+	statsStorage.EXPECT().Get().Return(&RaffleCounters{Raised: 400}, nil).Times(2)
+	statsStorage.EXPECT().Get().Return(&RaffleCounters{Raised: 1000}, nil)
+	prizeStorage.EXPECT().GetAll().Return(prizes, nil).Times(2)
+	donationStorage.EXPECT().Create(gomock.Any()).Return(nil)
+	donationStorage.EXPECT().Get("donation_1").Return(&Donation{ID: "donation_1", Amount: 600}, nil)
+
+	ds, err := om.RaffleService("organizer_1").DonationService("raffle_1")
+	require.NoError(t, err)
+
+	_, err = ds.Create(&DonationRequest{ParticipantID: "participant_1", Amount: 600})
+	require.NoError(t, err)
+
+	goal := Goal{Amount: 1000, Currency: "UAH"}
+	assert.Equal(t, []MilestoneEvent{
+		{RaffleID: "raffle_1", Percent: 50, Goal: goal, Raised: 1000, ReachedAt: now},
+		{RaffleID: "raffle_1", Percent: 75, Goal: goal, Raised: 1000, ReachedAt: now},
+		{RaffleID: "raffle_1", Percent: 100, Goal: goal, Raised: 1000, ReachedAt: now},
+	}, events)
+	assert.True(t, events[2].GoalReached())
+}
@@ -0,0 +1,32 @@
+package service
+
+import "time"
+
+// IdempotentResponse is a stored response to a request
+// made with an idempotency key. Retries of the request
+// with the same key get the stored response.
+type IdempotentResponse struct {
+	ID          string    `json:"id"`
+	RequestHash string    `json:"requestHash"`
+	Completed   bool      `json:"completed"`
+	StatusCode  int       `json:"statusCode"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// IsExpired reports whether the stored response shouldn't be used anymore.
+func (r *IdempotentResponse) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// IdempotencyStorage is a storage for responses to requests with idempotency keys.
+type IdempotencyStorage interface {
+	Get(id string) (*IdempotentResponse, error)
+	// Claim atomically stores the pending response unless a response
+	// with the same ID which isn't expired is stored. It returns the stored
+	// response in that case and nil if the response is claimed.
+	Claim(*IdempotentResponse) (*IdempotentResponse, error)
+	Save(*IdempotentResponse) error
+	Delete(id string) error
+}
@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source:  github.com/bluegophercult/yarmarok/service (interfaces: DeliveryStorage)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_delivery_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service DeliveryStorage
+//
+// Package service is a generated GoMock package.
+package service
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeliveryStorage is a mock of DeliveryStorage interface.
+type MockDeliveryStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryStorageMockRecorder
+}
+
+// MockDeliveryStorageMockRecorder is the mock recorder for MockDeliveryStorage.
+type MockDeliveryStorageMockRecorder struct {
+	mock *MockDeliveryStorage
+}
+
+// NewMockDeliveryStorage creates a new mock instance.
+func NewMockDeliveryStorage(ctrl *gomock.Controller) *MockDeliveryStorage {
+	mock := &MockDeliveryStorage{ctrl: ctrl}
+	mock.recorder = &MockDeliveryStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryStorage) EXPECT() *MockDeliveryStorageMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockDeliveryStorage) Create(arg0 *Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDeliveryStorageMockRecorder) Create(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDeliveryStorage)(nil).Create), arg0)
+}
+
+// Get mocks base method.
+func (m *MockDeliveryStorage) Get(arg0 string) (*Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockDeliveryStorageMockRecorder) Get(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDeliveryStorage)(nil).Get), arg0)
+}
+
+// GetByEventType mocks base method.
+func (m *MockDeliveryStorage) GetByEventType(arg0 EventType) ([]Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEventType", arg0)
+	ret0, _ := ret[0].([]Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEventType indicates an expected call of GetByEventType.
+func (mr *MockDeliveryStorageMockRecorder) GetByEventType(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEventType", reflect.TypeOf((*MockDeliveryStorage)(nil).GetByEventType), arg0)
+}
+
+// GetByWebhook mocks base method.
+func (m *MockDeliveryStorage) GetByWebhook(arg0 string) ([]Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByWebhook", arg0)
+	ret0, _ := ret[0].([]Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByWebhook indicates an expected call of GetByWebhook.
+func (mr *MockDeliveryStorageMockRecorder) GetByWebhook(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByWebhook", reflect.TypeOf((*MockDeliveryStorage)(nil).GetByWebhook), arg0)
+}
+
+// GetDue mocks base method.
+func (m *MockDeliveryStorage) GetDue(arg0 time.Time) ([]Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDue", arg0)
+	ret0, _ := ret[0].([]Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDue indicates an expected call of GetDue.
+func (mr *MockDeliveryStorageMockRecorder) GetDue(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDue", reflect.TypeOf((*MockDeliveryStorage)(nil).GetDue), arg0)
+}
+
+// Update mocks base method.
+func (m *MockDeliveryStorage) Update(arg0 *Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDeliveryStorageMockRecorder) Update(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDeliveryStorage)(nil).Update), arg0)
+}
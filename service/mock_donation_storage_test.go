@@ -108,3 +108,17 @@ func (mr *MockDonationStorageMockRecorder) Update(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDonationStorage)(nil).Update), arg0)
 }
+
+// UpdateFields mocks base method.
+func (m *MockDonationStorage) UpdateFields(arg0 *Donation, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFields", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFields indicates an expected call of UpdateFields.
+func (mr *MockDonationStorageMockRecorder) UpdateFields(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFields", reflect.TypeOf((*MockDonationStorage)(nil).UpdateFields), arg0, arg1)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source:  github.com/bluegophercult/yarmarok/service (interfaces: NotificationStorage)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_notification_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service NotificationStorage
+//
+// Package service is a generated GoMock package.
+package service
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationStorage is a mock of NotificationStorage interface.
+type MockNotificationStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationStorageMockRecorder
+}
+
+// MockNotificationStorageMockRecorder is the mock recorder for MockNotificationStorage.
+type MockNotificationStorageMockRecorder struct {
+	mock *MockNotificationStorage
+}
+
+// NewMockNotificationStorage creates a new mock instance.
+func NewMockNotificationStorage(ctrl *gomock.Controller) *MockNotificationStorage {
+	mock := &MockNotificationStorage{ctrl: ctrl}
+	mock.recorder = &MockNotificationStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationStorage) EXPECT() *MockNotificationStorageMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockNotificationStorage) Create(arg0 *QueuedNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockNotificationStorageMockRecorder) Create(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockNotificationStorage)(nil).Create), arg0)
+}
+
+// Delete mocks base method.
+func (m *MockNotificationStorage) Delete(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockNotificationStorageMockRecorder) Delete(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNotificationStorage)(nil).Delete), arg0)
+}
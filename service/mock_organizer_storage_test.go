@@ -10,6 +10,7 @@ package service
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -37,6 +38,21 @@ func (m *MockOrganizerStorage) EXPECT() *MockOrganizerStorageMockRecorder {
 	return m.recorder
 }
 
+// ClosedRaffles mocks base method.
+func (m *MockOrganizerStorage) ClosedRaffles(arg0 time.Time) ([]Raffle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClosedRaffles", arg0)
+	ret0, _ := ret[0].([]Raffle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClosedRaffles indicates an expected call of ClosedRaffles.
+func (mr *MockOrganizerStorageMockRecorder) ClosedRaffles(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClosedRaffles", reflect.TypeOf((*MockOrganizerStorage)(nil).ClosedRaffles), arg0)
+}
+
 // Create mocks base method.
 func (m *MockOrganizerStorage) Create(arg0 *Organizer) error {
 	m.ctrl.T.Helper()
@@ -51,6 +67,50 @@ func (mr *MockOrganizerStorageMockRecorder) Create(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrganizerStorage)(nil).Create), arg0)
 }
 
+// DeliveryStorage mocks base method.
+func (m *MockOrganizerStorage) DeliveryStorage(arg0 string) DeliveryStorage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliveryStorage", arg0)
+	ret0, _ := ret[0].(DeliveryStorage)
+	return ret0
+}
+
+// DeliveryStorage indicates an expected call of DeliveryStorage.
+func (mr *MockOrganizerStorageMockRecorder) DeliveryStorage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliveryStorage", reflect.TypeOf((*MockOrganizerStorage)(nil).DeliveryStorage), arg0)
+}
+
+// DueDeliveryOrganizers mocks base method.
+func (m *MockOrganizerStorage) DueDeliveryOrganizers(arg0 time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueDeliveryOrganizers", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueDeliveryOrganizers indicates an expected call of DueDeliveryOrganizers.
+func (mr *MockOrganizerStorageMockRecorder) DueDeliveryOrganizers(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueDeliveryOrganizers", reflect.TypeOf((*MockOrganizerStorage)(nil).DueDeliveryOrganizers), arg0)
+}
+
+// DuePrizes mocks base method.
+func (m *MockOrganizerStorage) DuePrizes(arg0 time.Time) ([]DuePrize, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DuePrizes", arg0)
+	ret0, _ := ret[0].([]DuePrize)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DuePrizes indicates an expected call of DuePrizes.
+func (mr *MockOrganizerStorageMockRecorder) DuePrizes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DuePrizes", reflect.TypeOf((*MockOrganizerStorage)(nil).DuePrizes), arg0)
+}
+
 // Exists mocks base method.
 func (m *MockOrganizerStorage) Exists(arg0 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +126,50 @@ func (mr *MockOrganizerStorageMockRecorder) Exists(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockOrganizerStorage)(nil).Exists), arg0)
 }
 
+// GetAll mocks base method.
+func (m *MockOrganizerStorage) GetAll() ([]Organizer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll")
+	ret0, _ := ret[0].([]Organizer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockOrganizerStorageMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockOrganizerStorage)(nil).GetAll))
+}
+
+// NotificationStorage mocks base method.
+func (m *MockOrganizerStorage) NotificationStorage(arg0 string) NotificationStorage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotificationStorage", arg0)
+	ret0, _ := ret[0].(NotificationStorage)
+	return ret0
+}
+
+// NotificationStorage indicates an expected call of NotificationStorage.
+func (mr *MockOrganizerStorageMockRecorder) NotificationStorage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotificationStorage", reflect.TypeOf((*MockOrganizerStorage)(nil).NotificationStorage), arg0)
+}
+
+// QueuedNotifications mocks base method.
+func (m *MockOrganizerStorage) QueuedNotifications() ([]QueuedNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueuedNotifications")
+	ret0, _ := ret[0].([]QueuedNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueuedNotifications indicates an expected call of QueuedNotifications.
+func (mr *MockOrganizerStorageMockRecorder) QueuedNotifications() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueuedNotifications", reflect.TypeOf((*MockOrganizerStorage)(nil).QueuedNotifications))
+}
+
 // RaffleStorage mocks base method.
 func (m *MockOrganizerStorage) RaffleStorage(arg0 string) RaffleStorage {
 	m.ctrl.T.Helper()
@@ -79,3 +183,32 @@ func (mr *MockOrganizerStorageMockRecorder) RaffleStorage(arg0 any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RaffleStorage", reflect.TypeOf((*MockOrganizerStorage)(nil).RaffleStorage), arg0)
 }
+
+// RetainedRaffles mocks base method.
+func (m *MockOrganizerStorage) RetainedRaffles(arg0 time.Time) ([]Raffle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetainedRaffles", arg0)
+	ret0, _ := ret[0].([]Raffle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetainedRaffles indicates an expected call of RetainedRaffles.
+func (mr *MockOrganizerStorageMockRecorder) RetainedRaffles(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetainedRaffles", reflect.TypeOf((*MockOrganizerStorage)(nil).RetainedRaffles), arg0)
+}
+
+// WebhookStorage mocks base method.
+func (m *MockOrganizerStorage) WebhookStorage(arg0 string) WebhookStorage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebhookStorage", arg0)
+	ret0, _ := ret[0].(WebhookStorage)
+	return ret0
+}
+
+// WebhookStorage indicates an expected call of WebhookStorage.
+func (mr *MockOrganizerStorageMockRecorder) WebhookStorage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookStorage", reflect.TypeOf((*MockOrganizerStorage)(nil).WebhookStorage), arg0)
+}
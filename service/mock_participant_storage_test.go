@@ -95,6 +95,20 @@ func (mr *MockParticipantStorageMockRecorder) GetAll() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockParticipantStorage)(nil).GetAll))
 }
 
+// Merge mocks base method.
+func (m *MockParticipantStorage) Merge(arg0 *ParticipantMerge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockParticipantStorageMockRecorder) Merge(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockParticipantStorage)(nil).Merge), arg0)
+}
+
 // Update mocks base method.
 func (m *MockParticipantStorage) Update(arg0 *Participant) error {
 	m.ctrl.T.Helper()
@@ -108,3 +122,17 @@ func (mr *MockParticipantStorageMockRecorder) Update(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockParticipantStorage)(nil).Update), arg0)
 }
+
+// UpdateFields mocks base method.
+func (m *MockParticipantStorage) UpdateFields(arg0 *Participant, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFields", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFields indicates an expected call of UpdateFields.
+func (mr *MockParticipantStorageMockRecorder) UpdateFields(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFields", reflect.TypeOf((*MockParticipantStorage)(nil).UpdateFields), arg0, arg1)
+}
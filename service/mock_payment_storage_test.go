@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source:  github.com/bluegophercult/yarmarok/service (interfaces: PaymentStorage)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_payment_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service PaymentStorage
+//
+// Package service is a generated GoMock package.
+package service
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPaymentStorage is a mock of PaymentStorage interface.
+type MockPaymentStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentStorageMockRecorder
+}
+
+// MockPaymentStorageMockRecorder is the mock recorder for MockPaymentStorage.
+type MockPaymentStorageMockRecorder struct {
+	mock *MockPaymentStorage
+}
+
+// NewMockPaymentStorage creates a new mock instance.
+func NewMockPaymentStorage(ctrl *gomock.Controller) *MockPaymentStorage {
+	mock := &MockPaymentStorage{ctrl: ctrl}
+	mock.recorder = &MockPaymentStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentStorage) EXPECT() *MockPaymentStorageMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPaymentStorage) Create(arg0 *Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPaymentStorageMockRecorder) Create(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPaymentStorage)(nil).Create), arg0)
+}
+
+// Delete mocks base method.
+func (m *MockPaymentStorage) Delete(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockPaymentStorageMockRecorder) Delete(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPaymentStorage)(nil).Delete), arg0)
+}
+
+// Get mocks base method.
+func (m *MockPaymentStorage) Get(arg0 string) (*Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPaymentStorageMockRecorder) Get(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPaymentStorage)(nil).Get), arg0)
+}
+
+// GetAll mocks base method.
+func (m *MockPaymentStorage) GetAll() ([]Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll")
+	ret0, _ := ret[0].([]Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockPaymentStorageMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockPaymentStorage)(nil).GetAll))
+}
+
+// Update mocks base method.
+func (m *MockPaymentStorage) Update(arg0 *Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPaymentStorageMockRecorder) Update(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPaymentStorage)(nil).Update), arg0)
+}
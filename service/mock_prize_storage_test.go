@@ -51,6 +51,20 @@ func (mr *MockPrizeStorageMockRecorder) Create(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPrizeStorage)(nil).Create), arg0)
 }
 
+// CreateDonations mocks base method.
+func (m *MockPrizeStorage) CreateDonations(arg0 map[string][]Donation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDonations", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDonations indicates an expected call of CreateDonations.
+func (mr *MockPrizeStorageMockRecorder) CreateDonations(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDonations", reflect.TypeOf((*MockPrizeStorage)(nil).CreateDonations), arg0)
+}
+
 // Delete mocks base method.
 func (m *MockPrizeStorage) Delete(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -122,3 +136,31 @@ func (mr *MockPrizeStorageMockRecorder) Update(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPrizeStorage)(nil).Update), arg0)
 }
+
+// UpdateAll mocks base method.
+func (m *MockPrizeStorage) UpdateAll(arg0 []Prize) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAll", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAll indicates an expected call of UpdateAll.
+func (mr *MockPrizeStorageMockRecorder) UpdateAll(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAll", reflect.TypeOf((*MockPrizeStorage)(nil).UpdateAll), arg0)
+}
+
+// UpdateFields mocks base method.
+func (m *MockPrizeStorage) UpdateFields(arg0 *Prize, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFields", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFields indicates an expected call of UpdateFields.
+func (mr *MockPrizeStorageMockRecorder) UpdateFields(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFields", reflect.TypeOf((*MockPrizeStorage)(nil).UpdateFields), arg0, arg1)
+}
@@ -65,6 +65,20 @@ func (mr *MockRaffleStorageMockRecorder) Delete(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRaffleStorage)(nil).Delete), arg0)
 }
 
+// DonationStorage mocks base method.
+func (m *MockRaffleStorage) DonationStorage(arg0 string) DonationStorage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DonationStorage", arg0)
+	ret0, _ := ret[0].(DonationStorage)
+	return ret0
+}
+
+// DonationStorage indicates an expected call of DonationStorage.
+func (mr *MockRaffleStorageMockRecorder) DonationStorage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DonationStorage", reflect.TypeOf((*MockRaffleStorage)(nil).DonationStorage), arg0)
+}
+
 // Get mocks base method.
 func (m *MockRaffleStorage) Get(arg0 string) (*Raffle, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +123,20 @@ func (mr *MockRaffleStorageMockRecorder) ParticipantStorage(arg0 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParticipantStorage", reflect.TypeOf((*MockRaffleStorage)(nil).ParticipantStorage), arg0)
 }
 
+// PaymentStorage mocks base method.
+func (m *MockRaffleStorage) PaymentStorage(arg0 string) PaymentStorage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaymentStorage", arg0)
+	ret0, _ := ret[0].(PaymentStorage)
+	return ret0
+}
+
+// PaymentStorage indicates an expected call of PaymentStorage.
+func (mr *MockRaffleStorageMockRecorder) PaymentStorage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaymentStorage", reflect.TypeOf((*MockRaffleStorage)(nil).PaymentStorage), arg0)
+}
+
 // PrizeStorage mocks base method.
 func (m *MockRaffleStorage) PrizeStorage(arg0 string) PrizeStorage {
 	m.ctrl.T.Helper()
@@ -123,6 +151,34 @@ func (mr *MockRaffleStorageMockRecorder) PrizeStorage(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrizeStorage", reflect.TypeOf((*MockRaffleStorage)(nil).PrizeStorage), arg0)
 }
 
+// ShiftStorage mocks base method.
+func (m *MockRaffleStorage) ShiftStorage(arg0 string) ShiftStorage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShiftStorage", arg0)
+	ret0, _ := ret[0].(ShiftStorage)
+	return ret0
+}
+
+// ShiftStorage indicates an expected call of ShiftStorage.
+func (mr *MockRaffleStorageMockRecorder) ShiftStorage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShiftStorage", reflect.TypeOf((*MockRaffleStorage)(nil).ShiftStorage), arg0)
+}
+
+// StatsStorage mocks base method.
+func (m *MockRaffleStorage) StatsStorage(arg0 string) StatsStorage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatsStorage", arg0)
+	ret0, _ := ret[0].(StatsStorage)
+	return ret0
+}
+
+// StatsStorage indicates an expected call of StatsStorage.
+func (mr *MockRaffleStorageMockRecorder) StatsStorage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatsStorage", reflect.TypeOf((*MockRaffleStorage)(nil).StatsStorage), arg0)
+}
+
 // Update mocks base method.
 func (m *MockRaffleStorage) Update(arg0 *Raffle) error {
 	m.ctrl.T.Helper()
@@ -136,3 +192,17 @@ func (mr *MockRaffleStorageMockRecorder) Update(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRaffleStorage)(nil).Update), arg0)
 }
+
+// UpdateFields mocks base method.
+func (m *MockRaffleStorage) UpdateFields(arg0 *Raffle, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFields", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFields indicates an expected call of UpdateFields.
+func (mr *MockRaffleStorageMockRecorder) UpdateFields(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFields", reflect.TypeOf((*MockRaffleStorage)(nil).UpdateFields), arg0, arg1)
+}
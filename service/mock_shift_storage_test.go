@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source:  github.com/bluegophercult/yarmarok/service (interfaces: ShiftStorage)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_shift_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service ShiftStorage
+//
+// Package service is a generated GoMock package.
+package service
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockShiftStorage is a mock of ShiftStorage interface.
+type MockShiftStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockShiftStorageMockRecorder
+}
+
+// MockShiftStorageMockRecorder is the mock recorder for MockShiftStorage.
+type MockShiftStorageMockRecorder struct {
+	mock *MockShiftStorage
+}
+
+// NewMockShiftStorage creates a new mock instance.
+func NewMockShiftStorage(ctrl *gomock.Controller) *MockShiftStorage {
+	mock := &MockShiftStorage{ctrl: ctrl}
+	mock.recorder = &MockShiftStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShiftStorage) EXPECT() *MockShiftStorageMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockShiftStorage) Create(arg0 *Shift) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockShiftStorageMockRecorder) Create(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockShiftStorage)(nil).Create), arg0)
+}
+
+// GetAll mocks base method.
+func (m *MockShiftStorage) GetAll() ([]Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll")
+	ret0, _ := ret[0].([]Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockShiftStorageMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockShiftStorage)(nil).GetAll))
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source:  github.com/bluegophercult/yarmarok/service (interfaces: StatsStorage)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_stats_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service StatsStorage
+//
+// Package service is a generated GoMock package.
+package service
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStatsStorage is a mock of StatsStorage interface.
+type MockStatsStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsStorageMockRecorder
+}
+
+// MockStatsStorageMockRecorder is the mock recorder for MockStatsStorage.
+type MockStatsStorageMockRecorder struct {
+	mock *MockStatsStorage
+}
+
+// NewMockStatsStorage creates a new mock instance.
+func NewMockStatsStorage(ctrl *gomock.Controller) *MockStatsStorage {
+	mock := &MockStatsStorage{ctrl: ctrl}
+	mock.recorder = &MockStatsStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsStorage) EXPECT() *MockStatsStorageMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockStatsStorage) Get() (*RaffleCounters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get")
+	ret0, _ := ret[0].(*RaffleCounters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStatsStorageMockRecorder) Get() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStatsStorage)(nil).Get))
+}
+
+// Replace mocks base method.
+func (m *MockStatsStorage) Replace(arg0 *RaffleCounters) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockStatsStorageMockRecorder) Replace(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockStatsStorage)(nil).Replace), arg0)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultCurrency is the base currency of raffles which don't set one.
+const DefaultCurrency = "UAH"
+
+// maxRateDecimals is the maximum number of decimals of an exchange rate.
+const maxRateDecimals = 6
+
+// fineUnits is the number of fine units in a whole unit of any currency.
+// Fine units are the minor units of currencies with three minor unit digits,
+// the most there are, so amounts of every currency are whole numbers of them.
+const fineUnits = 1000
+
+var (
+	ErrInvalidMoney        = errors.New("invalid money amount")
+	ErrInvalidExchangeRate = errors.New("invalid exchange rate")
+	ErrMissingExchangeRate = errors.New("exchange rate is required for donations in a foreign currency")
+	ErrAmountTooSmall      = errors.New("amount is less than a minor unit of the base currency")
+	ErrBaseCurrencyInUse   = errors.New("base currency can't be changed after donations are made")
+)
+
+// currencyExponents are numbers of minor unit digits of currencies
+// which differ from the usual two.
+var currencyExponents = map[string]int{
+	"BHD": 3,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// CurrencyExponent returns the number of minor unit digits of the currency.
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+
+	return 2
+}
+
+// Money is an amount in minor units of a currency, e.g. cents.
+// It's encoded to JSON with the amount as a decimal string, e.g. "12.50",
+// so it's never rounded by float arithmetic.
+type Money struct {
+	Amount   int64  `validate:"gt=0"`
+	Currency string `validate:"iso4217"`
+}
+
+// ParseMoney parses a decimal amount of the currency.
+// It fails if the amount has more decimals than the currency minor units.
+func ParseMoney(amount, currency string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, amount)
+	}
+
+	minor := r.Mul(r, new(big.Rat).SetInt(scale(CurrencyExponent(currency))))
+	if !minor.IsInt() || !minor.Num().IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q in %s", ErrInvalidMoney, amount, currency)
+	}
+
+	return Money{Amount: minor.Num().Int64(), Currency: currency}, nil
+}
+
+// Decimal formats the amount as a decimal, e.g. "12.50".
+func (m Money) Decimal() string {
+	return m.rat().FloatString(CurrencyExponent(m.Currency))
+}
+
+// String formats the money, e.g. "12.50 EUR".
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Convert converts the money to minor units of another currency at the rate,
+// rounding half up. It fails if the amount rounds to zero.
+func (m Money) Convert(rate ExchangeRate, currency string) (Money, error) {
+	r, err := rate.rat()
+	if err != nil {
+		return Money{}, err
+	}
+
+	minor := r.Mul(r, m.rat())
+	minor.Mul(minor, new(big.Rat).SetInt(scale(CurrencyExponent(currency))))
+
+	converted := Money{Amount: roundHalfUp(minor), Currency: currency}
+	if converted.Amount <= 0 {
+		return Money{}, fmt.Errorf("%w: %s in %s", ErrAmountTooSmall, m, currency)
+	}
+
+	return converted, nil
+}
+
+// Whole returns the amount in whole units, rounding half up.
+func (m Money) Whole() int {
+	return int(roundHalfUp(m.rat()))
+}
+
+// fine returns the amount in fine units, see fineUnits.
+func (m Money) fine() int {
+	return int(m.Amount) * fineUnits / int(scale(CurrencyExponent(m.Currency)).Int64())
+}
+
+func (m Money) rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), scale(CurrencyExponent(m.Currency)))
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes the money with a decimal amount.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes the money with a decimal amount,
+// given either as a JSON number or a string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	amount := strings.Trim(string(raw.Amount), `"`)
+
+	money, err := ParseMoney(amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = money
+
+	return nil
+}
+
+// ExchangeRate is an amount of the base currency for a unit
+// of another currency as a decimal, e.g. "44.15".
+type ExchangeRate string
+
+// Validate validates that the rate is a positive decimal
+// with at most maxRateDecimals decimals.
+func (r ExchangeRate) Validate() error {
+	_, err := r.rat()
+	return err
+}
+
+func (r ExchangeRate) rat() (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(string(r)))
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidExchangeRate, r)
+	}
+
+	if !new(big.Rat).Mul(rate, new(big.Rat).SetInt(scale(maxRateDecimals))).IsInt() {
+		return nil, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidExchangeRate, r, maxRateDecimals)
+	}
+
+	return rate, nil
+}
+
+// scale returns 10 to the power of the exponent.
+func scale(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// roundHalfUp rounds a non-negative number to an integer.
+func roundHalfUp(r *big.Rat) int64 {
+	half := new(big.Rat).SetFrac64(1, 2)
+	shifted := new(big.Rat).Add(r, half)
+
+	return new(big.Int).Quo(shifted.Num(), shifted.Denom()).Int64()
+}
+
+// currencyOrDefault treats raffles without a currency as ones in DefaultCurrency.
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+
+	return currency
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseMoney(t *testing.T) {
+	m, err := ParseMoney("12.5", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: 1250, Currency: "EUR"}, m)
+	assert.Equal(t, "12.50 EUR", m.String())
+
+	m, err = ParseMoney("1500", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, "1500 JPY", m.String())
+
+	_, err = ParseMoney("12.505", "EUR")
+	assert.ErrorIs(t, err, ErrInvalidMoney, "more decimals than minor units")
+
+	_, err = ParseMoney("twelve", "EUR")
+	assert.ErrorIs(t, err, ErrInvalidMoney)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(Money{Amount: 1999, Currency: "PLN"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"19.99","currency":"PLN"}`, string(data))
+
+	var m Money
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":0.1,"currency":"EUR"}`), &m))
+	assert.Equal(t, Money{Amount: 10, Currency: "EUR"}, m)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"0.001","currency":"EUR"}`), &m))
+}
+
+func TestMoneyConvert(t *testing.T) {
+	for name, tc := range map[string]struct {
+		money    Money
+		rate     ExchangeRate
+		expected int64
+	}{
+		"exact":      {Money{Amount: 1000, Currency: "EUR"}, "44.15", 44150},
+		"round_down": {Money{Amount: 1001, Currency: "EUR"}, "44.15", 44194},
+		"round_up":   {Money{Amount: 1002, Currency: "PLN"}, "10.45", 10471},
+		"half_up":    {Money{Amount: 1, Currency: "EUR"}, "0.5", 1},
+		"no_floats":  {Money{Amount: 10, Currency: "EUR"}, "30", 300},
+		"same":       {Money{Amount: 1250, Currency: "UAH"}, "1", 1250},
+		"exponents":  {Money{Amount: 1000, Currency: "JPY"}, "0.27", 27000},
+	} {
+		t.Run(name, func(t *testing.T) {
+			converted, err := tc.money.Convert(tc.rate, "UAH")
+			require.NoError(t, err)
+			assert.Equal(t, Money{Amount: tc.expected, Currency: "UAH"}, converted)
+		})
+	}
+
+	t.Run("rounds_to_zero", func(t *testing.T) {
+		_, err := Money{Amount: 1, Currency: "KWD"}.Convert("0.001", "UAH")
+		assert.ErrorIs(t, err, ErrAmountTooSmall)
+	})
+
+	t.Run("whole", func(t *testing.T) {
+		assert.Equal(t, 13, Money{Amount: 1250, Currency: "UAH"}.Whole())
+		assert.Equal(t, 0, Money{Amount: 40, Currency: "UAH"}.Whole())
+		assert.Equal(t, 1000, Money{Amount: 1000, Currency: "JPY"}.Whole())
+	})
+
+	t.Run("fine", func(t *testing.T) {
+		assert.Equal(t, 12500, Money{Amount: 1250, Currency: "UAH"}.fine())
+		assert.Equal(t, 1000000, Money{Amount: 1000, Currency: "JPY"}.fine())
+		assert.Equal(t, 1, Money{Amount: 1, Currency: "KWD"}.fine())
+	})
+
+	for _, rate := range []ExchangeRate{"", "0", "-1", "1.1234567", "abc"} {
+		assert.ErrorIs(t, rate.Validate(), ErrInvalidExchangeRate, rate)
+	}
+}
+
+func TestForeignCurrencyDonation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storageMock := NewMockDonationStorage(ctrl)
+
+	manager := NewDonationManager(storageMock)
+	manager.baseCurrency = "UAH"
+
+	original := &Money{Amount: 1250, Currency: "EUR"}
+
+	t.Run("converted", func(t *testing.T) {
+		storageMock.EXPECT().Create(gomock.Any()).DoAndReturn(func(d *Donation) error {
+			assert.Equal(t, 552, d.Amount)
+			assert.Equal(t, &Money{Amount: 55188, Currency: "UAH"}, d.Base)
+			assert.Equal(t, original, d.Original)
+			assert.Equal(t, ExchangeRate("44.15"), d.ExchangeRate)
+			return nil
+		})
+
+		_, err := manager.Create(&DonationRequest{Original: original, ExchangeRate: "44.15", ParticipantID: "participant_1"})
+		require.NoError(t, err)
+	})
+
+	t.Run("base_currency_minor_units", func(t *testing.T) {
+		storageMock.EXPECT().Create(gomock.Any()).DoAndReturn(func(d *Donation) error {
+			assert.Equal(t, 100, d.Amount)
+			assert.Equal(t, &Money{Amount: 9950, Currency: "UAH"}, d.Base)
+			assert.Empty(t, d.ExchangeRate)
+			return nil
+		})
+
+		_, err := manager.Create(&DonationRequest{Original: &Money{Amount: 9950, Currency: "UAH"}, ParticipantID: "participant_1"})
+		require.NoError(t, err)
+	})
+
+	t.Run("less_than_minor_unit", func(t *testing.T) {
+		_, err := manager.Create(&DonationRequest{
+			Original:      &Money{Amount: 1, Currency: "JPY"},
+			ExchangeRate:  "0.001",
+			ParticipantID: "participant_1",
+		})
+		assert.ErrorIs(t, err, ErrAmountTooSmall)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("missing_rate", func(t *testing.T) {
+		_, err := manager.Create(&DonationRequest{Original: original, ParticipantID: "participant_1"})
+		assert.ErrorIs(t, err, ErrMissingExchangeRate)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, (&DonationRequest{Original: original, ExchangeRate: "44.15", ParticipantID: "p"}).Validate())
+		assert.Error(t, (&DonationRequest{Original: &Money{Amount: 100, Currency: "XXY"}, ParticipantID: "p"}).Validate())
+		assert.ErrorIs(t, (&DonationRequest{Original: original, ExchangeRate: "0", ParticipantID: "p"}).Validate(), ErrInvalidExchangeRate)
+	})
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	// ErrNoAddress is returned by notifiers which can't reach the recipient,
+	// e.g. when the participant has no Telegram chat.
+	ErrNoAddress = errors.New("recipient has no address for the channel")
+
+	// ErrNoContactConsent is recorded for winners which didn't allow to contact them.
+	ErrNoContactConsent = errors.New("participant didn't allow to contact them")
+
+	// ErrNotDelivered is recorded for winners the notifier failed to send the message to.
+	// Notifier errors aren't recorded, since they may include provider credentials.
+	ErrNotDelivered = errors.New("message wasn't delivered")
+)
+
+// Notifier sends messages to participants through a messaging provider.
+type Notifier interface {
+	// Channel names the provider, e.g. "sms" or "telegram".
+	Channel() string
+	// Notify sends the message and returns its ID assigned by the provider.
+	Notify(m *Message) (id string, err error)
+}
+
+// Message is a text message to a participant.
+type Message struct {
+	Recipient Participant
+	Text      string
+}
+
+// NotificationStatus is a status of notifying a winner.
+type NotificationStatus string
+
+const (
+	NotificationSent   NotificationStatus = "sent"
+	NotificationFailed NotificationStatus = "failed"
+	// NotificationSkipped is a winner who didn't allow to contact them
+	// or who can't be reached through the channel.
+	NotificationSkipped NotificationStatus = "skipped"
+)
+
+// WinnerNotification is a result of notifying a winner.
+type WinnerNotification struct {
+	Channel   string             `json:"channel"`
+	Status    NotificationStatus `json:"status"`
+	MessageID string             `json:"messageId,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	At        time.Time          `json:"at"`
+}
+
+// QueuedNotification is a winner of a played prize waiting to be notified.
+type QueuedNotification struct {
+	ID string `json:"id"`
+	// OrganizerID is set by the storage from the location of the notification.
+	OrganizerID   string `json:"organizerId" firestore:"-"`
+	RaffleID      string `json:"raffleId"`
+	PrizeID       string `json:"prizeId"`
+	PrizeName     string `json:"prizeName"`
+	ParticipantID string `json:"participantId"`
+	// Notification is the result of notifying the winner,
+	// it's set once the notification is sent.
+	Notification *WinnerNotification `json:"notification,omitempty" firestore:"-"`
+	CreatedAt    time.Time           `json:"createdAt"`
+}
+
+// NotificationStorage is a storage for queued winner notifications of an organizer.
+//
+//go:generate mockgen -destination=mock_notification_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service NotificationStorage
+type NotificationStorage interface {
+	Create(*QueuedNotification) error
+	Delete(id string) error
+}
+
+// WinnerMessageData is the data of winner message templates, e.g.
+// "Congratulations, {{.Name}}! You won {{.Prize}} in {{.Raffle}}."
+type WinnerMessageData struct {
+	// Name is empty unless the winner consented to publish it,
+	// since messages pass through third-party gateways.
+	Name   string
+	Prize  string
+	Raffle string
+}
+
+// parseWinnerMessage parses a winner message template.
+func parseWinnerMessage(text string) (*template.Template, error) {
+	return template.New("winner").Option("missingkey=error").Parse(text)
+}
+
+// renderWinnerMessage renders the winner message template of the raffle.
+func renderWinnerMessage(text string, data WinnerMessageData) (string, error) {
+	tmpl, err := parseWinnerMessage(text)
+	if err != nil {
+		return "", fmt.Errorf("parse winner message: %w", err)
+	}
+
+	var message strings.Builder
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", fmt.Errorf("render winner message: %w", err)
+	}
+
+	return message.String(), nil
+}
+
+// NotifyWinners makes winners of raffles which have a winner message
+// notified through the notifier after the prizes are played.
+// Winners are queued when prizes are played, so plays don't wait
+// for the provider, and notified by SendNotifications.
+func (om *OrganizerManager) NotifyWinners(n Notifier) {
+	om.notifier = &winnerNotifier{organizerStorage: om.organizerStorage, notifier: n}
+	om.events.Subscribe(om.notifier.queue)
+}
+
+// SendNotifications notifies winners queued since the last run.
+// It is meant to be triggered periodically by a scheduler.
+// Notifications are removed from the queue before they are sent,
+// so winners are notified at most once. Failure to notify a winner
+// doesn't stop the others, errors of all of them are returned
+// along with the sent notifications.
+func (om *OrganizerManager) SendNotifications() ([]QueuedNotification, error) {
+	sent := make([]QueuedNotification, 0)
+
+	if om.notifier == nil {
+		return sent, nil
+	}
+
+	queued, err := om.organizerStorage.QueuedNotifications()
+	if err != nil {
+		return nil, fmt.Errorf("get queued notifications: %w", err)
+	}
+
+	errs := make([]error, 0)
+
+	for i := range queued {
+		q := &queued[i]
+
+		if err := om.organizerStorage.NotificationStorage(q.OrganizerID).Delete(q.ID); err != nil {
+			errs = append(errs, fmt.Errorf("dequeue notification %q: %w", q.ID, err))
+			continue
+		}
+
+		if err := om.notifier.notifyWinner(q); err != nil {
+			errs = append(errs, fmt.Errorf("notify winner of prize %q: %w", q.PrizeID, err))
+		}
+
+		sent = append(sent, *q)
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// winnerNotifier notifies winners of prize.played events
+// and records the results on the winner entries of the prizes.
+type winnerNotifier struct {
+	organizerStorage OrganizerStorage
+	notifier         Notifier
+}
+
+// queue is the event hook of the notifier.
+// Notifications are best effort: failing to queue the winner
+// doesn't fail the play.
+func (wn *winnerNotifier) queue(e Event) {
+	if e.Type != EventPrizePlayed {
+		return
+	}
+
+	played := e.Data.(PrizePlayed)
+
+	_ = wn.organizerStorage.NotificationStorage(e.OrganizerID).Create(&QueuedNotification{
+		ID:            stringUUID(),
+		RaffleID:      e.RaffleID,
+		PrizeID:       played.PrizeID,
+		PrizeName:     played.PrizeName,
+		ParticipantID: played.Winner.Participant.ID,
+		CreatedAt:     timeNow(),
+	})
+}
+
+// notifyWinner sends the winner message of the raffle to the winner
+// and records the result on the notification and the winner entry of the prize.
+func (wn *winnerNotifier) notifyWinner(q *QueuedNotification) error {
+	raffleStorage := wn.organizerStorage.RaffleStorage(q.OrganizerID)
+
+	raffle, err := raffleStorage.Get(q.RaffleID)
+	if err != nil {
+		return fmt.Errorf("get raffle: %w", err)
+	}
+
+	if raffle.WinnerMessage == "" {
+		return nil
+	}
+
+	// The participant is read again, so the consent and the phone are up to date.
+	participant, err := raffleStorage.ParticipantStorage(q.RaffleID).Get(q.ParticipantID)
+	if err != nil {
+		return fmt.Errorf("get participant: %w", err)
+	}
+
+	q.Notification = wn.send(participant, WinnerMessageData{
+		Name:   participant.published().Name,
+		Prize:  q.PrizeName,
+		Raffle: raffle.Name,
+	}, raffle.WinnerMessage)
+
+	prizeStorage := raffleStorage.PrizeStorage(q.RaffleID)
+
+	prize, err := prizeStorage.Get(q.PrizeID)
+	if err != nil {
+		return fmt.Errorf("get prize: %w", err)
+	}
+
+	winner := prize.notifiedWinner(participant.ID)
+	if winner == nil {
+		return nil
+	}
+
+	winner.Notification = q.Notification
+
+	if err := prizeStorage.Update(prize); err != nil {
+		return fmt.Errorf("update prize with notification: %w", err)
+	}
+
+	return nil
+}
+
+// send sends the winner message to the participant if they allowed to contact them.
+func (wn *winnerNotifier) send(participant *Participant, data WinnerMessageData, text string) *WinnerNotification {
+	notification := &WinnerNotification{
+		Channel: wn.notifier.Channel(),
+		At:      timeNow(),
+	}
+
+	if !participant.ContactAllowed {
+		notification.Status = NotificationSkipped
+		notification.Error = ErrNoContactConsent.Error()
+
+		return notification
+	}
+
+	message, err := renderWinnerMessage(text, data)
+	if err != nil {
+		notification.Status = NotificationFailed
+		notification.Error = err.Error()
+
+		return notification
+	}
+
+	id, err := wn.notifier.Notify(&Message{Recipient: *participant, Text: message})
+	switch {
+	case errors.Is(err, ErrNoAddress):
+		notification.Status = NotificationSkipped
+		notification.Error = err.Error()
+	case err != nil:
+		notification.Status = NotificationFailed
+		notification.Error = ErrNotDelivered.Error()
+	default:
+		notification.Status = NotificationSent
+		notification.MessageID = id
+	}
+
+	return notification
+}
+
+// notifiedWinner returns the latest winner entry of the participant
+// which isn't notified yet.
+func (p *Prize) notifiedWinner(participantID string) *PlayParticipant {
+	if p.PlayResult == nil {
+		return nil
+	}
+
+	for i := len(p.PlayResult.Winners) - 1; i >= 0; i-- {
+		winner := &p.PlayResult.Winners[i]
+		if winner.Participant.ID == participantID && winner.Notification == nil {
+			return winner
+		}
+	}
+
+	return nil
+}
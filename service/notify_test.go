@@ -0,0 +1,239 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeNotifier records messages and fails with err if it's set.
+type fakeNotifier struct {
+	messages []Message
+	err      error
+}
+
+func (n *fakeNotifier) Channel() string {
+	return "fake"
+}
+
+func (n *fakeNotifier) Notify(m *Message) (string, error) {
+	if n.err != nil {
+		return "", n.err
+	}
+
+	n.messages = append(n.messages, *m)
+
+	return "message_1", nil
+}
+
+func TestNotifyWinners(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	organizerStorage := NewMockOrganizerStorage(ctrl)
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	participantStorage := NewMockParticipantStorage(ctrl)
+	webhookStorage := NewMockWebhookStorage(ctrl)
+	notificationStorage := NewMockNotificationStorage(ctrl)
+
+	organizerStorage.EXPECT().RaffleStorage("organizer_1").Return(raffleStorage).AnyTimes()
+	organizerStorage.EXPECT().WebhookStorage("organizer_1").Return(webhookStorage).AnyTimes()
+	organizerStorage.EXPECT().DeliveryStorage("organizer_1").Return(nil).AnyTimes()
+	organizerStorage.EXPECT().NotificationStorage("organizer_1").Return(notificationStorage).AnyTimes()
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(participantStorage).AnyTimes()
+	webhookStorage.EXPECT().GetAll().Return(nil, nil).AnyTimes()
+
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	setTimeNowMock(now)
+
+	notifier := &fakeNotifier{}
+
+	om := NewOrganizerManager(organizerStorage)
+	om.NotifyWinners(notifier)
+
+	raffle := &Raffle{ID: "raffle_1", Name: "Spring fair", WinnerMessage: "Congratulations, {{.Name}}! You won {{.Prize}} in {{.Raffle}}."}
+	participant := Participant{ID: "participant_1", Name: "Olena", Phone: "+380501234567", ContactAllowed: true, PublishName: true}
+
+	queued := QueuedNotification{
+		ID:            "notification_1",
+		OrganizerID:   "organizer_1",
+		RaffleID:      "raffle_1",
+		PrizeID:       "prize_1",
+		PrizeName:     "Bicycle",
+		ParticipantID: "participant_1",
+		CreatedAt:     now,
+	}
+
+	// send sends the queued notification and returns its result.
+	send := func(t *testing.T) *WinnerNotification {
+		organizerStorage.EXPECT().QueuedNotifications().Return([]QueuedNotification{queued}, nil)
+		notificationStorage.EXPECT().Delete("notification_1").Return(nil)
+
+		sent, err := om.SendNotifications()
+		require.NoError(t, err)
+		require.Len(t, sent, 1)
+
+		return sent[0].Notification
+	}
+
+	t.Run("queued", func(t *testing.T) {
+		setUUIDMock("notification_1")
+
+		notificationStorage.EXPECT().Create(&QueuedNotification{
+			ID:            "notification_1",
+			RaffleID:      "raffle_1",
+			PrizeID:       "prize_1",
+			PrizeName:     "Bicycle",
+			ParticipantID: "participant_1",
+			CreatedAt:     now,
+		}).Return(nil)
+
+		om.Events().emit(Event{
+			Type:        EventPrizePlayed,
+			OrganizerID: "organizer_1",
+			RaffleID:    "raffle_1",
+			Data:        PrizePlayed{PrizeID: "prize_1", PrizeName: "Bicycle", Winner: PlayParticipant{Participant: participant}},
+		})
+
+		assert.Empty(t, notifier.messages)
+	})
+
+	// expectNotification expects the prize to be updated with the notification
+	// of the last winner entry of the participant.
+	expectNotification := func(t *testing.T, expected *WinnerNotification) {
+		forfeited := PlayParticipant{Participant: participant, Claim: &WinnerClaim{Status: ClaimForfeited}}
+		prize := &Prize{ID: "prize_1", PlayResult: &PrizePlayResult{
+			Winners: []PlayParticipant{forfeited, {Participant: participant}},
+		}}
+
+		prizeStorage.EXPECT().Get("prize_1").Return(prize, nil)
+		prizeStorage.EXPECT().Update(gomock.Any()).DoAndReturn(func(p *Prize) error {
+			assert.Nil(t, p.PlayResult.Winners[0].Notification)
+			assert.Equal(t, expected, p.PlayResult.Winners[1].Notification)
+			return nil
+		})
+	}
+
+	t.Run("sent", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(raffle, nil)
+		participantStorage.EXPECT().Get("participant_1").Return(&participant, nil)
+		expectNotification(t, &WinnerNotification{Channel: "fake", Status: NotificationSent, MessageID: "message_1", At: now})
+
+		assert.Equal(t, NotificationSent, send(t).Status)
+
+		require.Len(t, notifier.messages, 1)
+		assert.Equal(t, "Congratulations, Olena! You won Bicycle in Spring fair.", notifier.messages[0].Text)
+		assert.Equal(t, "+380501234567", notifier.messages[0].Recipient.Phone)
+	})
+
+	t.Run("name_not_published", func(t *testing.T) {
+		notifier.messages = nil
+
+		unpublished := participant
+		unpublished.PublishName = false
+
+		raffleStorage.EXPECT().Get("raffle_1").Return(raffle, nil)
+		participantStorage.EXPECT().Get("participant_1").Return(&unpublished, nil)
+		expectNotification(t, &WinnerNotification{Channel: "fake", Status: NotificationSent, MessageID: "message_1", At: now})
+
+		send(t)
+
+		require.Len(t, notifier.messages, 1)
+		assert.Equal(t, "Congratulations, ! You won Bicycle in Spring fair.", notifier.messages[0].Text)
+	})
+
+	t.Run("no_consent", func(t *testing.T) {
+		notifier.messages = nil
+
+		raffleStorage.EXPECT().Get("raffle_1").Return(raffle, nil)
+		participantStorage.EXPECT().Get("participant_1").Return(&Participant{ID: "participant_1"}, nil)
+		expectNotification(t, &WinnerNotification{Channel: "fake", Status: NotificationSkipped, Error: ErrNoContactConsent.Error(), At: now})
+
+		send(t)
+
+		assert.Empty(t, notifier.messages)
+	})
+
+	t.Run("no_address", func(t *testing.T) {
+		notifier.err = ErrNoAddress
+		defer func() { notifier.err = nil }()
+
+		raffleStorage.EXPECT().Get("raffle_1").Return(raffle, nil)
+		participantStorage.EXPECT().Get("participant_1").Return(&participant, nil)
+		expectNotification(t, &WinnerNotification{Channel: "fake", Status: NotificationSkipped, Error: ErrNoAddress.Error(), At: now})
+
+		send(t)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		notifier.err = errors.New("post https://api.telegram.org/bot123:secret/sendMessage: connection refused")
+		defer func() { notifier.err = nil }()
+
+		raffleStorage.EXPECT().Get("raffle_1").Return(raffle, nil)
+		participantStorage.EXPECT().Get("participant_1").Return(&participant, nil)
+		expectNotification(t, &WinnerNotification{Channel: "fake", Status: NotificationFailed, Error: ErrNotDelivered.Error(), At: now})
+
+		send(t)
+	})
+
+	t.Run("disabled_for_raffle", func(t *testing.T) {
+		notifier.messages = nil
+
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+
+		assert.Nil(t, send(t))
+		assert.Empty(t, notifier.messages)
+	})
+
+	t.Run("errors_dont_stop_others", func(t *testing.T) {
+		notifier.messages = nil
+
+		other := queued
+		other.ID = "notification_2"
+
+		organizerStorage.EXPECT().QueuedNotifications().Return([]QueuedNotification{queued, other}, nil)
+		notificationStorage.EXPECT().Delete("notification_1").Return(assert.AnError)
+		notificationStorage.EXPECT().Delete("notification_2").Return(nil)
+		raffleStorage.EXPECT().Get("raffle_1").Return(raffle, nil)
+		participantStorage.EXPECT().Get("participant_1").Return(&participant, nil)
+		expectNotification(t, &WinnerNotification{Channel: "fake", Status: NotificationSent, MessageID: "message_1", At: now})
+
+		sent, err := om.SendNotifications()
+		assert.ErrorIs(t, err, assert.AnError)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "notification_2", sent[0].ID)
+		assert.Len(t, notifier.messages, 1)
+	})
+
+	t.Run("queue_error", func(t *testing.T) {
+		organizerStorage.EXPECT().QueuedNotifications().Return(nil, assert.AnError)
+
+		sent, err := om.SendNotifications()
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, sent)
+	})
+}
+
+func TestSendNotificationsWithoutNotifier(t *testing.T) {
+	om := NewOrganizerManager(NewMockOrganizerStorage(gomock.NewController(t)))
+
+	sent, err := om.SendNotifications()
+	require.NoError(t, err)
+	assert.Empty(t, sent)
+}
+
+func TestWinnerMessageValidation(t *testing.T) {
+	request := func(message string) *RaffleRequest {
+		return &RaffleRequest{Name: "Raffle", WinnerMessage: message}
+	}
+
+	assert.NoError(t, request("").Validate())
+	assert.NoError(t, request("Вітаємо, {{.Name}}! Ви виграли {{.Prize}}.").Validate())
+	assert.Error(t, request("Congratulations, {{.Name}").Validate())
+	assert.Error(t, request("Congratulations, {{.Nickname}}!").Validate())
+}
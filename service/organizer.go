@@ -3,6 +3,8 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"time"
 )
 
 var (
@@ -13,6 +15,20 @@ var (
 	ErrNotFound = errors.New("item not found")
 )
 
+// AlreadyExistsError is an ErrAlreadyExists which knows
+// the ID of the existing item that conflicts with the new one.
+type AlreadyExistsError struct {
+	ID string
+}
+
+func (e *AlreadyExistsError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAlreadyExists, e.ID)
+}
+
+func (e *AlreadyExistsError) Unwrap() error {
+	return ErrAlreadyExists
+}
+
 // Organizer represents an organizer of the application.
 type Organizer struct {
 	ID string `json:"id"`
@@ -24,13 +40,33 @@ type Organizer struct {
 type OrganizerStorage interface {
 	Create(*Organizer) error
 	Exists(id string) (bool, error)
+	GetAll() ([]Organizer, error)
 	RaffleStorage(organizerID string) RaffleStorage
+	WebhookStorage(organizerID string) WebhookStorage
+	DeliveryStorage(organizerID string) DeliveryStorage
+	NotificationStorage(organizerID string) NotificationStorage
+	// DuePrizes returns prizes of all organizers which are due by the moment.
+	DuePrizes(now time.Time) ([]DuePrize, error)
+	// ClosedRaffles returns raffles of all organizers which raffle.closed events are due by the moment.
+	ClosedRaffles(now time.Time) ([]Raffle, error)
+	// RetainedRaffles returns raffles of all organizers archived by the moment
+	// which participants are not anonymized yet.
+	RetainedRaffles(archivedBy time.Time) ([]Raffle, error)
+	// DueDeliveryOrganizers returns IDs of organizers which have deliveries due by the moment.
+	DueDeliveryOrganizers(now time.Time) ([]string, error)
+	// QueuedNotifications returns queued winner notifications of all organizers.
+	QueuedNotifications() ([]QueuedNotification, error)
 }
 
 // OrganizerService is a service for organizers.
 type OrganizerService interface {
 	CreateOrganizerIfNotExists(id string) error
 	RaffleService(organizerID string) RaffleService
+	PlayDuePrizes() ([]ScheduledPlay, error)
+	WebhookService(organizerID string) WebhookService
+	DeliverWebhooks() ([]Delivery, error)
+	SendNotifications() ([]QueuedNotification, error)
+	AnonymizeExpired() ([]AnonymizedRaffle, error)
 }
 
 var _ OrganizerService = (*OrganizerManager)(nil)
@@ -38,13 +74,29 @@ var _ OrganizerService = (*OrganizerManager)(nil)
 // OrganizerManager is an implementation of OrganizerService.
 type OrganizerManager struct {
 	organizerStorage OrganizerStorage
+	milestones       *MilestoneHooks
+	events           *EventHooks
+	webhooks         *WebhookDispatcher
+	// notifier is set by NotifyWinners.
+	notifier *winnerNotifier
+	// retention is how long participants of archived raffles are kept
+	// before being anonymized, see SetRetentionPeriod.
+	retention time.Duration
 }
 
-// NewOrganizerManager creates a new OrganizerManager.
+// NewOrganizerManager creates a new OrganizerManager
+// which delivers events of raffles to webhooks.
 func NewOrganizerManager(os OrganizerStorage) *OrganizerManager {
-	return &OrganizerManager{
+	om := &OrganizerManager{
 		organizerStorage: os,
+		milestones:       &MilestoneHooks{},
+		events:           &EventHooks{},
+		webhooks:         NewWebhookDispatcher(os, newWebhookClient()),
 	}
+
+	om.events.Subscribe(om.webhooks.dispatch)
+
+	return om
 }
 
 // CreateOrganizerIfNotExists creates an organizer if it does not exist.
@@ -63,5 +115,26 @@ func (om *OrganizerManager) CreateOrganizerIfNotExists(id string) error {
 
 // RaffleService is a service for raffles.
 func (om *OrganizerManager) RaffleService(organizerID string) RaffleService {
-	return NewRaffleManager(om.organizerStorage.RaffleStorage(organizerID))
+	return om.raffleManager(organizerID)
+}
+
+func (om *OrganizerManager) raffleManager(organizerID string) *RaffleManager {
+	rm := NewRaffleManager(om.organizerStorage.RaffleStorage(organizerID))
+	rm.organizerID = organizerID
+	rm.milestones = om.milestones
+	rm.events = om.events
+	rm.deliveryStorage = om.organizerStorage.DeliveryStorage(organizerID)
+
+	return rm
+}
+
+// Milestones returns hooks notified about milestones of goals of all raffles.
+func (om *OrganizerManager) Milestones() *MilestoneHooks {
+	return om.milestones
+}
+
+// Events returns hooks notified about events of all raffles.
+// Webhooks are subscribed to them from the start.
+func (om *OrganizerManager) Events() *EventHooks {
+	return om.events
 }
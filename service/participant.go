@@ -1,36 +1,112 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
+
+	"github.com/nyaruka/phonenumbers"
+	"golang.org/x/exp/slices"
 )
 
+var ErrMergeWithItself = errors.New("participant can't be merged with itself")
+
 // Participant represents a participant of the application.
 type Participant struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Phone     string    `json:"phone"`
-	Note      string    `json:"note"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+	Note  string `json:"note"`
+
+	// ContactAllowed is the consent of the participant to be contacted,
+	// e.g. to be notified about winning a prize.
+	ContactAllowed bool `json:"contactAllowed"`
+	// TelegramChatID is the chat of the participant with the Telegram bot.
+	TelegramChatID string `json:"telegramChatId,omitempty"`
+	// PublishName is the consent of the participant
+	// to show their name among the winners on the public board.
+	PublishName bool `json:"publishName"`
+
+	// AnonymizedAt is set when personal data of the participant is scrubbed.
+	AnonymizedAt *time.Time `json:"anonymizedAt,omitempty"`
+
 	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the version of the participant, see Versioned.
+	UpdatedAt time.Time `json:"updatedAt" firestore:"-"`
 }
 
 // ParticipantRequest is a request for creating a new/updated participant.
 type ParticipantRequest struct {
+	Precondition
+
 	Name  string `json:"name" validate:"required,min=2,max=50,charsValidation"`
 	Phone string `json:"phone" validate:"required,phoneValidation"`
 	Note  string `json:"note" validate:"lte=1000,charsValidation"`
+
+	ContactAllowed bool   `json:"contactAllowed"`
+	TelegramChatID string `json:"telegramChatId,omitempty" validate:"omitempty,number,max=20"`
+	PublishName    bool   `json:"publishName"`
 }
 
 func (p *ParticipantRequest) Validate() error {
-	return defaultValidator().Struct(p)
+	return validateStruct(p)
+}
+
+// ParticipantDetails is a participant with their donations
+// to all prizes of the raffle and tickets they grant.
+type ParticipantDetails struct {
+	Participant
+	Donations     []ParticipantDonation `json:"donations"`
+	Tickets       []PrizeTickets        `json:"tickets"`
+	TotalDonation int                   `json:"totalDonation"`
+	TotalTickets  int                   `json:"totalTickets"`
+}
+
+// ParticipantDonation is a donation of a participant.
+// PrizeID is empty for donations to the whole raffle.
+type ParticipantDonation struct {
+	Donation
+	PrizeID string `json:"prizeId,omitempty"`
+}
+
+// PrizeTickets is a number of tickets of a participant for a prize.
+type PrizeTickets struct {
+	PrizeID   string `json:"prizeId"`
+	PrizeName string `json:"prizeName"`
+	Tickets   int    `json:"tickets"`
+}
+
+// DuplicateParticipants is a group of participants with the same normalized phone.
+type DuplicateParticipants struct {
+	Phone        string        `json:"phone"`
+	Participants []Participant `json:"participants"`
+}
+
+// MergeParticipantsRequest is a request for merging duplicates into a participant.
+type MergeParticipantsRequest struct {
+	DuplicateIDs []string `json:"duplicateIds" validate:"required,min=1,unique,dive,required"`
+}
+
+func (r *MergeParticipantsRequest) Validate() error {
+	return validateStruct(r)
 }
 
 // ParticipantService is a service for participants.
 type ParticipantService interface {
 	Create(p *ParticipantRequest) (id string, err error)
+	Get(id string) (*ParticipantDetails, error)
 	Edit(id string, p *ParticipantRequest) error
+	Patch(id string, p *MergePatch) error
 	Delete(id string) error
 	List() ([]Participant, error)
+	Search(query string) ([]Participant, error)
+	Duplicates() ([]DuplicateParticipants, error)
+	Merge(id string, r *MergeParticipantsRequest) (*ParticipantDetails, error)
+	Export(id string) (*ParticipantExport, error)
+	Anonymize(id string, r *AnonymizeRequest) (*Participant, error)
 }
 
 // ParticipantStorage is a storage for participants.
@@ -40,13 +116,32 @@ type ParticipantStorage interface {
 	Create(*Participant) error
 	Get(id string) (*Participant, error)
 	Update(*Participant) error
+	UpdateFields(p *Participant, fields []string) error
 	GetAll() ([]Participant, error)
 	Delete(id string) error
+	Merge(*ParticipantMerge) error
+}
+
+// ParticipantMerge is a merge of duplicates into a participant,
+// which is written at once.
+type ParticipantMerge struct {
+	ParticipantID string
+	DuplicateIDs  []string
+	// Donations of the duplicates to reassign to the participant by prize ID.
+	// Donations made to the raffle are under the empty ID.
+	Donations map[string][]Donation
+	// Payments of the duplicates to reassign along with their donations.
+	Payments []Payment
 }
 
 // ParticipantManager is an implementation of ParticipantService.
 type ParticipantManager struct {
 	participantStorage ParticipantStorage
+	raffleID           string
+	raffleStorage      RaffleStorage
+	// deliveryStorage is set for raffles of organizers,
+	// so anonymization scrubs webhook payloads too.
+	deliveryStorage DeliveryStorage
 }
 
 // NewParticipantManager creates a new ParticipantManager.
@@ -61,6 +156,8 @@ func (pm *ParticipantManager) Create(p *ParticipantRequest) (string, error) {
 	}
 
 	prt := toParticipant(p)
+	prt.Phone = NormalizePhone(p.Phone)
+
 	if err := pm.participantStorage.Create(prt); err != nil {
 		return "", fmt.Errorf("creating participant: %w", err)
 	}
@@ -79,9 +176,13 @@ func (pm *ParticipantManager) Edit(id string, p *ParticipantRequest) error {
 		return fmt.Errorf("getting participant: %w", err)
 	}
 
-	prt.Name = p.Name
-	prt.Phone = p.Phone
-	prt.Note = p.Note
+	if err := p.checkVersion(prt); err != nil {
+		return err
+	}
+
+	if err := prt.edit(p); err != nil {
+		return err
+	}
 
 	if err := pm.participantStorage.Update(prt); err != nil {
 		return fmt.Errorf("updating participant: %w", err)
@@ -90,6 +191,22 @@ func (pm *ParticipantManager) Edit(id string, p *ParticipantRequest) error {
 	return nil
 }
 
+// edit applies the validated request to the participant.
+func (p *Participant) edit(r *ParticipantRequest) error {
+	if p.AnonymizedAt != nil {
+		return ErrParticipantAnonymized
+	}
+
+	p.Name = r.Name
+	p.Phone = NormalizePhone(r.Phone)
+	p.Note = r.Note
+	p.ContactAllowed = r.ContactAllowed
+	p.TelegramChatID = r.TelegramChatID
+	p.PublishName = r.PublishName
+
+	return nil
+}
+
 // Delete deletes a participant.
 func (pm *ParticipantManager) Delete(id string) error {
 	if err := pm.participantStorage.Delete(id); err != nil {
@@ -109,6 +226,316 @@ func (pm *ParticipantManager) List() ([]Participant, error) {
 	return prts, nil
 }
 
+// Get returns a participant with their donations across all prizes of the raffle.
+func (pm *ParticipantManager) Get(id string) (*ParticipantDetails, error) {
+	prt, err := pm.participantStorage.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("getting participant: %w", err)
+	}
+
+	details := &ParticipantDetails{
+		Participant: *prt,
+		Donations:   make([]ParticipantDonation, 0),
+		Tickets:     make([]PrizeTickets, 0),
+	}
+
+	if pm.raffleStorage == nil {
+		return details, nil
+	}
+
+	raffleDonations, err := pm.raffleStorage.DonationStorage(pm.raffleID).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("getting raffle donations: %w", err)
+	}
+
+	details.addDonations("", raffleDonations)
+
+	prizeManager := NewRaffleManager(pm.raffleStorage).prizeManager(pm.raffleID)
+
+	prizes, err := prizeManager.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range prizes {
+		prize := &prizes[i]
+
+		donations, err := prizeManager.prizeStorage.DonationStorage(prize.ID).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("getting donations of prize %q: %w", prize.ID, err)
+		}
+
+		details.addDonations(prize.ID, donations)
+
+		if prize.UseRaffleTickets {
+			donations = append(donations, raffleDonations...)
+		}
+
+		counter, err := prizeManager.ticketCounter(prize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, counted := range counter.count(donations, []Participant{*prt}) {
+			details.Tickets = append(details.Tickets, PrizeTickets{
+				PrizeID:   prize.ID,
+				PrizeName: prize.Name,
+				Tickets:   counted.TotalTicketsNumber,
+			})
+			details.TotalTickets += counted.TotalTicketsNumber
+		}
+	}
+
+	return details, nil
+}
+
+// addDonations adds donations of the participant from the given list.
+func (d *ParticipantDetails) addDonations(prizeID string, donations []Donation) {
+	for _, donation := range donations {
+		if donation.ParticipantID != d.ID {
+			continue
+		}
+
+		d.Donations = append(d.Donations, ParticipantDonation{Donation: donation, PrizeID: prizeID})
+		d.TotalDonation += donation.Amount
+	}
+}
+
+// Search returns participants which name or phone starts with the query.
+// Any word of the name can match. An empty query matches everybody.
+func (pm *ParticipantManager) Search(query string) ([]Participant, error) {
+	prts, err := pm.List()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return prts, nil
+	}
+
+	found := make([]Participant, 0)
+	for _, prt := range prts {
+		if matchesName(prt.Name, query) || matchesPhone(prt.Phone, query) {
+			found = append(found, prt)
+		}
+	}
+
+	return found, nil
+}
+
+func matchesName(name, query string) bool {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, query) {
+		return true
+	}
+
+	for _, word := range strings.Fields(name) {
+		if strings.HasPrefix(word, query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesPhone matches the query both with the full phone number
+// and with the national one, as cashiers usually type it without the country code.
+func matchesPhone(phone, query string) bool {
+	digits := digitsOnly(query)
+	if digits == "" || strings.IndexFunc(query, unicode.IsLetter) != -1 {
+		return false
+	}
+
+	if strings.HasPrefix(digitsOnly(NormalizePhone(phone)), digits) {
+		return true
+	}
+
+	number, err := phonenumbers.Parse(phone, defaultRegion())
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(digitsOnly(phonenumbers.Format(number, phonenumbers.NATIONAL)), digits)
+}
+
+// NormalizePhone formats the phone number in E.164.
+// Numbers that can't be parsed are stripped to digits.
+func NormalizePhone(phone string) string {
+	number, err := phonenumbers.Parse(phone, defaultRegion())
+	if err != nil {
+		return digitsOnly(phone)
+	}
+
+	return phonenumbers.Format(number, phonenumbers.E164)
+}
+
+// digitsOnly strips everything but digits from the string.
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// Duplicates returns groups of participants with the same normalized phone.
+// Anonymized participants have no phone and are never duplicates.
+func (pm *ParticipantManager) Duplicates() ([]DuplicateParticipants, error) {
+	prts, err := pm.List()
+	if err != nil {
+		return nil, err
+	}
+
+	byPhone := make(map[string][]Participant)
+	for _, prt := range prts {
+		phone := NormalizePhone(prt.Phone)
+		if phone == "" {
+			continue
+		}
+
+		byPhone[phone] = append(byPhone[phone], prt)
+	}
+
+	duplicates := make([]DuplicateParticipants, 0)
+	for phone, group := range byPhone {
+		if len(group) < 2 {
+			continue
+		}
+
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].CreatedAt.Before(group[j].CreatedAt)
+		})
+
+		duplicates = append(duplicates, DuplicateParticipants{Phone: phone, Participants: group})
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Phone < duplicates[j].Phone
+	})
+
+	return duplicates, nil
+}
+
+// Merge reassigns donations of the duplicates to the participant
+// and deletes the duplicates at once. Donations to played prizes and donations
+// locked by closed shifts can't be reassigned, so nothing is changed
+// if any duplicate has them.
+func (pm *ParticipantManager) Merge(id string, r *MergeParticipantsRequest) (*ParticipantDetails, error) {
+	if err := r.Validate(); err != nil {
+		return nil, errors.Join(err, ErrInvalidRequest)
+	}
+
+	if _, err := pm.participantStorage.Get(id); err != nil {
+		return nil, fmt.Errorf("getting participant: %w", err)
+	}
+
+	for _, duplicateID := range r.DuplicateIDs {
+		if duplicateID == id {
+			return nil, errors.Join(ErrMergeWithItself, ErrInvalidRequest)
+		}
+
+		if _, err := pm.participantStorage.Get(duplicateID); err != nil {
+			return nil, fmt.Errorf("getting duplicate %q: %w", duplicateID, err)
+		}
+	}
+
+	merge := &ParticipantMerge{
+		ParticipantID: id,
+		DuplicateIDs:  r.DuplicateIDs,
+		Donations:     make(map[string][]Donation),
+	}
+
+	if pm.raffleStorage != nil {
+		if err := pm.mergeDonations(merge); err != nil {
+			return nil, err
+		}
+
+		if err := pm.mergePayments(merge); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := pm.participantStorage.Merge(merge); err != nil {
+		return nil, fmt.Errorf("merging participants: %w", err)
+	}
+
+	return pm.Get(id)
+}
+
+// mergeDonations adds donations of the duplicates made to the raffle
+// and to any of its prizes to the merge.
+// Donations of payments are moved along with their payments by mergePayments,
+// since moving them here would move them and their totals twice.
+func (pm *ParticipantManager) mergeDonations(merge *ParticipantMerge) error {
+	prizeStorage := pm.raffleStorage.PrizeStorage(pm.raffleID)
+
+	prizes, err := prizeStorage.GetAll()
+	if err != nil {
+		return fmt.Errorf("getting prizes: %w", err)
+	}
+
+	prizeIDs := []string{""}
+	storages := []DonationStorage{pm.raffleStorage.DonationStorage(pm.raffleID)}
+	played := []bool{false}
+
+	for _, prize := range prizes {
+		prizeIDs = append(prizeIDs, prize.ID)
+		storages = append(storages, prizeStorage.DonationStorage(prize.ID))
+		played = append(played, prize.IsPlayed())
+	}
+
+	for i, ds := range storages {
+		donations, err := ds.GetAll()
+		if err != nil {
+			return fmt.Errorf("getting donations: %w", err)
+		}
+
+		for _, d := range donations {
+			if !slices.Contains(merge.DuplicateIDs, d.ParticipantID) {
+				continue
+			}
+
+			if played[i] {
+				return ErrEditPlayedPrizeDonations
+			}
+
+			if d.ShiftID != "" {
+				return ErrShiftClosed
+			}
+
+			if d.PaymentID == "" {
+				merge.Donations[prizeIDs[i]] = append(merge.Donations[prizeIDs[i]], d)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergePayments adds payments of the duplicates to the merge.
+func (pm *ParticipantManager) mergePayments(merge *ParticipantMerge) error {
+	payments, err := pm.raffleStorage.PaymentStorage(pm.raffleID).GetAll()
+	if err != nil {
+		return fmt.Errorf("getting payments: %w", err)
+	}
+
+	for _, payment := range payments {
+		if !slices.Contains(merge.DuplicateIDs, payment.ParticipantID) {
+			continue
+		}
+
+		if payment.ShiftID != "" {
+			return ErrShiftClosed
+		}
+
+		merge.Payments = append(merge.Payments, payment)
+	}
+
+	return nil
+}
+
 func toParticipant(p *ParticipantRequest) *Participant {
 	return &Participant{
 		ID:        stringUUID(),
@@ -116,5 +543,9 @@ func toParticipant(p *ParticipantRequest) *Participant {
 		Phone:     p.Phone,
 		Note:      p.Note,
 		CreatedAt: timeNow(),
+
+		ContactAllowed: p.ContactAllowed,
+		TelegramChatID: p.TelegramChatID,
+		PublishName:    p.PublishName,
 	}
 }
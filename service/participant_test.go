@@ -15,6 +15,7 @@ type ParticipantSuite struct {
 
 	ctrl     *gomock.Controller
 	storage  *MockParticipantStorage
+	payments *MockPaymentStorage
 	manager  *ParticipantManager
 	mockUUID string
 	mockTime time.Time
@@ -59,6 +60,17 @@ func (s *ParticipantSuite) TestCreateParticipant() {
 		require.Error(s.T(), err)
 	})
 
+	s.Run("phone_already_exists", func() {
+		s.storage.EXPECT().Create(mockedParticipant).Return(&AlreadyExistsError{ID: "participant_id_2"})
+
+		_, err := s.manager.Create(participantRequest)
+		require.ErrorIs(s.T(), err, ErrAlreadyExists)
+
+		var alreadyExists *AlreadyExistsError
+		require.ErrorAs(s.T(), err, &alreadyExists)
+		s.Equal("participant_id_2", alreadyExists.ID)
+	})
+
 	s.Run("invalid name", func() {
 		participantRequest := dummyParticipantRequest()
 		participantRequest.Name = "a"
@@ -115,6 +127,34 @@ func (s *ParticipantSuite) TestEditParticipant() {
 		require.Error(s.T(), err)
 	})
 
+	s.Run("if_version", func() {
+		version := s.mockTime.Add(time.Minute)
+		participant := *participant
+		participant.UpdatedAt = version
+
+		participantRequest := dummyParticipantRequest()
+		participantRequest.ExpectVersion(version)
+
+		s.storage.EXPECT().Get(participant.ID).Return(&participant, nil)
+		s.storage.EXPECT().Update(&participant).Return(nil)
+
+		err := s.manager.Edit(participant.ID, participantRequest)
+		require.NoError(s.T(), err)
+	})
+
+	s.Run("version_mismatch", func() {
+		participant := *participant
+		participant.UpdatedAt = s.mockTime.Add(time.Minute)
+
+		participantRequest := dummyParticipantRequest()
+		participantRequest.ExpectVersion(s.mockTime)
+
+		s.storage.EXPECT().Get(participant.ID).Return(&participant, nil)
+
+		err := s.manager.Edit(participant.ID, participantRequest)
+		require.ErrorIs(s.T(), err, ErrVersionMismatch)
+	})
+
 	s.Run("invalid name", func() {
 		participantRequest := dummyParticipantRequest()
 		participantRequest.Name = "a"
@@ -140,6 +180,94 @@ func (s *ParticipantSuite) TestEditParticipant() {
 	})
 }
 
+func (s *ParticipantSuite) TestPatchParticipant() {
+	participant := func() *Participant {
+		return &Participant{
+			ID:        s.mockUUID,
+			Name:      "John Doe",
+			Phone:     "+380501234567",
+			Note:      "Test participant",
+			CreatedAt: s.mockTime,
+		}
+	}
+
+	patched := participant()
+	patched.Note = "Patched note"
+	patched.PublishName = true
+
+	s.storage.EXPECT().Get(s.mockUUID).Return(participant(), nil)
+	s.storage.EXPECT().UpdateFields(patched, []string{"Note", "PublishName"}).Return(nil)
+
+	err := s.manager.Patch(s.mockUUID, &MergePatch{Document: []byte(`{"note":"Patched note","publishName":true}`)})
+	require.NoError(s.T(), err)
+
+	s.Run("reset", func() {
+		patched := participant()
+		patched.Note = ""
+
+		s.storage.EXPECT().Get(s.mockUUID).Return(participant(), nil)
+		s.storage.EXPECT().UpdateFields(patched, []string{"Note"}).Return(nil)
+
+		err := s.manager.Patch(s.mockUUID, &MergePatch{Document: []byte(`{"note":null}`)})
+		require.NoError(s.T(), err)
+	})
+
+	s.Run("nothing_changed", func() {
+		s.storage.EXPECT().Get(s.mockUUID).Return(participant(), nil)
+		s.storage.EXPECT().UpdateFields(participant(), []string{}).Return(nil)
+
+		err := s.manager.Patch(s.mockUUID, &MergePatch{Document: []byte(`{}`)})
+		require.NoError(s.T(), err)
+	})
+
+	s.Run("error_in_update", func() {
+		s.storage.EXPECT().Get(s.mockUUID).Return(participant(), nil)
+		s.storage.EXPECT().UpdateFields(patched, []string{"Note", "PublishName"}).Return(errors.New("test error"))
+
+		err := s.manager.Patch(s.mockUUID, &MergePatch{Document: []byte(`{"note":"Patched note","publishName":true}`)})
+		require.Error(s.T(), err)
+	})
+
+	s.Run("invalid_merged_request", func() {
+		s.storage.EXPECT().Get(s.mockUUID).Return(participant(), nil)
+
+		err := s.manager.Patch(s.mockUUID, &MergePatch{Document: []byte(`{"name":null}`)})
+		require.Error(s.T(), err)
+	})
+
+	s.Run("unknown_member", func() {
+		s.storage.EXPECT().Get(s.mockUUID).Return(participant(), nil)
+
+		err := s.manager.Patch(s.mockUUID, &MergePatch{Document: []byte(`{"id":"participant_id_2"}`)})
+		require.ErrorIs(s.T(), err, ErrInvalidPatch)
+	})
+
+	s.Run("version_mismatch", func() {
+		p := &MergePatch{Document: []byte(`{"note":"Patched note"}`)}
+		p.ExpectVersion(s.mockTime.Add(time.Minute))
+
+		s.storage.EXPECT().Get(s.mockUUID).Return(participant(), nil)
+
+		err := s.manager.Patch(s.mockUUID, p)
+		require.ErrorIs(s.T(), err, ErrVersionMismatch)
+		require.True(s.T(), p.WrittenVersion().IsZero())
+	})
+
+	s.Run("written_version", func() {
+		written := s.mockTime.Add(time.Hour)
+
+		s.storage.EXPECT().Get(s.mockUUID).Return(participant(), nil)
+		s.storage.EXPECT().UpdateFields(gomock.Any(), []string{"Note"}).DoAndReturn(func(p *Participant, _ []string) error {
+			p.SetVersion(written)
+			return nil
+		})
+
+		p := &MergePatch{Document: []byte(`{"note":"Patched note"}`)}
+		require.NoError(s.T(), s.manager.Patch(s.mockUUID, p))
+		require.Equal(s.T(), written, p.WrittenVersion())
+	})
+}
+
 func (s *ParticipantSuite) TestDeleteParticipant() {
 	participant := dummyParticipant()
 
@@ -174,10 +302,272 @@ func (s *ParticipantSuite) TestListParticipant() {
 	})
 }
 
+func (s *ParticipantSuite) bindRaffle() (*MockPrizeStorage, *MockDonationStorage) {
+	raffleStorage := NewMockRaffleStorage(s.ctrl)
+	prizeStorage := NewMockPrizeStorage(s.ctrl)
+	raffleDonations := NewMockDonationStorage(s.ctrl)
+	s.payments = NewMockPaymentStorage(s.ctrl)
+
+	s.manager.raffleID = "raffle_id"
+	s.manager.raffleStorage = raffleStorage
+
+	raffleStorage.EXPECT().PrizeStorage("raffle_id").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_id").Return(s.storage).AnyTimes()
+	raffleStorage.EXPECT().DonationStorage("raffle_id").Return(raffleDonations).AnyTimes()
+	raffleStorage.EXPECT().PaymentStorage("raffle_id").Return(s.payments).AnyTimes()
+
+	return prizeStorage, raffleDonations
+}
+
+func (s *ParticipantSuite) TestGetParticipant() {
+	prizeStorage, raffleDonations := s.bindRaffle()
+	prizeDonations := NewMockDonationStorage(s.ctrl)
+	otherPrizeDonations := NewMockDonationStorage(s.ctrl)
+
+	participant := dummyParticipant()
+	prizes := []Prize{
+		{ID: "prize_1", Name: "Prize 1", TicketCost: 100, UseRaffleTickets: true},
+		{ID: "prize_2", Name: "Prize 2", TicketCost: 50},
+	}
+
+	s.storage.EXPECT().Get(participant.ID).Return(participant, nil)
+	raffleDonations.EXPECT().GetAll().Return([]Donation{
+		{ID: "d1", ParticipantID: participant.ID, Amount: 150},
+		{ID: "d2", ParticipantID: "someone_else", Amount: 500},
+	}, nil)
+	prizeStorage.EXPECT().GetAll().Return(prizes, nil)
+	prizeStorage.EXPECT().DonationStorage("prize_1").Return(prizeDonations)
+	prizeStorage.EXPECT().DonationStorage("prize_2").Return(otherPrizeDonations)
+	prizeDonations.EXPECT().GetAll().Return([]Donation{
+		{ID: "d3", ParticipantID: participant.ID, Amount: 50},
+	}, nil)
+	otherPrizeDonations.EXPECT().GetAll().Return([]Donation{
+		{ID: "d4", ParticipantID: participant.ID, Amount: 120},
+	}, nil)
+
+	details, err := s.manager.Get(participant.ID)
+	s.Require().NoError(err)
+
+	s.Equal(*participant, details.Participant)
+	s.Equal([]ParticipantDonation{
+		{Donation: Donation{ID: "d1", ParticipantID: participant.ID, Amount: 150}},
+		{Donation: Donation{ID: "d3", ParticipantID: participant.ID, Amount: 50}, PrizeID: "prize_1"},
+		{Donation: Donation{ID: "d4", ParticipantID: participant.ID, Amount: 120}, PrizeID: "prize_2"},
+	}, details.Donations)
+	s.Equal([]PrizeTickets{
+		{PrizeID: "prize_1", PrizeName: "Prize 1", Tickets: 2},
+		{PrizeID: "prize_2", PrizeName: "Prize 2", Tickets: 2},
+	}, details.Tickets)
+	s.Equal(320, details.TotalDonation)
+	s.Equal(4, details.TotalTickets)
+
+	s.Run("not_found", func() {
+		s.storage.EXPECT().Get("unknown").Return(nil, ErrNotFound)
+
+		details, err := s.manager.Get("unknown")
+		s.ErrorIs(err, ErrNotFound)
+		s.Nil(details)
+	})
+}
+
+func (s *ParticipantSuite) TestSearchParticipants() {
+	participants := []Participant{
+		{ID: "p1", Name: "Taras Shevchenko", Phone: "+380671234567"},
+		{ID: "p2", Name: "Lesya Ukrainka", Phone: "+380501112233"},
+		{ID: "p3", Name: "Ivan Franko", Phone: "+380679998877"},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "tar", want: []string{"p1"}},
+		{query: "UKR", want: []string{"p2"}},
+		{query: "+38067", want: []string{"p1", "p3"}},
+		{query: "050 111", want: []string{"p2"}},
+		{query: "nobody", want: []string{}},
+		{query: "", want: []string{"p1", "p2", "p3"}},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.query, func() {
+			s.storage.EXPECT().GetAll().Return(participants, nil)
+
+			found, err := s.manager.Search(tt.query)
+			s.Require().NoError(err)
+
+			ids := make([]string, 0, len(found))
+			for _, p := range found {
+				ids = append(ids, p.ID)
+			}
+
+			s.Equal(tt.want, ids)
+		})
+	}
+}
+
+func (s *ParticipantSuite) TestDuplicateParticipants() {
+	now := time.Now()
+	participants := []Participant{
+		{ID: "p1", Phone: "+380671234567", CreatedAt: now.Add(time.Minute)},
+		{ID: "p2", Phone: "+380501112233", CreatedAt: now},
+		{ID: "p3", Phone: "+38 067 123 45 67", CreatedAt: now},
+		{ID: "p4", AnonymizedAt: &now},
+		{ID: "p5", AnonymizedAt: &now},
+	}
+
+	s.storage.EXPECT().GetAll().Return(participants, nil)
+
+	duplicates, err := s.manager.Duplicates()
+	s.Require().NoError(err)
+	s.Equal([]DuplicateParticipants{
+		{Phone: "+380671234567", Participants: []Participant{participants[2], participants[0]}},
+	}, duplicates)
+}
+
+func (s *ParticipantSuite) TestMergeParticipants() {
+	prizeStorage, raffleDonations := s.bindRaffle()
+	prizeDonations := NewMockDonationStorage(s.ctrl)
+
+	target := &Participant{ID: "p1"}
+	duplicate := &Participant{ID: "p2"}
+
+	s.storage.EXPECT().Get("p1").Return(target, nil).Times(2)
+	s.storage.EXPECT().Get("p2").Return(duplicate, nil)
+	prizeStorage.EXPECT().GetAll().Return([]Prize{{ID: "prize_1", TicketCost: 10}}, nil).Times(2)
+	prizeStorage.EXPECT().DonationStorage("prize_1").Return(prizeDonations).Times(2)
+
+	raffleDonations.EXPECT().GetAll().Return([]Donation{{ID: "d1", ParticipantID: "p2", Amount: 10}}, nil)
+	prizeDonations.EXPECT().GetAll().Return([]Donation{{ID: "d2", ParticipantID: "p1", Amount: 10}, {ID: "d3", ParticipantID: "p2", Amount: 20}}, nil)
+	s.payments.EXPECT().GetAll().Return([]Payment{{ID: "pay1", ParticipantID: "p1"}, {ID: "pay2", ParticipantID: "p2"}}, nil)
+	s.storage.EXPECT().Merge(&ParticipantMerge{
+		ParticipantID: "p1",
+		DuplicateIDs:  []string{"p2"},
+		Donations: map[string][]Donation{
+			"":        {{ID: "d1", ParticipantID: "p2", Amount: 10}},
+			"prize_1": {{ID: "d3", ParticipantID: "p2", Amount: 20}},
+		},
+		Payments: []Payment{{ID: "pay2", ParticipantID: "p2"}},
+	}).Return(nil)
+
+	raffleDonations.EXPECT().GetAll().Return([]Donation{{ID: "d1", ParticipantID: "p1", Amount: 10}}, nil)
+	prizeDonations.EXPECT().GetAll().Return([]Donation{{ID: "d2", ParticipantID: "p1", Amount: 10}, {ID: "d3", ParticipantID: "p1", Amount: 20}}, nil)
+
+	details, err := s.manager.Merge("p1", &MergeParticipantsRequest{DuplicateIDs: []string{"p2"}})
+	s.Require().NoError(err)
+	s.Equal(40, details.TotalDonation)
+	s.Equal(3, details.TotalTickets)
+
+	s.Run("payment_donations", func() {
+		s.storage.EXPECT().Get("p1").Return(target, nil).Times(2)
+		s.storage.EXPECT().Get("p2").Return(duplicate, nil)
+		prizeStorage.EXPECT().GetAll().Return([]Prize{{ID: "prize_1", TicketCost: 10}}, nil).Times(2)
+		prizeStorage.EXPECT().DonationStorage("prize_1").Return(prizeDonations).Times(2)
+
+		payment := Payment{
+			ID:            "pay3",
+			ParticipantID: "p2",
+			Amount:        30,
+			Allocations:   []PaymentAllocation{{PrizeID: "prize_1", DonationID: "d5", Amount: 30}},
+		}
+
+		raffleDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+		prizeDonations.EXPECT().GetAll().Return([]Donation{
+			{ID: "d4", ParticipantID: "p2", Amount: 10},
+			{ID: "d5", ParticipantID: "p2", Amount: 30, PaymentID: "pay3"},
+		}, nil)
+
+		// The payment donation is moved only along with its payment.
+		s.payments.EXPECT().GetAll().Return([]Payment{payment}, nil)
+		s.storage.EXPECT().Merge(&ParticipantMerge{
+			ParticipantID: "p1",
+			DuplicateIDs:  []string{"p2"},
+			Donations:     map[string][]Donation{"prize_1": {{ID: "d4", ParticipantID: "p2", Amount: 10}}},
+			Payments:      []Payment{payment},
+		}).Return(nil)
+
+		raffleDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+		prizeDonations.EXPECT().GetAll().Return([]Donation{
+			{ID: "d4", ParticipantID: "p1", Amount: 10},
+			{ID: "d5", ParticipantID: "p1", Amount: 30, PaymentID: "pay3"},
+		}, nil)
+
+		details, err := s.manager.Merge("p1", &MergeParticipantsRequest{DuplicateIDs: []string{"p2"}})
+		s.Require().NoError(err)
+		s.Equal(40, details.TotalDonation)
+	})
+
+	s.Run("played_prize", func() {
+		s.storage.EXPECT().Get("p1").Return(target, nil)
+		s.storage.EXPECT().Get("p2").Return(duplicate, nil)
+		prizeStorage.EXPECT().GetAll().Return([]Prize{{ID: "prize_1", TicketCost: 10, PlayResult: dummyPlayResult()}}, nil)
+		prizeStorage.EXPECT().DonationStorage("prize_1").Return(prizeDonations)
+		raffleDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+		prizeDonations.EXPECT().GetAll().Return([]Donation{{ID: "d3", ParticipantID: "p2", Amount: 20}}, nil)
+
+		details, err := s.manager.Merge("p1", &MergeParticipantsRequest{DuplicateIDs: []string{"p2"}})
+		s.ErrorIs(err, ErrEditPlayedPrizeDonations)
+		s.Nil(details)
+	})
+
+	s.Run("closed_shift", func() {
+		s.storage.EXPECT().Get("p1").Return(target, nil)
+		s.storage.EXPECT().Get("p2").Return(duplicate, nil)
+		prizeStorage.EXPECT().GetAll().Return([]Prize{{ID: "prize_1", TicketCost: 10}}, nil)
+		prizeStorage.EXPECT().DonationStorage("prize_1").Return(prizeDonations)
+		raffleDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+		prizeDonations.EXPECT().GetAll().Return([]Donation{{ID: "d3", ParticipantID: "p2", Amount: 20, ShiftID: "shift_1"}}, nil)
+
+		details, err := s.manager.Merge("p1", &MergeParticipantsRequest{DuplicateIDs: []string{"p2"}})
+		s.ErrorIs(err, ErrShiftClosed)
+		s.Nil(details)
+	})
+
+	s.Run("closed_shift_payment", func() {
+		s.storage.EXPECT().Get("p1").Return(target, nil)
+		s.storage.EXPECT().Get("p2").Return(duplicate, nil)
+		prizeStorage.EXPECT().GetAll().Return([]Prize{}, nil)
+		raffleDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+		s.payments.EXPECT().GetAll().Return([]Payment{{ID: "pay4", ParticipantID: "p2", ShiftID: "shift_1"}}, nil)
+
+		details, err := s.manager.Merge("p1", &MergeParticipantsRequest{DuplicateIDs: []string{"p2"}})
+		s.ErrorIs(err, ErrShiftClosed)
+		s.Nil(details)
+	})
+
+	s.Run("merge_error", func() {
+		s.storage.EXPECT().Get("p1").Return(target, nil)
+		s.storage.EXPECT().Get("p2").Return(duplicate, nil)
+		prizeStorage.EXPECT().GetAll().Return([]Prize{}, nil)
+		raffleDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+		s.payments.EXPECT().GetAll().Return([]Payment{}, nil)
+		s.storage.EXPECT().Merge(gomock.Any()).Return(ErrVersionMismatch)
+
+		details, err := s.manager.Merge("p1", &MergeParticipantsRequest{DuplicateIDs: []string{"p2"}})
+		s.ErrorIs(err, ErrVersionMismatch)
+		s.Nil(details)
+	})
+
+	s.Run("with_itself", func() {
+		s.storage.EXPECT().Get("p1").Return(target, nil)
+
+		details, err := s.manager.Merge("p1", &MergeParticipantsRequest{DuplicateIDs: []string{"p1"}})
+		s.ErrorIs(err, ErrMergeWithItself)
+		s.ErrorIs(err, ErrInvalidRequest)
+		s.Nil(details)
+	})
+
+	s.Run("invalid_request", func() {
+		details, err := s.manager.Merge("p1", &MergeParticipantsRequest{})
+		s.ErrorIs(err, ErrInvalidRequest)
+		s.Nil(details)
+	})
+}
+
 func dummyParticipantRequest() *ParticipantRequest {
 	return &ParticipantRequest{
 		Name:  "John Doe",
-		Phone: "+380123456789",
+		Phone: "+380501234567",
 		Note:  "Test participant",
 	}
 }
@@ -186,7 +576,7 @@ func dummyParticipant() *Participant {
 	return &Participant{
 		ID:        stringUUID(),
 		Name:      "John Doe",
-		Phone:     "+380123456789",
+		Phone:     "+380501234567",
 		Note:      "Test participant",
 		CreatedAt: timeNow(),
 	}
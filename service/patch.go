@@ -0,0 +1,300 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrInvalidPatch is returned when a merge patch isn't a JSON object
+// or doesn't result in a valid request.
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// MergePatch is a JSON Merge Patch (RFC 7396) of an entity.
+// It's applied to the request editing the entity as a whole, e.g. RaffleRequest,
+// so members missing in the patch are kept and null members are reset.
+// The merged request is validated as if it was given in full.
+type MergePatch struct {
+	Precondition
+
+	// Document is the patch as it's given.
+	Document json.RawMessage
+}
+
+// UnmarshalJSON keeps the patch as it's given.
+func (p *MergePatch) UnmarshalJSON(data []byte) error {
+	p.Document = append(p.Document[:0], data...)
+	return nil
+}
+
+// applyPatch returns a new request merged from the current one and the patch.
+// Members which don't belong to the request are rejected.
+func applyPatch[R any](current *R, patch *MergePatch) (*R, error) {
+	var changes map[string]any
+	if err := json.Unmarshal(patch.Document, &changes); err != nil || changes == nil {
+		return nil, errors.Join(ErrInvalidPatch, ErrInvalidRequest)
+	}
+
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	var target map[string]any
+	if err := json.Unmarshal(encoded, &target); err != nil {
+		return nil, fmt.Errorf("decode request: %w", err)
+	}
+
+	merged, err := json.Marshal(mergePatch(target, changes))
+	if err != nil {
+		return nil, fmt.Errorf("encode merged request: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+
+	var r R
+	if err := decoder.Decode(&r); err != nil {
+		return nil, errors.Join(fmt.Errorf("%w: %s", ErrInvalidPatch, err), ErrInvalidRequest)
+	}
+
+	return &r, nil
+}
+
+// mergePatch merges the patch into the target as defined by RFC 7396.
+func mergePatch(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	merged, ok := target.(map[string]any)
+	if !ok {
+		merged = make(map[string]any, len(changes))
+	}
+
+	for name, value := range changes {
+		if value == nil {
+			delete(merged, name)
+			continue
+		}
+
+		merged[name] = mergePatch(merged[name], value)
+	}
+
+	return merged
+}
+
+// changedFields returns names of the fields which differ between the entities,
+// so only they are written by the storage. Versions are not compared.
+func changedFields[E any](old, updated *E) []string {
+	oldValue := reflect.ValueOf(old).Elem()
+	updatedValue := reflect.ValueOf(updated).Elem()
+
+	fields := make([]string, 0)
+
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if !field.IsExported() || field.Name == "UpdatedAt" {
+			continue
+		}
+
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), updatedValue.Field(i).Interface()) {
+			fields = append(fields, field.Name)
+		}
+	}
+
+	return fields
+}
+
+// Patch applies the merge patch to the raffle.
+func (rm *RaffleManager) Patch(id string, p *MergePatch) error {
+	raffle, err := rm.Get(id)
+	if err != nil {
+		return fmt.Errorf("get raffle: %w", err)
+	}
+
+	if err := p.checkVersion(raffle); err != nil {
+		return err
+	}
+
+	r, err := applyPatch(toRaffleRequest(raffle), p)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Validate(); err != nil {
+		return errors.Join(err, ErrInvalidRequest)
+	}
+
+	old := *raffle
+	if err := rm.edit(raffle, r); err != nil {
+		return err
+	}
+
+	if err := rm.raffleStorage.UpdateFields(raffle, changedFields(&old, raffle)); err != nil {
+		return fmt.Errorf("update raffle: %w", err)
+	}
+
+	p.wrote(raffle)
+
+	return nil
+}
+
+// Patch applies the merge patch to the prize.
+func (pm *PrizeManager) Patch(id string, p *MergePatch) error {
+	prize, err := pm.prizeStorage.Get(id)
+	if err != nil {
+		return fmt.Errorf("get prize: %w", err)
+	}
+
+	if err := p.checkVersion(prize); err != nil {
+		return err
+	}
+
+	r, err := applyPatch(toPrizeRequest(prize), p)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Validate(); err != nil {
+		return fmt.Errorf("validate prize: %w", err)
+	}
+
+	old := *prize
+	if err := prize.edit(r); err != nil {
+		return err
+	}
+
+	if err := pm.prizeStorage.UpdateFields(prize, changedFields(&old, prize)); err != nil {
+		return fmt.Errorf("update prize: %w", err)
+	}
+
+	p.wrote(prize)
+
+	return nil
+}
+
+// Patch applies the merge patch to the participant.
+func (pm *ParticipantManager) Patch(id string, p *MergePatch) error {
+	prt, err := pm.participantStorage.Get(id)
+	if err != nil {
+		return fmt.Errorf("getting participant: %w", err)
+	}
+
+	if err := p.checkVersion(prt); err != nil {
+		return err
+	}
+
+	r, err := applyPatch(toParticipantRequest(prt), p)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	old := *prt
+	if err := prt.edit(r); err != nil {
+		return err
+	}
+
+	if err := pm.participantStorage.UpdateFields(prt, changedFields(&old, prt)); err != nil {
+		return fmt.Errorf("updating participant: %w", err)
+	}
+
+	p.wrote(prt)
+
+	return nil
+}
+
+// Patch applies the merge patch to the donation.
+func (dm *DonationManager) Patch(id string, p *MergePatch) error {
+	donation, err := dm.donationStorage.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := p.checkVersion(donation); err != nil {
+		return err
+	}
+
+	d, err := applyPatch(toDonationRequest(donation), p)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Validate(); err != nil {
+		return errors.Join(err, ErrInvalidRequest)
+	}
+
+	old := *donation
+	if err := dm.edit(donation, d); err != nil {
+		return err
+	}
+
+	if err := dm.donationStorage.UpdateFields(donation, changedFields(&old, donation)); err != nil {
+		return err
+	}
+
+	p.wrote(donation)
+
+	return nil
+}
+
+// toRaffleRequest returns the request which the raffle is edited with as a whole.
+func toRaffleRequest(r *Raffle) *RaffleRequest {
+	return &RaffleRequest{
+		Name:                    r.Name,
+		Note:                    r.Note,
+		StartsAt:                r.StartsAt,
+		EndsAt:                  r.EndsAt,
+		ExcludeWinners:          r.ExcludeWinners,
+		MaxPrizesPerParticipant: r.MaxPrizesPerParticipant,
+		Currency:                r.Currency,
+		Goal:                    r.Goal,
+		WinnerMessage:           r.WinnerMessage,
+	}
+}
+
+// toPrizeRequest returns the request which the prize is edited with as a whole.
+func toPrizeRequest(p *Prize) *PrizeRequest {
+	return &PrizeRequest{
+		Name:             p.Name,
+		TicketCost:       p.TicketCost,
+		Description:      p.Description,
+		DrawAt:           p.DrawAt,
+		ClaimDays:        p.ClaimDays,
+		DrawStrategy:     p.DrawStrategy,
+		MaxTickets:       p.MaxTickets,
+		UseRaffleTickets: p.UseRaffleTickets,
+		ExcludeWinners:   p.ExcludeWinners,
+		Goal:             p.Goal,
+	}
+}
+
+// toParticipantRequest returns the request which the participant is edited with as a whole.
+func toParticipantRequest(p *Participant) *ParticipantRequest {
+	return &ParticipantRequest{
+		Name:           p.Name,
+		Phone:          p.Phone,
+		Note:           p.Note,
+		ContactAllowed: p.ContactAllowed,
+		TelegramChatID: p.TelegramChatID,
+		PublishName:    p.PublishName,
+	}
+}
+
+// toDonationRequest returns the request which the donation is edited with as a whole.
+func toDonationRequest(d *Donation) *DonationRequest {
+	return &DonationRequest{
+		Amount:        d.Amount,
+		Original:      d.Original,
+		ExchangeRate:  d.ExchangeRate,
+		ParticipantID: d.ParticipantID,
+		Method:        d.Method,
+	}
+}
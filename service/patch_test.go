@@ -0,0 +1,86 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7396, Appendix A.
+	tests := []struct {
+		target string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		var target, patch any
+		require.NoError(t, json.Unmarshal([]byte(tt.target), &target))
+		require.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+
+		result, err := json.Marshal(mergePatch(target, patch))
+		require.NoError(t, err)
+		require.JSONEq(t, tt.result, string(result), "%s merged with %s", tt.target, tt.patch)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	current := &ParticipantRequest{Name: "John Doe", Phone: "+380501234567", Note: "Test participant"}
+
+	t.Run("merged", func(t *testing.T) {
+		r, err := applyPatch(current, &MergePatch{Document: []byte(`{"note":null,"contactAllowed":true}`)})
+		require.NoError(t, err)
+		require.Equal(t, &ParticipantRequest{Name: "John Doe", Phone: "+380501234567", ContactAllowed: true}, r)
+	})
+
+	t.Run("decoded", func(t *testing.T) {
+		var p MergePatch
+		require.NoError(t, json.Unmarshal([]byte(`{"name":"Jane Doe"}`), &p))
+
+		r, err := applyPatch(current, &p)
+		require.NoError(t, err)
+		require.Equal(t, "Jane Doe", r.Name)
+	})
+
+	for name, patch := range map[string]string{
+		"not_object":     `["note"]`,
+		"null":           `null`,
+		"unknown_member": `{"id":"participant_id_2"}`,
+		"wrong_type":     `{"contactAllowed":"yes"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := applyPatch(current, &MergePatch{Document: []byte(patch)})
+			require.ErrorIs(t, err, ErrInvalidPatch)
+			require.ErrorIs(t, err, ErrInvalidRequest)
+		})
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	old := dummyParticipant()
+
+	updated := *old
+	updated.Note = "Changed note"
+	updated.UpdatedAt = old.UpdatedAt.Add(1)
+
+	require.Equal(t, []string{"Note"}, changedFields(old, &updated))
+	require.Empty(t, changedFields(old, old))
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrPaymentNotReconciled     = errors.New("allocations don't add up to the payment amount")
+	ErrDonationBelongsToPayment = errors.New("donation belongs to a payment, edit the payment instead")
+)
+
+// PaymentMethod is a way the payment was made.
+type PaymentMethod string
+
+const (
+	PaymentCash         PaymentMethod = "cash"
+	PaymentCard         PaymentMethod = "card"
+	PaymentBankTransfer PaymentMethod = "bank_transfer"
+)
+
+// Payment is a sum paid by a participant at once
+// and allocated to one or more prizes of the raffle.
+// Each allocation is stored as a donation to the prize.
+type Payment struct {
+	ID            string              `json:"id"`
+	ParticipantID string              `json:"participantId"`
+	Amount        int                 `json:"amount"`
+	Method        PaymentMethod       `json:"method"`
+	Reference     string              `json:"reference,omitempty"`
+	Allocations   []PaymentAllocation `json:"allocations"`
+	// EnteredBy is the authenticated user who entered the payment.
+	EnteredBy string `json:"enteredBy,omitempty"`
+	// ShiftID refers to the closed shift which locks the payment.
+	ShiftID   string    `json:"shiftId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the version of the payment, see Versioned.
+	UpdatedAt time.Time `json:"updatedAt" firestore:"-"`
+}
+
+// PaymentAllocation is a part of the payment donated to a prize.
+type PaymentAllocation struct {
+	PrizeID    string `json:"prizeId"`
+	DonationID string `json:"donationId"`
+	Amount     int    `json:"amount"`
+}
+
+// Donations returns donations made by the payment keyed by prize ID.
+func (p *Payment) Donations() map[string][]Donation {
+	donations := make(map[string][]Donation, len(p.Allocations))
+	for _, a := range p.Allocations {
+		donations[a.PrizeID] = append(donations[a.PrizeID], Donation{
+			ID:            a.DonationID,
+			ParticipantID: p.ParticipantID,
+			Amount:        a.Amount,
+			Method:        p.Method,
+			EnteredBy:     p.EnteredBy,
+			PaymentID:     p.ID,
+			ShiftID:       p.ShiftID,
+			CreatedAt:     p.CreatedAt,
+		})
+	}
+
+	return donations
+}
+
+// PaymentRequest is a request for creating/updating a payment.
+type PaymentRequest struct {
+	Precondition
+
+	ParticipantID string        `json:"participantId" validate:"required"`
+	Amount        int           `json:"amount" validate:"gt=0"`
+	Method        PaymentMethod `json:"method" validate:"required,oneof=cash card bank_transfer"`
+	// Reference identifies a bank transfer.
+	Reference   string              `json:"reference" validate:"required_if=Method bank_transfer,max=100"`
+	Allocations []AllocationRequest `json:"allocations" validate:"required,min=1,unique=PrizeID,dive"`
+	// EnteredBy is set from the authenticated user rather than the request body.
+	EnteredBy string `json:"-"`
+}
+
+// AllocationRequest is a request for allocating a part of the payment to a prize.
+type AllocationRequest struct {
+	PrizeID string `json:"prizeId" validate:"required"`
+	Amount  int    `json:"amount" validate:"gt=0"`
+}
+
+func (r *PaymentRequest) Validate() error {
+	if err := validateStruct(r); err != nil {
+		return err
+	}
+
+	total := 0
+	for _, a := range r.Allocations {
+		total += a.Amount
+	}
+
+	if total != r.Amount {
+		return fmt.Errorf("%w: %d allocated of %d", ErrPaymentNotReconciled, total, r.Amount)
+	}
+
+	return nil
+}
+
+// PaymentService is a service for payments.
+type PaymentService interface {
+	Create(*PaymentRequest) (id string, err error)
+	Get(id string) (*Payment, error)
+	List() ([]Payment, error)
+	Edit(id string, p *PaymentRequest) error
+	Delete(id string) error
+}
+
+// PaymentStorage is a storage for payments.
+// It writes payments along with donations of their allocations.
+//
+//go:generate mockgen -destination=mock_payment_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service PaymentStorage
+type PaymentStorage interface {
+	Create(*Payment) error
+	Get(id string) (*Payment, error)
+	GetAll() ([]Payment, error)
+	Update(*Payment) error
+	Delete(id string) error
+}
+
+var _ PaymentService = (*PaymentManager)(nil)
+
+// PaymentManager is an implementation of PaymentService.
+type PaymentManager struct {
+	paymentStorage     PaymentStorage
+	prizeStorage       PrizeStorage
+	participantStorage ParticipantStorage
+}
+
+// NewPaymentManager creates a new PaymentManager.
+func NewPaymentManager(ps PaymentStorage, prs PrizeStorage, pts ParticipantStorage) *PaymentManager {
+	return &PaymentManager{
+		paymentStorage:     ps,
+		prizeStorage:       prs,
+		participantStorage: pts,
+	}
+}
+
+// Create creates a new payment with a donation per allocation.
+func (pm *PaymentManager) Create(r *PaymentRequest) (string, error) {
+	if err := pm.validate(r); err != nil {
+		return "", err
+	}
+
+	payment := &Payment{
+		ID:            stringUUID(),
+		ParticipantID: r.ParticipantID,
+		Amount:        r.Amount,
+		Method:        r.Method,
+		Reference:     r.Reference,
+		Allocations:   toAllocations(r.Allocations, nil),
+		EnteredBy:     r.EnteredBy,
+		CreatedAt:     timeNow(),
+	}
+
+	if err := pm.checkPlayedPrizes(nil, payment); err != nil {
+		return "", err
+	}
+
+	if err := pm.paymentStorage.Create(payment); err != nil {
+		return "", fmt.Errorf("create payment: %w", err)
+	}
+
+	return payment.ID, nil
+}
+
+// Get returns a payment.
+func (pm *PaymentManager) Get(id string) (*Payment, error) {
+	payment, err := pm.paymentStorage.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+// List returns all payments of the raffle.
+func (pm *PaymentManager) List() ([]Payment, error) {
+	payments, err := pm.paymentStorage.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all payments: %w", err)
+	}
+
+	return payments, nil
+}
+
+// Edit updates a payment along with its allocations.
+// Donations to the prizes which stay allocated keep their IDs.
+func (pm *PaymentManager) Edit(id string, r *PaymentRequest) error {
+	if err := pm.validate(r); err != nil {
+		return err
+	}
+
+	old, err := pm.paymentStorage.Get(id)
+	if err != nil {
+		return fmt.Errorf("get payment: %w", err)
+	}
+
+	if err := r.checkVersion(old); err != nil {
+		return err
+	}
+
+	if old.ShiftID != "" {
+		return ErrShiftClosed
+	}
+
+	payment := *old
+	payment.ParticipantID = r.ParticipantID
+	payment.Amount = r.Amount
+	payment.Method = r.Method
+	payment.Reference = r.Reference
+	payment.Allocations = toAllocations(r.Allocations, old.Allocations)
+
+	if err := pm.checkPlayedPrizes(old, &payment); err != nil {
+		return err
+	}
+
+	if err := pm.paymentStorage.Update(&payment); err != nil {
+		return fmt.Errorf("update payment: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a payment along with its donations.
+func (pm *PaymentManager) Delete(id string) error {
+	payment, err := pm.paymentStorage.Get(id)
+	if err != nil {
+		return fmt.Errorf("get payment: %w", err)
+	}
+
+	if payment.ShiftID != "" {
+		return ErrShiftClosed
+	}
+
+	if err := pm.checkPlayedPrizes(payment, nil); err != nil {
+		return err
+	}
+
+	if err := pm.paymentStorage.Delete(id); err != nil {
+		return fmt.Errorf("delete payment: %w", err)
+	}
+
+	return nil
+}
+
+func (pm *PaymentManager) validate(r *PaymentRequest) error {
+	if err := r.Validate(); err != nil {
+		return errors.Join(err, ErrInvalidRequest)
+	}
+
+	if _, err := pm.participantStorage.Get(r.ParticipantID); err != nil {
+		return fmt.Errorf("get participant: %w", err)
+	}
+
+	return nil
+}
+
+// checkPlayedPrizes follows the ReadonlyDonationService rules:
+// donations to played prizes can't be created, changed or deleted.
+// Either of the payments may be nil for creating or deleting.
+func (pm *PaymentManager) checkPlayedPrizes(old, updated *Payment) error {
+	prizes, err := pm.prizeStorage.GetAll()
+	if err != nil {
+		return fmt.Errorf("get all prizes: %w", err)
+	}
+
+	prizesByID := make(map[string]*Prize, len(prizes))
+	for i := range prizes {
+		prizesByID[prizes[i].ID] = &prizes[i]
+	}
+
+	oldDonations, updatedDonations := paymentDonations(old), paymentDonations(updated)
+
+	for prizeID := range updatedDonations {
+		if _, ok := prizesByID[prizeID]; !ok {
+			return fmt.Errorf("prize %q: %w", prizeID, ErrNotFound)
+		}
+	}
+
+	for _, donations := range []map[string]Donation{oldDonations, updatedDonations} {
+		for prizeID := range donations {
+			prize, ok := prizesByID[prizeID]
+			if !ok || !prize.IsPlayed() {
+				continue
+			}
+
+			if oldDonations[prizeID] != updatedDonations[prizeID] {
+				return fmt.Errorf("prize %q: %w", prizeID, ErrEditPlayedPrizeDonations)
+			}
+		}
+	}
+
+	return nil
+}
+
+// paymentDonations returns the donation of the payment to each prize
+// reduced to the fields which affect the prize draw.
+func paymentDonations(p *Payment) map[string]Donation {
+	if p == nil {
+		return nil
+	}
+
+	donations := make(map[string]Donation, len(p.Allocations))
+	for prizeID, prizeDonations := range p.Donations() {
+		d := prizeDonations[0]
+		donations[prizeID] = Donation{ID: d.ID, ParticipantID: d.ParticipantID, Amount: d.Amount}
+	}
+
+	return donations
+}
+
+// toAllocations reuses donation IDs of the existing allocations to the same prizes.
+func toAllocations(requests []AllocationRequest, existing []PaymentAllocation) []PaymentAllocation {
+	donationIDs := make(map[string]string, len(existing))
+	for _, a := range existing {
+		donationIDs[a.PrizeID] = a.DonationID
+	}
+
+	allocations := make([]PaymentAllocation, 0, len(requests))
+	for _, r := range requests {
+		donationID, ok := donationIDs[r.PrizeID]
+		if !ok {
+			donationID = stringUUID()
+		}
+
+		allocations = append(allocations, PaymentAllocation{
+			PrizeID:    r.PrizeID,
+			DonationID: donationID,
+			Amount:     r.Amount,
+		})
+	}
+
+	return allocations
+}
+
+// ClosedPaymentService is a PaymentService that
+// refuses new payments after the raffle is over.
+type ClosedPaymentService struct {
+	PaymentService
+}
+
+// NewClosedPaymentService creates a new ClosedPaymentService.
+func NewClosedPaymentService(ps PaymentService) *ClosedPaymentService {
+	return &ClosedPaymentService{
+		PaymentService: ps,
+	}
+}
+
+// Create is a stub that returns an error.
+func (c *ClosedPaymentService) Create(*PaymentRequest) (string, error) {
+	return "", ErrRaffleClosed
+}
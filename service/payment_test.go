@@ -0,0 +1,325 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type PaymentSuite struct {
+	suite.Suite
+	paymentStorage     *MockPaymentStorage
+	prizeStorage       *MockPrizeStorage
+	participantStorage *MockParticipantStorage
+	manager            *PaymentManager
+	mockTime           time.Time
+	prizes             []Prize
+}
+
+func TestPayment(t *testing.T) {
+	suite.Run(t, &PaymentSuite{})
+}
+
+func (s *PaymentSuite) SetupTest() {
+	s.mockTime = time.Now().UTC()
+	setTimeNowMock(s.mockTime)
+	setUUIDMock("new_id")
+
+	ctrl := gomock.NewController(s.T())
+	s.paymentStorage = NewMockPaymentStorage(ctrl)
+	s.prizeStorage = NewMockPrizeStorage(ctrl)
+	s.participantStorage = NewMockParticipantStorage(ctrl)
+	s.manager = NewPaymentManager(s.paymentStorage, s.prizeStorage, s.participantStorage)
+
+	s.prizes = []Prize{
+		{ID: "prize_1"},
+		{ID: "prize_2"},
+		{ID: "played", PlayResult: dummyPlayResult()},
+	}
+
+	s.participantStorage.EXPECT().Get("olena").Return(&Participant{ID: "olena"}, nil).AnyTimes()
+	s.prizeStorage.EXPECT().GetAll().Return(s.prizes, nil).AnyTimes()
+}
+
+func (s *PaymentSuite) TestCreatePayment() {
+	request := &PaymentRequest{
+		ParticipantID: "olena",
+		Amount:        300,
+		Method:        PaymentCash,
+		Allocations: []AllocationRequest{
+			{PrizeID: "prize_1", Amount: 100},
+			{PrizeID: "prize_2", Amount: 200},
+		},
+	}
+
+	expected := &Payment{
+		ID:            "new_id",
+		ParticipantID: "olena",
+		Amount:        300,
+		Method:        PaymentCash,
+		Allocations: []PaymentAllocation{
+			{PrizeID: "prize_1", DonationID: "new_id", Amount: 100},
+			{PrizeID: "prize_2", DonationID: "new_id", Amount: 200},
+		},
+		CreatedAt: s.mockTime,
+	}
+
+	s.paymentStorage.EXPECT().Create(expected).Return(nil)
+
+	id, err := s.manager.Create(request)
+	s.Require().NoError(err)
+	s.Equal("new_id", id)
+
+	s.Run("donations", func() {
+		s.Equal(map[string][]Donation{
+			"prize_1": {{ID: "new_id", ParticipantID: "olena", Amount: 100, Method: PaymentCash, PaymentID: "new_id", CreatedAt: s.mockTime}},
+			"prize_2": {{ID: "new_id", ParticipantID: "olena", Amount: 200, Method: PaymentCash, PaymentID: "new_id", CreatedAt: s.mockTime}},
+		}, expected.Donations())
+	})
+
+	s.Run("storage_error", func() {
+		s.paymentStorage.EXPECT().Create(expected).Return(assert.AnError)
+
+		_, err := s.manager.Create(request)
+		s.ErrorIs(err, assert.AnError)
+	})
+
+	s.Run("played_prize", func() {
+		request := *request
+		request.Allocations = []AllocationRequest{{PrizeID: "prize_1", Amount: 100}, {PrizeID: "played", Amount: 200}}
+
+		_, err := s.manager.Create(&request)
+		s.ErrorIs(err, ErrEditPlayedPrizeDonations)
+	})
+
+	s.Run("unknown_prize", func() {
+		request := *request
+		request.Allocations = []AllocationRequest{{PrizeID: "prize_1", Amount: 100}, {PrizeID: "unknown", Amount: 200}}
+
+		_, err := s.manager.Create(&request)
+		s.ErrorIs(err, ErrNotFound)
+	})
+
+	s.Run("unknown_participant", func() {
+		request := *request
+		request.ParticipantID = "taras"
+
+		s.participantStorage.EXPECT().Get("taras").Return(nil, ErrNotFound)
+
+		_, err := s.manager.Create(&request)
+		s.ErrorIs(err, ErrNotFound)
+	})
+}
+
+func (s *PaymentSuite) TestValidatePaymentRequest() {
+	valid := func() *PaymentRequest {
+		return &PaymentRequest{
+			ParticipantID: "olena",
+			Amount:        300,
+			Method:        PaymentBankTransfer,
+			Reference:     "INV-2024/15",
+			Allocations: []AllocationRequest{
+				{PrizeID: "prize_1", Amount: 100},
+				{PrizeID: "prize_2", Amount: 200},
+			},
+		}
+	}
+
+	s.NoError(valid().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(r *PaymentRequest)
+	}{
+		{name: "not_reconciled", modify: func(r *PaymentRequest) { r.Amount = 250 }},
+		{name: "no_reference", modify: func(r *PaymentRequest) { r.Reference = "" }},
+		{name: "unknown_method", modify: func(r *PaymentRequest) { r.Method = "crypto" }},
+		{name: "no_allocations", modify: func(r *PaymentRequest) { r.Allocations = nil }},
+		{name: "same_prize_twice", modify: func(r *PaymentRequest) { r.Allocations[1].PrizeID = "prize_1" }},
+		{name: "zero_allocation", modify: func(r *PaymentRequest) {
+			r.Allocations = append(r.Allocations, AllocationRequest{PrizeID: "prize_3"})
+		}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			r := valid()
+			tt.modify(r)
+			s.Error(r.Validate())
+		})
+	}
+
+	s.Run("not_reconciled_error", func() {
+		r := valid()
+		r.Amount = 250
+
+		_, err := s.manager.Create(r)
+		s.ErrorIs(err, ErrPaymentNotReconciled)
+		s.ErrorIs(err, ErrInvalidRequest)
+	})
+}
+
+func (s *PaymentSuite) TestEditPayment() {
+	existing := func() *Payment {
+		return &Payment{
+			ID:            "payment_1",
+			ParticipantID: "olena",
+			Amount:        300,
+			Method:        PaymentCash,
+			Allocations: []PaymentAllocation{
+				{PrizeID: "prize_1", DonationID: "donation_1", Amount: 100},
+				{PrizeID: "played", DonationID: "donation_2", Amount: 200},
+			},
+			CreatedAt: s.mockTime.Add(-time.Hour),
+		}
+	}
+
+	s.Run("keeps_donation_ids", func() {
+		s.paymentStorage.EXPECT().Get("payment_1").Return(existing(), nil)
+
+		expected := existing()
+		expected.Amount = 400
+		expected.Method = PaymentCard
+		expected.Allocations = []PaymentAllocation{
+			{PrizeID: "prize_1", DonationID: "donation_1", Amount: 150},
+			{PrizeID: "played", DonationID: "donation_2", Amount: 200},
+			{PrizeID: "prize_2", DonationID: "new_id", Amount: 50},
+		}
+		s.paymentStorage.EXPECT().Update(expected).Return(nil)
+
+		err := s.manager.Edit("payment_1", &PaymentRequest{
+			ParticipantID: "olena",
+			Amount:        400,
+			Method:        PaymentCard,
+			Allocations: []AllocationRequest{
+				{PrizeID: "prize_1", Amount: 150},
+				{PrizeID: "played", Amount: 200},
+				{PrizeID: "prize_2", Amount: 50},
+			},
+		})
+		s.NoError(err)
+	})
+
+	s.Run("played_prize_changed", func() {
+		s.paymentStorage.EXPECT().Get("payment_1").Return(existing(), nil)
+
+		err := s.manager.Edit("payment_1", &PaymentRequest{
+			ParticipantID: "olena",
+			Amount:        300,
+			Method:        PaymentCash,
+			Allocations: []AllocationRequest{
+				{PrizeID: "prize_1", Amount: 150},
+				{PrizeID: "played", Amount: 150},
+			},
+		})
+		s.ErrorIs(err, ErrEditPlayedPrizeDonations)
+	})
+
+	s.Run("played_prize_removed", func() {
+		s.paymentStorage.EXPECT().Get("payment_1").Return(existing(), nil)
+
+		err := s.manager.Edit("payment_1", &PaymentRequest{
+			ParticipantID: "olena",
+			Amount:        300,
+			Method:        PaymentCash,
+			Allocations:   []AllocationRequest{{PrizeID: "prize_1", Amount: 300}},
+		})
+		s.ErrorIs(err, ErrEditPlayedPrizeDonations)
+	})
+
+	s.Run("shift_closed", func() {
+		locked := existing()
+		locked.ShiftID = "shift_id"
+		s.paymentStorage.EXPECT().Get("payment_1").Return(locked, nil)
+
+		err := s.manager.Edit("payment_1", &PaymentRequest{
+			ParticipantID: "olena",
+			Amount:        300,
+			Method:        PaymentCash,
+			Allocations:   []AllocationRequest{{PrizeID: "prize_1", Amount: 300}},
+		})
+		s.ErrorIs(err, ErrShiftClosed)
+	})
+
+	s.Run("not_found", func() {
+		s.paymentStorage.EXPECT().Get("payment_2").Return(nil, ErrNotFound)
+
+		err := s.manager.Edit("payment_2", &PaymentRequest{
+			ParticipantID: "olena",
+			Amount:        300,
+			Method:        PaymentCash,
+			Allocations:   []AllocationRequest{{PrizeID: "prize_1", Amount: 300}},
+		})
+		s.ErrorIs(err, ErrNotFound)
+	})
+}
+
+func (s *PaymentSuite) TestDeletePayment() {
+	s.Run("success", func() {
+		s.paymentStorage.EXPECT().Get("payment_1").Return(&Payment{
+			ID:          "payment_1",
+			Allocations: []PaymentAllocation{{PrizeID: "prize_1", DonationID: "donation_1", Amount: 100}},
+		}, nil)
+		s.paymentStorage.EXPECT().Delete("payment_1").Return(nil)
+
+		s.NoError(s.manager.Delete("payment_1"))
+	})
+
+	s.Run("played_prize", func() {
+		s.paymentStorage.EXPECT().Get("payment_2").Return(&Payment{
+			ID:          "payment_2",
+			Allocations: []PaymentAllocation{{PrizeID: "played", DonationID: "donation_2", Amount: 100}},
+		}, nil)
+
+		s.ErrorIs(s.manager.Delete("payment_2"), ErrEditPlayedPrizeDonations)
+	})
+
+	s.Run("shift_closed", func() {
+		s.paymentStorage.EXPECT().Get("payment_3").Return(&Payment{ID: "payment_3", ShiftID: "shift_id"}, nil)
+
+		s.ErrorIs(s.manager.Delete("payment_3"), ErrShiftClosed)
+	})
+}
+
+func TestRafflePaymentService(t *testing.T) {
+	now := time.Now().UTC()
+	setTimeNowMock(now)
+
+	ctrl := gomock.NewController(t)
+	raffleStorage := NewMockRaffleStorage(ctrl)
+
+	raffleStorage.EXPECT().PaymentStorage("raffle_1").Return(NewMockPaymentStorage(ctrl)).AnyTimes()
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(NewMockPrizeStorage(ctrl)).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(NewMockParticipantStorage(ctrl)).AnyTimes()
+
+	rm := NewRaffleManager(raffleStorage)
+
+	t.Run("open", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+
+		ps, err := rm.PaymentService("raffle_1")
+		require.NoError(t, err)
+		assert.IsType(t, &PaymentManager{}, ps)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		endsAt := now.Add(-time.Minute)
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1", EndsAt: &endsAt}, nil)
+
+		ps, err := rm.PaymentService("raffle_1")
+		require.NoError(t, err)
+
+		_, err = ps.Create(&PaymentRequest{})
+		require.ErrorIs(t, err, ErrRaffleClosed)
+	})
+
+	t.Run("raffle_not_found", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(nil, ErrNotFound)
+
+		_, err := rm.PaymentService("raffle_1")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/slices"
+)
+
+// ErrMissingPlayOrder is returned when the explicit play order lists no prizes.
+var ErrMissingPlayOrder = errors.New("prize IDs are required for explicit play order")
+
+// PlayOrder defines the order in which prizes are played
+// when the whole raffle is drawn at once.
+type PlayOrder string
+
+const (
+	// PlayByCreation plays prizes in the order they were created.
+	// It is the default order.
+	PlayByCreation PlayOrder = "created"
+
+	// PlayByTicketCost plays the most expensive prizes first.
+	PlayByTicketCost PlayOrder = "ticket_cost"
+
+	// PlayExplicit plays only the listed prizes in the listed order.
+	PlayExplicit PlayOrder = "explicit"
+)
+
+// PlayAllRequest is a request for playing all not yet played prizes of a raffle.
+type PlayAllRequest struct {
+	Order    PlayOrder `json:"order" validate:"omitempty,oneof=created ticket_cost explicit"`
+	PrizeIDs []string  `json:"prizeIds" validate:"unique"`
+	// Atomic makes the whole draw fail if any of the prizes can't be played.
+	// Otherwise, failures are reported per prize.
+	Atomic bool `json:"atomic"`
+}
+
+func (r *PlayAllRequest) Validate() error {
+	if err := validateStruct(r); err != nil {
+		return err
+	}
+
+	if r.Order == PlayExplicit && len(r.PrizeIDs) == 0 {
+		return ErrMissingPlayOrder
+	}
+
+	return nil
+}
+
+// RafflePlayResult is a consolidated result of drawing the whole raffle.
+type RafflePlayResult struct {
+	RaffleID string            `json:"raffleId"`
+	Prizes   []PrizeDrawResult `json:"prizes"`
+}
+
+// PrizeDrawResult is a result of drawing a single prize of the raffle.
+type PrizeDrawResult struct {
+	PrizeID   string                `json:"prizeId"`
+	PrizeName string                `json:"prizeName"`
+	Winner    *PlayParticipant      `json:"winner,omitempty"`
+	Excluded  []ExcludedParticipant `json:"excluded,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// PlayAll plays all not yet played prizes of the raffle.
+func (rm *RaffleManager) PlayAll(id string, r *PlayAllRequest) (*RafflePlayResult, error) {
+	result, err := rm.prizeManager(id).playAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result.RaffleID = id
+
+	return result, nil
+}
+
+// playAll plays not yet played prizes one by one in the requested order.
+// Prizes played earlier in the same run count for the draw policy
+// of the later ones. Prizes which failed to be saved don't count
+// and are reported with the error.
+func (pm *PrizeManager) playAll(r *PlayAllRequest) (*RafflePlayResult, error) {
+	if err := r.Validate(); err != nil {
+		return nil, errors.Join(err, ErrInvalidRequest)
+	}
+
+	prizes, err := pm.prizeStorage.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all prizes: %w", err)
+	}
+
+	ordered, err := orderPrizes(prizes, r)
+	if err != nil {
+		return nil, err
+	}
+
+	allPrizes := func() ([]Prize, error) {
+		return prizes, nil
+	}
+
+	result := &RafflePlayResult{Prizes: make([]PrizeDrawResult, 0, len(ordered))}
+	played := make([]Prize, 0, len(ordered))
+	playResults := make([]*PrizePlayResult, 0, len(ordered))
+
+	for _, prize := range ordered {
+		if prize.IsPlayed() {
+			continue
+		}
+
+		prizeResult := PrizeDrawResult{
+			PrizeID:   prize.ID,
+			PrizeName: prize.Name,
+		}
+
+		unplayed := prize.snapshot()
+
+		playResult, err := pm.drawAmong(prize, allPrizes)
+		if err == nil && !r.Atomic {
+			if err = pm.prizeStorage.Update(prize); err != nil {
+				err = fmt.Errorf("save play result: %w", err)
+			}
+		}
+
+		switch {
+		case err != nil && r.Atomic:
+			return nil, fmt.Errorf("play prize %q: %w", prize.ID, err)
+		case err != nil:
+			// The winner isn't saved, so it doesn't count for the later prizes.
+			*prize = unplayed
+			prizeResult.Error = err.Error()
+		default:
+			winner := playResult.Winners[len(playResult.Winners)-1].published()
+			prizeResult.Winner = &winner
+			prizeResult.Excluded = playResult.Excluded
+			played = append(played, *prize)
+			playResults = append(playResults, playResult)
+		}
+
+		result.Prizes = append(result.Prizes, prizeResult)
+	}
+
+	if r.Atomic && len(played) > 0 {
+		if err := pm.prizeStorage.UpdateAll(played); err != nil {
+			return nil, fmt.Errorf("update played prizes: %w", err)
+		}
+	}
+
+	for i := range played {
+		pm.publishPlayed(&played[i], playResults[i])
+	}
+
+	return result, nil
+}
+
+// snapshot returns a copy of the prize which isn't changed by playing the prize.
+func (p *Prize) snapshot() Prize {
+	s := *p
+	if p.PlayResult != nil {
+		result := *p.PlayResult
+		result.Winners = slices.Clone(result.Winners)
+		s.PlayResult = &result
+	}
+
+	return s
+}
+
+// orderPrizes returns pointers to the prizes in the requested play order.
+func orderPrizes(prizes []Prize, r *PlayAllRequest) ([]*Prize, error) {
+	ordered := make([]*Prize, 0, len(prizes))
+
+	if r.Order == PlayExplicit {
+		for _, id := range r.PrizeIDs {
+			index := -1
+			for i := range prizes {
+				if prizes[i].ID == id {
+					index = i
+					break
+				}
+			}
+
+			if index == -1 {
+				return nil, fmt.Errorf("prize %q: %w", id, ErrNotFound)
+			}
+
+			ordered = append(ordered, &prizes[index])
+		}
+
+		return ordered, nil
+	}
+
+	for i := range prizes {
+		ordered = append(ordered, &prizes[i])
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if r.Order == PlayByTicketCost && ordered[i].TicketCost != ordered[j].TicketCost {
+			return ordered[i].TicketCost > ordered[j].TicketCost
+		}
+
+		return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+	})
+
+	return ordered, nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPlayAll(t *testing.T) {
+	setTimeNowMock(time.Now().UTC())
+
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	participantStorage := NewMockParticipantStorage(ctrl)
+	cheapDonations := NewMockDonationStorage(ctrl)
+	expensiveDonations := NewMockDonationStorage(ctrl)
+
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(participantStorage).AnyTimes()
+	raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1", ExcludeWinners: true}, nil).AnyTimes()
+	prizeStorage.EXPECT().DonationStorage("cheap").Return(cheapDonations).AnyTimes()
+	prizeStorage.EXPECT().DonationStorage("expensive").Return(expensiveDonations).AnyTimes()
+
+	participants := []Participant{
+		{ID: "p1", Name: "Olena", Phone: "+380501234567", PublishName: true},
+		{ID: "p2", Name: "Taras", Phone: "+380671234567"},
+	}
+	donations := []Donation{
+		{ID: "d1", ParticipantID: "p1", Amount: 100},
+		{ID: "d2", ParticipantID: "p2", Amount: 100},
+	}
+
+	now := time.Now()
+	prizes := func() []Prize {
+		return []Prize{
+			{ID: "cheap", Name: "Cheap", TicketCost: 10, CreatedAt: now},
+			{ID: "expensive", Name: "Expensive", TicketCost: 50, CreatedAt: now.Add(time.Minute)},
+			{ID: "played", Name: "Played", TicketCost: 10, PlayResult: dummyPlayResult()},
+		}
+	}
+
+	pm := NewRaffleManager(raffleStorage).prizeManager("raffle_1")
+	pm.randomizer = func(uint) uint { return 0 }
+
+	t.Run("by_ticket_cost", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(prizes(), nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil).Times(2)
+		expensiveDonations.EXPECT().GetAll().Return(donations, nil)
+		cheapDonations.EXPECT().GetAll().Return(donations, nil)
+		prizeStorage.EXPECT().Update(gomock.Any()).Return(nil).Times(2)
+
+		result, err := pm.playAll(&PlayAllRequest{Order: PlayByTicketCost})
+		require.NoError(t, err)
+		require.Len(t, result.Prizes, 2)
+
+		assert.Equal(t, "expensive", result.Prizes[0].PrizeID)
+		assert.Equal(t, Participant{ID: "p1", Name: "Olena", PublishName: true}, result.Prizes[0].Winner.Participant)
+		assert.Empty(t, result.Prizes[0].Excluded)
+
+		assert.Equal(t, "cheap", result.Prizes[1].PrizeID)
+		assert.Equal(t, Participant{ID: "p2"}, result.Prizes[1].Winner.Participant, "the name isn't published without consent")
+		assert.Equal(t, []ExcludedParticipant{{ParticipantID: "p1", PrizesWon: 1}}, result.Prizes[1].Excluded)
+	})
+
+	t.Run("partial_failure", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(prizes(), nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil).Times(2)
+		cheapDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+		expensiveDonations.EXPECT().GetAll().Return(donations, nil)
+		prizeStorage.EXPECT().Update(gomock.Any()).Return(nil)
+
+		result, err := pm.playAll(&PlayAllRequest{})
+		require.NoError(t, err)
+		require.Len(t, result.Prizes, 2)
+
+		assert.Equal(t, "cheap", result.Prizes[0].PrizeID)
+		assert.Nil(t, result.Prizes[0].Winner)
+		assert.Contains(t, result.Prizes[0].Error, ErrNoDonations.Error())
+
+		assert.Equal(t, "expensive", result.Prizes[1].PrizeID)
+		assert.Equal(t, "p1", result.Prizes[1].Winner.Participant.ID)
+	})
+
+	t.Run("update_failure", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(prizes(), nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil).Times(2)
+		cheapDonations.EXPECT().GetAll().Return(donations, nil)
+		expensiveDonations.EXPECT().GetAll().Return(donations, nil)
+		gomock.InOrder(
+			prizeStorage.EXPECT().Update(gomock.Any()).Return(assert.AnError),
+			prizeStorage.EXPECT().Update(gomock.Any()).Return(nil),
+		)
+
+		result, err := pm.playAll(&PlayAllRequest{})
+		require.NoError(t, err)
+		require.Len(t, result.Prizes, 2)
+
+		assert.Equal(t, "cheap", result.Prizes[0].PrizeID)
+		assert.Nil(t, result.Prizes[0].Winner)
+		assert.Contains(t, result.Prizes[0].Error, assert.AnError.Error())
+
+		// p1 didn't win the cheap prize, so it isn't excluded.
+		assert.Equal(t, "expensive", result.Prizes[1].PrizeID)
+		assert.Equal(t, "p1", result.Prizes[1].Winner.Participant.ID)
+		assert.Empty(t, result.Prizes[1].Excluded)
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(prizes(), nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil).Times(2)
+		cheapDonations.EXPECT().GetAll().Return(donations, nil)
+		expensiveDonations.EXPECT().GetAll().Return(donations, nil)
+		prizeStorage.EXPECT().UpdateAll(gomock.Len(2)).Return(nil)
+
+		result, err := pm.playAll(&PlayAllRequest{Atomic: true})
+		require.NoError(t, err)
+		require.Len(t, result.Prizes, 2)
+	})
+
+	t.Run("atomic_failure", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(prizes(), nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil)
+		cheapDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+
+		result, err := pm.playAll(&PlayAllRequest{Atomic: true})
+		require.ErrorIs(t, err, ErrNoDonations)
+		assert.Nil(t, result)
+	})
+
+	t.Run("explicit", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(prizes(), nil)
+		participantStorage.EXPECT().GetAll().Return(participants, nil)
+		expensiveDonations.EXPECT().GetAll().Return(donations, nil)
+		prizeStorage.EXPECT().Update(gomock.Any()).Return(nil)
+
+		result, err := pm.playAll(&PlayAllRequest{Order: PlayExplicit, PrizeIDs: []string{"expensive", "played"}})
+		require.NoError(t, err)
+		require.Len(t, result.Prizes, 1)
+		assert.Equal(t, "expensive", result.Prizes[0].PrizeID)
+	})
+
+	t.Run("explicit_unknown_prize", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(prizes(), nil)
+
+		result, err := pm.playAll(&PlayAllRequest{Order: PlayExplicit, PrizeIDs: []string{"unknown"}})
+		require.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid_request", func(t *testing.T) {
+		result, err := pm.playAll(&PlayAllRequest{Order: PlayExplicit})
+		require.ErrorIs(t, err, ErrInvalidRequest)
+		require.ErrorIs(t, err, ErrMissingPlayOrder)
+		assert.Nil(t, result)
+
+		result, err = pm.playAll(&PlayAllRequest{Order: "random"})
+		require.ErrorIs(t, err, ErrInvalidRequest)
+		assert.Nil(t, result)
+	})
+
+	t.Run("get_prizes_error", func(t *testing.T) {
+		prizeStorage.EXPECT().GetAll().Return(nil, assert.AnError)
+
+		result, err := NewRaffleManager(raffleStorage).PlayAll("raffle_1", &PlayAllRequest{})
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, result)
+	})
+}
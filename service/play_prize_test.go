@@ -65,7 +65,7 @@ func (s *PlayPrizeSuite) TestPlayPrize() {
 		Participant:        participants[0],
 		TotalDonation:      200,
 		TotalTicketsNumber: 20,
-		Donations:          donations[:2],
+		Donations:          donations[:2], Claim: &WinnerClaim{Status: ClaimPending, UpdatedAt: s.mockTime},
 	}
 
 	expectedParticipants := []PlayParticipant{
@@ -231,7 +231,7 @@ func (s *PlayPrizeSuite) TestPlayPrizeSingleParticipant() {
 		Participant:        participants[0],
 		TotalDonation:      200,
 		TotalTicketsNumber: 20,
-		Donations:          donations,
+		Donations:          donations, Claim: &WinnerClaim{Status: ClaimPending, UpdatedAt: s.mockTime},
 	}
 
 	expectedPrize := &Prize{
@@ -312,6 +312,7 @@ func (s *PlayPrizeSuite) TestPlayPrizeAgain() {
 					TotalDonation:      400,
 					TotalTicketsNumber: 40,
 					Donations:          donations[2:4],
+					Claim:              &WinnerClaim{Status: ClaimPending, UpdatedAt: s.mockTime},
 				},
 			},
 			PlayParticipants: []PlayParticipant{
@@ -631,3 +632,511 @@ func dummyParticipantsList() []Participant {
 		{ID: "p3", Name: "Participant 3"},
 	}
 }
+
+func (s *PlayPrizeSuite) TestVoidDraw() {
+	participants := dummyParticipantsList()
+
+	playedPrize := func() *Prize {
+		return &Prize{
+			ID:         s.prizeID,
+			Name:       "Prize 1",
+			TicketCost: 10,
+			PlayResult: &PrizePlayResult{
+				Winners: []PlayParticipant{
+					{Participant: participants[0], TotalDonation: 200, TotalTicketsNumber: 20},
+				},
+				PlayParticipants: []PlayParticipant{
+					{Participant: participants[1], TotalDonation: 400, TotalTicketsNumber: 40},
+				},
+			},
+		}
+	}
+
+	s.Run("return_to_pool", func() {
+		prize := playedPrize()
+		request := &VoidDrawRequest{Reason: "winner is absent", ReturnToPool: true}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+		s.storage.EXPECT().Update(prize).Return(nil)
+
+		res, err := s.manager.VoidDraw(s.prizeID, request)
+		s.Require().NoError(err)
+		s.Empty(res.Winners)
+		s.Len(res.PlayParticipants, 2)
+		s.Equal(participants[0], res.PlayParticipants[1].Participant)
+		s.Equal([]VoidedWinner{
+			{
+				PlayParticipant: PlayParticipant{Participant: participants[0], TotalDonation: 200, TotalTicketsNumber: 20},
+				Reason:          request.Reason,
+				ReturnedToPool:  true,
+				VoidedAt:        s.mockTime,
+			},
+		}, res.Voided)
+		s.Equal([]PlayEvent{
+			{Type: PlayEventVoided, ParticipantID: "p1", Reason: request.Reason, CreatedAt: s.mockTime},
+		}, res.History)
+		s.False(prize.IsPlayed())
+	})
+
+	s.Run("exclude", func() {
+		prize := playedPrize()
+		request := &VoidDrawRequest{Reason: "triggered by mistake"}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+		s.storage.EXPECT().Update(prize).Return(nil)
+
+		res, err := s.manager.VoidDraw(s.prizeID, request)
+		s.Require().NoError(err)
+		s.Empty(res.Winners)
+		s.Len(res.PlayParticipants, 1)
+		s.Len(res.Voided, 1)
+		s.False(res.Voided[0].ReturnedToPool)
+	})
+
+	s.Run("excluded_participant_is_not_played_again", func() {
+		prize := playedPrize()
+		s.Require().NoError(prize.VoidLastDraw("triggered by mistake", false))
+
+		donations := []Donation{
+			{ID: "dn1", ParticipantID: "p1", Amount: 100},
+			{ID: "dn2", ParticipantID: "p2", Amount: 100},
+		}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+		s.participantStorage.EXPECT().GetAll().Return(participants, nil)
+		s.donationStorage.EXPECT().GetAll().Return(donations, nil)
+		s.storage.EXPECT().Update(prize).Return(nil)
+
+		res, err := s.manager.Play(s.prizeID)
+		s.Require().NoError(err)
+		s.Require().Len(res.Winners, 1)
+		s.Equal("p2", res.Winners[0].Participant.ID)
+		s.Len(res.Voided, 1)
+	})
+
+	s.Run("no_winners", func() {
+		prize := &Prize{ID: s.prizeID, TicketCost: 10}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+
+		res, err := s.manager.VoidDraw(s.prizeID, &VoidDrawRequest{Reason: "winner is absent"})
+		s.Require().ErrorIs(err, ErrNoWinners)
+		s.Nil(res)
+	})
+
+	s.Run("missing_reason", func() {
+		res, err := s.manager.VoidDraw(s.prizeID, &VoidDrawRequest{})
+		s.Require().ErrorIs(err, ErrInvalidRequest)
+		s.Nil(res)
+	})
+
+	s.Run("update_error", func() {
+		s.storage.EXPECT().Get(s.prizeID).Return(playedPrize(), nil)
+		s.storage.EXPECT().Update(gomock.Any()).Return(assert.AnError)
+
+		res, err := s.manager.VoidDraw(s.prizeID, &VoidDrawRequest{Reason: "winner is absent"})
+		s.Require().ErrorIs(err, assert.AnError)
+		s.Nil(res)
+	})
+}
+
+func (s *PlayPrizeSuite) TestClaim() {
+	participants := dummyParticipantsList()
+
+	playedPrize := func() *Prize {
+		return &Prize{
+			ID:         s.prizeID,
+			TicketCost: 10,
+			PlayResult: &PrizePlayResult{
+				Winners: []PlayParticipant{
+					{Participant: participants[0], Claim: &WinnerClaim{Status: ClaimPending}},
+				},
+			},
+		}
+	}
+
+	s.Run("success", func() {
+		prize := playedPrize()
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+		s.storage.EXPECT().Update(prize).Return(nil)
+
+		res, err := s.manager.Claim(s.prizeID, &ClaimRequest{ParticipantID: "p1"})
+		s.Require().NoError(err)
+		s.Equal(&WinnerClaim{Status: ClaimClaimed, UpdatedAt: s.mockTime}, res.Winners[0].Claim)
+		s.Equal([]PlayEvent{{Type: PlayEventClaimed, ParticipantID: "p1", CreatedAt: s.mockTime}}, res.History)
+	})
+
+	s.Run("already_claimed", func() {
+		prize := playedPrize()
+		prize.PlayResult.Winners[0].Claim.Status = ClaimClaimed
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+
+		res, err := s.manager.Claim(s.prizeID, &ClaimRequest{ParticipantID: "p1"})
+		s.Require().ErrorIs(err, ErrClaimNotPending)
+		s.Nil(res)
+	})
+
+	s.Run("not_a_winner", func() {
+		s.storage.EXPECT().Get(s.prizeID).Return(playedPrize(), nil)
+
+		res, err := s.manager.Claim(s.prizeID, &ClaimRequest{ParticipantID: "p2"})
+		s.Require().ErrorIs(err, ErrWinnerNotFound)
+		s.Nil(res)
+	})
+
+	s.Run("not_played", func() {
+		s.storage.EXPECT().Get(s.prizeID).Return(&Prize{ID: s.prizeID}, nil)
+
+		res, err := s.manager.Claim(s.prizeID, &ClaimRequest{ParticipantID: "p1"})
+		s.Require().ErrorIs(err, ErrNoWinners)
+		s.Nil(res)
+	})
+
+	s.Run("invalid_request", func() {
+		res, err := s.manager.Claim(s.prizeID, &ClaimRequest{})
+		s.Require().ErrorIs(err, ErrInvalidRequest)
+		s.Nil(res)
+	})
+}
+
+func (s *PlayPrizeSuite) TestForfeit() {
+	participants := dummyParticipantsList()
+
+	playedPrize := func(pool ...PlayParticipant) *Prize {
+		return &Prize{
+			ID:         s.prizeID,
+			TicketCost: 10,
+			ClaimDays:  3,
+			PlayResult: &PrizePlayResult{
+				Winners: []PlayParticipant{
+					{Participant: participants[0], TotalTicketsNumber: 1, Claim: &WinnerClaim{Status: ClaimPending}},
+				},
+				PlayParticipants: pool,
+			},
+		}
+	}
+
+	s.Run("redraw", func() {
+		prize := playedPrize(PlayParticipant{Participant: participants[1], TotalTicketsNumber: 2})
+		request := &ForfeitRequest{ParticipantID: "p1", Reason: "did not show up"}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+		s.storage.EXPECT().Update(prize).Return(nil).Times(2)
+
+		res, err := s.manager.Forfeit(s.prizeID, request)
+		s.Require().NoError(err)
+		s.Require().Len(res.Winners, 2)
+		s.Equal(ClaimForfeited, res.Winners[0].Claim.Status)
+
+		deadline := s.mockTime.AddDate(0, 0, 3)
+		s.Equal(participants[1], res.Winners[1].Participant)
+		s.Equal(&WinnerClaim{Status: ClaimPending, Deadline: &deadline, UpdatedAt: s.mockTime}, res.Winners[1].Claim)
+		s.Empty(res.PlayParticipants)
+		s.Equal([]PlayEvent{
+			{Type: PlayEventForfeited, ParticipantID: "p1", Reason: request.Reason, CreatedAt: s.mockTime},
+		}, res.History)
+	})
+
+	s.Run("no_participants_left", func() {
+		prize := playedPrize()
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+		s.storage.EXPECT().Update(prize).Return(nil)
+
+		res, err := s.manager.Forfeit(s.prizeID, &ForfeitRequest{ParticipantID: "p1", Reason: "did not show up"})
+		s.Require().NoError(err)
+		s.Require().Len(res.Winners, 1)
+		s.Equal(ClaimForfeited, res.Winners[0].Claim.Status)
+	})
+
+	s.Run("redraw_failed", func() {
+		prize := playedPrize(PlayParticipant{Participant: participants[1], TotalTicketsNumber: 2})
+		prize.ExcludeWinners = true
+
+		forfeited := false
+
+		s.storage.EXPECT().Get(s.prizeID).Return(prize, nil)
+		s.storage.EXPECT().Update(prize).DoAndReturn(func(p *Prize) error {
+			forfeited = p.PlayResult.Winners[0].Claim.Status == ClaimForfeited
+			return nil
+		})
+		s.storage.EXPECT().GetAll().Return(nil, assert.AnError)
+		s.storage.EXPECT().Update(prize).Return(nil)
+
+		res, err := s.manager.Forfeit(s.prizeID, &ForfeitRequest{ParticipantID: "p1", Reason: "did not show up"})
+		s.Require().NoError(err)
+		s.True(forfeited, "the forfeit is saved before the re-draw")
+		s.Require().Len(res.Winners, 1)
+		s.Equal(ClaimForfeited, res.Winners[0].Claim.Status)
+		s.Require().Len(res.History, 2)
+		s.Equal(PlayEventRedrawFailed, res.History[1].Type)
+		s.Equal("p1", res.History[1].ParticipantID)
+		s.Contains(res.History[1].Reason, assert.AnError.Error())
+	})
+
+	s.Run("update_error", func() {
+		s.storage.EXPECT().Get(s.prizeID).Return(playedPrize(), nil)
+		s.storage.EXPECT().Update(gomock.Any()).Return(assert.AnError)
+
+		res, err := s.manager.Forfeit(s.prizeID, &ForfeitRequest{ParticipantID: "p1", Reason: "did not show up"})
+		s.Require().ErrorIs(err, assert.AnError)
+		s.Nil(res)
+	})
+
+	s.Run("missing_reason", func() {
+		res, err := s.manager.Forfeit(s.prizeID, &ForfeitRequest{ParticipantID: "p1"})
+		s.Require().ErrorIs(err, ErrInvalidRequest)
+		s.Nil(res)
+	})
+}
+
+func (s *PlayPrizeSuite) TestListUnclaimed() {
+	participants := dummyParticipantsList()
+
+	pending := PlayParticipant{Participant: participants[1], Claim: &WinnerClaim{Status: ClaimPending}}
+	prizes := []Prize{
+		{ID: "not_played"},
+		{
+			ID:   "played",
+			Name: "Prize 1",
+			PlayResult: &PrizePlayResult{
+				Winners: []PlayParticipant{
+					{Participant: participants[0], Claim: &WinnerClaim{Status: ClaimForfeited}},
+					pending,
+				},
+			},
+		},
+		{
+			ID: "claimed",
+			PlayResult: &PrizePlayResult{
+				Winners: []PlayParticipant{
+					{Participant: participants[2], Claim: &WinnerClaim{Status: ClaimClaimed}},
+				},
+			},
+		},
+	}
+
+	s.storage.EXPECT().GetAll().Return(prizes, nil)
+
+	res, err := s.manager.ListUnclaimed()
+	s.Require().NoError(err)
+	s.Equal([]UnclaimedPrize{{PrizeID: "played", PrizeName: "Prize 1", Winner: pending}}, res)
+
+	s.Run("error", func() {
+		s.storage.EXPECT().GetAll().Return(nil, assert.AnError)
+
+		res, err := s.manager.ListUnclaimed()
+		s.Require().ErrorIs(err, assert.AnError)
+		s.Nil(res)
+	})
+}
+
+func (s *PlayPrizeSuite) TestPlayPrizeRaffleTickets() {
+	participants := dummyParticipantsList()
+	donations := []Donation{
+		{ID: "dn1", ParticipantID: "p1", Amount: 100},
+	}
+	raffleDonations := []Donation{
+		{ID: "dn2", ParticipantID: "p1", Amount: 50},
+		{ID: "dn3", ParticipantID: "p2", Amount: 300},
+	}
+
+	raffleStorage := NewMockRaffleStorage(s.ctrl)
+	raffleDonationStorage := NewMockDonationStorage(s.ctrl)
+	s.manager.raffleID = s.raffleID
+	s.manager.raffleStorage = raffleStorage
+
+	mockedPrize := &Prize{
+		ID:               s.prizeID,
+		Name:             "Prize 1",
+		TicketCost:       10,
+		UseRaffleTickets: true,
+	}
+
+	s.storage.EXPECT().Get(s.prizeID).Return(mockedPrize, nil)
+	s.participantStorage.EXPECT().GetAll().Return(participants, nil)
+	s.donationStorage.EXPECT().GetAll().Return(donations, nil)
+	raffleStorage.EXPECT().DonationStorage(s.raffleID).Return(raffleDonationStorage)
+	raffleStorage.EXPECT().Get(s.raffleID).Return(&Raffle{ID: s.raffleID}, nil).AnyTimes()
+	raffleDonationStorage.EXPECT().GetAll().Return(raffleDonations, nil)
+	s.storage.EXPECT().Update(gomock.Any()).Return(nil)
+
+	res, err := s.manager.Play(s.prizeID)
+	s.Require().NoError(err)
+	s.Require().Len(res.Winners, 1)
+	s.Require().Len(res.PlayParticipants, 1)
+
+	s.Equal(150, res.Winners[0].TotalDonation)
+	s.Equal(15, res.Winners[0].TotalTicketsNumber)
+	s.Equal(30, res.PlayParticipants[0].TotalTicketsNumber)
+
+	s.Run("not_used_by_prize", func() {
+		mockedPrize := &Prize{ID: s.prizeID, Name: "Prize 1", TicketCost: 10}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(mockedPrize, nil)
+		s.participantStorage.EXPECT().GetAll().Return(participants, nil)
+		s.donationStorage.EXPECT().GetAll().Return(donations, nil)
+		s.storage.EXPECT().Update(gomock.Any()).Return(nil)
+
+		res, err := s.manager.Play(s.prizeID)
+		s.Require().NoError(err)
+		s.Require().Len(res.Winners, 1)
+		s.Equal(10, res.Winners[0].TotalTicketsNumber)
+		s.Empty(res.PlayParticipants)
+	})
+}
+
+func (s *PlayPrizeSuite) TestPlayPrizeExcludeWinners() {
+	participants := dummyParticipantsList()
+	donations := []Donation{
+		{ID: "dn1", ParticipantID: "p1", Amount: 100},
+		{ID: "dn2", ParticipantID: "p2", Amount: 100},
+		{ID: "dn3", ParticipantID: "p3", Amount: 100},
+	}
+
+	mockedPrize := &Prize{
+		ID:             s.prizeID,
+		Name:           "Prize 1",
+		TicketCost:     10,
+		ExcludeWinners: true,
+	}
+
+	otherPrizes := []Prize{
+		*mockedPrize,
+		{
+			ID: "prize_2",
+			PlayResult: &PrizePlayResult{
+				Winners: []PlayParticipant{
+					{Participant: participants[0], Claim: &WinnerClaim{Status: ClaimPending}},
+					{Participant: participants[1], Claim: &WinnerClaim{Status: ClaimForfeited}},
+				},
+			},
+		},
+	}
+
+	s.storage.EXPECT().Get(s.prizeID).Return(mockedPrize, nil)
+	s.participantStorage.EXPECT().GetAll().Return(participants, nil)
+	s.donationStorage.EXPECT().GetAll().Return(donations, nil)
+	s.storage.EXPECT().GetAll().Return(otherPrizes, nil)
+	s.storage.EXPECT().Update(gomock.Any()).Return(nil)
+
+	res, err := s.manager.Play(s.prizeID)
+	s.Require().NoError(err)
+	s.Require().Len(res.Winners, 1)
+	s.Equal("p2", res.Winners[0].Participant.ID)
+	s.Require().Len(res.PlayParticipants, 2)
+	s.Equal("p3", res.PlayParticipants[0].Participant.ID)
+	s.Equal("p1", res.PlayParticipants[1].Participant.ID)
+	s.Equal([]ExcludedParticipant{{ParticipantID: "p1", PrizesWon: 1}}, res.Excluded)
+
+	s.Run("everybody_won", func() {
+		mockedPrize := &Prize{ID: s.prizeID, TicketCost: 10, ExcludeWinners: true}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(mockedPrize, nil)
+		s.participantStorage.EXPECT().GetAll().Return(participants, nil)
+		s.donationStorage.EXPECT().GetAll().Return(donations[:1], nil)
+		s.storage.EXPECT().GetAll().Return(otherPrizes, nil)
+
+		res, err := s.manager.Play(s.prizeID)
+		s.Require().ErrorIs(err, ErrNoParticipants)
+		s.Nil(res)
+	})
+}
+
+func (s *PlayPrizeSuite) TestPlayPrizeRafflePolicy() {
+	participants := dummyParticipantsList()
+	donations := []Donation{
+		{ID: "dn1", ParticipantID: "p1", Amount: 100},
+		{ID: "dn2", ParticipantID: "p2", Amount: 100},
+	}
+
+	raffleStorage := NewMockRaffleStorage(s.ctrl)
+	s.manager.raffleID = s.raffleID
+	s.manager.raffleStorage = raffleStorage
+
+	won := func(ids ...string) *PrizePlayResult {
+		result := &PrizePlayResult{}
+		for _, id := range ids {
+			result.Winners = append(result.Winners, PlayParticipant{Participant: Participant{ID: id}})
+		}
+		return result
+	}
+
+	prizes := []Prize{
+		{ID: "prize_2", PlayResult: won("p1")},
+		{ID: "prize_3", PlayResult: won("p1", "p2")},
+	}
+
+	s.Run("max_prizes", func() {
+		mockedPrize := &Prize{ID: s.prizeID, TicketCost: 10}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(mockedPrize, nil)
+		s.participantStorage.EXPECT().GetAll().Return(participants, nil)
+		s.donationStorage.EXPECT().GetAll().Return(donations, nil)
+		raffleStorage.EXPECT().Get(s.raffleID).Return(&Raffle{ID: s.raffleID, MaxPrizesPerParticipant: 2}, nil)
+		s.storage.EXPECT().GetAll().Return(prizes, nil)
+		s.storage.EXPECT().Update(gomock.Any()).Return(nil)
+
+		res, err := s.manager.Play(s.prizeID)
+		s.Require().NoError(err)
+		s.Equal("p2", res.Winners[0].Participant.ID)
+		s.Equal([]ExcludedParticipant{{ParticipantID: "p1", PrizesWon: 2}}, res.Excluded)
+	})
+
+	s.Run("exclude_winners", func() {
+		mockedPrize := &Prize{ID: s.prizeID, TicketCost: 10}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(mockedPrize, nil)
+		s.participantStorage.EXPECT().GetAll().Return(participants, nil)
+		s.donationStorage.EXPECT().GetAll().Return(donations, nil)
+		raffleStorage.EXPECT().Get(s.raffleID).Return(&Raffle{ID: s.raffleID, ExcludeWinners: true, MaxPrizesPerParticipant: 2}, nil)
+		s.storage.EXPECT().GetAll().Return(prizes, nil)
+
+		res, err := s.manager.Play(s.prizeID)
+		s.Require().ErrorIs(err, ErrNoParticipants)
+		s.Nil(res)
+	})
+
+	s.Run("no_policy", func() {
+		mockedPrize := &Prize{ID: s.prizeID, TicketCost: 10}
+
+		s.storage.EXPECT().Get(s.prizeID).Return(mockedPrize, nil)
+		s.participantStorage.EXPECT().GetAll().Return(participants, nil)
+		s.donationStorage.EXPECT().GetAll().Return(donations, nil)
+		raffleStorage.EXPECT().Get(s.raffleID).Return(&Raffle{ID: s.raffleID}, nil)
+		s.storage.EXPECT().Update(gomock.Any()).Return(nil)
+
+		res, err := s.manager.Play(s.prizeID)
+		s.Require().NoError(err)
+		s.Equal("p1", res.Winners[0].Participant.ID)
+		s.Empty(res.Excluded)
+	})
+}
+
+func (s *PlayPrizeSuite) TestPlayResult() {
+	playResult := dummyPlayResult()
+
+	s.storage.EXPECT().Get(s.prizeID).Return(&Prize{ID: s.prizeID, PlayResult: playResult}, nil)
+
+	res, err := s.manager.PlayResult(s.prizeID)
+	s.Require().NoError(err)
+	s.Equal(playResult, res)
+
+	s.Run("not_played", func() {
+		s.storage.EXPECT().Get(s.prizeID).Return(&Prize{ID: s.prizeID}, nil)
+
+		res, err := s.manager.PlayResult(s.prizeID)
+		s.ErrorIs(err, ErrPrizeNotPlayed)
+		s.Nil(res)
+	})
+
+	s.Run("error", func() {
+		s.storage.EXPECT().Get(s.prizeID).Return(nil, assert.AnError)
+
+		res, err := s.manager.PlayResult(s.prizeID)
+		s.ErrorIs(err, assert.AnError)
+		s.Nil(res)
+	})
+}
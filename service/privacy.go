@@ -0,0 +1,449 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrParticipantAnonymized is returned when an anonymized participant is edited.
+var ErrParticipantAnonymized = errors.New("participant is anonymized")
+
+// ParticipantExport is all data kept about a participant in the raffle.
+type ParticipantExport struct {
+	ParticipantDetails
+	Payments   []Payment        `json:"payments"`
+	Wins       []ParticipantWin `json:"wins"`
+	ExportedAt time.Time        `json:"exportedAt"`
+}
+
+// ParticipantWin is a prize won by a participant.
+type ParticipantWin struct {
+	PrizeID      string              `json:"prizeId"`
+	PrizeName    string              `json:"prizeName"`
+	Claim        *WinnerClaim        `json:"claim,omitempty"`
+	Notification *WinnerNotification `json:"notification,omitempty"`
+}
+
+// AnonymizeRequest is a request for anonymizing a participant.
+type AnonymizeRequest struct{}
+
+// ArchiveRequest is a request for archiving a raffle.
+type ArchiveRequest struct{}
+
+// AnonymizedRaffle is a result of anonymizing participants
+// of a raffle which retention period has passed.
+type AnonymizedRaffle struct {
+	OrganizerID  string `json:"organizerId"`
+	RaffleID     string `json:"raffleId"`
+	Participants int    `json:"participants"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Export returns all data kept about the participant:
+// the participant, their donations, tickets, payments and wins.
+func (pm *ParticipantManager) Export(id string) (*ParticipantExport, error) {
+	details, err := pm.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &ParticipantExport{
+		ParticipantDetails: *details,
+		Payments:           make([]Payment, 0),
+		Wins:               make([]ParticipantWin, 0),
+		ExportedAt:         timeNow(),
+	}
+
+	if pm.raffleStorage == nil {
+		return export, nil
+	}
+
+	payments, err := pm.raffleStorage.PaymentStorage(pm.raffleID).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("getting payments: %w", err)
+	}
+
+	for _, payment := range payments {
+		if payment.ParticipantID == id {
+			export.Payments = append(export.Payments, payment)
+		}
+	}
+
+	prizes, err := pm.raffleStorage.PrizeStorage(pm.raffleID).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("getting prizes: %w", err)
+	}
+
+	for _, prize := range prizes {
+		if prize.PlayResult == nil {
+			continue
+		}
+
+		for _, winner := range prize.PlayResult.Winners {
+			if winner.Participant.ID != id {
+				continue
+			}
+
+			export.Wins = append(export.Wins, ParticipantWin{
+				PrizeID:      prize.ID,
+				PrizeName:    prize.Name,
+				Claim:        winner.Claim,
+				Notification: winner.Notification,
+			})
+		}
+	}
+
+	return export, nil
+}
+
+// Anonymize scrubs personal data of the participant.
+// Donations and draw history are kept, so totals and results of the raffle
+// don't change. Anonymizing an anonymized participant changes nothing.
+func (pm *ParticipantManager) Anonymize(id string, _ *AnonymizeRequest) (*Participant, error) {
+	prt, err := pm.participantStorage.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("getting participant: %w", err)
+	}
+
+	prts := []Participant{*prt}
+	if err := pm.anonymize(prts, timeNow()); err != nil {
+		return nil, err
+	}
+
+	return &prts[0], nil
+}
+
+// anonymize scrubs personal data of the participants
+// along with their copies in play results of the prizes
+// and in payloads of webhook deliveries.
+// Participants are updated first, so a failed run can be repeated.
+func (pm *ParticipantManager) anonymize(prts []Participant, now time.Time) error {
+	scrubbed := make(map[string]Participant, len(prts))
+
+	for i := range prts {
+		prt := &prts[i]
+
+		if prt.AnonymizedAt == nil {
+			prt.scrub(now)
+
+			if err := pm.participantStorage.Update(prt); err != nil {
+				return fmt.Errorf("updating participant %q: %w", prt.ID, err)
+			}
+		}
+
+		scrubbed[prt.ID] = *prt
+	}
+
+	if len(scrubbed) == 0 {
+		return nil
+	}
+
+	if err := pm.scrubPlayResults(scrubbed); err != nil {
+		return err
+	}
+
+	return pm.scrubDeliveries(scrubbed)
+}
+
+// scrubPlayResults replaces copies of the participants in play results of the prizes.
+func (pm *ParticipantManager) scrubPlayResults(scrubbed map[string]Participant) error {
+	if pm.raffleStorage == nil {
+		return nil
+	}
+
+	prizeStorage := pm.raffleStorage.PrizeStorage(pm.raffleID)
+
+	prizes, err := prizeStorage.GetAll()
+	if err != nil {
+		return fmt.Errorf("getting prizes: %w", err)
+	}
+
+	changed := make([]Prize, 0)
+	for i := range prizes {
+		if prizes[i].scrubParticipants(scrubbed) {
+			changed = append(changed, prizes[i])
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := prizeStorage.UpdateAll(changed); err != nil {
+		return fmt.Errorf("updating play results: %w", err)
+	}
+
+	return nil
+}
+
+// scrubDeliveries replaces winners of the raffle in payloads of prize.played deliveries,
+// so neither retries nor replays send their personal data.
+func (pm *ParticipantManager) scrubDeliveries(scrubbed map[string]Participant) error {
+	if pm.deliveryStorage == nil {
+		return nil
+	}
+
+	deliveries, err := pm.deliveryStorage.GetByEventType(EventPrizePlayed)
+	if err != nil {
+		return fmt.Errorf("getting deliveries: %w", err)
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		changed, err := delivery.scrubWinner(pm.raffleID, scrubbed)
+		if err != nil {
+			return fmt.Errorf("scrubbing delivery %q: %w", delivery.ID, err)
+		}
+
+		if !changed {
+			continue
+		}
+
+		if err := pm.deliveryStorage.Update(delivery); err != nil {
+			return fmt.Errorf("updating delivery %q: %w", delivery.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// scrub removes personal data and consents of the participant.
+func (p *Participant) scrub(now time.Time) {
+	p.Name = ""
+	p.Phone = ""
+	p.Note = ""
+	p.TelegramChatID = ""
+	p.ContactAllowed = false
+	p.PublishName = false
+	p.AnonymizedAt = &now
+}
+
+// scrubParticipants replaces copies of the participants kept in the play result
+// with the scrubbed ones. It reports whether any copy was replaced.
+func (p *Prize) scrubParticipants(scrubbed map[string]Participant) bool {
+	if p.PlayResult == nil {
+		return false
+	}
+
+	changed := false
+
+	replace := func(player *PlayParticipant) {
+		prt, ok := scrubbed[player.Participant.ID]
+		if ok && player.Participant.AnonymizedAt == nil {
+			player.Participant = prt
+			changed = true
+		}
+	}
+
+	for i := range p.PlayResult.Winners {
+		replace(&p.PlayResult.Winners[i])
+	}
+
+	for i := range p.PlayResult.PlayParticipants {
+		replace(&p.PlayResult.PlayParticipants[i])
+	}
+
+	for i := range p.PlayResult.Voided {
+		replace(&p.PlayResult.Voided[i].PlayParticipant)
+	}
+
+	return changed
+}
+
+// scrubWinner replaces the winner of the prize.played payload with the published copy
+// of the scrubbed participant if the event is of the raffle.
+// It reports whether the payload is changed.
+func (d *Delivery) scrubWinner(raffleID string, scrubbed map[string]Participant) (bool, error) {
+	return d.replaceWinner(func(e *Event, winner *Participant) bool {
+		prt, ok := scrubbed[winner.ID]
+		if e.RaffleID != raffleID || !ok || winner.AnonymizedAt != nil {
+			return false
+		}
+
+		*winner = prt.published()
+
+		return true
+	})
+}
+
+// RedactContacts reduces the winner of the prize.played payload to its published copy,
+// so payloads stored before contacts were left out of events don't keep them.
+// It reports whether the payload is changed.
+func (d *Delivery) RedactContacts() (bool, error) {
+	return d.replaceWinner(func(_ *Event, winner *Participant) bool {
+		published := winner.published()
+		if published == *winner {
+			return false
+		}
+
+		*winner = published
+
+		return true
+	})
+}
+
+// replaceWinner decodes the prize.played payload and encodes it back
+// if the function replaces the winner participant.
+func (d *Delivery) replaceWinner(replace func(e *Event, winner *Participant) bool) (bool, error) {
+	if d.EventType != EventPrizePlayed {
+		return false, nil
+	}
+
+	var played PrizePlayed
+	event := Event{Data: &played}
+
+	if err := json.Unmarshal([]byte(d.Payload), &event); err != nil {
+		return false, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	if !replace(&event, &played.Winner.Participant) {
+		return false, nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return false, fmt.Errorf("encoding payload: %w", err)
+	}
+
+	d.Payload = string(payload)
+
+	return true, nil
+}
+
+// published returns the copy of the participant which is sent outside,
+// e.g. to webhooks. Contacts and the note are left out, and the name is kept
+// only if the participant consented to publish it.
+func (p *Participant) published() Participant {
+	published := Participant{
+		ID:           p.ID,
+		PublishName:  p.PublishName,
+		AnonymizedAt: p.AnonymizedAt,
+		CreatedAt:    p.CreatedAt,
+	}
+
+	if p.PublishName {
+		published.Name = p.Name
+	}
+
+	return published
+}
+
+// published returns the copy of the player with the published participant.
+func (p *PlayParticipant) published() PlayParticipant {
+	published := *p
+	published.Participant = p.Participant.published()
+
+	return published
+}
+
+// Archive archives the raffle. Archived raffles are closed for donations
+// and payments, and their participants are anonymized
+// once the retention period passes, see OrganizerManager.AnonymizeExpired.
+func (rm *RaffleManager) Archive(id string, _ *ArchiveRequest) (*Raffle, error) {
+	raffle, err := rm.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get raffle: %w", err)
+	}
+
+	if raffle.ArchivedAt != nil {
+		return raffle, nil
+	}
+
+	now := timeNow()
+	raffle.ArchivedAt = &now
+
+	if err := rm.raffleStorage.Update(raffle); err != nil {
+		return nil, fmt.Errorf("update raffle: %w", err)
+	}
+
+	return raffle, nil
+}
+
+// anonymize anonymizes all participants of the raffle
+// and returns the number of them.
+func (rm *RaffleManager) anonymize(raffle *Raffle, now time.Time) (int, error) {
+	pm := rm.participantManager(raffle.ID)
+
+	prts, err := pm.List()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := pm.anonymize(prts, now); err != nil {
+		return 0, err
+	}
+
+	raffle.AnonymizedAt = &now
+
+	if err := rm.raffleStorage.Update(raffle); err != nil {
+		return 0, fmt.Errorf("update raffle: %w", err)
+	}
+
+	return len(prts), nil
+}
+
+// retentionExpired reports whether the raffle is archived longer than the retention period
+// and its participants are not anonymized yet.
+func (r *Raffle) retentionExpired(now time.Time, retention time.Duration) bool {
+	return r.ArchivedAt != nil && r.AnonymizedAt == nil && !now.Before(r.ArchivedAt.Add(retention))
+}
+
+// NextRetainedSince returns since when personal data of participants of the raffle
+// is retained: its archive time until the participants are anonymized, nil otherwise.
+func (r *Raffle) NextRetainedSince() *time.Time {
+	if r.AnonymizedAt != nil {
+		return nil
+	}
+
+	return r.ArchivedAt
+}
+
+// SetRetentionPeriod sets how long personal data of participants
+// of archived raffles is kept. Zero keeps it forever.
+func (om *OrganizerManager) SetRetentionPeriod(d time.Duration) {
+	om.retention = d
+}
+
+// AnonymizeExpired anonymizes participants of all raffles
+// archived longer than the retention period.
+// It is meant to be triggered periodically by a scheduler.
+// Failure to anonymize a single raffle doesn't stop the others,
+// the error is reported in the corresponding result instead.
+func (om *OrganizerManager) AnonymizeExpired() ([]AnonymizedRaffle, error) {
+	anonymized := make([]AnonymizedRaffle, 0)
+
+	if om.retention <= 0 {
+		return anonymized, nil
+	}
+
+	now := timeNow()
+
+	raffles, err := om.organizerStorage.RetainedRaffles(now.Add(-om.retention))
+	if err != nil {
+		return nil, fmt.Errorf("get retained raffles: %w", err)
+	}
+
+	for i := range raffles {
+		raffle := &raffles[i]
+		if !raffle.retentionExpired(now, om.retention) {
+			continue
+		}
+
+		result := AnonymizedRaffle{
+			OrganizerID: raffle.OrganizerID,
+			RaffleID:    raffle.ID,
+		}
+
+		result.Participants, err = om.raffleManager(raffle.OrganizerID).anonymize(raffle, now)
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		anonymized = append(anonymized, result)
+	}
+
+	return anonymized, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *ParticipantSuite) TestExportParticipant() {
+	prizeStorage, raffleDonations := s.bindRaffle()
+	prizeDonations := NewMockDonationStorage(s.ctrl)
+
+	participant := &Participant{ID: "p1", Name: "Olena", Phone: "+380501234567", ContactAllowed: true}
+	claim := &WinnerClaim{Status: ClaimClaimed}
+	prizes := []Prize{
+		{ID: "prize_1", Name: "Bicycle", TicketCost: 10, PlayResult: &PrizePlayResult{
+			Winners: []PlayParticipant{{Participant: *participant, Claim: claim}},
+		}},
+	}
+
+	s.storage.EXPECT().Get("p1").Return(participant, nil)
+	prizeStorage.EXPECT().GetAll().Return(prizes, nil).Times(2)
+	prizeStorage.EXPECT().DonationStorage("prize_1").Return(prizeDonations)
+	raffleDonations.EXPECT().GetAll().Return([]Donation{}, nil)
+	prizeDonations.EXPECT().GetAll().Return([]Donation{{ID: "d1", ParticipantID: "p1", Amount: 20}, {ID: "d2", ParticipantID: "p2", Amount: 10}}, nil)
+	s.payments.EXPECT().GetAll().Return([]Payment{{ID: "pay1", ParticipantID: "p1", Amount: 20}, {ID: "pay2", ParticipantID: "p2"}}, nil)
+
+	export, err := s.manager.Export("p1")
+	s.Require().NoError(err)
+	s.Equal(*participant, export.Participant)
+	s.Equal(20, export.TotalDonation)
+	s.Equal([]Payment{{ID: "pay1", ParticipantID: "p1", Amount: 20}}, export.Payments)
+	s.Equal([]ParticipantWin{{PrizeID: "prize_1", PrizeName: "Bicycle", Claim: claim}}, export.Wins)
+	s.Equal(s.mockTime, export.ExportedAt)
+}
+
+func (s *ParticipantSuite) TestAnonymizeParticipant() {
+	prizeStorage, _ := s.bindRaffle()
+
+	participant := Participant{
+		ID:             "p1",
+		Name:           "Olena",
+		Phone:          "+380501234567",
+		Note:           "pays in cash",
+		ContactAllowed: true,
+		TelegramChatID: "123456",
+		PublishName:    true,
+		CreatedAt:      s.mockTime,
+	}
+	other := Participant{ID: "p2", Name: "Taras", Phone: "+380671234567"}
+	anonymized := Participant{ID: "p1", AnonymizedAt: &s.mockTime, CreatedAt: s.mockTime}
+
+	player := func(p Participant) PlayParticipant {
+		return PlayParticipant{Participant: p, TotalDonation: 20, Donations: []Donation{{ID: "d1", ParticipantID: p.ID, Amount: 20}}}
+	}
+
+	prizes := []Prize{
+		{ID: "prize_1", PlayResult: &PrizePlayResult{
+			Winners:          []PlayParticipant{player(participant)},
+			PlayParticipants: []PlayParticipant{player(participant), player(other)},
+			Voided:           []VoidedWinner{{PlayParticipant: player(participant), Reason: "absent"}},
+		}},
+		{ID: "prize_2"},
+	}
+
+	s.storage.EXPECT().Get("p1").Return(&participant, nil)
+	s.storage.EXPECT().Update(&anonymized).Return(nil)
+	prizeStorage.EXPECT().GetAll().Return(prizes, nil)
+	prizeStorage.EXPECT().UpdateAll([]Prize{{ID: "prize_1", PlayResult: &PrizePlayResult{
+		Winners:          []PlayParticipant{player(anonymized)},
+		PlayParticipants: []PlayParticipant{player(anonymized), player(other)},
+		Voided:           []VoidedWinner{{PlayParticipant: player(anonymized), Reason: "absent"}},
+	}}}).Return(nil)
+
+	result, err := s.manager.Anonymize("p1", &AnonymizeRequest{})
+	s.Require().NoError(err)
+	s.Equal(&anonymized, result)
+
+	s.Run("already_anonymized", func() {
+		s.storage.EXPECT().Get("p1").Return(&anonymized, nil)
+		prizeStorage.EXPECT().GetAll().Return([]Prize{{ID: "prize_1", PlayResult: &PrizePlayResult{
+			Winners: []PlayParticipant{player(anonymized)},
+		}}}, nil)
+
+		result, err := s.manager.Anonymize("p1", &AnonymizeRequest{})
+		s.Require().NoError(err)
+		s.Equal(&anonymized, result)
+	})
+
+	s.Run("edit", func() {
+		s.storage.EXPECT().Get("p1").Return(&anonymized, nil)
+
+		err := s.manager.Edit("p1", dummyParticipantRequest())
+		s.ErrorIs(err, ErrParticipantAnonymized)
+	})
+}
+
+func TestAnonymizeExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	organizerStorage := NewMockOrganizerStorage(ctrl)
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	participantStorage := NewMockParticipantStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	deliveryStorage := NewMockDeliveryStorage(ctrl)
+
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	setTimeNowMock(now)
+
+	expired := now.Add(-31 * 24 * time.Hour)
+	recent := now.Add(-24 * time.Hour)
+
+	organizerStorage.EXPECT().RetainedRaffles(now.Add(-30*24*time.Hour)).Return([]Raffle{
+		{ID: "raffle_1", OrganizerID: "organizer_1", ArchivedAt: &expired},
+		{ID: "raffle_2", OrganizerID: "organizer_1", ArchivedAt: &recent},
+	}, nil)
+	organizerStorage.EXPECT().RaffleStorage("organizer_1").Return(raffleStorage).AnyTimes()
+	organizerStorage.EXPECT().DeliveryStorage("organizer_1").Return(deliveryStorage).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(participantStorage)
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage)
+
+	participantStorage.EXPECT().GetAll().Return([]Participant{
+		{ID: "p1", Name: "Olena", Phone: "+380501234567"},
+		{ID: "p2", AnonymizedAt: &recent},
+	}, nil)
+	participantStorage.EXPECT().Update(&Participant{ID: "p1", AnonymizedAt: &now}).Return(nil)
+	prizeStorage.EXPECT().GetAll().Return([]Prize{{ID: "prize_1"}}, nil)
+
+	// Deliveries sent before the name consent was enforced hold contacts.
+	played := func(raffleID string, winner Participant) string {
+		payload, err := json.Marshal(Event{ID: "event_1", Type: EventPrizePlayed, RaffleID: raffleID, Data: PrizePlayed{
+			PrizeID: "prize_1",
+			Winner:  PlayParticipant{Participant: winner, TotalDonation: 20},
+		}})
+		require.NoError(t, err)
+		return string(payload)
+	}
+
+	olena := Participant{ID: "p1", Name: "Olena", Phone: "+380501234567", PublishName: true}
+	deliveryStorage.EXPECT().GetByEventType(EventPrizePlayed).Return([]Delivery{
+		{ID: "delivery_1", EventType: EventPrizePlayed, Payload: played("raffle_1", olena)},
+		{ID: "delivery_2", EventType: EventPrizePlayed, Payload: played("raffle_1", Participant{ID: "p3", Name: "Taras"})},
+		{ID: "delivery_3", EventType: EventPrizePlayed, Payload: played("raffle_2", olena)},
+	}, nil)
+	deliveryStorage.EXPECT().Update(&Delivery{
+		ID:        "delivery_1",
+		EventType: EventPrizePlayed,
+		Payload:   played("raffle_1", Participant{ID: "p1", AnonymizedAt: &now}),
+	}).Return(nil)
+
+	raffleStorage.EXPECT().Update(gomock.Any()).DoAndReturn(func(r *Raffle) error {
+		assert.Equal(t, "raffle_1", r.ID)
+		assert.Equal(t, &now, r.AnonymizedAt)
+		return nil
+	})
+
+	om := &OrganizerManager{organizerStorage: organizerStorage}
+
+	anonymized, err := om.AnonymizeExpired()
+	require.NoError(t, err)
+	assert.Empty(t, anonymized, "retention period is not set")
+
+	om.SetRetentionPeriod(30 * 24 * time.Hour)
+
+	anonymized, err = om.AnonymizeExpired()
+	require.NoError(t, err)
+	assert.Equal(t, []AnonymizedRaffle{{OrganizerID: "organizer_1", RaffleID: "raffle_1", Participants: 2}}, anonymized)
+}
+
+func TestArchiveRaffle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	setTimeNowMock(now)
+
+	raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	prizeStorage.EXPECT().GetAll().Return(nil, nil).AnyTimes()
+	raffleStorage.EXPECT().Update(&Raffle{ID: "raffle_1", ArchivedAt: &now}).Return(nil)
+
+	rm := NewRaffleManager(raffleStorage)
+
+	raffle, err := rm.Archive("raffle_1", &ArchiveRequest{})
+	require.NoError(t, err)
+	assert.True(t, raffle.IsClosed(now))
+
+	raffleStorage.EXPECT().Get("raffle_1").Return(raffle, nil)
+	raffleStorage.EXPECT().DonationStorage("raffle_1").Return(nil)
+
+	ds, err := rm.DonationService("raffle_1")
+	require.NoError(t, err)
+	assert.IsType(t, &ClosedDonationService{}, ds)
+}
+
+func TestRedactContacts(t *testing.T) {
+	payload := func(winner Participant) string {
+		encoded, err := json.Marshal(Event{ID: "event_1", Type: EventPrizePlayed, Data: PrizePlayed{
+			PrizeID: "prize_1",
+			Winner:  PlayParticipant{Participant: winner, TotalDonation: 20},
+		}})
+		require.NoError(t, err)
+		return string(encoded)
+	}
+
+	delivery := Delivery{
+		ID:        "delivery_1",
+		EventType: EventPrizePlayed,
+		Payload:   payload(Participant{ID: "p1", Name: "Olena", Phone: "+380501234567", TelegramChatID: "123456"}),
+	}
+
+	changed, err := delivery.RedactContacts()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, payload(Participant{ID: "p1"}), delivery.Payload, "the name isn't kept without consent")
+
+	changed, err = delivery.RedactContacts()
+	require.NoError(t, err)
+	assert.False(t, changed, "redacted payloads are kept as is")
+
+	published := Delivery{EventType: EventPrizePlayed, Payload: payload(Participant{ID: "p1", Name: "Olena", PublishName: true})}
+	changed, err = published.RedactContacts()
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	other := Delivery{EventType: EventDonationCreated, Payload: `{"data": {"winner": {"participant": {"phone": "+380501234567"}}}}`}
+	changed, err = other.RedactContacts()
+	require.NoError(t, err)
+	assert.False(t, changed, "only prize.played events have participants")
+}
+
+func TestRaffleNextRetainedSince(t *testing.T) {
+	now := time.Now().UTC()
+
+	assert.Nil(t, (&Raffle{}).NextRetainedSince())
+	assert.Equal(t, &now, (&Raffle{ArchivedAt: &now}).NextRetainedSince())
+	assert.Nil(t, (&Raffle{ArchivedAt: &now, AnonymizedAt: &now}).NextRetainedSince())
+}
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 	"time"
 
 	"golang.org/x/exp/slices"
@@ -14,22 +16,134 @@ var (
 	ErrNoDonations              = fmt.Errorf("no donations")
 	ErrNotEnoughDonations       = fmt.Errorf("not enough donations")
 	ErrEditPlayedPrizeDonations = fmt.Errorf("can't edit played prize donations")
+	ErrNoWinners                = fmt.Errorf("no winners")
+	ErrWinnerNotFound           = fmt.Errorf("winner not found")
+	ErrClaimNotPending          = fmt.Errorf("prize claim is not pending")
+	ErrPrizeNotPlayed           = fmt.Errorf("prize is not played yet")
+	ErrRedrawFailed             = fmt.Errorf("prize re-draw failed")
 )
 
 // Prize represents a prize of the application.
 type Prize struct {
-	ID          string           `json:"id"`
-	Name        string           `json:"name"`
-	TicketCost  int              `json:"ticketCost"`
-	Description string           `json:"description"`
-	CreatedAt   time.Time        `json:"createdAt"`
-	PlayResult  *PrizePlayResult `json:"playResult"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	TicketCost  int        `json:"ticketCost"`
+	Description string     `json:"description"`
+	DrawAt      *time.Time `json:"drawAt,omitempty"`
+	ClaimDays   int        `json:"claimDays"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	// DueAt is when the scheduler has to act on the prize, see NextDueAt.
+	// It's kept by the storage along with the prize, so due prizes
+	// are found without reading all prizes of all raffles.
+	DueAt *time.Time `json:"-"`
+	// UpdatedAt is the version of the prize, see Versioned.
+	UpdatedAt time.Time `json:"updatedAt" firestore:"-"`
+
+	DrawStrategy DrawStrategy `json:"drawStrategy"`
+	// MaxTickets caps the number of tickets of a single participant.
+	// Zero means no limit.
+	MaxTickets int `json:"maxTickets"`
+
+	// UseRaffleTickets makes donations to the whole raffle count for the prize.
+	UseRaffleTickets bool `json:"useRaffleTickets"`
+	// ExcludeWinners excludes winners of other prizes from the draw.
+	ExcludeWinners bool `json:"excludeWinners"`
+
+	// TotalDonated, DonationCount and ParticipantCount are running totals
+	// of donations to the prize maintained by the storage along with donations.
+	TotalDonated     int `json:"totalDonated"`
+	DonationCount    int `json:"donationCount"`
+	ParticipantCount int `json:"participantCount"`
+
+	// Goal is an optional amount to be raised by the prize.
+	Goal *Goal `json:"goal,omitempty"`
+	// Progress is computed from the running totals when the prize is read.
+	Progress *GoalProgress `json:"progress,omitempty" firestore:"-"`
+
+	PlayResult *PrizePlayResult `json:"playResult"`
+}
+
+// IsPlayed reports whether the prize has at least one winner.
+func (p *Prize) IsPlayed() bool {
+	return p.PlayResult != nil && len(p.PlayResult.Winners) > 0
+}
+
+// IsDue reports whether the prize is scheduled
+// to be played and its draw time has come.
+func (p *Prize) IsDue(now time.Time) bool {
+	return p.PlayResult == nil && p.DrawAt != nil && !now.Before(*p.DrawAt)
+}
+
+// NextDueAt returns when the scheduler has to act on the prize next:
+// its draw time if it's not played yet, or otherwise the earliest deadline
+// of pending claims of its winners. It's nil if there is nothing to do.
+func (p *Prize) NextDueAt() *time.Time {
+	if p.PlayResult == nil {
+		return p.DrawAt
+	}
+
+	var due *time.Time
+
+	for _, winner := range p.PlayResult.Winners {
+		claim := winner.Claim
+		if claim == nil || claim.Status != ClaimPending || claim.Deadline == nil {
+			continue
+		}
+
+		if due == nil || claim.Deadline.Before(*due) {
+			due = claim.Deadline
+		}
+	}
+
+	return due
 }
 
 // PrizePlayResult is a response for played prize
 type PrizePlayResult struct {
 	Winners          []PlayParticipant `json:"winners"`
 	PlayParticipants []PlayParticipant `json:"participants"`
+	Voided           []VoidedWinner    `json:"voided,omitempty"`
+	History          []PlayEvent       `json:"history,omitempty"`
+	// Excluded lists participants left out of the last draw by the draw policy.
+	Excluded []ExcludedParticipant `json:"excluded,omitempty"`
+}
+
+// VoidedWinner is a winner whose draw was voided.
+type VoidedWinner struct {
+	PlayParticipant
+	Reason         string    `json:"reason"`
+	ReturnedToPool bool      `json:"returnedToPool"`
+	VoidedAt       time.Time `json:"voidedAt"`
+}
+
+// PlayEventType is a type of action taken on a play result.
+type PlayEventType string
+
+const (
+	PlayEventVoided    PlayEventType = "voided"
+	PlayEventClaimed   PlayEventType = "claimed"
+	PlayEventForfeited PlayEventType = "forfeited"
+	// PlayEventRedrawFailed is recorded when a forfeited prize can't be re-drawn.
+	PlayEventRedrawFailed PlayEventType = "redraw_failed"
+)
+
+// PlayEvent is a record of an action taken on a play result.
+type PlayEvent struct {
+	Type          PlayEventType `json:"type"`
+	ParticipantID string        `json:"participantId"`
+	Reason        string        `json:"reason,omitempty"`
+	CreatedAt     time.Time     `json:"createdAt"`
+}
+
+// VoidDrawRequest is a request for voiding the last draw of a prize.
+type VoidDrawRequest struct {
+	Reason       string `json:"reason" validate:"required,min=3,max=1000,charsValidation"`
+	ReturnToPool bool   `json:"returnToPool"`
+}
+
+// Validate validates VoidDrawRequest.
+func (v *VoidDrawRequest) Validate() error {
+	return validateStruct(v)
 }
 
 // PlayParticipant representation of result response of participant
@@ -38,18 +152,86 @@ type PlayParticipant struct {
 	TotalDonation      int         `json:"totalDonation"`
 	TotalTicketsNumber int         `json:"totalTicketsNumber"`
 	Donations          []Donation  `json:"donations"`
+
+	// Claim is set for winners only.
+	Claim *WinnerClaim `json:"claim,omitempty"`
+	// Notification is set for winners notified through a Notifier.
+	Notification *WinnerNotification `json:"notification,omitempty"`
+}
+
+// ClaimStatus is a status of a prize claim by its winner.
+type ClaimStatus string
+
+const (
+	ClaimPending   ClaimStatus = "pending"
+	ClaimClaimed   ClaimStatus = "claimed"
+	ClaimForfeited ClaimStatus = "forfeited"
+)
+
+// WinnerClaim tracks whether the winner collected the prize.
+type WinnerClaim struct {
+	Status    ClaimStatus `json:"status"`
+	Deadline  *time.Time  `json:"deadline,omitempty"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// IsExpired reports whether the claim is still pending
+// after its deadline.
+func (c *WinnerClaim) IsExpired(now time.Time) bool {
+	return c != nil && c.Status == ClaimPending && c.Deadline != nil && now.After(*c.Deadline)
+}
+
+// ClaimRequest is a request for marking a prize as collected by its winner.
+type ClaimRequest struct {
+	ParticipantID string `json:"participantId" validate:"required"`
+}
+
+// Validate validates ClaimRequest.
+func (c *ClaimRequest) Validate() error {
+	return validateStruct(c)
+}
+
+// ForfeitRequest is a request for forfeiting a prize
+// that was not collected by its winner.
+type ForfeitRequest struct {
+	ParticipantID string `json:"participantId" validate:"required"`
+	Reason        string `json:"reason" validate:"required,min=3,max=1000,charsValidation"`
+}
+
+// Validate validates ForfeitRequest.
+func (f *ForfeitRequest) Validate() error {
+	return validateStruct(f)
+}
+
+// UnclaimedPrize is a prize with a winner that hasn't collected it yet.
+type UnclaimedPrize struct {
+	PrizeID   string          `json:"prizeId"`
+	PrizeName string          `json:"prizeName"`
+	Winner    PlayParticipant `json:"winner"`
 }
 
 // PrizeRequest is a request for creating a new prize.
 type PrizeRequest struct {
-	Name        string `json:"name" validate:"required,min=3,max=50,charsValidation"`
-	TicketCost  int    `json:"ticketCost" validate:"gte=1,lte=5000"`
-	Description string `json:"description" validate:"lte=1000,charsValidation"`
+	Precondition
+
+	Name        string     `json:"name" validate:"required,min=3,max=50,charsValidation"`
+	TicketCost  int        `json:"ticketCost" validate:"gte=1,lte=5000"`
+	Description string     `json:"description" validate:"lte=1000,charsValidation"`
+	DrawAt      *time.Time `json:"drawAt,omitempty"`
+	ClaimDays   int        `json:"claimDays" validate:"gte=0,lte=365"`
+
+	DrawStrategy DrawStrategy `json:"drawStrategy" validate:"omitempty,oneof=tickets amount carry_over equal"`
+	MaxTickets   int          `json:"maxTickets" validate:"gte=0"`
+
+	UseRaffleTickets bool `json:"useRaffleTickets"`
+	ExcludeWinners   bool `json:"excludeWinners"`
+
+	Goal *Goal `json:"goal,omitempty"`
 }
 
 // Validate validates PrizeRequest.
 func (p *PrizeRequest) Validate() error {
-	return defaultValidator().Struct(p)
+	return validateStruct(p)
 }
 
 // PrizeService is a service for prizes.
@@ -57,10 +239,16 @@ type PrizeService interface {
 	Create(*PrizeRequest) (id string, err error)
 	Get(id string) (*Prize, error)
 	Edit(id string, p *PrizeRequest) error
+	Patch(id string, p *MergePatch) error
 	Delete(id string) error
 	List() ([]Prize, error)
 	DonationService(id string) (DonationService, error)
 	Play(prizeID string) (*PrizePlayResult, error)
+	PlayResult(prizeID string) (*PrizePlayResult, error)
+	VoidDraw(prizeID string, v *VoidDrawRequest) (*PrizePlayResult, error)
+	Claim(prizeID string, c *ClaimRequest) (*PrizePlayResult, error)
+	Forfeit(prizeID string, f *ForfeitRequest) (*PrizePlayResult, error)
+	ListUnclaimed() ([]UnclaimedPrize, error)
 }
 
 // PrizeStorage is a storage for prizes.
@@ -70,9 +258,13 @@ type PrizeStorage interface {
 	Create(*Prize) error
 	Get(id string) (*Prize, error)
 	Update(*Prize) error
+	UpdateFields(p *Prize, fields []string) error
+	UpdateAll([]Prize) error
 	GetAll() ([]Prize, error)
 	Delete(id string) error
 	DonationStorage(id string) DonationStorage
+	// CreateDonations creates donations keyed by prize ID in a single batch.
+	CreateDonations(donations map[string][]Donation) error
 }
 
 // PrizeManager is an implementation of PrizeService.
@@ -80,6 +272,13 @@ type PrizeManager struct {
 	prizeStorage       PrizeStorage
 	participantStorage ParticipantStorage
 	randomizer         Randomizer
+
+	// raffleID and raffleStorage are set when the manager
+	// is created in scope of a raffle. See RaffleManager.PrizeService.
+	raffleID      string
+	raffleStorage RaffleStorage
+	milestones    *MilestoneHooks
+	events        *raffleEvents
 }
 
 // NewPrizeManager creates a new PrizeManager.
@@ -112,6 +311,8 @@ func (pm *PrizeManager) Get(id string) (*Prize, error) {
 		return nil, fmt.Errorf("get prize: %w", err)
 	}
 
+	prizeProgress(prize)
+
 	return prize, nil
 }
 
@@ -126,13 +327,13 @@ func (pm *PrizeManager) Edit(id string, p *PrizeRequest) error {
 		return fmt.Errorf("get prize: %w", err)
 	}
 
-	if prize.PlayResult != nil {
-		return ErrPrizeAlreadyPlayed
+	if err := p.checkVersion(prize); err != nil {
+		return err
 	}
 
-	prize.Name = p.Name
-	prize.TicketCost = p.TicketCost
-	prize.Description = p.Description
+	if err := prize.edit(p); err != nil {
+		return err
+	}
 
 	if err := pm.prizeStorage.Update(prize); err != nil {
 		return fmt.Errorf("update prize: %w", err)
@@ -141,6 +342,26 @@ func (pm *PrizeManager) Edit(id string, p *PrizeRequest) error {
 	return nil
 }
 
+// edit applies the validated request to the prize.
+func (p *Prize) edit(r *PrizeRequest) error {
+	if p.IsPlayed() {
+		return ErrPrizeAlreadyPlayed
+	}
+
+	p.Name = r.Name
+	p.TicketCost = r.TicketCost
+	p.Description = r.Description
+	p.DrawAt = r.DrawAt
+	p.ClaimDays = r.ClaimDays
+	p.DrawStrategy = r.DrawStrategy
+	p.MaxTickets = r.MaxTickets
+	p.UseRaffleTickets = r.UseRaffleTickets
+	p.ExcludeWinners = r.ExcludeWinners
+	p.Goal = r.Goal
+
+	return nil
+}
+
 // Delete removes a Prize.
 func (pm *PrizeManager) Delete(id string) error {
 	if err := pm.prizeStorage.Delete(id); err != nil {
@@ -157,6 +378,10 @@ func (pm *PrizeManager) List() ([]Prize, error) {
 		return nil, fmt.Errorf("get all prizes: %w", err)
 	}
 
+	for i := range prizes {
+		prizeProgress(&prizes[i])
+	}
+
 	return prizes, nil
 }
 
@@ -167,22 +392,191 @@ func (pm *PrizeManager) Play(prizeID string) (*PrizePlayResult, error) {
 		return nil, fmt.Errorf("get prize to play: %w", err)
 	}
 
-	participants, err := pm.prepareParticipants(prize)
+	playResult, err := pm.draw(prize)
 	if err != nil {
 		return nil, fmt.Errorf("prepare participant for play: %w", err)
 	}
 
-	playResult := prize.Play(participants, pm.randomizer)
 	err = pm.prizeStorage.Update(prize)
 	if err != nil {
 		return nil, fmt.Errorf("update prize with play results: %w", err)
 	}
 
+	pm.publishPlayed(prize, playResult)
+
 	return playResult, nil
 }
 
+// publishPlayed publishes the last winner of the prize, see Participant.published.
+func (pm *PrizeManager) publishPlayed(prize *Prize, result *PrizePlayResult) {
+	pm.events.publish(EventPrizePlayed, PrizePlayed{
+		PrizeID:   prize.ID,
+		PrizeName: prize.Name,
+		Winner:    result.Winners[len(result.Winners)-1].published(),
+	})
+}
+
+// PlayResult returns the current play result of a prize without playing it.
+func (pm *PrizeManager) PlayResult(prizeID string) (*PrizePlayResult, error) {
+	prize, err := pm.prizeStorage.Get(prizeID)
+	if err != nil {
+		return nil, fmt.Errorf("get prize: %w", err)
+	}
+
+	if prize.PlayResult == nil {
+		return nil, ErrPrizeNotPlayed
+	}
+
+	return prize.PlayResult, nil
+}
+
+// VoidDraw voids the last draw of a prize.
+func (pm *PrizeManager) VoidDraw(prizeID string, v *VoidDrawRequest) (*PrizePlayResult, error) {
+	if err := v.Validate(); err != nil {
+		return nil, errors.Join(err, ErrInvalidRequest)
+	}
+
+	prize, err := pm.prizeStorage.Get(prizeID)
+	if err != nil {
+		return nil, fmt.Errorf("get prize to void draw: %w", err)
+	}
+
+	if err := prize.VoidLastDraw(v.Reason, v.ReturnToPool); err != nil {
+		return nil, err
+	}
+
+	if err := pm.prizeStorage.Update(prize); err != nil {
+		return nil, fmt.Errorf("update prize with voided draw: %w", err)
+	}
+
+	return prize.PlayResult, nil
+}
+
+// Claim marks the prize as collected by the winner.
+func (pm *PrizeManager) Claim(prizeID string, c *ClaimRequest) (*PrizePlayResult, error) {
+	if err := c.Validate(); err != nil {
+		return nil, errors.Join(err, ErrInvalidRequest)
+	}
+
+	prize, err := pm.prizeStorage.Get(prizeID)
+	if err != nil {
+		return nil, fmt.Errorf("get prize to claim: %w", err)
+	}
+
+	if err := prize.SetClaimStatus(c.ParticipantID, ClaimClaimed, ""); err != nil {
+		return nil, err
+	}
+
+	if err := pm.prizeStorage.Update(prize); err != nil {
+		return nil, fmt.Errorf("update prize with claim: %w", err)
+	}
+
+	return prize.PlayResult, nil
+}
+
+// Forfeit marks the prize as not collected by the winner
+// and draws a new winner from the remaining participants.
+func (pm *PrizeManager) Forfeit(prizeID string, f *ForfeitRequest) (*PrizePlayResult, error) {
+	if err := f.Validate(); err != nil {
+		return nil, errors.Join(err, ErrInvalidRequest)
+	}
+
+	prize, err := pm.prizeStorage.Get(prizeID)
+	if err != nil {
+		return nil, fmt.Errorf("get prize to forfeit: %w", err)
+	}
+
+	// A failed re-draw is recorded in the history of the returned result.
+	if err := pm.forfeit(prize, f.ParticipantID, f.Reason); err != nil && !errors.Is(err, ErrRedrawFailed) {
+		return nil, err
+	}
+
+	return prize.PlayResult, nil
+}
+
+// forfeit forfeits the claim of the winner and saves it,
+// then re-draws the prize if there are participants left.
+// A failed re-draw is recorded on the prize, so the winner isn't kept
+// and the claim isn't forfeited again, see recordRedrawFailure.
+func (pm *PrizeManager) forfeit(prize *Prize, participantID, reason string) error {
+	if err := prize.SetClaimStatus(participantID, ClaimForfeited, reason); err != nil {
+		return err
+	}
+
+	if err := pm.prizeStorage.Update(prize); err != nil {
+		return fmt.Errorf("update prize with forfeit: %w", err)
+	}
+
+	result, err := pm.draw(prize)
+	switch {
+	case errors.Is(err, ErrNoParticipants):
+		// Nobody left to re-draw the prize among.
+		return nil
+	case err != nil:
+		return pm.recordRedrawFailure(prize, participantID, err)
+	}
+
+	if err := pm.prizeStorage.Update(prize); err != nil {
+		return fmt.Errorf("update prize with re-draw: %w", err)
+	}
+
+	pm.publishPlayed(prize, result)
+
+	return nil
+}
+
+// recordRedrawFailure records the failure of the re-draw
+// after the claim of the participant was forfeited.
+// It returns ErrRedrawFailed with the cause once it's recorded.
+func (pm *PrizeManager) recordRedrawFailure(prize *Prize, participantID string, cause error) error {
+	prize.PlayResult.History = append(prize.PlayResult.History, PlayEvent{
+		Type:          PlayEventRedrawFailed,
+		ParticipantID: participantID,
+		Reason:        cause.Error(),
+		CreatedAt:     timeNow(),
+	})
+
+	if err := pm.prizeStorage.Update(prize); err != nil {
+		return fmt.Errorf("update prize with failed re-draw: %w", err)
+	}
+
+	return fmt.Errorf("%w: %w", ErrRedrawFailed, cause)
+}
+
+// ListUnclaimed returns winners that haven't collected their prizes yet.
+func (pm *PrizeManager) ListUnclaimed() ([]UnclaimedPrize, error) {
+	prizes, err := pm.List()
+	if err != nil {
+		return nil, err
+	}
+
+	unclaimed := make([]UnclaimedPrize, 0)
+
+	for _, prize := range prizes {
+		if prize.PlayResult == nil {
+			continue
+		}
+
+		for _, winner := range prize.PlayResult.Winners {
+			if winner.Claim == nil || winner.Claim.Status != ClaimPending {
+				continue
+			}
+
+			unclaimed = append(unclaimed, UnclaimedPrize{
+				PrizeID:   prize.ID,
+				PrizeName: prize.Name,
+				Winner:    winner,
+			})
+		}
+	}
+
+	return unclaimed, nil
+}
+
+// prepareParticipants returns the pool of participants of the prize.
+// For a played prize it is the stored pool, otherwise it's built from donations.
 func (pm *PrizeManager) prepareParticipants(prize *Prize) ([]PlayParticipant, error) {
-	if prize.PlayResult != nil {
+	if prize.IsPlayed() {
 		if len(prize.PlayResult.PlayParticipants) == 0 {
 			return nil, ErrNoParticipants
 		}
@@ -199,17 +593,22 @@ func (pm *PrizeManager) prepareParticipants(prize *Prize) ([]PlayParticipant, er
 		return nil, ErrNoParticipants
 	}
 
-	ds := pm.prizeStorage.DonationStorage(prize.ID)
-	donationsList, err := ds.GetAll()
+	donationsList, err := pm.prizeDonations(prize)
 	if err != nil {
-		return nil, fmt.Errorf("get donation list: %w", err)
+		return nil, err
 	}
 
 	if len(donationsList) == 0 {
 		return nil, ErrNoDonations
 	}
 
-	donations := countDonations(donationsList, participantList, prize.TicketCost)
+	counter, err := pm.ticketCounter(prize)
+	if err != nil {
+		return nil, err
+	}
+
+	donations := counter.count(donationsList, participantList)
+	donations = excludeParticipants(donations, prize.PlayResult.excludedIDs())
 	if len(donations) == 0 {
 		return nil, ErrNotEnoughDonations
 	}
@@ -217,8 +616,61 @@ func (pm *PrizeManager) prepareParticipants(prize *Prize) ([]PlayParticipant, er
 	return donations, nil
 }
 
+// prizeDonations returns donations that grant tickets for the prize,
+// including donations to the whole raffle if the prize accepts them.
+func (pm *PrizeManager) prizeDonations(prize *Prize) ([]Donation, error) {
+	donations, err := pm.prizeStorage.DonationStorage(prize.ID).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get donation list: %w", err)
+	}
+
+	if prize.UseRaffleTickets && pm.raffleStorage != nil {
+		raffleDonations, err := pm.raffleStorage.DonationStorage(pm.raffleID).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("get raffle donation list: %w", err)
+		}
+
+		donations = append(donations, raffleDonations...)
+	}
+
+	return donations, nil
+}
+
+// excludedIDs returns IDs of voided winners
+// that were not returned to the pool.
+func (r *PrizePlayResult) excludedIDs() []string {
+	if r == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(r.Voided))
+	for _, v := range r.Voided {
+		if !v.ReturnedToPool {
+			ids = append(ids, v.Participant.ID)
+		}
+	}
+
+	return ids
+}
+
+// excludeParticipants filters out participants with the given IDs.
+func excludeParticipants(participants []PlayParticipant, ids []string) []PlayParticipant {
+	if len(ids) == 0 {
+		return participants
+	}
+
+	result := make([]PlayParticipant, 0, len(participants))
+	for _, p := range participants {
+		if !slices.Contains(ids, p.Participant.ID) {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
+
 func (p *Prize) Play(participants []PlayParticipant, randomizer Randomizer) *PrizePlayResult {
-	winnerDonationID := randomizer.GenerateWinner(participants, p.TicketCost)
+	winnerDonationID := randomizer.GenerateWeightedWinner(participants, p.weightFunc())
 
 	winnerIndex := slices.IndexFunc(
 		participants,
@@ -230,6 +682,8 @@ func (p *Prize) Play(participants []PlayParticipant, randomizer Randomizer) *Pri
 	winner := participants[winnerIndex]
 	participants = append(participants[:winnerIndex], participants[winnerIndex+1:]...)
 
+	winner.Claim = p.newClaim()
+
 	if p.PlayResult == nil {
 		p.PlayResult = &PrizePlayResult{}
 	}
@@ -240,34 +694,103 @@ func (p *Prize) Play(participants []PlayParticipant, randomizer Randomizer) *Pri
 	return p.PlayResult
 }
 
-// countDonations counts donations, total amount and totat tickets count for each participant.
-func countDonations(donations []Donation, participants []Participant, ticketCost int) []PlayParticipant {
-	donationsMap := make(map[string][]Donation)
+// newClaim creates a pending claim for a new winner.
+func (p *Prize) newClaim() *WinnerClaim {
+	now := timeNow()
+	claim := &WinnerClaim{
+		Status:    ClaimPending,
+		UpdatedAt: now,
+	}
 
-	for _, d := range donations {
-		donationsMap[d.ParticipantID] = append(donationsMap[d.ParticipantID], d)
+	if p.ClaimDays > 0 {
+		deadline := now.AddDate(0, 0, p.ClaimDays)
+		claim.Deadline = &deadline
 	}
 
-	result := make([]PlayParticipant, 0, len(donationsMap))
+	return claim
+}
 
-	for _, participant := range participants {
-		donations := donationsMap[participant.ID]
-		totalDonation := countTotalDonation(donations)
+// SetClaimStatus resolves a pending claim of the winner.
+func (p *Prize) SetClaimStatus(participantID string, status ClaimStatus, reason string) error {
+	if p.PlayResult == nil {
+		return ErrNoWinners
+	}
 
-		ticketsNumber := totalDonation / ticketCost
-		if ticketsNumber == 0 {
-			continue
-		}
+	index := slices.IndexFunc(
+		p.PlayResult.Winners,
+		func(w PlayParticipant) bool {
+			return w.Participant.ID == participantID
+		},
+	)
 
-		result = append(result, PlayParticipant{
-			Participant:        participant,
-			TotalDonation:      totalDonation,
-			TotalTicketsNumber: ticketsNumber,
-			Donations:          donations,
-		})
+	if index == -1 {
+		return ErrWinnerNotFound
 	}
 
-	return result
+	winner := &p.PlayResult.Winners[index]
+	if winner.Claim == nil || winner.Claim.Status != ClaimPending {
+		return ErrClaimNotPending
+	}
+
+	now := timeNow()
+	winner.Claim.Status = status
+	winner.Claim.UpdatedAt = now
+
+	eventType := PlayEventClaimed
+	if status == ClaimForfeited {
+		eventType = PlayEventForfeited
+	}
+
+	p.PlayResult.History = append(p.PlayResult.History, PlayEvent{
+		Type:          eventType,
+		ParticipantID: participantID,
+		Reason:        reason,
+		CreatedAt:     now,
+	})
+
+	return nil
+}
+
+// VoidLastDraw moves the last winner to the voided list.
+// The voided winner is either returned to the pool
+// of participants or excluded from further draws.
+func (p *Prize) VoidLastDraw(reason string, returnToPool bool) error {
+	if !p.IsPlayed() {
+		return ErrNoWinners
+	}
+
+	lastIndex := len(p.PlayResult.Winners) - 1
+	winner := p.PlayResult.Winners[lastIndex]
+	p.PlayResult.Winners = p.PlayResult.Winners[:lastIndex]
+
+	if returnToPool {
+		returned := winner
+		returned.Claim = nil
+		p.PlayResult.PlayParticipants = append(p.PlayResult.PlayParticipants, returned)
+	}
+
+	now := timeNow()
+
+	p.PlayResult.Voided = append(p.PlayResult.Voided, VoidedWinner{
+		PlayParticipant: winner,
+		Reason:          reason,
+		ReturnedToPool:  returnToPool,
+		VoidedAt:        now,
+	})
+
+	p.PlayResult.History = append(p.PlayResult.History, PlayEvent{
+		Type:          PlayEventVoided,
+		ParticipantID: winner.Participant.ID,
+		Reason:        reason,
+		CreatedAt:     now,
+	})
+
+	return nil
+}
+
+// countDonations counts donations, total amount and totat tickets count for each participant.
+func countDonations(donations []Donation, participants []Participant, ticketCost int) []PlayParticipant {
+	return ticketCounter{ticketCost: ticketCost}.count(donations, participants)
 }
 
 func countTotalDonation(donations []Donation) int {
@@ -280,6 +803,17 @@ func countTotalDonation(donations []Donation) int {
 	return total
 }
 
+// countFineDonation counts the exact total amount in fine units, see fineUnits.
+func countFineDonation(donations []Donation) int {
+	total := 0
+
+	for i := range donations {
+		total += donations[i].fineAmount()
+	}
+
+	return total
+}
+
 // DonationService returns a DonationService for a prize.
 func (pm *PrizeManager) DonationService(prizeID string) (DonationService, error) {
 	prize, err := pm.prizeStorage.Get(prizeID)
@@ -290,13 +824,47 @@ func (pm *PrizeManager) DonationService(prizeID string) (DonationService, error)
 	donationStorage := pm.prizeStorage.DonationStorage(prize.ID)
 	donationService := NewDonationManager(donationStorage)
 
-	if prize.PlayResult != nil {
+	if prize.IsPlayed() {
 		return &ReadonlyDonationService{
 			DonationService: donationService,
 		}, nil
 	}
 
-	return donationService, nil
+	raffle, err := pm.raffle()
+	if err != nil {
+		return nil, fmt.Errorf("get raffle: %w", err)
+	}
+
+	if raffle != nil && raffle.IsClosed(timeNow()) {
+		return NewClosedDonationService(donationService), nil
+	}
+
+	if raffle != nil {
+		donationService.baseCurrency = raffle.BaseCurrency()
+	}
+
+	var ds DonationService = donationService
+
+	if pm.events != nil {
+		ds = &EventDonationService{DonationService: ds, prizeID: prize.ID, events: pm.events}
+	}
+
+	if raffle != nil && pm.milestones.subscribed() {
+		tracker := &milestoneTracker{raffleID: pm.raffleID, raffleStorage: pm.raffleStorage, hooks: pm.milestones}
+		ds = &MilestoneDonationService{DonationService: ds, tracker: tracker}
+	}
+
+	return ds, nil
+}
+
+// raffle returns the raffle the prizes belong to.
+// It returns nil if the manager is not bound to a raffle.
+func (pm *PrizeManager) raffle() (*Raffle, error) {
+	if pm.raffleStorage == nil {
+		return nil, nil
+	}
+
+	return pm.raffleStorage.Get(pm.raffleID)
 }
 
 // ReadonlyDonationService is a DonationService that
@@ -321,18 +889,51 @@ func (r *ReadonlyDonationService) Edit(string, *DonationRequest) error {
 	return ErrEditPlayedPrizeDonations
 }
 
+// Patch is a stub that returns an error.
+func (r *ReadonlyDonationService) Patch(string, *MergePatch) error {
+	return ErrEditPlayedPrizeDonations
+}
+
 // Delete is a stub that returns an error.
 func (r *ReadonlyDonationService) Delete(string) error {
 	return ErrEditPlayedPrizeDonations
 }
 
+// ClosedDonationService is a DonationService that
+// refuses new donations after the raffle is over.
+type ClosedDonationService struct {
+	DonationService
+}
+
+// NewClosedDonationService creates a new ClosedDonationService.
+func NewClosedDonationService(ds DonationService) *ClosedDonationService {
+	return &ClosedDonationService{
+		DonationService: ds,
+	}
+}
+
+// Create is a stub that returns an error.
+func (c *ClosedDonationService) Create(*DonationRequest) (string, error) {
+	return "", ErrRaffleClosed
+}
+
 func toPrize(p *PrizeRequest) *Prize {
 	return &Prize{
 		ID:          stringUUID(),
 		Name:        p.Name,
 		TicketCost:  p.TicketCost,
 		Description: p.Description,
+		DrawAt:      p.DrawAt,
+		ClaimDays:   p.ClaimDays,
 		CreatedAt:   timeNow(),
+
+		DrawStrategy: p.DrawStrategy,
+		MaxTickets:   p.MaxTickets,
+
+		UseRaffleTickets: p.UseRaffleTickets,
+		ExcludeWinners:   p.ExcludeWinners,
+
+		Goal: p.Goal,
 	}
 }
 
@@ -343,20 +944,30 @@ type Randomizer func(uint) uint
 // The winner is selected randomly as the person that made the donation.
 // Function panics if donations list is empty or ticketCost is 0.
 func (r Randomizer) GenerateWinner(participants []PlayParticipant, ticketCost int) (id string) {
-	tickets := generateParticipantChanceList(participants, ticketCost)
-	winnerTicketIndex := r(uint(len(tickets)))
-	return tickets[winnerTicketIndex]
+	return r.GenerateWeightedWinner(participants, ticketsWeight)
 }
 
-func generateParticipantChanceList(participants []PlayParticipant, ticketCost int) []string {
-	participantIDs := make([]string, 0)
-	for _, participant := range participants {
-		for i := 0; i < participant.TotalTicketsNumber; i++ {
-			participantIDs = append(participantIDs, participant.Participant.ID)
-		}
+// GenerateWeightedWinner returns a winner ID.
+// The chance of each participant is proportional to its weight.
+// Instead of allocating an entry per chance, a random point is picked
+// within the sum of all weights and the owner of that point is found
+// with a binary search over the cumulative sums of weights.
+// Function panics if participants list is empty or all weights are 0.
+func (r Randomizer) GenerateWeightedWinner(participants []PlayParticipant, weight WeightFunc) (id string) {
+	cumulative := make([]uint, len(participants))
+
+	var total uint
+	for i, participant := range participants {
+		total += uint(weight(participant))
+		cumulative[i] = total
 	}
 
-	return participantIDs
+	point := r(total)
+	winnerIndex := sort.Search(len(cumulative), func(i int) bool {
+		return cumulative[i] > point
+	})
+
+	return participants[winnerIndex].Participant.ID
 }
 
 // NewSimpleRandomizer creates a new Randomizer
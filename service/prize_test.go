@@ -273,6 +273,7 @@ func (s *PrizeSuite) TestDontaionService() {
 		ID:            s.mockUUID,
 		ParticipantID: mockedDonation.ParticipantID,
 		Amount:        mockedDonation.Amount,
+		Method:        PaymentCash,
 		CreatedAt:     s.mockTime,
 	}
 
@@ -365,6 +366,39 @@ func (s *PrizeSuite) TestDonationServiceReadonly() {
 
 }
 
+func (s *PrizeSuite) TestDonationServiceClosedRaffle() {
+	mockedPrize := dummyPrize()
+	endsAt := s.mockTime.Add(-time.Minute)
+
+	raffleStorage := NewMockRaffleStorage(s.ctrl)
+	s.manager.raffleID = "raffle_id"
+	s.manager.raffleStorage = raffleStorage
+
+	s.storage.EXPECT().Get(mockedPrize.ID).Return(mockedPrize, nil)
+	s.storage.EXPECT().DonationStorage(mockedPrize.ID).Return(NewMockDonationStorage(s.ctrl))
+	raffleStorage.EXPECT().Get("raffle_id").Return(&Raffle{ID: "raffle_id", EndsAt: &endsAt}, nil)
+
+	ds, err := s.manager.DonationService(mockedPrize.ID)
+	s.Require().NoError(err)
+	s.Require().IsType(&ClosedDonationService{}, ds)
+
+	response, err := ds.Create(&DonationRequest{ParticipantID: "participant_id_1", Amount: 200})
+	s.ErrorIs(err, ErrRaffleClosed)
+	s.Empty(response)
+
+	s.Run("not_closed_yet", func() {
+		endsAt := s.mockTime.Add(time.Minute)
+
+		s.storage.EXPECT().Get(mockedPrize.ID).Return(mockedPrize, nil)
+		s.storage.EXPECT().DonationStorage(mockedPrize.ID).Return(NewMockDonationStorage(s.ctrl))
+		raffleStorage.EXPECT().Get("raffle_id").Return(&Raffle{ID: "raffle_id", EndsAt: &endsAt}, nil)
+
+		ds, err := s.manager.DonationService(mockedPrize.ID)
+		s.Require().NoError(err)
+		s.Require().IsType(&DonationManager{}, ds)
+	})
+}
+
 func dummyPrizeRequest() *PrizeRequest {
 	return &PrizeRequest{
 		Name:        "prize_name_1",
@@ -11,6 +11,8 @@ import (
 
 var (
 	ErrAllWinnersFound = errors.New("all winners already found")
+	ErrInvalidSchedule = errors.New("raffle should end after it starts")
+	ErrRaffleClosed    = errors.New("raffle is closed")
 )
 
 // stringUUID is a plumbing function for generating UUIDs.
@@ -27,11 +29,88 @@ var timeNow = func() time.Time {
 
 // Raffle represents a raffle.
 type Raffle struct {
-	ID          string    `json:"id"`
-	OrganizerID string    `json:"organizerId"`
-	Name        string    `json:"name"`
-	Note        string    `json:"note"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID          string     `json:"id"`
+	OrganizerID string     `json:"organizerId"`
+	Name        string     `json:"name"`
+	Note        string     `json:"note"`
+	StartsAt    *time.Time `json:"startsAt,omitempty"`
+	EndsAt      *time.Time `json:"endsAt,omitempty"`
+
+	// ExcludeWinners excludes winners of any prize from subsequent draws.
+	ExcludeWinners bool `json:"excludeWinners"`
+	// MaxPrizesPerParticipant caps the number of prizes a participant can win.
+	// Zero means no limit.
+	MaxPrizesPerParticipant int `json:"maxPrizesPerParticipant"`
+
+	// Currency is the base currency of amounts of the raffle.
+	// Raffles created before it was introduced have it empty, see BaseCurrency.
+	Currency string `json:"currency"`
+
+	// Goal is an optional amount to be raised by the raffle.
+	Goal *Goal `json:"goal,omitempty"`
+	// Progress is computed from donations when the raffle is read.
+	Progress *GoalProgress `json:"progress,omitempty" firestore:"-"`
+
+	// WinnerMessage is the template of messages notifying winners,
+	// see WinnerMessageData. Winners are not notified if it's empty.
+	WinnerMessage string `json:"winnerMessage,omitempty"`
+
+	// ClosedPublishedAt is set when the raffle.closed event is published.
+	ClosedPublishedAt *time.Time `json:"closedPublishedAt,omitempty"`
+	// CloseDueAt is when the raffle.closed event is due, see NextCloseDueAt.
+	// It's kept by the storage along with the raffle, so closed raffles
+	// are found without reading all raffles of all organizers.
+	CloseDueAt *time.Time `json:"-"`
+
+	// ArchivedAt is set when the raffle is archived. Archived raffles are closed
+	// and their participants are anonymized after the retention period.
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+	// AnonymizedAt is set when all participants of the raffle are anonymized.
+	AnonymizedAt *time.Time `json:"anonymizedAt,omitempty"`
+	// RetainedSince is when retention of personal data of participants started,
+	// see NextRetainedSince. It's kept by the storage along with the raffle,
+	// so raffles to be anonymized are found without reading all raffles.
+	RetainedSince *time.Time `json:"-"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the version of the raffle, see Versioned.
+	UpdatedAt time.Time `json:"updatedAt" firestore:"-"`
+}
+
+// BaseCurrency returns the currency of amounts of the raffle.
+func (r *Raffle) BaseCurrency() string {
+	return currencyOrDefault(r.Currency)
+}
+
+// IsClosed reports whether the raffle is over at the given moment.
+// Archived raffles are always closed.
+func (r *Raffle) IsClosed(now time.Time) bool {
+	return r.ArchivedAt != nil || r.EndsAt != nil && !now.Before(*r.EndsAt)
+}
+
+// NextCloseDueAt returns when the raffle.closed event of the raffle is due:
+// the earliest of its end and archive times. It's nil if the event
+// is already published or the raffle isn't going to be closed yet.
+func (r *Raffle) NextCloseDueAt() *time.Time {
+	if r.ClosedPublishedAt != nil {
+		return nil
+	}
+
+	if r.ArchivedAt != nil && (r.EndsAt == nil || r.ArchivedAt.Before(*r.EndsAt)) {
+		return r.ArchivedAt
+	}
+
+	return r.EndsAt
+}
+
+// prizesLimit returns the number of prizes a participant can win
+// in the raffle. Zero means no limit.
+func (r *Raffle) prizesLimit() int {
+	if r.ExcludeWinners {
+		return 1
+	}
+
+	return r.MaxPrizesPerParticipant
 }
 
 // RaffleService is a service for raffles.
@@ -39,11 +118,20 @@ type RaffleService interface {
 	Create(*RaffleRequest) (id string, err error)
 	Get(id string) (*Raffle, error)
 	Edit(id string, r *RaffleRequest) error
+	Patch(id string, p *MergePatch) error
 	Delete(id string) error
 	List() ([]Raffle, error)
 	Export(id string) (*RaffleExportResult, error)
 	ParticipantService(id string) ParticipantService
 	PrizeService(id string) PrizeService
+	DonationService(id string) (DonationService, error)
+	PaymentService(id string) (PaymentService, error)
+	ReconciliationService(id string) ReconciliationService
+	PlayAll(id string, r *PlayAllRequest) (*RafflePlayResult, error)
+	CreateDonations(id string, r *BulkDonationRequest) (*BulkDonationResult, error)
+	Stats(id string) (*RaffleStats, error)
+	RepairTotals(id string, r *RepairTotalsRequest) (*RepairTotalsResult, error)
+	Archive(id string, r *ArchiveRequest) (*Raffle, error)
 }
 
 // RaffleStorage is a storage for raffles.
@@ -53,10 +141,15 @@ type RaffleStorage interface {
 	Create(*Raffle) error
 	Get(id string) (*Raffle, error)
 	Update(*Raffle) error
+	UpdateFields(r *Raffle, fields []string) error
 	Delete(id string) error
 	GetAll() ([]Raffle, error)
 	ParticipantStorage(id string) ParticipantStorage
 	PrizeStorage(id string) PrizeStorage
+	DonationStorage(id string) DonationStorage
+	PaymentStorage(id string) PaymentStorage
+	ShiftStorage(id string) ShiftStorage
+	StatsStorage(id string) StatsStorage
 }
 
 var _ RaffleService = (*RaffleManager)(nil)
@@ -64,6 +157,13 @@ var _ RaffleService = (*RaffleManager)(nil)
 // RaffleManager is an implementation of RaffleService.
 type RaffleManager struct {
 	raffleStorage RaffleStorage
+
+	// organizerID, milestones, events and deliveryStorage are set when
+	// the manager is created by OrganizerManager. See OrganizerManager.RaffleService.
+	organizerID     string
+	milestones      *MilestoneHooks
+	events          *EventHooks
+	deliveryStorage DeliveryStorage
 }
 
 // NewRaffleManager creates a new RaffleManager.
@@ -83,7 +183,15 @@ func (rm *RaffleManager) Create(request *RaffleRequest) (string, error) {
 		ID:        stringUUID(),
 		Name:      request.Name,
 		Note:      request.Note,
+		StartsAt:  request.StartsAt,
+		EndsAt:    request.EndsAt,
 		CreatedAt: timeNow(),
+
+		ExcludeWinners:          request.ExcludeWinners,
+		MaxPrizesPerParticipant: request.MaxPrizesPerParticipant,
+		Currency:                currencyOrDefault(request.Currency),
+		Goal:                    request.Goal,
+		WinnerMessage:           request.WinnerMessage,
 	}
 
 	if err := rm.raffleStorage.Create(&raffle); err != nil {
@@ -93,9 +201,18 @@ func (rm *RaffleManager) Create(request *RaffleRequest) (string, error) {
 	return raffle.ID, nil
 }
 
-// Get returns a raffle by id.
+// Get returns a raffle by id along with the progress of its goal.
 func (rm *RaffleManager) Get(id string) (*Raffle, error) {
-	return rm.raffleStorage.Get(id)
+	raffle, err := rm.raffleStorage.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rm.raffleProgress(raffle); err != nil {
+		return nil, err
+	}
+
+	return raffle, nil
 }
 
 // Edit edits a raffle.
@@ -109,8 +226,13 @@ func (rm *RaffleManager) Edit(id string, r *RaffleRequest) error {
 		return fmt.Errorf("get raffle: %w", err)
 	}
 
-	raffle.Name = r.Name
-	raffle.Note = r.Note
+	if err := r.checkVersion(raffle); err != nil {
+		return err
+	}
+
+	if err := rm.edit(raffle, r); err != nil {
+		return err
+	}
 
 	if err := rm.raffleStorage.Update(raffle); err != nil {
 		return fmt.Errorf("update raffle: %w", err)
@@ -119,6 +241,33 @@ func (rm *RaffleManager) Edit(id string, r *RaffleRequest) error {
 	return nil
 }
 
+// edit applies the validated request to the raffle.
+func (rm *RaffleManager) edit(raffle *Raffle, r *RaffleRequest) error {
+	raffle.Name = r.Name
+	raffle.Note = r.Note
+	raffle.StartsAt = r.StartsAt
+	raffle.EndsAt = r.EndsAt
+	raffle.ExcludeWinners = r.ExcludeWinners
+	raffle.MaxPrizesPerParticipant = r.MaxPrizesPerParticipant
+	raffle.Goal = r.Goal
+	raffle.WinnerMessage = r.WinnerMessage
+
+	// The raffle is published again when it ends after being reopened.
+	if !raffle.IsClosed(timeNow()) {
+		raffle.ClosedPublishedAt = nil
+	}
+
+	if r.Currency != "" && r.Currency != raffle.BaseCurrency() {
+		if err := rm.checkNoDonations(raffle.ID); err != nil {
+			return err
+		}
+
+		raffle.Currency = r.Currency
+	}
+
+	return nil
+}
+
 // Delete a raffle.
 func (rm *RaffleManager) Delete(id string) error {
 	if err := rm.raffleStorage.Delete(id); err != nil {
@@ -135,6 +284,12 @@ func (rm *RaffleManager) List() ([]Raffle, error) {
 		return nil, fmt.Errorf("get all raffles: %w", err)
 	}
 
+	for i := range raffles {
+		if err := rm.raffleProgress(&raffles[i]); err != nil {
+			return nil, err
+		}
+	}
+
 	return raffles, nil
 }
 
@@ -154,10 +309,20 @@ func (rm *RaffleManager) Export(id string) (*RaffleExportResult, error) {
 		return nil, fmt.Errorf("get prizes: %w", err)
 	}
 
+	donations, err := NewReconciliationManager(id, rm.raffleStorage).donations()
+	if err != nil {
+		return nil, err
+	}
+
+	collections := []any{raf, prts, przs}
+	if len(donations) > 0 {
+		collections = append(collections, exportDonations(donations, raf.BaseCurrency()))
+	}
+
 	xlsx := NewXLSX()
 
 	buf := new(bytes.Buffer)
-	if err := xlsx.WriteXLSX(buf, raf, prts, przs); err != nil {
+	if err := xlsx.WriteXLSX(buf, collections...); err != nil {
 		return nil, fmt.Errorf("write xlsx: %w", err)
 	}
 
@@ -171,25 +336,176 @@ func (rm *RaffleManager) Export(id string) (*RaffleExportResult, error) {
 
 // ParticipantService is a service for participants.
 func (rm *RaffleManager) ParticipantService(id string) ParticipantService {
-	return NewParticipantManager(rm.raffleStorage.ParticipantStorage(id))
+	return rm.participantManager(id)
+}
+
+func (rm *RaffleManager) participantManager(id string) *ParticipantManager {
+	pm := NewParticipantManager(rm.raffleStorage.ParticipantStorage(id))
+	pm.raffleID = id
+	pm.raffleStorage = rm.raffleStorage
+	pm.deliveryStorage = rm.deliveryStorage
+
+	return pm
 }
 
 // PrizeService is a service for prizes.
 func (rm *RaffleManager) PrizeService(id string) PrizeService {
-	return NewPrizeManager(
+	return rm.prizeManager(id)
+}
+
+// DonationService is a service for donations made to the whole raffle.
+// Such donations grant tickets for prizes that accept raffle tickets.
+func (rm *RaffleManager) DonationService(id string) (DonationService, error) {
+	raffle, err := rm.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get raffle: %w", err)
+	}
+
+	donationService := NewDonationManager(rm.raffleStorage.DonationStorage(id))
+	donationService.baseCurrency = raffle.BaseCurrency()
+
+	if raffle.IsClosed(timeNow()) {
+		return NewClosedDonationService(donationService), nil
+	}
+
+	var ds DonationService = donationService
+
+	if events := rm.raffleEvents(id); events != nil {
+		ds = &EventDonationService{DonationService: ds, events: events}
+	}
+
+	if tracker := rm.milestoneTracker(id); tracker != nil {
+		ds = &MilestoneDonationService{DonationService: ds, tracker: tracker}
+	}
+
+	return ds, nil
+}
+
+// PaymentService returns a service for payments split across prizes of the raffle.
+func (rm *RaffleManager) PaymentService(id string) (PaymentService, error) {
+	raffle, err := rm.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get raffle: %w", err)
+	}
+
+	paymentService := NewPaymentManager(
+		rm.raffleStorage.PaymentStorage(id),
+		rm.raffleStorage.PrizeStorage(id),
+		rm.raffleStorage.ParticipantStorage(id),
+	)
+
+	if raffle.IsClosed(timeNow()) {
+		return NewClosedPaymentService(paymentService), nil
+	}
+
+	var ps PaymentService = paymentService
+
+	if events := rm.raffleEvents(id); events != nil {
+		ps = &EventPaymentService{PaymentService: ps, events: events}
+	}
+
+	if tracker := rm.milestoneTracker(id); tracker != nil {
+		ps = &MilestonePaymentService{PaymentService: ps, tracker: tracker}
+	}
+
+	return ps, nil
+}
+
+// checkNoDonations returns ErrBaseCurrencyInUse if the raffle has donations,
+// so their amounts keep the currency they're entered in.
+func (rm *RaffleManager) checkNoDonations(id string) error {
+	counters, err := rm.raffleStorage.StatsStorage(id).Get()
+	if err != nil {
+		return fmt.Errorf("get counters: %w", err)
+	}
+
+	if counters.Donations > 0 {
+		return ErrBaseCurrencyInUse
+	}
+
+	return nil
+}
+
+// ReconciliationService returns a service for reconciling donations of the raffle.
+func (rm *RaffleManager) ReconciliationService(id string) ReconciliationService {
+	return NewReconciliationManager(id, rm.raffleStorage)
+}
+
+// prizeManager creates a PrizeManager bound to the raffle,
+// so it can respect the raffle schedule and use raffle donations.
+func (rm *RaffleManager) prizeManager(id string) *PrizeManager {
+	pm := NewPrizeManager(
 		rm.raffleStorage.PrizeStorage(id),
 		rm.raffleStorage.ParticipantStorage(id),
 	)
+
+	pm.raffleID = id
+	pm.raffleStorage = rm.raffleStorage
+	pm.milestones = rm.milestones
+	pm.events = rm.raffleEvents(id)
+
+	return pm
+}
+
+// milestoneTracker returns a tracker of milestones of the raffle,
+// nil if no hooks are subscribed to them.
+func (rm *RaffleManager) milestoneTracker(id string) *milestoneTracker {
+	if !rm.milestones.subscribed() {
+		return nil
+	}
+
+	return &milestoneTracker{
+		raffleID:      id,
+		raffleStorage: rm.raffleStorage,
+		hooks:         rm.milestones,
+	}
+}
+
+// raffleEvents returns a publisher of events of the raffle,
+// nil if no hooks are subscribed to them.
+func (rm *RaffleManager) raffleEvents(id string) *raffleEvents {
+	if !rm.events.subscribed() {
+		return nil
+	}
+
+	return &raffleEvents{
+		organizerID: rm.organizerID,
+		raffleID:    id,
+		hooks:       rm.events,
+	}
 }
 
 // RaffleRequest is a request for initializing a raffle.
 type RaffleRequest struct {
-	Name string `json:"name" validate:"required,min=3,max=50,charsValidation"`
-	Note string `json:"note" validate:"lte=1000,charsValidation"`
+	Precondition
+
+	Name     string     `json:"name" validate:"required,min=3,max=50,charsValidation"`
+	Note     string     `json:"note" validate:"lte=1000,charsValidation"`
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+
+	ExcludeWinners          bool `json:"excludeWinners"`
+	MaxPrizesPerParticipant int  `json:"maxPrizesPerParticipant" validate:"gte=0"`
+
+	// Currency is the base currency, DefaultCurrency if it's not set.
+	// It's kept unchanged on edit if it's not set.
+	Currency string `json:"currency,omitempty" validate:"omitempty,iso4217"`
+
+	Goal *Goal `json:"goal,omitempty"`
+
+	WinnerMessage string `json:"winnerMessage,omitempty" validate:"lte=1000,messageTemplate"`
 }
 
 func (r *RaffleRequest) Validate() error {
-	return defaultValidator().Struct(r)
+	if err := validateStruct(r); err != nil {
+		return err
+	}
+
+	if r.StartsAt != nil && r.EndsAt != nil && !r.EndsAt.After(*r.StartsAt) {
+		return ErrInvalidSchedule
+	}
+
+	return nil
 }
 
 // RaffleExportResult is a response for exporting a raffle sub-collections.
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"github.com/xuri/excelize/v2"
 	"go.uber.org/mock/gomock"
 )
 
@@ -43,6 +45,7 @@ func (s *RaffleSuite) TestCreateRaffle() {
 		ID:        s.mockUUID,
 		Name:      raffleRequest.Name,
 		Note:      raffleRequest.Note,
+		Currency:  DefaultCurrency,
 		CreatedAt: s.mockTime,
 	}
 
@@ -58,6 +61,7 @@ func (s *RaffleSuite) TestCreateRaffle() {
 			ID:        s.mockUUID,
 			Name:      request.Name,
 			Note:      request.Note,
+			Currency:  DefaultCurrency,
 			CreatedAt: s.mockTime,
 		}
 
@@ -86,6 +90,45 @@ func (s *RaffleSuite) TestCreateRaffle() {
 		s.ErrorIs(err, ErrInvalidRequest)
 		s.Equal("", response)
 	})
+
+	s.Run("scheduled", func() {
+		startsAt := s.mockTime.Add(time.Hour)
+		endsAt := startsAt.Add(time.Hour)
+
+		request := dummyRaffleRequest()
+		request.StartsAt = &startsAt
+		request.EndsAt = &endsAt
+
+		expectedRaffle := &Raffle{
+			ID:        s.mockUUID,
+			Name:      request.Name,
+			Note:      request.Note,
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+			Currency:  DefaultCurrency,
+			CreatedAt: s.mockTime,
+		}
+
+		s.storage.EXPECT().Create(expectedRaffle).Return(nil)
+
+		response, err := s.manager.Create(request)
+		s.NoError(err)
+		s.Equal(s.mockUUID, response)
+	})
+
+	s.Run("ends_before_start", func() {
+		startsAt := s.mockTime.Add(time.Hour)
+		endsAt := startsAt.Add(-time.Minute)
+
+		request := dummyRaffleRequest()
+		request.StartsAt = &startsAt
+		request.EndsAt = &endsAt
+
+		response, err := s.manager.Create(request)
+		s.ErrorIs(err, ErrInvalidSchedule)
+		s.ErrorIs(err, ErrInvalidRequest)
+		s.Equal("", response)
+	})
 }
 
 func (s *RaffleSuite) TestGetRaffle() {
@@ -150,6 +193,37 @@ func (s *RaffleSuite) TestEditRaffle() {
 	})
 }
 
+func (s *RaffleSuite) TestEditRaffleCurrency() {
+	statsStorage := NewMockStatsStorage(s.ctrl)
+
+	request := dummyRaffleRequest()
+	request.Currency = "EUR"
+
+	s.Run("no_donations", func() {
+		raffle := dummyRaffle()
+
+		s.storage.EXPECT().Get(raffle.ID).Return(raffle, nil)
+		s.storage.EXPECT().StatsStorage(raffle.ID).Return(statsStorage)
+		statsStorage.EXPECT().Get().Return(&RaffleCounters{}, nil)
+		s.storage.EXPECT().Update(gomock.Any()).DoAndReturn(func(r *Raffle) error {
+			s.Equal("EUR", r.Currency)
+			return nil
+		})
+
+		s.NoError(s.manager.Edit(raffle.ID, request))
+	})
+
+	s.Run("with_donations", func() {
+		raffle := dummyRaffle()
+
+		s.storage.EXPECT().Get(raffle.ID).Return(raffle, nil)
+		s.storage.EXPECT().StatsStorage(raffle.ID).Return(statsStorage)
+		statsStorage.EXPECT().Get().Return(&RaffleCounters{Donations: 1}, nil)
+
+		s.ErrorIs(s.manager.Edit(raffle.ID, request), ErrBaseCurrencyInUse)
+	})
+}
+
 func (s *RaffleSuite) TestDeleteRaffle() {
 	mockedRaffle := dummyRaffle()
 
@@ -204,14 +278,71 @@ func (s *RaffleSuite) TestExportRaffle() {
 	psMock.EXPECT().GetAll().Return(prts, nil)
 
 	pzMock := NewMockPrizeStorage(s.ctrl)
-	s.storage.EXPECT().PrizeStorage(s.mockUUID).Return(pzMock)
-	pzMock.EXPECT().GetAll().Return(przs, nil)
+	s.storage.EXPECT().PrizeStorage(s.mockUUID).Return(pzMock).Times(2)
+	pzMock.EXPECT().GetAll().Return(przs, nil).Times(2)
+
+	raffleDonations := NewMockDonationStorage(s.ctrl)
+	s.storage.EXPECT().DonationStorage(s.mockUUID).Return(raffleDonations)
+	raffleDonations.EXPECT().GetAll().Return(nil, nil)
+
+	prizeDonations := NewMockDonationStorage(s.ctrl)
+	pzMock.EXPECT().DonationStorage("pr1").Return(prizeDonations)
+	noDonations := NewMockDonationStorage(s.ctrl)
+	pzMock.EXPECT().DonationStorage("pr2").Return(noDonations)
+	noDonations.EXPECT().GetAll().Return(nil, nil)
+	prizeDonations.EXPECT().GetAll().Return([]Donation{{
+		ID:            "d1",
+		ParticipantID: "p1",
+		Amount:        552,
+		Original:      &Money{Amount: 1250, Currency: "EUR"},
+		ExchangeRate:  "44.15",
+	}}, nil)
 
 	res, err := s.manager.Export(s.mockUUID)
 	s.Require().NoError(err)
 	s.Require().NotNil(res)
 	s.Require().Equal("yarmarok_"+s.mockUUID+".xlsx", res.FileName)
 	s.Require().NotEmpty(res.Content)
+
+	file, err := excelize.OpenReader(bytes.NewReader(res.Content))
+	s.Require().NoError(err)
+
+	rows, err := file.GetRows("ExportedDonation")
+	s.Require().NoError(err)
+	s.Require().Len(rows, 2)
+	s.Equal([]string{"d1", "pr1", "p1", "552", "UAH", "12.50", "EUR", "44.15", "cash"}, rows[1][:9])
+}
+
+func (s *RaffleSuite) TestRaffleDonationService() {
+	raffle := dummyRaffle()
+
+	s.storage.EXPECT().Get(raffle.ID).Return(raffle, nil)
+	s.storage.EXPECT().DonationStorage(raffle.ID).Return(NewMockDonationStorage(s.ctrl))
+
+	ds, err := s.manager.DonationService(raffle.ID)
+	s.Require().NoError(err)
+	s.IsType(&DonationManager{}, ds)
+
+	s.Run("closed", func() {
+		endsAt := s.mockTime.Add(-time.Minute)
+		closed := dummyRaffle()
+		closed.EndsAt = &endsAt
+
+		s.storage.EXPECT().Get(closed.ID).Return(closed, nil)
+		s.storage.EXPECT().DonationStorage(closed.ID).Return(NewMockDonationStorage(s.ctrl))
+
+		ds, err := s.manager.DonationService(closed.ID)
+		s.Require().NoError(err)
+		s.IsType(&ClosedDonationService{}, ds)
+	})
+
+	s.Run("error", func() {
+		s.storage.EXPECT().Get(raffle.ID).Return(nil, assert.AnError)
+
+		ds, err := s.manager.DonationService(raffle.ID)
+		s.ErrorIs(err, assert.AnError)
+		s.Nil(ds)
+	})
 }
 
 func setUUIDMock(uuid string) {
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrShiftClosed        = errors.New("shift is closed, its donations can't be changed")
+	ErrShiftOutdated      = errors.New("donations changed while closing the shift")
+	ErrInvalidTimeWindow  = errors.New("time window must end after it starts")
+	ErrMissingVolunteerID = errors.New("volunteer id is required")
+)
+
+// ReconciliationTotals are sums of donations.
+type ReconciliationTotals struct {
+	Total    int                   `json:"total"`
+	Count    int                   `json:"count"`
+	ByMethod map[PaymentMethod]int `json:"byMethod"`
+}
+
+func (t *ReconciliationTotals) add(d *Donation) {
+	if t.ByMethod == nil {
+		t.ByMethod = make(map[PaymentMethod]int)
+	}
+
+	t.Total += d.Amount
+	t.Count++
+	t.ByMethod[methodOrDefault(d.Method)] += d.Amount
+}
+
+// VolunteerTotals are sums of donations entered by a volunteer.
+type VolunteerTotals struct {
+	VolunteerID string               `json:"volunteerId"`
+	Totals      ReconciliationTotals `json:"totals"`
+	// Unlocked is the sum of donations not covered by a closed shift yet.
+	Unlocked int `json:"unlocked"`
+}
+
+// ReconciliationRequest limits the report to donations made in a time window.
+// Both bounds are optional, From is inclusive and To is exclusive.
+type ReconciliationRequest struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+func (r *ReconciliationRequest) Validate() error {
+	if r.From != nil && r.To != nil && !r.To.After(*r.From) {
+		return ErrInvalidTimeWindow
+	}
+
+	return nil
+}
+
+func (r *ReconciliationRequest) contains(t time.Time) bool {
+	if r.From != nil && t.Before(*r.From) {
+		return false
+	}
+
+	return r.To == nil || t.Before(*r.To)
+}
+
+// ReconciliationReport sums donations of the raffle
+// by payment method and by volunteer who entered them.
+type ReconciliationReport struct {
+	From        *time.Time           `json:"from,omitempty"`
+	To          *time.Time           `json:"to,omitempty"`
+	Totals      ReconciliationTotals `json:"totals"`
+	ByVolunteer []VolunteerTotals    `json:"byVolunteer"`
+}
+
+// CloseShiftRequest is a request for closing a shift of a volunteer.
+type CloseShiftRequest struct {
+	VolunteerID string `json:"volunteerId"`
+}
+
+func (r *CloseShiftRequest) Validate() error {
+	if r.VolunteerID == "" {
+		return ErrMissingVolunteerID
+	}
+
+	return nil
+}
+
+// Shift is a closed shift of a volunteer.
+// It locks the donations and payments the volunteer entered
+// since the previous shift, so the counted cash matches the system.
+type Shift struct {
+	ID          string               `json:"id"`
+	VolunteerID string               `json:"volunteerId"`
+	Totals      ReconciliationTotals `json:"totals"`
+	Donations   []ShiftDonation      `json:"donations"`
+	PaymentIDs  []string             `json:"paymentIds,omitempty"`
+	ClosedAt    time.Time            `json:"closedAt"`
+}
+
+// ShiftDonation refers to a donation locked by a shift.
+// PrizeID is empty for donations made to the whole raffle.
+type ShiftDonation struct {
+	PrizeID    string `json:"prizeId,omitempty"`
+	DonationID string `json:"donationId"`
+	Amount     int    `json:"amount"`
+}
+
+// ReconciliationService is a service for reconciling donations
+// with the money counted by volunteers.
+type ReconciliationService interface {
+	Report(*ReconciliationRequest) (*ReconciliationReport, error)
+	CloseShift(*CloseShiftRequest) (*Shift, error)
+	ListShifts() ([]Shift, error)
+}
+
+// ShiftStorage is a storage for closed shifts.
+//
+//go:generate mockgen -destination=mock_shift_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service ShiftStorage
+type ShiftStorage interface {
+	// Create creates the shift and locks its donations and payments at once.
+	// ErrShiftOutdated is returned if any of them has changed in the meantime.
+	Create(*Shift) error
+	GetAll() ([]Shift, error)
+}
+
+var _ ReconciliationService = (*ReconciliationManager)(nil)
+
+// ReconciliationManager is an implementation of ReconciliationService.
+type ReconciliationManager struct {
+	raffleID      string
+	raffleStorage RaffleStorage
+}
+
+// NewReconciliationManager creates a new ReconciliationManager for the raffle.
+func NewReconciliationManager(raffleID string, rs RaffleStorage) *ReconciliationManager {
+	return &ReconciliationManager{
+		raffleID:      raffleID,
+		raffleStorage: rs,
+	}
+}
+
+// Report sums donations made in the requested time window.
+func (rm *ReconciliationManager) Report(r *ReconciliationRequest) (*ReconciliationReport, error) {
+	if err := r.Validate(); err != nil {
+		return nil, errors.Join(err, ErrInvalidRequest)
+	}
+
+	donations, err := rm.donations()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{From: r.From, To: r.To, ByVolunteer: []VolunteerTotals{}}
+	volunteers := make(map[string]*VolunteerTotals)
+
+	for i := range donations {
+		d := &donations[i].Donation
+		if !r.contains(d.CreatedAt) {
+			continue
+		}
+
+		report.Totals.add(d)
+
+		volunteer, ok := volunteers[d.EnteredBy]
+		if !ok {
+			volunteer = &VolunteerTotals{VolunteerID: d.EnteredBy}
+			volunteers[d.EnteredBy] = volunteer
+		}
+
+		volunteer.Totals.add(d)
+		if d.ShiftID == "" {
+			volunteer.Unlocked += d.Amount
+		}
+	}
+
+	for _, v := range volunteers {
+		report.ByVolunteer = append(report.ByVolunteer, *v)
+	}
+
+	sort.Slice(report.ByVolunteer, func(i, j int) bool {
+		return report.ByVolunteer[i].VolunteerID < report.ByVolunteer[j].VolunteerID
+	})
+
+	return report, nil
+}
+
+// CloseShift locks donations and payments entered by the volunteer
+// which are not locked by any of the previous shifts.
+func (rm *ReconciliationManager) CloseShift(r *CloseShiftRequest) (*Shift, error) {
+	if err := r.Validate(); err != nil {
+		return nil, errors.Join(err, ErrInvalidRequest)
+	}
+
+	donations, err := rm.donations()
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := rm.raffleStorage.PaymentStorage(rm.raffleID).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all payments: %w", err)
+	}
+
+	shift := &Shift{
+		ID:          stringUUID(),
+		VolunteerID: r.VolunteerID,
+		Donations:   []ShiftDonation{},
+		ClosedAt:    timeNow(),
+	}
+
+	for i := range donations {
+		d := &donations[i].Donation
+		if d.EnteredBy != r.VolunteerID || d.ShiftID != "" {
+			continue
+		}
+
+		shift.Totals.add(d)
+		shift.Donations = append(shift.Donations, ShiftDonation{
+			PrizeID:    donations[i].PrizeID,
+			DonationID: d.ID,
+			Amount:     d.Amount,
+		})
+	}
+
+	for _, p := range payments {
+		if p.EnteredBy == r.VolunteerID && p.ShiftID == "" {
+			shift.PaymentIDs = append(shift.PaymentIDs, p.ID)
+		}
+	}
+
+	if err := rm.raffleStorage.ShiftStorage(rm.raffleID).Create(shift); err != nil {
+		return nil, fmt.Errorf("create shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+// ListShifts returns closed shifts of the raffle.
+func (rm *ReconciliationManager) ListShifts() ([]Shift, error) {
+	shifts, err := rm.raffleStorage.ShiftStorage(rm.raffleID).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all shifts: %w", err)
+	}
+
+	return shifts, nil
+}
+
+// prizeDonation is a donation along with the prize it is made to.
+type prizeDonation struct {
+	PrizeID string
+	Donation
+}
+
+// donations returns donations made to the raffle and to all of its prizes.
+func (rm *ReconciliationManager) donations() ([]prizeDonation, error) {
+	raffleDonations, err := rm.raffleStorage.DonationStorage(rm.raffleID).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get raffle donations: %w", err)
+	}
+
+	donations := make([]prizeDonation, 0, len(raffleDonations))
+	for _, d := range raffleDonations {
+		donations = append(donations, prizeDonation{Donation: d})
+	}
+
+	prizeStorage := rm.raffleStorage.PrizeStorage(rm.raffleID)
+
+	prizes, err := prizeStorage.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all prizes: %w", err)
+	}
+
+	for _, prize := range prizes {
+		prizeDonations, err := prizeStorage.DonationStorage(prize.ID).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("get donations of prize %q: %w", prize.ID, err)
+		}
+
+		for _, d := range prizeDonations {
+			donations = append(donations, prizeDonation{PrizeID: prize.ID, Donation: d})
+		}
+	}
+
+	return donations, nil
+}
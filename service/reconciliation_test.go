@@ -0,0 +1,139 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconciliation(t *testing.T) {
+	now := time.Now().UTC()
+	setTimeNowMock(now)
+	setUUIDMock("shift_id")
+
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	raffleDonations := NewMockDonationStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	prizeDonations := NewMockDonationStorage(ctrl)
+	paymentStorage := NewMockPaymentStorage(ctrl)
+	shiftStorage := NewMockShiftStorage(ctrl)
+
+	raffleStorage.EXPECT().DonationStorage("raffle_1").Return(raffleDonations).AnyTimes()
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().PaymentStorage("raffle_1").Return(paymentStorage).AnyTimes()
+	raffleStorage.EXPECT().ShiftStorage("raffle_1").Return(shiftStorage).AnyTimes()
+	prizeStorage.EXPECT().GetAll().Return([]Prize{{ID: "prize_1"}}, nil).AnyTimes()
+	prizeStorage.EXPECT().DonationStorage("prize_1").Return(prizeDonations).AnyTimes()
+
+	hourAgo := now.Add(-time.Hour)
+
+	raffleDonations.EXPECT().GetAll().Return([]Donation{
+		{ID: "d1", Amount: 100, Method: PaymentCash, EnteredBy: "olena", CreatedAt: hourAgo},
+		{ID: "d2", Amount: 50, Method: PaymentCard, EnteredBy: "taras", CreatedAt: now},
+	}, nil).AnyTimes()
+	prizeDonations.EXPECT().GetAll().Return([]Donation{
+		{ID: "d3", Amount: 30, Method: PaymentCash, EnteredBy: "olena", PaymentID: "p1", CreatedAt: now},
+		{ID: "d4", Amount: 20, Method: PaymentCash, EnteredBy: "olena", ShiftID: "old_shift", CreatedAt: hourAgo},
+		{ID: "d5", Amount: 10, CreatedAt: now},
+	}, nil).AnyTimes()
+
+	rm := NewRaffleManager(raffleStorage).ReconciliationService("raffle_1")
+
+	t.Run("report", func(t *testing.T) {
+		report, err := rm.Report(&ReconciliationRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, ReconciliationTotals{
+			Total:    210,
+			Count:    5,
+			ByMethod: map[PaymentMethod]int{PaymentCash: 160, PaymentCard: 50},
+		}, report.Totals)
+
+		assert.Equal(t, []VolunteerTotals{
+			{
+				Totals:   ReconciliationTotals{Total: 10, Count: 1, ByMethod: map[PaymentMethod]int{PaymentCash: 10}},
+				Unlocked: 10,
+			},
+			{
+				VolunteerID: "olena",
+				Totals:      ReconciliationTotals{Total: 150, Count: 3, ByMethod: map[PaymentMethod]int{PaymentCash: 150}},
+				Unlocked:    130,
+			},
+			{
+				VolunteerID: "taras",
+				Totals:      ReconciliationTotals{Total: 50, Count: 1, ByMethod: map[PaymentMethod]int{PaymentCard: 50}},
+				Unlocked:    50,
+			},
+		}, report.ByVolunteer)
+	})
+
+	t.Run("report_time_window", func(t *testing.T) {
+		from, to := hourAgo, now
+
+		report, err := rm.Report(&ReconciliationRequest{From: &from, To: &to})
+		require.NoError(t, err)
+
+		assert.Equal(t, 120, report.Totals.Total)
+		assert.Len(t, report.ByVolunteer, 1)
+	})
+
+	t.Run("report_invalid_time_window", func(t *testing.T) {
+		from, to := now, hourAgo
+
+		_, err := rm.Report(&ReconciliationRequest{From: &from, To: &to})
+		assert.ErrorIs(t, err, ErrInvalidTimeWindow)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("close_shift", func(t *testing.T) {
+		paymentStorage.EXPECT().GetAll().Return([]Payment{
+			{ID: "p1", EnteredBy: "olena"},
+			{ID: "p2", EnteredBy: "olena", ShiftID: "old_shift"},
+			{ID: "p3", EnteredBy: "taras"},
+		}, nil)
+
+		expected := &Shift{
+			ID:          "shift_id",
+			VolunteerID: "olena",
+			Totals:      ReconciliationTotals{Total: 130, Count: 2, ByMethod: map[PaymentMethod]int{PaymentCash: 130}},
+			Donations: []ShiftDonation{
+				{DonationID: "d1", Amount: 100},
+				{PrizeID: "prize_1", DonationID: "d3", Amount: 30},
+			},
+			PaymentIDs: []string{"p1"},
+			ClosedAt:   now,
+		}
+		shiftStorage.EXPECT().Create(expected).Return(nil)
+
+		shift, err := rm.CloseShift(&CloseShiftRequest{VolunteerID: "olena"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, shift)
+	})
+
+	t.Run("close_shift_outdated", func(t *testing.T) {
+		paymentStorage.EXPECT().GetAll().Return(nil, nil)
+		shiftStorage.EXPECT().Create(gomock.Any()).Return(ErrShiftOutdated)
+
+		_, err := rm.CloseShift(&CloseShiftRequest{VolunteerID: "taras"})
+		assert.ErrorIs(t, err, ErrShiftOutdated)
+	})
+
+	t.Run("close_shift_without_volunteer", func(t *testing.T) {
+		_, err := rm.CloseShift(&CloseShiftRequest{})
+		assert.ErrorIs(t, err, ErrMissingVolunteerID)
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("list_shifts", func(t *testing.T) {
+		shiftStorage.EXPECT().GetAll().Return([]Shift{{ID: "shift_id"}}, nil)
+
+		shifts, err := rm.ListShifts()
+		require.NoError(t, err)
+		assert.Equal(t, []Shift{{ID: "shift_id"}}, shifts)
+	})
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// claimExpiredReason is recorded when the winner
+// didn't collect the prize before the claim deadline.
+const claimExpiredReason = "claim deadline passed"
+
+// ScheduledPlay is a result of an automatic play
+// of a prize which draw time has come
+// or which winner didn't claim it in time.
+type ScheduledPlay struct {
+	OrganizerID string           `json:"organizerId"`
+	RaffleID    string           `json:"raffleId"`
+	PrizeID     string           `json:"prizeId"`
+	Winner      *PlayParticipant `json:"winner,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// DuePrize identifies a prize the scheduler has to act on, see Prize.NextDueAt.
+type DuePrize struct {
+	OrganizerID string
+	RaffleID    string
+	PrizeID     string
+}
+
+// PlayDuePrizes plays all not yet played prizes
+// of all organizers which draw time has come,
+// and re-draws prizes with expired claims.
+// It is meant to be triggered periodically by a scheduler.
+// Failure to play a single prize doesn't stop the others,
+// the error is reported in the corresponding result instead.
+func (om *OrganizerManager) PlayDuePrizes() ([]ScheduledPlay, error) {
+	now := timeNow()
+
+	due, err := om.organizerStorage.DuePrizes(now)
+	if err != nil {
+		return nil, fmt.Errorf("get due prizes: %w", err)
+	}
+
+	plays := make([]ScheduledPlay, 0, len(due))
+
+	for _, d := range due {
+		prizePlays := om.raffleManager(d.OrganizerID).prizeManager(d.RaffleID).playDue(d.PrizeID, now)
+
+		for i := range prizePlays {
+			prizePlays[i].OrganizerID = d.OrganizerID
+		}
+
+		plays = append(plays, prizePlays...)
+	}
+
+	return plays, nil
+}
+
+// playDue plays the prize if its draw time has come
+// or forfeits its claims which deadline has passed.
+func (pm *PrizeManager) playDue(prizeID string, now time.Time) []ScheduledPlay {
+	play := ScheduledPlay{
+		RaffleID: pm.raffleID,
+		PrizeID:  prizeID,
+	}
+
+	prize, err := pm.prizeStorage.Get(prizeID)
+	if err != nil {
+		play.Error = fmt.Errorf("get prize: %w", err).Error()
+		return []ScheduledPlay{play}
+	}
+
+	if expired := prize.expiredClaimants(now); len(expired) > 0 {
+		return pm.forfeitExpired(prize, expired)
+	}
+
+	if !prize.IsDue(now) {
+		return nil
+	}
+
+	result, err := pm.Play(prize.ID)
+	if err != nil {
+		play.Error = err.Error()
+	} else {
+		play.Winner = &result.Winners[len(result.Winners)-1]
+	}
+
+	return []ScheduledPlay{play}
+}
+
+// forfeitExpired forfeits claims of the given winners one by one,
+// re-drawing the prize after each of them.
+func (pm *PrizeManager) forfeitExpired(prize *Prize, participantIDs []string) []ScheduledPlay {
+	plays := make([]ScheduledPlay, 0, len(participantIDs))
+
+	for _, id := range participantIDs {
+		play := ScheduledPlay{
+			RaffleID: pm.raffleID,
+			PrizeID:  prize.ID,
+		}
+
+		winnersBefore := len(prize.PlayResult.Winners)
+
+		if err := pm.forfeit(prize, id, claimExpiredReason); err != nil {
+			play.Error = err.Error()
+		} else if len(prize.PlayResult.Winners) > winnersBefore {
+			play.Winner = &prize.PlayResult.Winners[len(prize.PlayResult.Winners)-1]
+		}
+
+		plays = append(plays, play)
+	}
+
+	return plays
+}
+
+// expiredClaimants returns IDs of winners which claims have expired.
+func (p *Prize) expiredClaimants(now time.Time) []string {
+	if p.PlayResult == nil {
+		return nil
+	}
+
+	ids := make([]string, 0)
+	for _, winner := range p.PlayResult.Winners {
+		if winner.Claim.IsExpired(now) {
+			ids = append(ids, winner.Participant.ID)
+		}
+	}
+
+	return ids
+}
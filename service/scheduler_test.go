@@ -0,0 +1,207 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPlayDuePrizes(t *testing.T) {
+	now := time.Now().UTC()
+	setTimeNowMock(now)
+
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	ctrl := gomock.NewController(t)
+
+	organizerStorage := NewMockOrganizerStorage(ctrl)
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	participantStorage := NewMockParticipantStorage(ctrl)
+	donationStorage := NewMockDonationStorage(ctrl)
+	webhookStorage := NewMockWebhookStorage(ctrl)
+
+	organizerStorage.EXPECT().RaffleStorage("org_1").Return(raffleStorage).AnyTimes()
+	organizerStorage.EXPECT().WebhookStorage("org_1").Return(webhookStorage).AnyTimes()
+	organizerStorage.EXPECT().DeliveryStorage("org_1").Return(nil).AnyTimes()
+	webhookStorage.EXPECT().GetAll().Return(nil, nil).AnyTimes()
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(participantStorage).AnyTimes()
+	raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil).AnyTimes()
+
+	om := NewOrganizerManager(organizerStorage)
+
+	t.Run("success", func(t *testing.T) {
+		duePrize := Prize{ID: "due", TicketCost: 10, DrawAt: &past}
+
+		organizerStorage.EXPECT().DuePrizes(now).Return([]DuePrize{dueRef(duePrize.ID)}, nil)
+		prizeStorage.EXPECT().Get(duePrize.ID).Return(&duePrize, nil).Times(2)
+		prizeStorage.EXPECT().DonationStorage(duePrize.ID).Return(donationStorage)
+		participantStorage.EXPECT().GetAll().Return([]Participant{{ID: "p1"}}, nil)
+		donationStorage.EXPECT().GetAll().Return([]Donation{{ID: "d1", ParticipantID: "p1", Amount: 10}}, nil)
+		prizeStorage.EXPECT().Update(gomock.Any()).Return(nil)
+
+		plays, err := om.PlayDuePrizes()
+		require.NoError(t, err)
+		require.Len(t, plays, 1)
+
+		assert.Equal(t, "org_1", plays[0].OrganizerID)
+		assert.Equal(t, "raffle_1", plays[0].RaffleID)
+		assert.Equal(t, "due", plays[0].PrizeID)
+		assert.Empty(t, plays[0].Error)
+		require.NotNil(t, plays[0].Winner)
+		assert.Equal(t, "p1", plays[0].Winner.Participant.ID)
+	})
+
+	t.Run("play_error_is_reported", func(t *testing.T) {
+		duePrize := Prize{ID: "due", TicketCost: 10, DrawAt: &past}
+
+		organizerStorage.EXPECT().DuePrizes(now).Return([]DuePrize{dueRef(duePrize.ID)}, nil)
+		prizeStorage.EXPECT().Get(duePrize.ID).Return(&duePrize, nil).Times(2)
+		participantStorage.EXPECT().GetAll().Return([]Participant{}, nil)
+
+		plays, err := om.PlayDuePrizes()
+		require.NoError(t, err)
+		require.Len(t, plays, 1)
+		assert.Equal(t, "due", plays[0].PrizeID)
+		assert.Nil(t, plays[0].Winner)
+		assert.Contains(t, plays[0].Error, ErrNoParticipants.Error())
+	})
+
+	t.Run("expired_claim_is_forfeited", func(t *testing.T) {
+		prize := Prize{
+			ID:         "expired",
+			TicketCost: 10,
+			DrawAt:     &past,
+			PlayResult: &PrizePlayResult{
+				Winners: []PlayParticipant{
+					{Participant: Participant{ID: "p1"}, Claim: &WinnerClaim{Status: ClaimPending, Deadline: &past}},
+				},
+				PlayParticipants: []PlayParticipant{
+					{Participant: Participant{ID: "p2"}, TotalTicketsNumber: 1},
+				},
+			},
+		}
+
+		organizerStorage.EXPECT().DuePrizes(now).Return([]DuePrize{dueRef(prize.ID)}, nil)
+		prizeStorage.EXPECT().Get(prize.ID).Return(&prize, nil)
+		prizeStorage.EXPECT().Update(gomock.Any()).Return(nil).Times(2)
+
+		plays, err := om.PlayDuePrizes()
+		require.NoError(t, err)
+		require.Len(t, plays, 1)
+		assert.Equal(t, "expired", plays[0].PrizeID)
+		require.NotNil(t, plays[0].Winner)
+		assert.Equal(t, "p2", plays[0].Winner.Participant.ID)
+	})
+
+	t.Run("failed_redraw_is_recorded", func(t *testing.T) {
+		prize := Prize{
+			ID:             "expired",
+			TicketCost:     10,
+			ExcludeWinners: true,
+			PlayResult: &PrizePlayResult{
+				Winners: []PlayParticipant{
+					{Participant: Participant{ID: "p1"}, Claim: &WinnerClaim{Status: ClaimPending, Deadline: &past}},
+				},
+				PlayParticipants: []PlayParticipant{
+					{Participant: Participant{ID: "p2"}, TotalTicketsNumber: 1},
+				},
+			},
+		}
+
+		organizerStorage.EXPECT().DuePrizes(now).Return([]DuePrize{dueRef(prize.ID)}, nil)
+		prizeStorage.EXPECT().Get(prize.ID).Return(&prize, nil)
+		prizeStorage.EXPECT().GetAll().Return(nil, assert.AnError)
+		prizeStorage.EXPECT().Update(gomock.Any()).Return(nil).Times(2)
+
+		plays, err := om.PlayDuePrizes()
+		require.NoError(t, err)
+		require.Len(t, plays, 1)
+		assert.Contains(t, plays[0].Error, ErrRedrawFailed.Error())
+		assert.Nil(t, plays[0].Winner)
+		assert.Empty(t, prize.expiredClaimants(now), "the claim is not forfeited again")
+	})
+
+	t.Run("not_due_yet", func(t *testing.T) {
+		prizes := []Prize{
+			{ID: "not_yet", TicketCost: 10, DrawAt: &future},
+			{ID: "played", TicketCost: 10, DrawAt: &past, PlayResult: dummyPlayResult()},
+		}
+
+		organizerStorage.EXPECT().DuePrizes(now).Return([]DuePrize{dueRef(prizes[0].ID), dueRef(prizes[1].ID)}, nil)
+		prizeStorage.EXPECT().Get(prizes[0].ID).Return(&prizes[0], nil)
+		prizeStorage.EXPECT().Get(prizes[1].ID).Return(&prizes[1], nil)
+
+		plays, err := om.PlayDuePrizes()
+		require.NoError(t, err)
+		require.Empty(t, plays)
+	})
+
+	t.Run("get_error_doesnt_stop_others", func(t *testing.T) {
+		duePrize := Prize{ID: "due", TicketCost: 10, DrawAt: &past}
+
+		organizerStorage.EXPECT().DuePrizes(now).Return([]DuePrize{dueRef("deleted"), dueRef(duePrize.ID)}, nil)
+		prizeStorage.EXPECT().Get("deleted").Return(nil, ErrNotFound)
+		prizeStorage.EXPECT().Get(duePrize.ID).Return(&duePrize, nil).Times(2)
+		participantStorage.EXPECT().GetAll().Return([]Participant{}, nil)
+
+		plays, err := om.PlayDuePrizes()
+		require.NoError(t, err)
+		require.Len(t, plays, 2)
+		assert.Equal(t, "deleted", plays[0].PrizeID)
+		assert.Contains(t, plays[0].Error, ErrNotFound.Error())
+		assert.Equal(t, "due", plays[1].PrizeID)
+		assert.Contains(t, plays[1].Error, ErrNoParticipants.Error())
+	})
+
+	t.Run("due_prizes_error", func(t *testing.T) {
+		organizerStorage.EXPECT().DuePrizes(now).Return(nil, assert.AnError)
+
+		plays, err := om.PlayDuePrizes()
+		require.ErrorIs(t, err, assert.AnError)
+		require.Nil(t, plays)
+	})
+}
+
+func dueRef(prizeID string) DuePrize {
+	return DuePrize{OrganizerID: "org_1", RaffleID: "raffle_1", PrizeID: prizeID}
+}
+
+func TestPrizeNextDueAt(t *testing.T) {
+	now := time.Now().UTC()
+	later := now.Add(time.Hour)
+
+	claim := func(status ClaimStatus, deadline *time.Time) PlayParticipant {
+		return PlayParticipant{Claim: &WinnerClaim{Status: status, Deadline: deadline}}
+	}
+
+	testCases := []struct {
+		name     string
+		prize    Prize
+		expected *time.Time
+	}{
+		{"unscheduled", Prize{}, nil},
+		{"scheduled", Prize{DrawAt: &now}, &now},
+		{"played_without_claims", Prize{DrawAt: &now, PlayResult: dummyPlayResult()}, nil},
+		{
+			"earliest_pending_claim",
+			Prize{DrawAt: &now, PlayResult: &PrizePlayResult{Winners: []PlayParticipant{
+				claim(ClaimPending, &later),
+				claim(ClaimClaimed, &now),
+				claim(ClaimPending, &now),
+			}}},
+			&now,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.prize.NextDueAt())
+		})
+	}
+}
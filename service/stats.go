@@ -0,0 +1,275 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StatsHourLayout formats hours donations are bucketed by.
+const StatsHourLayout = "2006-01-02T15"
+
+// topDonorsLimit is the number of donors shown in the raffle stats.
+const topDonorsLimit = 10
+
+// RaffleCounters are sums of donations of a raffle maintained by the storage
+// along with every donation change, so stats don't require loading donations.
+type RaffleCounters struct {
+	Raised    int
+	Donations int
+	// Donors are sums donated by each participant.
+	Donors map[string]int
+	// Hourly are sums donated within each hour formatted with StatsHourLayout in UTC.
+	Hourly map[string]int
+	// Raffle counts donations made to the whole raffle.
+	Raffle DonationCounters
+	Prizes map[string]DonationCounters
+}
+
+// DonationCounters are sums of donations made to a prize or to the whole raffle.
+type DonationCounters struct {
+	Raised    int
+	Donations int
+	// Participants are sums donated by each participant.
+	Participants map[string]int
+}
+
+// AddDonation adds the donation to the counters.
+// PrizeID is empty for donations made to the whole raffle.
+func (c *RaffleCounters) AddDonation(prizeID string, d *Donation) {
+	c.add(prizeID, d, 1)
+}
+
+// RemoveDonation subtracts the donation from the counters.
+func (c *RaffleCounters) RemoveDonation(prizeID string, d *Donation) {
+	c.add(prizeID, d, -1)
+}
+
+func (c *RaffleCounters) add(prizeID string, d *Donation, sign int) {
+	amount := sign * d.Amount
+
+	c.Raised += amount
+	c.Donations += sign
+	c.Donors = addToMap(c.Donors, d.ParticipantID, amount)
+	c.Hourly = addToMap(c.Hourly, d.CreatedAt.UTC().Format(StatsHourLayout), amount)
+
+	if prizeID == "" {
+		c.Raffle.add(d.ParticipantID, amount, sign)
+		return
+	}
+
+	if c.Prizes == nil {
+		c.Prizes = make(map[string]DonationCounters)
+	}
+
+	prize := c.Prizes[prizeID]
+	prize.add(d.ParticipantID, amount, sign)
+	c.Prizes[prizeID] = prize
+}
+
+func (c *DonationCounters) add(participantID string, amount, count int) {
+	c.Raised += amount
+	c.Donations += count
+	c.Participants = addToMap(c.Participants, participantID, amount)
+}
+
+func addToMap(m map[string]int, key string, value int) map[string]int {
+	if m == nil {
+		m = make(map[string]int)
+	}
+
+	m[key] += value
+
+	return m
+}
+
+// RaffleStats is an aggregate view of donations of a raffle.
+type RaffleStats struct {
+	TotalRaised     int           `json:"totalRaised"`
+	DonationCount   int           `json:"donationCount"`
+	AverageDonation float64       `json:"averageDonation"`
+	DonorCount      int           `json:"donorCount"`
+	RaffleDonations PrizeStats    `json:"raffleDonations"`
+	Prizes          []PrizeStats  `json:"prizes"`
+	TopDonors       []DonorStats  `json:"topDonors"`
+	Hourly          []HourlyStats `json:"hourly"`
+}
+
+// PrizeStats are sums of donations made to a prize.
+// Tickets don't include remainders carried over from other prizes.
+type PrizeStats struct {
+	PrizeID          string `json:"prizeId,omitempty"`
+	PrizeName        string `json:"prizeName,omitempty"`
+	Raised           int    `json:"raised"`
+	DonationCount    int    `json:"donationCount"`
+	ParticipantCount int    `json:"participantCount"`
+	Tickets          int    `json:"tickets"`
+}
+
+// DonorStats is a sum donated by a participant.
+type DonorStats struct {
+	ParticipantID string `json:"participantId"`
+	Name          string `json:"name"`
+	Amount        int    `json:"amount"`
+}
+
+// HourlyStats is a sum donated within an hour.
+type HourlyStats struct {
+	Hour   time.Time `json:"hour"`
+	Amount int       `json:"amount"`
+}
+
+// StatsStorage is a storage for raffle counters.
+//
+//go:generate mockgen -destination=mock_stats_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service StatsStorage
+type StatsStorage interface {
+	// Get returns the counters, empty ones if there are no donations yet.
+	Get() (*RaffleCounters, error)
+	// Replace replaces the counters along with running totals of the prizes.
+	Replace(*RaffleCounters) error
+}
+
+// Stats returns an aggregate view of donations of the raffle.
+// It reads maintained counters and prizes rather than all donations.
+func (rm *RaffleManager) Stats(id string) (*RaffleStats, error) {
+	if _, err := rm.Get(id); err != nil {
+		return nil, fmt.Errorf("get raffle: %w", err)
+	}
+
+	counters, err := rm.raffleStorage.StatsStorage(id).Get()
+	if err != nil {
+		return nil, fmt.Errorf("get counters: %w", err)
+	}
+
+	prizes, err := rm.raffleStorage.PrizeStorage(id).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all prizes: %w", err)
+	}
+
+	stats := &RaffleStats{
+		TotalRaised:     counters.Raised,
+		DonationCount:   counters.Donations,
+		RaffleDonations: toPrizeStats(counters.Raffle),
+		Prizes:          make([]PrizeStats, 0, len(prizes)),
+		Hourly:          hourlyStats(counters.Hourly),
+	}
+
+	if counters.Donations > 0 {
+		stats.AverageDonation = float64(counters.Raised) / float64(counters.Donations)
+	}
+
+	for _, prize := range prizes {
+		prizeCounters := counters.Prizes[prize.ID]
+
+		prizeStats := toPrizeStats(prizeCounters)
+		prizeStats.PrizeID = prize.ID
+		prizeStats.PrizeName = prize.Name
+		prizeStats.Tickets = countTickets(&prize, prizeCounters, counters.Raffle)
+
+		stats.Prizes = append(stats.Prizes, prizeStats)
+	}
+
+	donors := make([]DonorStats, 0, len(counters.Donors))
+	for participantID, amount := range counters.Donors {
+		if amount > 0 {
+			donors = append(donors, DonorStats{ParticipantID: participantID, Amount: amount})
+		}
+	}
+
+	sort.Slice(donors, func(i, j int) bool {
+		if donors[i].Amount != donors[j].Amount {
+			return donors[i].Amount > donors[j].Amount
+		}
+
+		return donors[i].ParticipantID < donors[j].ParticipantID
+	})
+
+	stats.DonorCount = len(donors)
+
+	if len(donors) > topDonorsLimit {
+		donors = donors[:topDonorsLimit]
+	}
+
+	participantStorage := rm.raffleStorage.ParticipantStorage(id)
+	for i := range donors {
+		participant, err := participantStorage.Get(donors[i].ParticipantID)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("get donor %q: %w", donors[i].ParticipantID, err)
+		}
+
+		donors[i].Name = participant.Name
+	}
+
+	stats.TopDonors = donors
+
+	return stats, nil
+}
+
+func toPrizeStats(c DonationCounters) PrizeStats {
+	totals := c.PrizeTotals()
+
+	return PrizeStats{
+		Raised:           totals.TotalDonated,
+		DonationCount:    totals.DonationCount,
+		ParticipantCount: totals.ParticipantCount,
+	}
+}
+
+// countTickets counts tickets of all participants for the prize,
+// including ones granted by donations to the whole raffle if the prize accepts them.
+func countTickets(prize *Prize, prizeCounters, raffleCounters DonationCounters) int {
+	if prize.TicketCost <= 0 {
+		return 0
+	}
+
+	amounts := make(map[string]int, len(prizeCounters.Participants))
+	for participantID, amount := range prizeCounters.Participants {
+		amounts[participantID] += amount
+	}
+
+	if prize.UseRaffleTickets {
+		for participantID, amount := range raffleCounters.Participants {
+			amounts[participantID] += amount
+		}
+	}
+
+	total := 0
+	for _, amount := range amounts {
+		tickets := amount / prize.TicketCost
+		if prize.MaxTickets > 0 && tickets > prize.MaxTickets {
+			tickets = prize.MaxTickets
+		}
+
+		total += tickets
+	}
+
+	return total
+}
+
+func hourlyStats(hourly map[string]int) []HourlyStats {
+	stats := make([]HourlyStats, 0, len(hourly))
+
+	for hour, amount := range hourly {
+		if amount == 0 {
+			continue
+		}
+
+		t, err := time.Parse(StatsHourLayout, hour)
+		if err != nil {
+			continue
+		}
+
+		stats = append(stats, HourlyStats{Hour: t, Amount: amount})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Hour.Before(stats[j].Hour)
+	})
+
+	return stats
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRaffleCounters(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	var c RaffleCounters
+	c.AddDonation("prize_1", &Donation{ParticipantID: "olena", Amount: 100, CreatedAt: at})
+	c.AddDonation("", &Donation{ParticipantID: "taras", Amount: 50, CreatedAt: at.Add(time.Hour)})
+	c.RemoveDonation("prize_1", &Donation{ParticipantID: "olena", Amount: 100, CreatedAt: at})
+	c.AddDonation("prize_1", &Donation{ParticipantID: "taras", Amount: 30, CreatedAt: at})
+
+	assert.Equal(t, RaffleCounters{
+		Raised:    80,
+		Donations: 2,
+		Donors:    map[string]int{"olena": 0, "taras": 80},
+		Hourly:    map[string]int{"2024-05-01T09": 30, "2024-05-01T10": 50},
+		Raffle:    DonationCounters{Raised: 50, Donations: 1, Participants: map[string]int{"taras": 50}},
+		Prizes: map[string]DonationCounters{
+			"prize_1": {Raised: 30, Donations: 1, Participants: map[string]int{"olena": 0, "taras": 30}},
+		},
+	}, c)
+}
+
+func TestRaffleStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	statsStorage := NewMockStatsStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	participantStorage := NewMockParticipantStorage(ctrl)
+
+	raffleStorage.EXPECT().StatsStorage("raffle_1").Return(statsStorage).AnyTimes()
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(participantStorage).AnyTimes()
+
+	rm := NewRaffleManager(raffleStorage)
+
+	t.Run("success", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		statsStorage.EXPECT().Get().Return(&RaffleCounters{
+			Raised:    400,
+			Donations: 5,
+			Donors:    map[string]int{"olena": 250, "taras": 150, "gone": 0},
+			Hourly:    map[string]int{"2024-05-01T10": 150, "2024-05-01T09": 250, "2024-05-01T11": 0},
+			Raffle:    DonationCounters{Raised: 100, Donations: 1, Participants: map[string]int{"olena": 100}},
+			Prizes: map[string]DonationCounters{
+				"prize_1": {Raised: 300, Donations: 4, Participants: map[string]int{"olena": 150, "taras": 150}},
+			},
+		}, nil)
+		prizeStorage.EXPECT().GetAll().Return([]Prize{
+			{ID: "prize_1", Name: "Bike", TicketCost: 100, UseRaffleTickets: true},
+			{ID: "prize_2", Name: "Book", TicketCost: 10},
+		}, nil)
+		participantStorage.EXPECT().Get("olena").Return(&Participant{ID: "olena", Name: "Olena"}, nil)
+		participantStorage.EXPECT().Get("taras").Return(nil, ErrNotFound)
+
+		stats, err := rm.Stats("raffle_1")
+		require.NoError(t, err)
+
+		assert.Equal(t, &RaffleStats{
+			TotalRaised:     400,
+			DonationCount:   5,
+			AverageDonation: 80,
+			DonorCount:      2,
+			RaffleDonations: PrizeStats{Raised: 100, DonationCount: 1, ParticipantCount: 1},
+			Prizes: []PrizeStats{
+				{PrizeID: "prize_1", PrizeName: "Bike", Raised: 300, DonationCount: 4, ParticipantCount: 2, Tickets: 3},
+				{PrizeID: "prize_2", PrizeName: "Book"},
+			},
+			TopDonors: []DonorStats{
+				{ParticipantID: "olena", Name: "Olena", Amount: 250},
+				{ParticipantID: "taras", Amount: 150},
+			},
+			Hourly: []HourlyStats{
+				{Hour: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), Amount: 250},
+				{Hour: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Amount: 150},
+			},
+		}, stats)
+	})
+
+	t.Run("no_donations", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		statsStorage.EXPECT().Get().Return(&RaffleCounters{}, nil)
+		prizeStorage.EXPECT().GetAll().Return(nil, nil)
+
+		stats, err := rm.Stats("raffle_1")
+		require.NoError(t, err)
+		assert.Zero(t, stats.AverageDonation)
+		assert.Empty(t, stats.TopDonors)
+	})
+
+	t.Run("raffle_not_found", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(nil, ErrNotFound)
+
+		_, err := rm.Stats("raffle_1")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
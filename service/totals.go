@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// PrizeTotals are running totals of donations made to a prize.
+type PrizeTotals struct {
+	TotalDonated     int `json:"totalDonated"`
+	DonationCount    int `json:"donationCount"`
+	ParticipantCount int `json:"participantCount"`
+}
+
+// Totals returns the running totals of the prize.
+func (p *Prize) Totals() PrizeTotals {
+	return PrizeTotals{
+		TotalDonated:     p.TotalDonated,
+		DonationCount:    p.DonationCount,
+		ParticipantCount: p.ParticipantCount,
+	}
+}
+
+// PrizeTotals returns the running totals of the prize the counters belong to.
+func (c DonationCounters) PrizeTotals() PrizeTotals {
+	totals := PrizeTotals{
+		TotalDonated:  c.Raised,
+		DonationCount: c.Donations,
+	}
+
+	for _, amount := range c.Participants {
+		if amount > 0 {
+			totals.ParticipantCount++
+		}
+	}
+
+	return totals
+}
+
+// RepairTotalsRequest is a request for recomputing running totals of a raffle.
+type RepairTotalsRequest struct {
+	// DryRun only reports the drift without repairing it.
+	DryRun bool `json:"dryRun"`
+}
+
+// RepairTotalsResult reports the drift of running totals from donations.
+type RepairTotalsResult struct {
+	Prizes          []PrizeTotalsDrift `json:"prizes"`
+	CountersDrifted bool               `json:"countersDrifted"`
+	Repaired        bool               `json:"repaired"`
+}
+
+// PrizeTotalsDrift is a difference between stored
+// and actual running totals of a prize.
+type PrizeTotalsDrift struct {
+	PrizeID   string      `json:"prizeId"`
+	PrizeName string      `json:"prizeName"`
+	Stored    PrizeTotals `json:"stored"`
+	Actual    PrizeTotals `json:"actual"`
+}
+
+// RepairTotals recomputes running totals of the prizes and the raffle counters
+// from donations and replaces the stored ones if they have drifted.
+// Donations made while repairing may be missed, so it's better run
+// when donations aren't being entered.
+func (rm *RaffleManager) RepairTotals(id string, r *RepairTotalsRequest) (*RepairTotalsResult, error) {
+	if _, err := rm.Get(id); err != nil {
+		return nil, fmt.Errorf("get raffle: %w", err)
+	}
+
+	reconciliation := NewReconciliationManager(id, rm.raffleStorage)
+
+	donations, err := reconciliation.donations()
+	if err != nil {
+		return nil, err
+	}
+
+	var actual RaffleCounters
+	for i := range donations {
+		actual.AddDonation(donations[i].PrizeID, &donations[i].Donation)
+	}
+
+	prizes, err := rm.raffleStorage.PrizeStorage(id).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all prizes: %w", err)
+	}
+
+	statsStorage := rm.raffleStorage.StatsStorage(id)
+
+	stored, err := statsStorage.Get()
+	if err != nil {
+		return nil, fmt.Errorf("get counters: %w", err)
+	}
+
+	result := &RepairTotalsResult{
+		Prizes:          []PrizeTotalsDrift{},
+		CountersDrifted: !reflect.DeepEqual(stored.withoutZeros(), actual.withoutZeros()),
+	}
+
+	for i := range prizes {
+		prizeTotals := actual.Prizes[prizes[i].ID].PrizeTotals()
+		if prizeTotals == prizes[i].Totals() {
+			continue
+		}
+
+		result.Prizes = append(result.Prizes, PrizeTotalsDrift{
+			PrizeID:   prizes[i].ID,
+			PrizeName: prizes[i].Name,
+			Stored:    prizes[i].Totals(),
+			Actual:    prizeTotals,
+		})
+	}
+
+	if r.DryRun || (len(result.Prizes) == 0 && !result.CountersDrifted) {
+		return result, nil
+	}
+
+	if err := statsStorage.Replace(&actual); err != nil {
+		return nil, fmt.Errorf("replace counters: %w", err)
+	}
+
+	result.Repaired = true
+
+	return result, nil
+}
+
+// withoutZeros returns a copy of the counters without zero sums,
+// which are left behind by removed donations.
+func (c *RaffleCounters) withoutZeros() RaffleCounters {
+	normalized := RaffleCounters{
+		Raised:    c.Raised,
+		Donations: c.Donations,
+		Donors:    nonZero(c.Donors),
+		Hourly:    nonZero(c.Hourly),
+		Raffle:    c.Raffle.withoutZeros(),
+	}
+
+	for prizeID, counters := range c.Prizes {
+		counters = counters.withoutZeros()
+		if counters.Raised == 0 && counters.Donations == 0 && counters.Participants == nil {
+			continue
+		}
+
+		if normalized.Prizes == nil {
+			normalized.Prizes = make(map[string]DonationCounters)
+		}
+
+		normalized.Prizes[prizeID] = counters
+	}
+
+	return normalized
+}
+
+func (c DonationCounters) withoutZeros() DonationCounters {
+	c.Participants = nonZero(c.Participants)
+	return c
+}
+
+func nonZero(m map[string]int) map[string]int {
+	var result map[string]int
+
+	for key, value := range m {
+		if value == 0 {
+			continue
+		}
+
+		if result == nil {
+			result = make(map[string]int)
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
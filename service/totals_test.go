@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRepairTotals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	statsStorage := NewMockStatsStorage(ctrl)
+	prizeStorage := NewMockPrizeStorage(ctrl)
+	raffleDonations := NewMockDonationStorage(ctrl)
+	prizeDonations := NewMockDonationStorage(ctrl)
+
+	raffleStorage.EXPECT().StatsStorage("raffle_1").Return(statsStorage).AnyTimes()
+	raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+	raffleStorage.EXPECT().DonationStorage("raffle_1").Return(raffleDonations).AnyTimes()
+	prizeStorage.EXPECT().DonationStorage("prize_1").Return(prizeDonations).AnyTimes()
+
+	rm := NewRaffleManager(raffleStorage)
+
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	var actual RaffleCounters
+	actual.AddDonation("", &Donation{ID: "don_1", ParticipantID: "olena", Amount: 50, CreatedAt: at})
+	actual.AddDonation("prize_1", &Donation{ID: "don_2", ParticipantID: "olena", Amount: 100, CreatedAt: at})
+	actual.AddDonation("prize_1", &Donation{ID: "don_3", ParticipantID: "taras", Amount: 30, CreatedAt: at})
+
+	expectDonations := func(prizes []Prize) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		raffleDonations.EXPECT().GetAll().Return([]Donation{
+			{ID: "don_1", ParticipantID: "olena", Amount: 50, CreatedAt: at},
+		}, nil)
+		prizeStorage.EXPECT().GetAll().Return(prizes, nil).Times(2)
+		prizeDonations.EXPECT().GetAll().Return([]Donation{
+			{ID: "don_2", ParticipantID: "olena", Amount: 100, CreatedAt: at},
+			{ID: "don_3", ParticipantID: "taras", Amount: 30, CreatedAt: at},
+		}, nil)
+	}
+
+	drifted := []Prize{{ID: "prize_1", Name: "Bike", TotalDonated: 100, DonationCount: 1, ParticipantCount: 1}}
+
+	t.Run("repair", func(t *testing.T) {
+		expectDonations(drifted)
+		statsStorage.EXPECT().Get().Return(&RaffleCounters{Raised: 150, Donations: 2}, nil)
+		statsStorage.EXPECT().Replace(&actual).Return(nil)
+
+		result, err := rm.RepairTotals("raffle_1", &RepairTotalsRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, &RepairTotalsResult{
+			Prizes: []PrizeTotalsDrift{{
+				PrizeID:   "prize_1",
+				PrizeName: "Bike",
+				Stored:    PrizeTotals{TotalDonated: 100, DonationCount: 1, ParticipantCount: 1},
+				Actual:    PrizeTotals{TotalDonated: 130, DonationCount: 2, ParticipantCount: 2},
+			}},
+			CountersDrifted: true,
+			Repaired:        true,
+		}, result)
+	})
+
+	t.Run("dry_run", func(t *testing.T) {
+		expectDonations(drifted)
+		statsStorage.EXPECT().Get().Return(&actual, nil)
+
+		result, err := rm.RepairTotals("raffle_1", &RepairTotalsRequest{DryRun: true})
+		require.NoError(t, err)
+		assert.Len(t, result.Prizes, 1)
+		assert.False(t, result.CountersDrifted)
+		assert.False(t, result.Repaired)
+	})
+
+	t.Run("no_drift", func(t *testing.T) {
+		stored := actual
+		stored.Donors = map[string]int{"olena": 150, "taras": 30, "gone": 0}
+
+		expectDonations([]Prize{{ID: "prize_1", Name: "Bike", TotalDonated: 130, DonationCount: 2, ParticipantCount: 2}})
+		statsStorage.EXPECT().Get().Return(&stored, nil)
+
+		result, err := rm.RepairTotals("raffle_1", &RepairTotalsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, &RepairTotalsResult{Prizes: []PrizeTotalsDrift{}}, result)
+	})
+
+	t.Run("raffle_not_found", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(nil, ErrNotFound)
+
+		_, err := rm.RepairTotals("raffle_1", &RepairTotalsRequest{})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -2,43 +2,380 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
 
-	"github.com/go-playground/validator"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/uk"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	"github.com/nyaruka/phonenumbers"
+	"golang.org/x/exp/slices"
 )
 
 var (
 	ErrParticipantPhoneOnlyDigits = errors.New("phone should contain only digits")
 	ErrNameTooShort               = errors.New("name is too short")
 	ErrInvalidRequest             = errors.New("invalid request")
+	ErrInvalidValidationConfig    = errors.New("invalid validation config")
 )
 
-// Acceptable characters are the English alphabet, numbers,
-// symbols from the symbols variable, the Ukrainian alphabet
-func charsValidation(fl validator.FieldLevel) bool {
-	value := fl.Field().String()
+const (
+	// LocaleEnglish is the locale of English validation messages.
+	LocaleEnglish = "en"
+	// LocaleUkrainian is the locale of Ukrainian validation messages.
+	LocaleUkrainian = "uk"
+)
 
-	regex := regexp.MustCompile(`^[a-zA-Z0-9 !@#$%^&*()_{}\[\]:;<>,.?~абвгґдеєжзиіїйклмнопрстуфхцчшщьюяАБВГҐДЕЄЖЗИІЇЙКЛМНОПРСТУФХЦЧШЩЬЮЯ]*$`)
-	return regex.MatchString(value)
+// ValidationConfig defines validation rules configurable per deployment.
+type ValidationConfig struct {
+	// DefaultRegion is the ISO 3166-1 region code used for phones
+	// given without the international prefix, e.g. "UA".
+	DefaultRegion string
+	// AllowedRegions are the regions of accepted phones.
+	// Phones of any region are accepted if it's empty.
+	AllowedRegions []string
+	// Chars is the policy for names and notes.
+	Chars CharsPolicy
+	// Locale is the locale of validation messages.
+	Locale string
 }
 
-func phoneValidation(fl validator.FieldLevel) bool {
-	value := fl.Field().String()
+// CharsPolicy defines characters acceptable in names and notes.
+// Digits are always acceptable.
+type CharsPolicy struct {
+	// Scripts are names of Unicode scripts, e.g. "Latin" or "Cyrillic",
+	// all letters of which are acceptable, including the diacritics.
+	Scripts []string
+	// Letters are acceptable letters in addition to the scripts.
+	Letters string
+	// Symbols are acceptable non-letter characters.
+	Symbols string
+}
 
-	regex := regexp.MustCompile(`^\+380\d{9,10}$`)
-	return regex.MatchString(value)
+// DefaultValidationConfig accepts Ukrainian phones,
+// English and Ukrainian letters and English messages.
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{
+		DefaultRegion:  "UA",
+		AllowedRegions: []string{"UA"},
+		Chars: CharsPolicy{
+			Letters: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+				"абвгґдеєжзиіїйклмнопрстуфхцчшщьюяАБВГҐДЕЄЖЗИІЇЙКЛМНОПРСТУФХЦЧШЩЬЮЯ",
+			Symbols: " !@#$%^&*()_{}[]:;<>,.?~",
+		},
+		Locale: LocaleEnglish,
+	}
 }
 
-func defaultValidator() *validator.Validate {
-	validate := validator.New()
+// validation is the shared validator,
+// it's replaced as a whole when the config changes.
+var validation atomic.Pointer[validatorSet]
 
-	if err := validate.RegisterValidation("charsValidation", charsValidation); err != nil {
+func init() {
+	v, err := newValidatorSet(DefaultValidationConfig())
+	if err != nil {
 		panic(err)
 	}
 
-	if err := validate.RegisterValidation("phoneValidation", phoneValidation); err != nil {
-		panic(err)
+	validation.Store(v)
+}
+
+// SetValidationConfig replaces the validation rules.
+// It is meant to be called once on startup,
+// but it's safe to call it concurrently with validations.
+func SetValidationConfig(c ValidationConfig) error {
+	if err := c.Validate(); err != nil {
+		return errors.Join(err, ErrInvalidValidationConfig)
+	}
+
+	c.DefaultRegion = strings.ToUpper(c.DefaultRegion)
+	regions := make([]string, 0, len(c.AllowedRegions))
+	for _, region := range c.AllowedRegions {
+		regions = append(regions, strings.ToUpper(region))
+	}
+	c.AllowedRegions = regions
+
+	v, err := newValidatorSet(c)
+	if err != nil {
+		return err
+	}
+
+	validation.Store(v)
+
+	return nil
+}
+
+func (c *ValidationConfig) Validate() error {
+	supported := phonenumbers.GetSupportedRegions()
+
+	if !supported[strings.ToUpper(c.DefaultRegion)] {
+		return fmt.Errorf("unknown default region %q", c.DefaultRegion)
+	}
+
+	for _, region := range c.AllowedRegions {
+		if !supported[strings.ToUpper(region)] {
+			return fmt.Errorf("unknown allowed region %q", region)
+		}
+	}
+
+	for _, script := range c.Chars.Scripts {
+		if _, ok := unicode.Scripts[script]; !ok {
+			return fmt.Errorf("unknown script %q", script)
+		}
+	}
+
+	if c.Locale != LocaleEnglish && c.Locale != LocaleUkrainian {
+		return fmt.Errorf("unsupported locale %q", c.Locale)
+	}
+
+	return nil
+}
+
+// pattern returns the regular expression matching strings
+// made of the acceptable characters only.
+func (p *CharsPolicy) pattern() string {
+	var class strings.Builder
+	class.WriteString("0-9")
+
+	for _, r := range p.Letters + p.Symbols {
+		if r < unicode.MaxASCII && (unicode.IsPunct(r) || unicode.IsSymbol(r)) {
+			class.WriteRune('\\')
+		}
+		class.WriteRune(r)
+	}
+
+	for _, script := range p.Scripts {
+		class.WriteString(`\p{` + script + `}`)
+	}
+
+	// Combining diacritics belong to the Inherited script
+	// and are acceptable along with letters of any script.
+	if len(p.Scripts) > 0 {
+		class.WriteString(`\p{Inherited}`)
+	}
+
+	return `^[` + class.String() + `]*$`
+}
+
+// ValidationError is a failed validation of a request
+// with messages in the configured locale.
+type ValidationError struct {
+	Messages []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Messages, "; ")
+}
+
+// validatorSet is a validator with translations
+// and patterns prepared for the config.
+// It is safe for concurrent use.
+type validatorSet struct {
+	config   ValidationConfig
+	validate *validator.Validate
+	trans    ut.Translator
+	chars    *regexp.Regexp
+}
+
+func newValidatorSet(config ValidationConfig) (*validatorSet, error) {
+	chars, err := regexp.Compile(config.Chars.pattern())
+	if err != nil {
+		return nil, fmt.Errorf("compile chars pattern: %w", err)
+	}
+
+	v := &validatorSet{
+		config:   config,
+		validate: validator.New(),
+		chars:    chars,
 	}
 
-	return validate
+	// Messages refer to fields by their JSON names.
+	v.validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+
+		return name
+	})
+
+	if err := v.validate.RegisterValidation("charsValidation", v.charsValidation); err != nil {
+		return nil, fmt.Errorf("register chars validation: %w", err)
+	}
+
+	if err := v.validate.RegisterValidation("phoneValidation", v.phoneValidation); err != nil {
+		return nil, fmt.Errorf("register phone validation: %w", err)
+	}
+
+	if err := v.validate.RegisterValidation("messageTemplate", messageTemplateValidation); err != nil {
+		return nil, fmt.Errorf("register message template validation: %w", err)
+	}
+
+	v.trans, err = registerTranslations(v.validate, config.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// charsValidation accepts characters allowed by the configured policy.
+func (v *validatorSet) charsValidation(fl validator.FieldLevel) bool {
+	return v.chars.MatchString(fl.Field().String())
+}
+
+// phoneValidation accepts valid phones of the allowed regions.
+func (v *validatorSet) phoneValidation(fl validator.FieldLevel) bool {
+	_, err := v.parsePhone(fl.Field().String())
+	return err == nil
+}
+
+// messageTemplateValidation accepts winner message templates
+// which render with WinnerMessageData.
+func messageTemplateValidation(fl validator.FieldLevel) bool {
+	_, err := renderWinnerMessage(fl.Field().String(), WinnerMessageData{})
+	return err == nil
+}
+
+// ParsePhone parses the phone in the international or
+// the default region format and returns it in E.164.
+func ParsePhone(phone string) (string, error) {
+	return validation.Load().parsePhone(phone)
+}
+
+func (v *validatorSet) parsePhone(phone string) (string, error) {
+	number, err := phonenumbers.Parse(phone, v.config.DefaultRegion)
+	if err != nil {
+		return "", fmt.Errorf("parse phone: %w", err)
+	}
+
+	if !phonenumbers.IsValidNumber(number) {
+		return "", fmt.Errorf("invalid phone %q", phone)
+	}
+
+	region := phonenumbers.GetRegionCodeForNumber(number)
+	if len(v.config.AllowedRegions) > 0 && !slices.Contains(v.config.AllowedRegions, region) {
+		return "", fmt.Errorf("phone region %q is not allowed", region)
+	}
+
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
+
+// defaultRegion is the region of phones given without the international prefix.
+func defaultRegion() string {
+	return validation.Load().config.DefaultRegion
+}
+
+// validateStruct validates the struct and translates the failures.
+func validateStruct(s any) error {
+	v := validation.Load()
+
+	err := v.validate.Struct(s)
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		messages = append(messages, fe.Translate(v.trans))
+	}
+
+	return &ValidationError{Messages: messages}
+}
+
+func registerTranslations(validate *validator.Validate, locale string) (ut.Translator, error) {
+	uni := ut.New(en.New(), en.New(), uk.New())
+
+	trans, _ := uni.GetTranslator(locale)
+
+	messages := englishMessages
+	if locale == LocaleUkrainian {
+		messages = ukrainianMessages
+	} else if err := en_translations.RegisterDefaultTranslations(validate, trans); err != nil {
+		return nil, fmt.Errorf("register default translations: %w", err)
+	}
+
+	for key, message := range messages {
+		if err := trans.Add(key, message, true); err != nil {
+			return nil, fmt.Errorf("add %q translation: %w", key, err)
+		}
+	}
+
+	for key := range messages {
+		if strings.HasSuffix(key, stringSuffix) {
+			continue
+		}
+
+		if err := validate.RegisterTranslation(key, trans, addedMessages, translateMessage); err != nil {
+			return nil, fmt.Errorf("register %q translation: %w", key, err)
+		}
+	}
+
+	return trans, nil
+}
+
+// stringSuffix marks messages specific to string fields,
+// for which limits are about the length.
+const stringSuffix = "-string"
+
+func addedMessages(ut.Translator) error {
+	return nil
+}
+
+func translateMessage(trans ut.Translator, fe validator.FieldError) string {
+	if fe.Kind() == reflect.String {
+		if message, err := trans.T(fe.Tag()+stringSuffix, fe.Field(), fe.Param()); err == nil {
+			return message
+		}
+	}
+
+	message, err := trans.T(fe.Tag(), fe.Field(), fe.Param())
+	if err != nil {
+		return fe.Error()
+	}
+
+	return message
+}
+
+// englishMessages complement the default English translations.
+var englishMessages = map[string]string{
+	"charsValidation":  "{0} contains unsupported characters",
+	"phoneValidation":  "{0} must be a valid phone number",
+	"required_without": "{0} is required if {1} is not given",
+	"iso4217":          "{0} must be a valid currency code",
+	"http_url":         "{0} must be a valid HTTP(S) URL",
+	"messageTemplate":  "{0} must be a valid message template",
+	"number":           "{0} must be a valid number",
+}
+
+// ukrainianMessages are defined for the tags used by requests,
+// failures of other tags are described by the validator.
+var ukrainianMessages = map[string]string{
+	"required":         "{0} є обов'язковим полем",
+	"min":              "{0} має бути не менше {1}",
+	"max":              "{0} має бути не більше {1}",
+	"gt":               "{0} має бути більше {1}",
+	"gte":              "{0} має бути не менше {1}",
+	"lt":               "{0} має бути менше {1}",
+	"lte":              "{0} має бути не більше {1}",
+	"oneof":            "{0} має бути одним із [{1}]",
+	"unique":           "{0} має містити унікальні значення",
+	"min-string":       "{0} має містити щонайменше {1} символів",
+	"max-string":       "{0} має містити не більше {1} символів",
+	"lte-string":       "{0} має містити не більше {1} символів",
+	"charsValidation":  "{0} містить недопустимі символи",
+	"phoneValidation":  "{0} має бути дійсним номером телефону",
+	"required_without": "{0} є обов'язковим, якщо не вказано {1}",
+	"iso4217":          "{0} має бути дійсним кодом валюти",
+	"http_url":         "{0} має бути дійсною HTTP(S) адресою",
+	"messageTemplate":  "{0} має бути дійсним шаблоном повідомлення",
+	"number":           "{0} має бути дійсним числом",
 }
@@ -1,6 +1,10 @@
 package service
 
 import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -43,6 +47,16 @@ func Test_validateRaffle(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Invalid RaffleRequest (Negative max prizes)",
+			args: args{
+				raf: &RaffleRequest{
+					Name:                    "Example Raffle",
+					MaxPrizesPerParticipant: -1,
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -102,6 +116,37 @@ func Test_validatePrize(t *testing.T) {
 				},
 			},
 			wantErr: true,
+		}, {
+			name: "Valid PrizeRequest (Draw strategy)",
+			args: args{
+				p: &PrizeRequest{
+					Name:         "Example Prize",
+					TicketCost:   100,
+					DrawStrategy: DrawByAmount,
+					MaxTickets:   10,
+				},
+			},
+			wantErr: false,
+		}, {
+			name: "Invalid PrizeRequest (Unknown draw strategy)",
+			args: args{
+				p: &PrizeRequest{
+					Name:         "Example Prize",
+					TicketCost:   100,
+					DrawStrategy: "lottery",
+				},
+			},
+			wantErr: true,
+		}, {
+			name: "Invalid PrizeRequest (Negative max tickets)",
+			args: args{
+				p: &PrizeRequest{
+					Name:       "Example Prize",
+					TicketCost: 100,
+					MaxTickets: -1,
+				},
+			},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -127,7 +172,7 @@ func Test_validateParticipant(t *testing.T) {
 			args: args{
 				p: &ParticipantRequest{
 					Name:  "John DoeЇ",
-					Phone: "+380123456789",
+					Phone: "+380501234567",
 					Note:  "Example",
 				},
 			},
@@ -148,7 +193,7 @@ func Test_validateParticipant(t *testing.T) {
 			args: args{
 				p: &ParticipantRequest{
 					Name:  "J",
-					Phone: "+380123456789",
+					Phone: "+380501234567",
 					Note:  "Example",
 				},
 			},
@@ -158,7 +203,7 @@ func Test_validateParticipant(t *testing.T) {
 			args: args{
 				p: &ParticipantRequest{
 					Name:  "John DoeЇ世",
-					Phone: "+380123456789",
+					Phone: "+380501234567",
 					Note:  "Example世",
 				},
 			},
@@ -174,3 +219,259 @@ func Test_validateParticipant(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePhone(t *testing.T) {
+	t.Cleanup(func() { _ = SetValidationConfig(DefaultValidationConfig()) })
+
+	tests := []struct {
+		name    string
+		phone   string
+		want    string
+		wantErr bool
+	}{
+		{name: "international", phone: "+380501234567", want: "+380501234567"},
+		{name: "formatted", phone: "+38 (050) 123-45-67", want: "+380501234567"},
+		{name: "national", phone: "050 123 45 67", want: "+380501234567"},
+		{name: "not allowed region", phone: "+48 501 234 567", wantErr: true},
+		{name: "invalid", phone: "123", wantErr: true},
+		{name: "letters", phone: "phone", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePhone(tt.phone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePhone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePhone() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("configured regions", func(t *testing.T) {
+		config := DefaultValidationConfig()
+		config.DefaultRegion = "pl"
+		config.AllowedRegions = []string{"pl", "ua"}
+		if err := SetValidationConfig(config); err != nil {
+			t.Fatal(err)
+		}
+
+		for phone, want := range map[string]string{
+			"501 234 567":   "+48501234567",
+			"+380501234567": "+380501234567",
+		} {
+			got, err := ParsePhone(phone)
+			if err != nil || got != want {
+				t.Errorf("ParsePhone(%q) = %v, %v, want %v", phone, got, err, want)
+			}
+		}
+
+		if _, err := ParsePhone("+49 1512 3456789"); err == nil {
+			t.Error("ParsePhone() accepted a phone of not allowed region")
+		}
+	})
+
+	t.Run("any region", func(t *testing.T) {
+		config := DefaultValidationConfig()
+		config.AllowedRegions = nil
+		if err := SetValidationConfig(config); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ParsePhone("+49 1512 3456789"); err != nil {
+			t.Errorf("ParsePhone() error = %v", err)
+		}
+	})
+}
+
+func TestCharsPolicy(t *testing.T) {
+	t.Cleanup(func() { _ = SetValidationConfig(DefaultValidationConfig()) })
+
+	request := &RaffleRequest{Name: "Łódź Zażółć", Note: "Crème brûlée"}
+	if err := request.Validate(); err == nil {
+		t.Error("Validate() accepted diacritics with the default policy")
+	}
+
+	config := DefaultValidationConfig()
+	config.Chars.Scripts = []string{"Latin", "Cyrillic"}
+	if err := SetValidationConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := request.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	// "e" followed by the combining acute accent.
+	request.Note = "Cafe\u0301"
+	if err := request.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	request.Note = "Example世"
+	if err := request.Validate(); err == nil {
+		t.Error("Validate() accepted letters of not allowed script")
+	}
+}
+
+func TestValidationMessages(t *testing.T) {
+	t.Cleanup(func() { _ = SetValidationConfig(DefaultValidationConfig()) })
+
+	request := &ParticipantRequest{Name: "J", Phone: "123"}
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{
+			locale: LocaleEnglish,
+			want:   "name must be at least 2 characters in length; phone must be a valid phone number",
+		},
+		{
+			locale: LocaleUkrainian,
+			want:   "name має містити щонайменше 2 символів; phone має бути дійсним номером телефону",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			config := DefaultValidationConfig()
+			config.Locale = tt.locale
+			if err := SetValidationConfig(config); err != nil {
+				t.Fatal(err)
+			}
+
+			err := request.Validate()
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() error = %v, want ValidationError", err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Validate() error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetValidationConfig(t *testing.T) {
+	t.Cleanup(func() { _ = SetValidationConfig(DefaultValidationConfig()) })
+
+	tests := []struct {
+		name   string
+		modify func(c *ValidationConfig)
+	}{
+		{name: "unknown default region", modify: func(c *ValidationConfig) { c.DefaultRegion = "XX" }},
+		{name: "unknown allowed region", modify: func(c *ValidationConfig) { c.AllowedRegions = []string{"UA", "XX"} }},
+		{name: "unknown script", modify: func(c *ValidationConfig) { c.Chars.Scripts = []string{"Elvish"} }},
+		{name: "unsupported locale", modify: func(c *ValidationConfig) { c.Locale = "de" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultValidationConfig()
+			tt.modify(&config)
+
+			if err := SetValidationConfig(config); !errors.Is(err, ErrInvalidValidationConfig) {
+				t.Errorf("SetValidationConfig() error = %v, want %v", err, ErrInvalidValidationConfig)
+			}
+		})
+	}
+}
+
+func TestValidateConcurrently(t *testing.T) {
+	t.Cleanup(func() { _ = SetValidationConfig(DefaultValidationConfig()) })
+
+	request := dummyParticipantRequest()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				if err := request.Validate(); err != nil {
+					t.Errorf("Validate() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	config := DefaultValidationConfig()
+	config.Locale = LocaleUkrainian
+	if err := SetValidationConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+}
+
+// BenchmarkValidate compares the shared validator
+// with building one for each validation, as it was done before.
+func BenchmarkValidate(b *testing.B) {
+	request := dummyParticipantRequest()
+
+	b.Run("shared", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if err := request.Validate(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("shared_parallel", func(b *testing.B) {
+		b.ReportAllocs()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if err := request.Validate(); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+
+	b.Run("per_call", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			v, err := newValidatorSet(DefaultValidationConfig())
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			if err := v.validate.Struct(request); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkCharsValidation(b *testing.B) {
+	note := strings.Repeat("Благодійний розіграш 2024! ", 20)
+	policy := DefaultValidationConfig().Chars
+
+	b.Run("precompiled", func(b *testing.B) {
+		chars := regexp.MustCompile(policy.pattern())
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if !chars.MatchString(note) {
+				b.Fatal("note doesn't match")
+			}
+		}
+	})
+
+	b.Run("compiled_per_call", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if !regexp.MustCompile(policy.pattern()).MatchString(note) {
+				b.Fatal("note doesn't match")
+			}
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrVersionMismatch is returned when an entity is edited
+// based on a version other than its current one.
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// Versioned is an entity edited with optimistic concurrency.
+// Its version is the time of its last update, which is set by the storage
+// when the entity is read or written. The storage rejects updates
+// of an entity which was updated after its version was read
+// with ErrVersionMismatch. Updates of entities with a zero version aren't checked.
+type Versioned interface {
+	Version() time.Time
+	SetVersion(time.Time)
+}
+
+var (
+	_ Versioned = (*Raffle)(nil)
+	_ Versioned = (*Prize)(nil)
+	_ Versioned = (*Participant)(nil)
+	_ Versioned = (*Donation)(nil)
+	_ Versioned = (*Payment)(nil)
+)
+
+// Precondition makes an edit request conditional on the version of the entity.
+type Precondition struct {
+	// IfVersion is the version the entity is expected to have.
+	// The entity is edited regardless of its version if it's zero.
+	IfVersion time.Time `json:"-"`
+
+	// written is the version of the entity after the edit.
+	written time.Time
+}
+
+// WrittenVersion returns the version of the entity after the edit.
+// It's zero if the edit failed or the storage doesn't know the new version.
+func (p *Precondition) WrittenVersion() time.Time {
+	return p.written
+}
+
+// wrote records the version of the edited entity.
+func (p *Precondition) wrote(v Versioned) {
+	p.written = v.Version()
+}
+
+// ExpectVersion makes the request fail with ErrVersionMismatch
+// if the entity has another version.
+func (p *Precondition) ExpectVersion(version time.Time) {
+	p.IfVersion = version
+}
+
+// checkVersion checks the version of the entity read to be edited.
+// The storage checks the version again when the entity is written.
+func (p *Precondition) checkVersion(v Versioned) error {
+	if p.IfVersion.IsZero() || p.IfVersion.Equal(v.Version()) {
+		return nil
+	}
+
+	return ErrVersionMismatch
+}
+
+// Version returns the time of the last update of the raffle.
+func (r *Raffle) Version() time.Time { return r.UpdatedAt }
+
+// SetVersion sets the time of the last update of the raffle.
+func (r *Raffle) SetVersion(v time.Time) { r.UpdatedAt = v }
+
+// Version returns the time of the last update of the prize.
+// Donations change the running totals and so the version of the prize.
+func (p *Prize) Version() time.Time { return p.UpdatedAt }
+
+// SetVersion sets the time of the last update of the prize.
+func (p *Prize) SetVersion(v time.Time) { p.UpdatedAt = v }
+
+// Version returns the time of the last update of the participant.
+func (p *Participant) Version() time.Time { return p.UpdatedAt }
+
+// SetVersion sets the time of the last update of the participant.
+func (p *Participant) SetVersion(v time.Time) { p.UpdatedAt = v }
+
+// Version returns the time of the last update of the donation.
+func (d *Donation) Version() time.Time { return d.UpdatedAt }
+
+// SetVersion sets the time of the last update of the donation.
+func (d *Donation) SetVersion(v time.Time) { d.UpdatedAt = v }
+
+// Version returns the time of the last update of the payment.
+func (p *Payment) Version() time.Time { return p.UpdatedAt }
+
+// SetVersion sets the time of the last update of the payment.
+func (p *Payment) SetVersion(v time.Time) { p.UpdatedAt = v }
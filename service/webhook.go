@@ -0,0 +1,589 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// WebhookMaxAttempts is the number of attempts to deliver an event
+	// after which the delivery is failed.
+	WebhookMaxAttempts = 6
+
+	// webhookBackoff is the delay of the first retry, it doubles for every next one.
+	webhookBackoff = time.Minute
+
+	// webhookTimeout limits a single delivery attempt.
+	webhookTimeout = 10 * time.Second
+)
+
+// Headers of webhook requests.
+const (
+	WebhookEventHeader     = "X-Yarmarok-Event"
+	WebhookDeliveryHeader  = "X-Yarmarok-Delivery"
+	WebhookTimestampHeader = "X-Yarmarok-Timestamp"
+	// WebhookSignatureHeader is "sha256=" followed by the hex encoded
+	// HMAC-SHA256 of the timestamp and the payload, see SignWebhookPayload.
+	WebhookSignatureHeader = "X-Yarmarok-Signature"
+)
+
+var (
+	// ErrWebhookDeleted is recorded for pending deliveries of deleted webhooks.
+	ErrWebhookDeleted = errors.New("webhook is deleted")
+
+	// ErrInsecureWebhookURL is recorded for attempts to deliver to URLs other than https.
+	ErrInsecureWebhookURL = errors.New("webhook URL is not https")
+
+	// ErrNonPublicWebhookAddress is recorded for attempts to deliver to loopback,
+	// private, link-local (including cloud metadata) and other non-public addresses.
+	ErrNonPublicWebhookAddress = errors.New("webhook address is not public")
+)
+
+// Webhook is a subscription of a URL to events of raffles of an organizer.
+type Webhook struct {
+	ID string `json:"id"`
+	// RaffleID limits the events to the raffle, events of all raffles
+	// of the organizer are delivered if it's empty.
+	RaffleID string `json:"raffleId,omitempty"`
+	URL      string `json:"url"`
+	// Secret signs the payloads, it's never returned.
+	Secret    string      `json:"-"`
+	Events    []EventType `json:"events"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// subscribed reports whether the webhook is subscribed to the event.
+func (w *Webhook) subscribed(e *Event) bool {
+	if w.RaffleID != "" && w.RaffleID != e.RaffleID {
+		return false
+	}
+
+	for _, eventType := range w.Events {
+		if eventType == e.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookRequest is a request for creating a webhook.
+type WebhookRequest struct {
+	RaffleID string      `json:"raffleId,omitempty"`
+	URL      string      `json:"url" validate:"required,url,startswith=https://,max=2000"`
+	Secret   string      `json:"secret" validate:"required,min=16,max=256"`
+	Events   []EventType `json:"events" validate:"required,min=1,unique,dive,oneof=donation.created payment.created prize.played raffle.closed"`
+}
+
+func (r *WebhookRequest) Validate() error {
+	return validateStruct(r)
+}
+
+// DeliveryStatus is a status of delivering an event to a webhook.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is a delivery which is going to be attempted.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded is a delivery the webhook responded to with 2xx.
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed is a delivery which ran out of attempts.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is a log entry of delivering an event to a webhook.
+type Delivery struct {
+	ID        string    `json:"id"`
+	WebhookID string    `json:"webhookId"`
+	EventID   string    `json:"eventId"`
+	EventType EventType `json:"eventType"`
+	// Payload is the JSON encoded event, it's sent as is by every attempt.
+	Payload  string            `json:"payload"`
+	Status   DeliveryStatus    `json:"status"`
+	Attempts []DeliveryAttempt `json:"attempts"`
+	// NextAttemptAt is set for pending deliveries only.
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	// ReplayOf refers to the delivery replayed by this one.
+	ReplayOf  string    `json:"replayOf,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DeliveryAttempt is a single request to a webhook.
+type DeliveryAttempt struct {
+	At time.Time `json:"at"`
+	// StatusCode is zero if no response was received.
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ReplayRequest is a request for replaying a delivery.
+// It has no options yet.
+type ReplayRequest struct{}
+
+// WebhookStorage is a storage for webhooks of an organizer.
+//
+//go:generate mockgen -destination=mock_webhook_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service WebhookStorage
+type WebhookStorage interface {
+	Create(*Webhook) error
+	Get(id string) (*Webhook, error)
+	GetAll() ([]Webhook, error)
+	Delete(id string) error
+}
+
+// DeliveryStorage is a storage for deliveries to webhooks of an organizer.
+//
+//go:generate mockgen -destination=mock_delivery_storage_test.go -package=service  github.com/bluegophercult/yarmarok/service DeliveryStorage
+type DeliveryStorage interface {
+	Create(*Delivery) error
+	Get(id string) (*Delivery, error)
+	Update(*Delivery) error
+	GetByWebhook(webhookID string) ([]Delivery, error)
+	GetByEventType(eventType EventType) ([]Delivery, error)
+	// GetDue returns pending deliveries which next attempt is due by the moment.
+	GetDue(now time.Time) ([]Delivery, error)
+}
+
+// WebhookService is a service for webhooks of an organizer.
+type WebhookService interface {
+	Create(*WebhookRequest) (id string, err error)
+	Get(id string) (*Webhook, error)
+	List() ([]Webhook, error)
+	Delete(id string) error
+	Deliveries(webhookID string) ([]Delivery, error)
+	Replay(deliveryID string, r *ReplayRequest) (*Delivery, error)
+}
+
+var _ WebhookService = (*WebhookManager)(nil)
+
+// WebhookManager is an implementation of WebhookService.
+type WebhookManager struct {
+	raffleStorage   RaffleStorage
+	webhookStorage  WebhookStorage
+	deliveryStorage DeliveryStorage
+}
+
+// NewWebhookManager creates a new WebhookManager.
+func NewWebhookManager(rs RaffleStorage, ws WebhookStorage, ds DeliveryStorage) *WebhookManager {
+	return &WebhookManager{
+		raffleStorage:   rs,
+		webhookStorage:  ws,
+		deliveryStorage: ds,
+	}
+}
+
+// Create creates a webhook.
+func (wm *WebhookManager) Create(r *WebhookRequest) (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", errors.Join(err, ErrInvalidRequest)
+	}
+
+	if r.RaffleID != "" {
+		if _, err := wm.raffleStorage.Get(r.RaffleID); err != nil {
+			return "", fmt.Errorf("get raffle: %w", err)
+		}
+	}
+
+	webhook := Webhook{
+		ID:        stringUUID(),
+		RaffleID:  r.RaffleID,
+		URL:       r.URL,
+		Secret:    r.Secret,
+		Events:    r.Events,
+		CreatedAt: timeNow(),
+	}
+
+	if err := wm.webhookStorage.Create(&webhook); err != nil {
+		return "", fmt.Errorf("create webhook: %w", err)
+	}
+
+	return webhook.ID, nil
+}
+
+// Get returns a webhook by id.
+func (wm *WebhookManager) Get(id string) (*Webhook, error) {
+	return wm.webhookStorage.Get(id)
+}
+
+// List lists webhooks of the organizer.
+func (wm *WebhookManager) List() ([]Webhook, error) {
+	webhooks, err := wm.webhookStorage.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Delete deletes a webhook. Its pending deliveries fail on the next retry.
+func (wm *WebhookManager) Delete(id string) error {
+	if err := wm.webhookStorage.Delete(id); err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// Deliveries returns the delivery log of a webhook.
+func (wm *WebhookManager) Deliveries(webhookID string) ([]Delivery, error) {
+	if _, err := wm.webhookStorage.Get(webhookID); err != nil {
+		return nil, fmt.Errorf("get webhook: %w", err)
+	}
+
+	deliveries, err := wm.deliveryStorage.GetByWebhook(webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("get deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Replay queues the payload of a delivery once again
+// as a new pending delivery with its own attempts.
+func (wm *WebhookManager) Replay(deliveryID string, _ *ReplayRequest) (*Delivery, error) {
+	original, err := wm.deliveryStorage.Get(deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("get delivery: %w", err)
+	}
+
+	webhook, err := wm.webhookStorage.Get(original.WebhookID)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook: %w", err)
+	}
+
+	delivery := newDelivery(webhook.ID, original.EventID, original.EventType, original.Payload)
+	delivery.ReplayOf = original.ID
+
+	if err := wm.deliveryStorage.Create(delivery); err != nil {
+		return nil, fmt.Errorf("create delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// WebhookDispatcher delivers events to webhooks subscribed to them.
+// Events are logged as pending deliveries, so requests publishing them
+// don't wait for webhooks. Deliveries are attempted and the failed ones
+// are retried with exponential backoff by OrganizerManager.DeliverWebhooks.
+type WebhookDispatcher struct {
+	organizerStorage OrganizerStorage
+	client           *http.Client
+}
+
+// NewWebhookDispatcher creates a new WebhookDispatcher.
+func NewWebhookDispatcher(os OrganizerStorage, client *http.Client) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		organizerStorage: os,
+		client:           client,
+	}
+}
+
+// Dispatch queues the event for webhooks of its organizer subscribed to it.
+func (d *WebhookDispatcher) Dispatch(e Event) error {
+	webhooks, err := d.organizerStorage.WebhookStorage(e.OrganizerID).GetAll()
+	if err != nil {
+		return fmt.Errorf("get all webhooks: %w", err)
+	}
+
+	var payload []byte
+	deliveryStorage := d.organizerStorage.DeliveryStorage(e.OrganizerID)
+
+	for i := range webhooks {
+		webhook := &webhooks[i]
+		if !webhook.subscribed(&e) {
+			continue
+		}
+
+		if payload == nil {
+			if payload, err = json.Marshal(e); err != nil {
+				return fmt.Errorf("encode event: %w", err)
+			}
+		}
+
+		delivery := newDelivery(webhook.ID, e.ID, e.Type, string(payload))
+		if err := deliveryStorage.Create(delivery); err != nil {
+			return fmt.Errorf("create delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// dispatch is the event hook of the dispatcher.
+// Events are best effort: the ones which can't be logged
+// as deliveries are not delivered.
+func (d *WebhookDispatcher) dispatch(e Event) {
+	_ = d.Dispatch(e)
+}
+
+// retryDue attempts pending deliveries of the organizer which are due.
+func (d *WebhookDispatcher) retryDue(organizerID string, now time.Time) ([]Delivery, error) {
+	deliveryStorage := d.organizerStorage.DeliveryStorage(organizerID)
+	webhookStorage := d.organizerStorage.WebhookStorage(organizerID)
+
+	due, err := deliveryStorage.GetDue(now)
+	if err != nil {
+		return nil, fmt.Errorf("get due deliveries: %w", err)
+	}
+
+	for i := range due {
+		delivery := &due[i]
+
+		webhook, err := webhookStorage.Get(delivery.WebhookID)
+		if errors.Is(err, ErrNotFound) {
+			delivery.fail(DeliveryAttempt{At: now, Error: ErrWebhookDeleted.Error()})
+			if err := deliveryStorage.Update(delivery); err != nil {
+				return nil, fmt.Errorf("update delivery: %w", err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("get webhook: %w", err)
+		}
+
+		d.attempt(webhook, delivery)
+
+		if err := deliveryStorage.Update(delivery); err != nil {
+			return nil, fmt.Errorf("update delivery: %w", err)
+		}
+	}
+
+	return due, nil
+}
+
+// attempt sends the payload to the webhook and records the result.
+func (d *WebhookDispatcher) attempt(w *Webhook, delivery *Delivery) {
+	now := timeNow()
+	attempt := DeliveryAttempt{At: now}
+
+	statusCode, err := d.post(w, delivery, now)
+	attempt.StatusCode = statusCode
+
+	if err == nil {
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		delivery.Status = DeliverySucceeded
+		delivery.NextAttemptAt = nil
+
+		return
+	}
+
+	attempt.Error = err.Error()
+
+	if len(delivery.Attempts)+1 >= WebhookMaxAttempts {
+		delivery.fail(attempt)
+		return
+	}
+
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	next := now.Add(webhookBackoff << (len(delivery.Attempts) - 1))
+	delivery.NextAttemptAt = &next
+}
+
+// post sends a signed request with the payload to the webhook.
+// It fails if the webhook doesn't respond with 2xx.
+// Responses are not recorded, so webhooks can't be used to read
+// from hosts the service can reach.
+func (d *WebhookDispatcher) post(w *Webhook, delivery *Delivery, now time.Time) (int, error) {
+	if u, err := url.Parse(w.URL); err != nil || u.Scheme != "https" {
+		return 0, ErrInsecureWebhookURL
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(w.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp.StatusCode, nil
+	}
+
+	return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// newWebhookClient creates a client which connects to public addresses only
+// and doesn't follow redirects, since webhook URLs are given by organizers.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: publicAddressOnly,
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddressOnly is a net.Dialer control rejecting connections
+// to non-public addresses. It checks resolved addresses,
+// so host names resolving to internal ones are rejected too.
+func publicAddressOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrNonPublicWebhookAddress, host)
+	}
+
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, RFC 6598.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// fail records the last attempt and stops retrying the delivery.
+func (d *Delivery) fail(attempt DeliveryAttempt) {
+	d.Attempts = append(d.Attempts, attempt)
+	d.Status = DeliveryFailed
+	d.NextAttemptAt = nil
+}
+
+func newDelivery(webhookID, eventID string, eventType EventType, payload string) *Delivery {
+	now := timeNow()
+
+	return &Delivery{
+		ID:            stringUUID(),
+		WebhookID:     webhookID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		Attempts:      make([]DeliveryAttempt, 0, 1),
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+}
+
+// SignWebhookPayload returns the signature of the payload sent at the timestamp,
+// the way it's sent in WebhookSignatureHeader. Receivers verify it with the webhook
+// secret and reject stale timestamps to prevent replays by third parties.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliverWebhooks publishes raffle.closed events of raffles which have ended
+// and attempts pending deliveries which are due, including the new ones.
+// It is meant to be triggered periodically by a scheduler.
+// Failure to deliver events of a single organizer doesn't stop the others,
+// errors of all of them are returned along with the attempted deliveries.
+func (om *OrganizerManager) DeliverWebhooks() ([]Delivery, error) {
+	now := timeNow()
+	errs := make([]error, 0)
+
+	if err := om.publishClosed(now); err != nil {
+		errs = append(errs, err)
+	}
+
+	organizerIDs, err := om.organizerStorage.DueDeliveryOrganizers(now)
+	if err != nil {
+		return nil, errors.Join(append(errs, fmt.Errorf("get organizers of due deliveries: %w", err))...)
+	}
+
+	retried := make([]Delivery, 0)
+
+	for _, id := range organizerIDs {
+		deliveries, err := om.webhooks.retryDue(id, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("retry deliveries of organizer %q: %w", id, err))
+		}
+
+		retried = append(retried, deliveries...)
+	}
+
+	return retried, errors.Join(errs...)
+}
+
+// WebhookService is a service for webhooks.
+func (om *OrganizerManager) WebhookService(organizerID string) WebhookService {
+	return NewWebhookManager(
+		om.organizerStorage.RaffleStorage(organizerID),
+		om.organizerStorage.WebhookStorage(organizerID),
+		om.organizerStorage.DeliveryStorage(organizerID),
+	)
+}
+
+// publishClosed publishes raffle.closed events of raffles which have ended
+// by the moment and are not published yet.
+func (om *OrganizerManager) publishClosed(now time.Time) error {
+	if !om.events.subscribed() {
+		return nil
+	}
+
+	raffles, err := om.organizerStorage.ClosedRaffles(now)
+	if err != nil {
+		return fmt.Errorf("get closed raffles: %w", err)
+	}
+
+	errs := make([]error, 0)
+
+	for i := range raffles {
+		if err := om.raffleManager(raffles[i].OrganizerID).publishClosed(&raffles[i], now); err != nil {
+			errs = append(errs, fmt.Errorf("publish closed raffle %q: %w", raffles[i].ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// publishClosed publishes the raffle.closed event of the raffle
+// unless it's still open or the event is already published.
+func (rm *RaffleManager) publishClosed(raffle *Raffle, now time.Time) error {
+	if !raffle.IsClosed(now) || raffle.ClosedPublishedAt != nil {
+		return nil
+	}
+
+	raffle.ClosedPublishedAt = &now
+	if err := rm.raffleStorage.Update(raffle); err != nil {
+		return fmt.Errorf("update raffle: %w", err)
+	}
+
+	rm.raffleEvents(raffle.ID).publish(EventRaffleClosed, *raffle)
+
+	return nil
+}
@@ -0,0 +1,552 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// webhookReceiver is a webhook endpoint responding with the queued statuses,
+// 200 once they are over. Test receivers share a certificate,
+// so the client of any of them trusts all.
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	r := &webhookReceiver{statuses: statuses}
+	r.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("internal details"))
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+// deliveryLog is an in-memory DeliveryStorage.
+type deliveryLog struct {
+	deliveries map[string]Delivery
+}
+
+func newDeliveryLog(t *testing.T, ctrl *gomock.Controller) (*deliveryLog, *MockDeliveryStorage) {
+	log := &deliveryLog{deliveries: make(map[string]Delivery)}
+	storage := NewMockDeliveryStorage(ctrl)
+
+	storage.EXPECT().Create(gomock.Any()).DoAndReturn(func(d *Delivery) error {
+		require.NotContains(t, log.deliveries, d.ID)
+		log.deliveries[d.ID] = *d
+		return nil
+	}).AnyTimes()
+
+	storage.EXPECT().Update(gomock.Any()).DoAndReturn(func(d *Delivery) error {
+		require.Contains(t, log.deliveries, d.ID)
+		log.deliveries[d.ID] = *d
+		return nil
+	}).AnyTimes()
+
+	storage.EXPECT().Get(gomock.Any()).DoAndReturn(func(id string) (*Delivery, error) {
+		d, ok := log.deliveries[id]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		return &d, nil
+	}).AnyTimes()
+
+	storage.EXPECT().GetDue(gomock.Any()).DoAndReturn(func(now time.Time) ([]Delivery, error) {
+		due := make([]Delivery, 0)
+		for _, d := range log.deliveries {
+			if d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+				due = append(due, d)
+			}
+		}
+		return due, nil
+	}).AnyTimes()
+
+	return log, storage
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	organizerStorage := NewMockOrganizerStorage(ctrl)
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	webhookStorage := NewMockWebhookStorage(ctrl)
+	log, deliveryStorage := newDeliveryLog(t, ctrl)
+
+	organizerStorage.EXPECT().RaffleStorage("organizer_1").Return(raffleStorage).AnyTimes()
+	organizerStorage.EXPECT().WebhookStorage("organizer_1").Return(webhookStorage).AnyTimes()
+	organizerStorage.EXPECT().DeliveryStorage("organizer_1").Return(deliveryStorage).AnyTimes()
+	organizerStorage.EXPECT().ClosedRaffles(gomock.Any()).Return(nil, nil).AnyTimes()
+	organizerStorage.EXPECT().DueDeliveryOrganizers(gomock.Any()).Return([]string{"organizer_1"}, nil).AnyTimes()
+
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	setTimeNowMock(now)
+
+	om := NewOrganizerManager(organizerStorage)
+
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	other := newWebhookReceiver(t)
+	om.webhooks.client = receiver.Client()
+
+	webhook := Webhook{
+		ID:     "webhook_1",
+		URL:    receiver.URL,
+		Secret: "0123456789abcdef",
+		Events: []EventType{EventDonationCreated, EventPrizePlayed},
+	}
+
+	webhookStorage.EXPECT().GetAll().DoAndReturn(func() ([]Webhook, error) {
+		return []Webhook{
+			webhook,
+			{ID: "webhook_2", URL: other.URL, Secret: "0123456789abcdef", Events: []EventType{EventPrizePlayed}},
+			{ID: "webhook_3", RaffleID: "raffle_2", URL: other.URL, Secret: "0123456789abcdef", Events: []EventType{EventDonationCreated}},
+		}, nil
+	}).AnyTimes()
+	webhookStorage.EXPECT().Get("webhook_1").Return(&webhook, nil).AnyTimes()
+
+	event := Event{
+		ID:          "event_1",
+		Type:        EventDonationCreated,
+		OrganizerID: "organizer_1",
+		RaffleID:    "raffle_1",
+		Data:        DonationCreated{PrizeID: "prize_1", Donation: Donation{ID: "donation_1", Amount: 100}},
+		CreatedAt:   now,
+	}
+
+	setUUIDMock("delivery_1")
+	om.Events().emit(event)
+
+	t.Run("queued", func(t *testing.T) {
+		assert.Empty(t, receiver.requests, "events are not delivered by requests publishing them")
+
+		delivery := log.deliveries["delivery_1"]
+		assert.Equal(t, DeliveryPending, delivery.Status)
+		assert.Empty(t, delivery.Attempts)
+		assert.Equal(t, now, *delivery.NextAttemptAt)
+
+		delivered, err := om.DeliverWebhooks()
+		require.NoError(t, err)
+		require.Len(t, delivered, 1)
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		require.Len(t, receiver.requests, 1)
+		assert.Empty(t, other.requests, "only subscribed webhooks of the raffle receive events")
+
+		req := receiver.requests[0]
+		body := receiver.bodies[0]
+
+		assert.Equal(t, "donation.created", req.Header.Get(WebhookEventHeader))
+		assert.Equal(t, "delivery_1", req.Header.Get(WebhookDeliveryHeader))
+		assert.Equal(t, "1714555800", req.Header.Get(WebhookTimestampHeader))
+		assert.Equal(t, SignWebhookPayload(webhook.Secret, "1714555800", body), req.Header.Get(WebhookSignatureHeader))
+		assert.NotEqual(t, SignWebhookPayload("another secret!!", "1714555800", body), req.Header.Get(WebhookSignatureHeader))
+
+		var received Event
+		require.NoError(t, json.Unmarshal(body, &received))
+		assert.Equal(t, "event_1", received.ID)
+		assert.Equal(t, EventDonationCreated, received.Type)
+		assert.Equal(t, "raffle_1", received.RaffleID)
+	})
+
+	t.Run("retried_with_backoff", func(t *testing.T) {
+		delivery := log.deliveries["delivery_1"]
+		assert.Equal(t, DeliveryPending, delivery.Status)
+		require.Len(t, delivery.Attempts, 1)
+		assert.Equal(t, http.StatusInternalServerError, delivery.Attempts[0].StatusCode)
+		assert.Equal(t, "unexpected status 500", delivery.Attempts[0].Error, "responses are not recorded")
+		assert.Equal(t, now.Add(time.Minute), *delivery.NextAttemptAt)
+
+		retried, err := om.DeliverWebhooks()
+		require.NoError(t, err)
+		assert.Empty(t, retried, "the retry is not due yet")
+
+		setTimeNowMock(now.Add(time.Minute))
+		retried, err = om.DeliverWebhooks()
+		require.NoError(t, err)
+		require.Len(t, retried, 1)
+
+		delivery = log.deliveries["delivery_1"]
+		assert.Equal(t, DeliveryPending, delivery.Status)
+		require.Len(t, delivery.Attempts, 2)
+		assert.Equal(t, http.StatusBadGateway, delivery.Attempts[1].StatusCode)
+		assert.Equal(t, now.Add(3*time.Minute), *delivery.NextAttemptAt)
+
+		setTimeNowMock(now.Add(3 * time.Minute))
+		_, err = om.DeliverWebhooks()
+		require.NoError(t, err)
+
+		delivery = log.deliveries["delivery_1"]
+		assert.Equal(t, DeliverySucceeded, delivery.Status)
+		assert.Len(t, delivery.Attempts, 3)
+		assert.Nil(t, delivery.NextAttemptAt)
+		require.Len(t, receiver.bodies, 3)
+		assert.Equal(t, receiver.bodies[0], receiver.bodies[2], "retries send the same payload")
+	})
+
+	t.Run("failed_after_max_attempts", func(t *testing.T) {
+		setTimeNowMock(now)
+		setUUIDMock("delivery_2")
+
+		failing := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError,
+			http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError,
+			http.StatusInternalServerError)
+
+		webhook.URL = failing.URL
+		require.NoError(t, om.webhooks.Dispatch(event))
+
+		for i := 0; i < WebhookMaxAttempts; i++ {
+			setTimeNowMock(now.Add(time.Duration(i+1) * time.Hour))
+			_, err := om.DeliverWebhooks()
+			require.NoError(t, err)
+		}
+
+		delivery := log.deliveries["delivery_2"]
+		assert.Equal(t, DeliveryFailed, delivery.Status)
+		assert.Len(t, delivery.Attempts, WebhookMaxAttempts)
+		assert.Len(t, failing.requests, WebhookMaxAttempts)
+		assert.Nil(t, delivery.NextAttemptAt)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		setUUIDMock("delivery_3")
+		webhook.URL = receiver.URL
+
+		replayed, err := om.WebhookService("organizer_1").Replay("delivery_2", &ReplayRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, "delivery_3", replayed.ID)
+		assert.Equal(t, "delivery_2", replayed.ReplayOf)
+		assert.Equal(t, DeliveryPending, replayed.Status)
+		assert.Equal(t, log.deliveries["delivery_2"].Payload, replayed.Payload)
+		assert.Equal(t, *replayed, log.deliveries["delivery_3"])
+
+		_, err = om.DeliverWebhooks()
+		require.NoError(t, err)
+
+		assert.Equal(t, DeliverySucceeded, log.deliveries["delivery_3"].Status)
+		assert.Equal(t, DeliveryFailed, log.deliveries["delivery_2"].Status, "the replayed delivery is kept as is")
+	})
+
+	t.Run("insecure_url", func(t *testing.T) {
+		setTimeNowMock(now)
+		setUUIDMock("delivery_4")
+
+		webhook.URL = "http" + strings.TrimPrefix(receiver.URL, "https")
+		require.NoError(t, om.webhooks.Dispatch(event))
+
+		_, err := om.DeliverWebhooks()
+		require.NoError(t, err)
+
+		delivery := log.deliveries["delivery_4"]
+		require.Len(t, delivery.Attempts, 1)
+		assert.Equal(t, ErrInsecureWebhookURL.Error(), delivery.Attempts[0].Error)
+	})
+}
+
+func TestWebhookClient(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	_, err := newWebhookClient().Get(receiver.URL)
+	require.ErrorIs(t, err, ErrNonPublicWebhookAddress)
+	assert.Empty(t, receiver.requests)
+
+	for address, public := range map[string]bool{
+		"93.184.216.34:443":        true,
+		"[2606:2800:220:1::]:443":  true,
+		"127.0.0.1:443":            false,
+		"10.1.2.3:443":             false,
+		"172.16.0.1:443":           false,
+		"192.168.1.1:443":          false,
+		"100.64.0.1:443":           false,
+		"169.254.169.254:80":       false,
+		"0.0.0.0:443":              false,
+		"[::1]:443":                false,
+		"[fd00:ec2::254]:80":       false,
+		"[fe80::1]:443":            false,
+		"[::ffff:127.0.0.1]:443":   false,
+		"[::ffff:169.254.1.1]:443": false,
+	} {
+		err := publicAddressOnly("tcp", address, nil)
+		if public {
+			assert.NoError(t, err, address)
+		} else {
+			assert.ErrorIs(t, err, ErrNonPublicWebhookAddress, address)
+		}
+	}
+}
+
+func TestPublishClosedRaffles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	organizerStorage := NewMockOrganizerStorage(ctrl)
+	raffleStorage1 := NewMockRaffleStorage(ctrl)
+	raffleStorage2 := NewMockRaffleStorage(ctrl)
+	webhookStorage := NewMockWebhookStorage(ctrl)
+
+	organizerStorage.EXPECT().RaffleStorage("organizer_1").Return(raffleStorage1).AnyTimes()
+	organizerStorage.EXPECT().RaffleStorage("organizer_2").Return(raffleStorage2).AnyTimes()
+	organizerStorage.EXPECT().DeliveryStorage(gomock.Any()).Return(nil).AnyTimes()
+	organizerStorage.EXPECT().WebhookStorage(gomock.Any()).Return(webhookStorage).AnyTimes()
+	webhookStorage.EXPECT().GetAll().Return(nil, nil).AnyTimes()
+
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	setTimeNowMock(now)
+	setUUIDMock("event_1")
+
+	om := NewOrganizerManager(organizerStorage)
+
+	var events []Event
+	om.Events().Subscribe(func(e Event) {
+		events = append(events, e)
+	})
+
+	organizerStorage.EXPECT().ClosedRaffles(now).Return([]Raffle{
+		{ID: "raffle_1", OrganizerID: "organizer_1", EndsAt: &past},
+		{ID: "raffle_2", OrganizerID: "organizer_2", ArchivedAt: &past},
+	}, nil)
+	organizerStorage.EXPECT().DueDeliveryOrganizers(now).Return(nil, nil)
+	raffleStorage1.EXPECT().Update(gomock.Any()).Return(assert.AnError)
+	raffleStorage2.EXPECT().Update(&Raffle{ID: "raffle_2", OrganizerID: "organizer_2", ArchivedAt: &past, ClosedPublishedAt: &now}).Return(nil)
+
+	_, err := om.DeliverWebhooks()
+	require.ErrorIs(t, err, assert.AnError)
+
+	require.Len(t, events, 1, "failure to publish one raffle doesn't stop the others")
+	assert.Equal(t, "organizer_2", events[0].OrganizerID)
+	assert.Equal(t, "raffle_2", events[0].RaffleID)
+}
+
+func TestRaffleNextCloseDueAt(t *testing.T) {
+	now := time.Now().UTC()
+	later := now.Add(time.Hour)
+
+	testCases := []struct {
+		name     string
+		raffle   Raffle
+		expected *time.Time
+	}{
+		{"open", Raffle{}, nil},
+		{"ends", Raffle{EndsAt: &later}, &later},
+		{"archived_before_end", Raffle{EndsAt: &later, ArchivedAt: &now}, &now},
+		{"archived_after_end", Raffle{EndsAt: &now, ArchivedAt: &later}, &now},
+		{"published", Raffle{EndsAt: &now, ClosedPublishedAt: &now}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.raffle.NextCloseDueAt())
+		})
+	}
+}
+
+func TestWebhookDeletedBeforeRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	organizerStorage := NewMockOrganizerStorage(ctrl)
+	webhookStorage := NewMockWebhookStorage(ctrl)
+	log, deliveryStorage := newDeliveryLog(t, ctrl)
+
+	organizerStorage.EXPECT().WebhookStorage("organizer_1").Return(webhookStorage).AnyTimes()
+	organizerStorage.EXPECT().DeliveryStorage("organizer_1").Return(deliveryStorage).AnyTimes()
+
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	setTimeNowMock(now)
+
+	log.deliveries["delivery_1"] = Delivery{ID: "delivery_1", WebhookID: "webhook_1", Status: DeliveryPending, NextAttemptAt: &now}
+	webhookStorage.EXPECT().Get("webhook_1").Return(nil, ErrNotFound)
+
+	d := NewWebhookDispatcher(organizerStorage, http.DefaultClient)
+
+	retried, err := d.retryDue("organizer_1", now)
+	require.NoError(t, err)
+	require.Len(t, retried, 1)
+
+	delivery := log.deliveries["delivery_1"]
+	assert.Equal(t, DeliveryFailed, delivery.Status)
+	assert.Equal(t, []DeliveryAttempt{{At: now, Error: ErrWebhookDeleted.Error()}}, delivery.Attempts)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+	webhookStorage := NewMockWebhookStorage(ctrl)
+
+	wm := NewWebhookManager(raffleStorage, webhookStorage, nil)
+
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	setTimeNowMock(now)
+	setUUIDMock("webhook_1")
+
+	request := &WebhookRequest{
+		RaffleID: "raffle_1",
+		URL:      "https://example.com/hooks",
+		Secret:   "0123456789abcdef",
+		Events:   []EventType{EventRaffleClosed},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		webhookStorage.EXPECT().Create(&Webhook{
+			ID:        "webhook_1",
+			RaffleID:  "raffle_1",
+			URL:       "https://example.com/hooks",
+			Secret:    "0123456789abcdef",
+			Events:    []EventType{EventRaffleClosed},
+			CreatedAt: now,
+		}).Return(nil)
+
+		id, err := wm.Create(request)
+		require.NoError(t, err)
+		assert.Equal(t, "webhook_1", id)
+	})
+
+	t.Run("unknown_raffle", func(t *testing.T) {
+		raffleStorage.EXPECT().Get("raffle_1").Return(nil, ErrNotFound)
+
+		_, err := wm.Create(request)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		invalid := []*WebhookRequest{
+			{URL: "ftp://example.com", Secret: "0123456789abcdef", Events: []EventType{EventRaffleClosed}},
+			{URL: "http://example.com", Secret: "0123456789abcdef", Events: []EventType{EventRaffleClosed}},
+			{URL: "https://example.com", Secret: "short", Events: []EventType{EventRaffleClosed}},
+			{URL: "https://example.com", Secret: "0123456789abcdef", Events: []EventType{"raffle.deleted"}},
+			{URL: "https://example.com", Secret: "0123456789abcdef"},
+		}
+
+		for _, r := range invalid {
+			_, err := wm.Create(r)
+			assert.ErrorIs(t, err, ErrInvalidRequest)
+		}
+	})
+
+	t.Run("secret_is_not_encoded", func(t *testing.T) {
+		encoded, err := json.Marshal(Webhook{ID: "webhook_1", Secret: "0123456789abcdef"})
+		require.NoError(t, err)
+		assert.NotContains(t, string(encoded), "0123456789abcdef")
+	})
+}
+
+func TestRaffleEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	raffleStorage := NewMockRaffleStorage(ctrl)
+
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	setTimeNowMock(now)
+	setUUIDMock("event_1")
+
+	var events []Event
+	hooks := &EventHooks{}
+	hooks.Subscribe(func(e Event) {
+		events = append(events, e)
+	})
+
+	rm := NewRaffleManager(raffleStorage)
+	rm.organizerID = "organizer_1"
+	rm.events = hooks
+
+	t.Run("raffle_closed", func(t *testing.T) {
+		events = nil
+
+		raffles := []Raffle{
+			{ID: "open"},
+			{ID: "closed", EndsAt: &past},
+			{ID: "published", EndsAt: &past, ClosedPublishedAt: &past},
+		}
+		raffleStorage.EXPECT().Update(&Raffle{ID: "closed", EndsAt: &past, ClosedPublishedAt: &now}).Return(nil)
+
+		for i := range raffles {
+			require.NoError(t, rm.publishClosed(&raffles[i], now))
+		}
+
+		require.Len(t, events, 1)
+		assert.Equal(t, EventRaffleClosed, events[0].Type)
+		assert.Equal(t, "organizer_1", events[0].OrganizerID)
+		assert.Equal(t, "closed", events[0].RaffleID)
+	})
+
+	t.Run("bulk_donations", func(t *testing.T) {
+		events = nil
+
+		prizeStorage := NewMockPrizeStorage(ctrl)
+		participantStorage := NewMockParticipantStorage(ctrl)
+
+		raffleStorage.EXPECT().Get("raffle_1").Return(&Raffle{ID: "raffle_1"}, nil)
+		raffleStorage.EXPECT().PrizeStorage("raffle_1").Return(prizeStorage).AnyTimes()
+		raffleStorage.EXPECT().ParticipantStorage("raffle_1").Return(participantStorage).AnyTimes()
+		prizeStorage.EXPECT().GetAll().Return([]Prize{{ID: "prize_1"}}, nil)
+		participantStorage.EXPECT().GetAll().Return([]Participant{{ID: "participant_1"}}, nil)
+		prizeStorage.EXPECT().CreateDonations(gomock.Any()).Return(nil)
+
+		setUUIDMock("donation_1")
+
+		_, err := rm.CreateDonations("raffle_1", &BulkDonationRequest{
+			Donations: []PrizeDonationRequest{
+				{PrizeID: "prize_1", DonationRequest: DonationRequest{ParticipantID: "participant_1", Amount: 100}},
+				{PrizeID: "prize_2", DonationRequest: DonationRequest{ParticipantID: "participant_1", Amount: 100}},
+			},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, events, 1, "rejected donations are not published")
+		assert.Equal(t, EventDonationCreated, events[0].Type)
+		assert.Equal(t, "prize_1", events[0].Data.(DonationCreated).PrizeID)
+		assert.Equal(t, 100, events[0].Data.(DonationCreated).Donation.Amount)
+	})
+
+	t.Run("prize_played", func(t *testing.T) {
+		events = nil
+
+		winner := func(p Participant) *PrizePlayResult {
+			return &PrizePlayResult{Winners: []PlayParticipant{{Participant: p, TotalDonation: 20}}}
+		}
+
+		pm := rm.prizeManager("raffle_1")
+		pm.publishPlayed(&Prize{ID: "prize_1"}, winner(Participant{
+			ID: "p1", Name: "Olena", Phone: "+380501234567", Note: "pays in cash", TelegramChatID: "123456", PublishName: true,
+		}))
+		pm.publishPlayed(&Prize{ID: "prize_2"}, winner(Participant{ID: "p2", Name: "Taras", Phone: "+380671234567"}))
+
+		require.Len(t, events, 2)
+		assert.Equal(t, PlayParticipant{Participant: Participant{ID: "p1", Name: "Olena", PublishName: true}, TotalDonation: 20},
+			events[0].Data.(PrizePlayed).Winner, "contacts are never published")
+		assert.Equal(t, PlayParticipant{Participant: Participant{ID: "p2"}, TotalDonation: 20},
+			events[1].Data.(PrizePlayed).Winner, "names are published with consent only")
+	})
+}
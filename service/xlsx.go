@@ -6,6 +6,7 @@ import (
 	"io"
 	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -20,6 +21,47 @@ func NewXLSX() *XLSXManager {
 	return &XLSXManager{File: excelize.NewFile()}
 }
 
+// ExportedDonation is a row of the donations sheet of a raffle export
+// with amounts both in the base currency and in the currency paid.
+type ExportedDonation struct {
+	ID               string
+	PrizeID          string
+	ParticipantID    string
+	Amount           int
+	Currency         string
+	OriginalAmount   string
+	OriginalCurrency string
+	ExchangeRate     string
+	Method           string
+	CreatedAt        time.Time
+}
+
+func exportDonations(donations []prizeDonation, baseCurrency string) []ExportedDonation {
+	rows := make([]ExportedDonation, 0, len(donations))
+
+	for _, d := range donations {
+		row := ExportedDonation{
+			ID:            d.ID,
+			PrizeID:       d.PrizeID,
+			ParticipantID: d.ParticipantID,
+			Amount:        d.Amount,
+			Currency:      baseCurrency,
+			ExchangeRate:  string(d.ExchangeRate),
+			Method:        string(methodOrDefault(d.Method)),
+			CreatedAt:     d.CreatedAt,
+		}
+
+		if d.Original != nil {
+			row.OriginalAmount = d.Original.Decimal()
+			row.OriginalCurrency = d.Original.Currency
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
 // Sheet is a type that represents xlsx sheet
 // that corresponds Go flat struct.
 type Sheet struct {
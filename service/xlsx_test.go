@@ -34,7 +34,7 @@ func TestExcelManagerWriteXLSX(t *testing.T) {
 		},
 		"slice of structs": {
 			collections: []interface{}{
-				&Raffle{"raffle_id", "organizer_id", "Raffle", "Wow wow wow", time.Now()},
+				&Raffle{"raffle_id", "organizer_id", "Raffle", "Wow wow wow", nil, nil, false, 0, "", nil, nil, "", nil, nil, nil, nil, nil, time.Now(), time.Now()},
 				Prize{
 					ID:          "prize_id",
 					Name:        "Super prize",
@@ -66,9 +66,9 @@ func TestExcelManagerWriteXLSX(t *testing.T) {
 					},
 				},
 				[]Participant{
-					{"participant_id_1", "Bob George", "323421341", "nope", time.Now()},
-					{"participant_id_2", "Mr Kitty", "123455", "mew mew", time.Now()},
-					{"participant_id_3", "Mr Cat", "123456", "mew mew", time.Now()},
+					{"participant_id_1", "Bob George", "323421341", "nope", false, "", false, nil, time.Now(), time.Now()},
+					{"participant_id_2", "Mr Kitty", "123455", "mew mew", false, "", false, nil, time.Now(), time.Now()},
+					{"participant_id_3", "Mr Cat", "123456", "mew mew", false, "", false, nil, time.Now(), time.Now()},
 				},
 			},
 			sheetIdx:    2,
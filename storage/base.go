@@ -3,7 +3,11 @@ package storage
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
+	"golang.org/x/exp/slices"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -14,21 +18,26 @@ import (
 
 // Storable is a type parameter constraint for all storable items.
 type Storable interface {
-	service.Raffle | service.Prize | service.Participant | service.Organizer | service.Donation
+	service.Raffle | service.Prize | service.Participant | service.Organizer | service.Donation |
+		service.IdempotentResponse | service.Payment | service.Shift | service.Webhook | service.Delivery |
+		service.QueuedNotification
 }
 
 // IDExtractor is a typed function that extracts an ID from the item it serves.
 type IDExtractor[Item Storable] func(*Item) string
 
 // StorageBase is a base with common functionality for all storages.
+// Items implementing service.Versioned get the update times of their documents as versions.
 type StorageBase[Item Storable] struct {
+	firestoreClient     *firestore.Client
 	collectionReference *firestore.CollectionRef
 	extractID           IDExtractor[Item]
 }
 
 // NewStorageBase creates a new StorageBase.
-func NewStorageBase[Item Storable](collectionReference *firestore.CollectionRef, idExtractor IDExtractor[Item]) *StorageBase[Item] {
+func NewStorageBase[Item Storable](firestoreClient *firestore.Client, collectionReference *firestore.CollectionRef, idExtractor IDExtractor[Item]) *StorageBase[Item] {
 	return &StorageBase[Item]{
+		firestoreClient:     firestoreClient,
 		collectionReference: collectionReference,
 		extractID:           idExtractor,
 	}
@@ -46,11 +55,13 @@ func (sb *StorageBase[Item]) Create(item *Item) error {
 		return service.ErrAlreadyExists
 	}
 
-	_, err = sb.collectionReference.Doc(id).Set(context.Background(), item)
+	res, err := sb.collectionReference.Doc(id).Set(context.Background(), item)
 	if err != nil {
 		return fmt.Errorf("create item: %w", err)
 	}
 
+	setVersion(item, res.UpdateTime)
+
 	return nil
 }
 
@@ -69,26 +80,70 @@ func (sb *StorageBase[Item]) Get(id string) (*Item, error) {
 		return nil, fmt.Errorf("decode item: %w", err)
 	}
 
+	setVersion(&i, doc.UpdateTime)
+
 	return &i, nil
 }
 
 // Update replaces an item with the given ID with the given item.
+// A versioned item is replaced only if it wasn't updated after its version was read,
+// otherwise service.ErrVersionMismatch is returned.
 func (sb *StorageBase[Item]) Update(item *Item) error {
-	id := sb.extractID(item)
-	exists, err := sb.Exists(id)
+	return sb.update(item, item, fieldUpdates(item))
+}
+
+// UpdateFields updates only the given fields of an item with the given ID
+// with the ones of the given item, see Update.
+func (sb *StorageBase[Item]) UpdateFields(item *Item, fields []string) error {
+	return sb.update(item, item, selectFields(fieldUpdates(item), fields))
+}
+
+// update updates the document of the stored item and sets the new version of the item.
+// The stored item is either the item itself or its copy with encrypted fields.
+// Nothing is written if there are no updates.
+func (sb *StorageBase[Item]) update(item, stored *Item, updates []firestore.Update) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	ref := sb.collectionReference.Doc(sb.extractID(stored))
+
+	res, err := ref.Update(context.Background(), updates, versionPreconditions(stored)...)
 	if err != nil {
-		return fmt.Errorf("check item exists: %w", err)
+		return fmt.Errorf("update item: %w", updateError(err))
 	}
 
-	if !exists {
-		return service.ErrNotFound
+	setVersion(item, res.UpdateTime)
+
+	return nil
+}
+
+// UpdateAll replaces the given items in a single transaction.
+// Nothing is updated if any of the items doesn't exist or has a stale version.
+// Versions of the items are read back, see readVersions.
+func (sb *StorageBase[Item]) UpdateAll(items []Item) error {
+	refs := make([]*firestore.DocumentRef, 0, len(items))
+	for i := range items {
+		refs = append(refs, sb.collectionReference.Doc(sb.extractID(&items[i])))
 	}
 
-	_, err = sb.collectionReference.Doc(id).Set(context.Background(), item)
+	err := sb.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		for i := range items {
+			if err := tx.Update(refs[i], fieldUpdates(&items[i]), versionPreconditions(&items[i])...); err != nil {
+				return fmt.Errorf("update item: %w", err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("create item: %w", err)
+		return fmt.Errorf("update items: %w", updateError(err))
 	}
 
+	readVersions(sb.firestoreClient, refs, func(i int, version time.Time) {
+		setVersion(&items[i], version)
+	})
+
 	return nil
 }
 
@@ -101,14 +156,21 @@ func (sb *StorageBase[Item]) GetAll() ([]Item, error) {
 
 	// TODO: ErrNotFound if no docs.
 
+	return decodeAll[Item](docs)
+}
+
+// decodeAll decodes the documents into items with their versions.
+func decodeAll[Item Storable](docs []*firestore.DocumentSnapshot) ([]Item, error) {
 	items := make([]Item, 0, len(docs))
 
 	for _, doc := range docs {
 		var item Item
-		if err = doc.DataTo(&item); err != nil {
+		if err := doc.DataTo(&item); err != nil {
 			return nil, fmt.Errorf("decode items: %w", err)
 		}
 
+		setVersion(&item, doc.UpdateTime)
+
 		items = append(items, item)
 	}
 
@@ -151,3 +213,114 @@ func (sb *StorageBase[Item]) Exists(id string) (bool, error) {
 func isNotFound(err error) bool {
 	return status.Code(err) == codes.NotFound
 }
+
+// fieldUpdates returns updates of all stored fields of the item except the skipped ones,
+// so updating a document with them replaces it like setting it does.
+// Stored items don't embed structs, so only their own fields are considered.
+func fieldUpdates(item any, skip ...string) []firestore.Update {
+	v := reflect.Indirect(reflect.ValueOf(item))
+	t := v.Type()
+
+	updates := make([]firestore.Update, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := storedFieldName(t.Field(i))
+		if !ok || slices.Contains(skip, name) {
+			continue
+		}
+
+		updates = append(updates, firestore.Update{
+			FieldPath: firestore.FieldPath{name},
+			Value:     v.Field(i).Interface(),
+		})
+	}
+
+	return updates
+}
+
+// selectFields returns the updates of the given fields.
+// Fields are named as the fields of the structs are.
+func selectFields(updates []firestore.Update, fields []string) []firestore.Update {
+	selected := make([]firestore.Update, 0, len(fields))
+
+	for _, u := range updates {
+		if slices.Contains(fields, u.FieldPath[0]) {
+			selected = append(selected, u)
+		}
+	}
+
+	return selected
+}
+
+// storedFieldName returns the name of the document field the struct field is stored in.
+func storedFieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(f.Tag.Get("firestore"), ",")
+
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	default:
+		return name, true
+	}
+}
+
+// versionPreconditions returns preconditions of an update of the item,
+// which fails if the document was updated after the version of the item was read.
+// Without preconditions the document is only required to exist.
+func versionPreconditions(item any) []firestore.Precondition {
+	v, ok := item.(service.Versioned)
+	if !ok || v.Version().IsZero() {
+		return nil
+	}
+
+	return []firestore.Precondition{firestore.LastUpdateTime(v.Version())}
+}
+
+// readVersions reads versions of the documents written by a transaction,
+// since the transaction doesn't tell them, and sets them with the function.
+// A write landing in between gives the version of that write.
+// Versions which can't be read are reset, so they are never stale.
+func readVersions(client *firestore.Client, refs []*firestore.DocumentRef, set func(i int, version time.Time)) {
+	docs, err := client.GetAll(context.Background(), refs)
+
+	for i := range refs {
+		if err != nil || !docs[i].Exists() {
+			set(i, time.Time{})
+			continue
+		}
+
+		set(i, docs[i].UpdateTime)
+	}
+}
+
+// readVersion reads the version of a single document, see readVersions.
+func readVersion(client *firestore.Client, ref *firestore.DocumentRef, item any) {
+	readVersions(client, []*firestore.DocumentRef{ref}, func(_ int, version time.Time) {
+		setVersion(item, version)
+	})
+}
+
+// setVersion sets the version of the item if it's versioned.
+func setVersion(item any, version time.Time) {
+	if v, ok := item.(service.Versioned); ok {
+		v.SetVersion(version)
+	}
+}
+
+// updateError converts errors of failed preconditions of updates to service errors.
+func updateError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return service.ErrNotFound
+	case codes.FailedPrecondition:
+		return service.ErrVersionMismatch
+	default:
+		return err
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+func TestFieldUpdates(t *testing.T) {
+	type item struct {
+		ID       string
+		Renamed  int    `firestore:"renamed,omitempty"`
+		Computed string `firestore:"-"`
+		Total    int
+		hidden   bool
+	}
+
+	i := &item{ID: "item_id_1", Renamed: 2, Computed: "computed", Total: 3, hidden: true}
+
+	t.Run("all stored fields", func(t *testing.T) {
+		require.Equal(t, []firestore.Update{
+			{FieldPath: firestore.FieldPath{"ID"}, Value: "item_id_1"},
+			{FieldPath: firestore.FieldPath{"renamed"}, Value: 2},
+			{FieldPath: firestore.FieldPath{"Total"}, Value: 3},
+		}, fieldUpdates(i))
+	})
+
+	t.Run("skipped fields", func(t *testing.T) {
+		require.Equal(t, []firestore.Update{
+			{FieldPath: firestore.FieldPath{"ID"}, Value: "item_id_1"},
+			{FieldPath: firestore.FieldPath{"renamed"}, Value: 2},
+		}, fieldUpdates(i, "Total"))
+	})
+}
+
+func TestSelectFields(t *testing.T) {
+	updates := []firestore.Update{
+		{FieldPath: firestore.FieldPath{"ID"}, Value: "item_id_1"},
+		{FieldPath: firestore.FieldPath{"Name"}, Value: "name"},
+		{FieldPath: firestore.FieldPath{"Total"}, Value: 3},
+	}
+
+	require.Equal(t, []firestore.Update{
+		{FieldPath: firestore.FieldPath{"Name"}, Value: "name"},
+		{FieldPath: firestore.FieldPath{"Total"}, Value: 3},
+	}, selectFields(updates, []string{"Total", "Name", "Unknown"}))
+	require.Empty(t, selectFields(updates, nil))
+}
+
+func TestVersionPreconditions(t *testing.T) {
+	version := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+
+	require.Nil(t, versionPreconditions(&service.Organizer{ID: "organizer_id_1"}))
+	require.Nil(t, versionPreconditions(&service.Prize{ID: "prize_id_1"}))
+	require.Equal(t,
+		[]firestore.Precondition{firestore.LastUpdateTime(version)},
+		versionPreconditions(&service.Prize{ID: "prize_id_1", UpdatedAt: version}),
+	)
+}
+
+func TestUpdateError(t *testing.T) {
+	require.ErrorIs(t, updateError(status.Error(codes.NotFound, "no document")), service.ErrNotFound)
+	require.ErrorIs(t, updateError(status.Error(codes.FailedPrecondition, "stale")), service.ErrVersionMismatch)
+
+	err := errors.New("test error")
+	require.Equal(t, err, updateError(err))
+}
+
+// unversioned resets the version of the item read from a storage,
+// so it can be compared with the written one regardless of when it was written.
+func unversioned[V service.Versioned](item V) V {
+	item.SetVersion(time.Time{})
+	return item
+}
+
+// unversionedAll resets versions of the items read from a storage, see unversioned.
+func unversionedAll[Item any, V interface {
+	*Item
+	service.Versioned
+}](items []Item) []Item {
+	for i := range items {
+		V(&items[i]).SetVersion(time.Time{})
+	}
+
+	return items
+}
@@ -1,29 +1,173 @@
 package storage
 
 import (
+	"context"
+	"fmt"
+
 	"cloud.google.com/go/firestore"
 
 	"github.com/kaznasho/yarmarok/service"
 )
 
 // FirestoreDonationStorage is a storage for donation based on Firestore.
+// Donations are written in transactions along with the raffle counters
+// and running totals of the prize.
 type FirestoreDonationStorage struct {
-	prizeID      string
-	prizeStorage service.PrizeStorage
+	prizeID         string
+	prizeStorage    service.PrizeStorage
+	raffleReference *firestore.DocumentRef
 	*StorageBase[service.Donation]
 }
 
 // NewFirestoreDonationStorage creates a new FirestoreDonationStorage.
-func NewFirestoreDonationStorage(client *firestore.CollectionRef, prizeStorage service.PrizeStorage, prizeID string) *FirestoreDonationStorage {
-	donationIDExtractor := IDExtractor[service.Donation](
-		func(p *service.Donation) string {
-			return p.ID
-		},
-	)
-
+func NewFirestoreDonationStorage(firestoreClient *firestore.Client, client *firestore.CollectionRef, prizeStorage service.PrizeStorage, prizeID string, raffle *firestore.DocumentRef) *FirestoreDonationStorage {
 	return &FirestoreDonationStorage{
-		prizeID:      prizeID,
-		prizeStorage: prizeStorage,
-		StorageBase:  NewStorageBase(client, donationIDExtractor),
+		prizeID:         prizeID,
+		prizeStorage:    prizeStorage,
+		raffleReference: raffle,
+		StorageBase:     NewStorageBase(firestoreClient, client, donationIDExtractor),
 	}
 }
+
+// NewFirestoreRaffleDonationStorage creates a new storage
+// for donations made to a raffle rather than to a single prize.
+func NewFirestoreRaffleDonationStorage(firestoreClient *firestore.Client, client *firestore.CollectionRef, raffle *firestore.DocumentRef) *FirestoreDonationStorage {
+	return NewFirestoreDonationStorage(firestoreClient, client, nil, "", raffle)
+}
+
+// Create creates a new donation and adds it to the totals.
+func (ds *FirestoreDonationStorage) Create(d *service.Donation) error {
+	ref := ds.collectionReference.Doc(d.ID)
+
+	err := ds.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		exists, err := docExists(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return service.ErrAlreadyExists
+		}
+
+		totals := newDonationTotals(ds.raffleReference)
+		totals.add(ds.prizeID, d)
+
+		if err := totals.read(tx); err != nil {
+			return err
+		}
+
+		if err := tx.Create(ref, d); err != nil {
+			return err
+		}
+
+		return totals.write(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("create donation: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the donation and moves its totals.
+// It fails with service.ErrVersionMismatch if the donation was updated after its version was read.
+func (ds *FirestoreDonationStorage) Update(d *service.Donation) error {
+	return ds.update(d, fieldUpdates(d))
+}
+
+// UpdateFields updates only the given fields of the donation, see Update.
+func (ds *FirestoreDonationStorage) UpdateFields(d *service.Donation, fields []string) error {
+	updates := selectFields(fieldUpdates(d), fields)
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return ds.update(d, updates)
+}
+
+// update applies the updates to the donation and moves its totals.
+func (ds *FirestoreDonationStorage) update(d *service.Donation, updates []firestore.Update) error {
+	ref := ds.collectionReference.Doc(d.ID)
+
+	err := ds.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		old, err := getDonation(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		totals := newDonationTotals(ds.raffleReference)
+		totals.remove(ds.prizeID, old)
+		totals.add(ds.prizeID, d)
+
+		if err := totals.read(tx); err != nil {
+			return err
+		}
+
+		if err := tx.Update(ref, updates, versionPreconditions(d)...); err != nil {
+			return err
+		}
+
+		return totals.write(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("update donation: %w", updateError(err))
+	}
+
+	readVersion(ds.firestoreClient, ref, d)
+
+	return nil
+}
+
+// Delete deletes the donation and subtracts it from the totals.
+func (ds *FirestoreDonationStorage) Delete(id string) error {
+	ref := ds.collectionReference.Doc(id)
+
+	err := ds.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		old, err := getDonation(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		totals := newDonationTotals(ds.raffleReference)
+		totals.remove(ds.prizeID, old)
+
+		if err := totals.read(tx); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+
+		return totals.write(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("delete donation: %w", err)
+	}
+
+	return nil
+}
+
+func getDonation(tx *firestore.Transaction, ref *firestore.DocumentRef) (*service.Donation, error) {
+	doc, err := tx.Get(ref)
+	if isNotFound(err) {
+		return nil, service.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get donation: %w", err)
+	}
+
+	var d service.Donation
+	if err := doc.DataTo(&d); err != nil {
+		return nil, fmt.Errorf("decode donation: %w", err)
+	}
+
+	return &d, nil
+}
+
+var donationIDExtractor = IDExtractor[service.Donation](
+	func(p *service.Donation) string {
+		return p.ID
+	},
+)
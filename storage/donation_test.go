@@ -25,7 +25,7 @@ func TestDonationStorage(t *testing.T) {
 	require.NoError(t, err)
 
 	raffle := service.Raffle{ID: "raffle_id_1"}
-	raffleStorage := NewFirestoreRaffleStorage(orgStorage.collectionReference.Doc(org.ID).Collection(raffleCollection), raffle.ID)
+	raffleStorage := NewFirestoreRaffleStorage(orgStorage.firestoreClient, orgStorage.collectionReference.Doc(org.ID).Collection(raffleCollection), raffle.ID)
 
 	err = raffleStorage.Create(&raffle)
 	require.NoError(t, err)
@@ -58,7 +58,7 @@ func TestDonationStorage(t *testing.T) {
 			t.Run("Get donation", func(t *testing.T) {
 				d2, err := donationStorage.Get(d.ID)
 				require.NoError(t, err)
-				require.Equal(t, d, d2)
+				require.Equal(t, d, unversioned(d2))
 			})
 
 			t.Run("Update donation", func(t *testing.T) {
@@ -67,7 +67,7 @@ func TestDonationStorage(t *testing.T) {
 
 				d2, err := donationStorage.Get(d.ID)
 				require.NoError(t, err)
-				require.Equal(t, d, d2)
+				require.Equal(t, d, unversioned(d2))
 
 				testDonations[i-1] = *d
 			})
@@ -75,7 +75,7 @@ func TestDonationStorage(t *testing.T) {
 			t.Run("Get all donations", func(t *testing.T) {
 				getDonations, err := donationStorage.GetAll()
 				require.NoError(t, err)
-				require.ElementsMatch(t, testDonations, getDonations)
+				require.ElementsMatch(t, testDonations, unversionedAll(getDonations))
 			})
 		}
 
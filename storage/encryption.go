@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks encrypted values, e.g. "enc:2024-05:<base64>".
+// Values without it were written before the encryption was enabled.
+const encryptedPrefix = "enc:"
+
+// indexKeySize is the minimal size of the blind index key.
+const indexKeySize = 32
+
+var (
+	// ErrInvalidKeyring is returned when the keys of the provider can't be used.
+	ErrInvalidKeyring = errors.New("invalid keyring")
+
+	// ErrUnknownKey is returned when a value is encrypted with a key missing in the keyring.
+	ErrUnknownKey = errors.New("unknown encryption key")
+)
+
+// FieldCipher encrypts fields of documents with AES-256-GCM.
+// Values are encrypted with the primary key of the keyring
+// and can be decrypted with any key of it, so the keys can be rotated.
+// Encrypted values can't be compared, so they are looked up by blind indexes.
+// A nil FieldCipher keeps values in plaintext.
+type FieldCipher struct {
+	primary  string
+	aeads    map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// NewFieldCipher creates a new FieldCipher with the keys of the provider.
+func NewFieldCipher(ctx context.Context, kp KeyProvider) (*FieldCipher, error) {
+	keyring, err := kp.Keyring(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get keyring: %w", err)
+	}
+
+	if _, ok := keyring.Keys[keyring.Primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q is missing", ErrInvalidKeyring, keyring.Primary)
+	}
+
+	if len(keyring.IndexKey) < indexKeySize {
+		return nil, fmt.Errorf("%w: index key should be at least %d bytes", ErrInvalidKeyring, indexKeySize)
+	}
+
+	c := &FieldCipher{
+		primary:  keyring.Primary,
+		aeads:    make(map[string]cipher.AEAD, len(keyring.Keys)),
+		indexKey: keyring.IndexKey,
+	}
+
+	for id, key := range keyring.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("%w: key ID %q should be non-empty and have no colons", ErrInvalidKeyring, id)
+		}
+
+		if len(key) != 32 {
+			return nil, fmt.Errorf("%w: key %q should be 32 bytes", ErrInvalidKeyring, id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("create cipher of key %q: %w", id, err)
+		}
+
+		c.aeads[id], err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("create GCM of key %q: %w", id, err)
+		}
+	}
+
+	return c, nil
+}
+
+// Encrypt encrypts the value with the primary key.
+// The associated data, e.g. the ID of the document,
+// is authenticated, so the value can't be moved to another document.
+// Empty values are kept empty.
+func (c *FieldCipher) Encrypt(value, associated string) (string, error) {
+	if c == nil || value == "" {
+		return value, nil
+	}
+
+	aead := c.aeads[c.primary]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(associated))
+
+	return encryptedPrefix + c.primary + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the value encrypted by Encrypt with the same associated data.
+// Values written before the encryption was enabled are returned as is.
+func (c *FieldCipher) Decrypt(value, associated string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+
+	id, data, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	if c == nil {
+		return "", fmt.Errorf("%w: encryption is not configured", ErrUnknownKey)
+	}
+
+	aead, ok := c.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(data)
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted value: %w", err)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, sealed, []byte(associated))
+	if err != nil {
+		return "", fmt.Errorf("decrypt value: %w", err)
+	}
+
+	return string(plain), nil
+}
+
+// IsCurrent reports whether the value doesn't need to be re-encrypted,
+// i.e. it's empty or encrypted with the primary key.
+func (c *FieldCipher) IsCurrent(value string) bool {
+	if c == nil || value == "" {
+		return true
+	}
+
+	return strings.HasPrefix(value, encryptedPrefix+c.primary+":")
+}
+
+// BlindIndex returns a keyed hash of the value,
+// which can be compared and used as a document ID without revealing the value.
+// The index key is never rotated, since all indexes would have to be rebuilt.
+func (c *FieldCipher) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/testinfra"
+	fsemulator "github.com/kaznasho/yarmarok/testinfra/firestore"
+)
+
+// staticKeys is a KeyProvider of a fixed keyring.
+type staticKeys Keyring
+
+func (k *staticKeys) Keyring(context.Context) (*Keyring, error) {
+	keyring := Keyring(*k)
+	return &keyring, nil
+}
+
+func testKeyring(primary string, ids ...string) *staticKeys {
+	keys := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte(id[:1]), 32)
+	}
+
+	return &staticKeys{Primary: primary, Keys: keys, IndexKey: bytes.Repeat([]byte("i"), 32)}
+}
+
+func newTestCipher(t *testing.T, primary string, ids ...string) *FieldCipher {
+	t.Helper()
+
+	c, err := NewFieldCipher(context.Background(), testKeyring(primary, ids...))
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestFieldCipher(t *testing.T) {
+	c := newTestCipher(t, "a", "a")
+
+	t.Run("round_trip", func(t *testing.T) {
+		encrypted, err := c.Encrypt("+380501234567", "participant_1")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(encrypted, "enc:a:"))
+		assert.NotContains(t, encrypted, "380501234567")
+		assert.True(t, c.IsCurrent(encrypted))
+
+		again, err := c.Encrypt("+380501234567", "participant_1")
+		require.NoError(t, err)
+		assert.NotEqual(t, encrypted, again, "nonces are random")
+
+		decrypted, err := c.Decrypt(encrypted, "participant_1")
+		require.NoError(t, err)
+		assert.Equal(t, "+380501234567", decrypted)
+
+		_, err = c.Decrypt(encrypted, "participant_2")
+		assert.Error(t, err, "value is bound to its document")
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		decrypted, err := c.Decrypt("+380501234567", "participant_1")
+		require.NoError(t, err)
+		assert.Equal(t, "+380501234567", decrypted)
+		assert.False(t, c.IsCurrent("+380501234567"))
+
+		empty, err := c.Encrypt("", "participant_1")
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+		assert.True(t, c.IsCurrent(empty))
+	})
+
+	t.Run("rotation", func(t *testing.T) {
+		encrypted, err := c.Encrypt("+380501234567", "participant_1")
+		require.NoError(t, err)
+
+		rotated := newTestCipher(t, "b", "a", "b")
+		assert.False(t, rotated.IsCurrent(encrypted))
+
+		decrypted, err := rotated.Decrypt(encrypted, "participant_1")
+		require.NoError(t, err)
+		assert.Equal(t, "+380501234567", decrypted)
+
+		retired := newTestCipher(t, "b", "b")
+		_, err = retired.Decrypt(encrypted, "participant_1")
+		assert.ErrorIs(t, err, ErrUnknownKey)
+
+		assert.Equal(t, c.BlindIndex("+380501234567"), rotated.BlindIndex("+380501234567"))
+		assert.NotEqual(t, c.BlindIndex("+380501234567"), c.BlindIndex("+380501234568"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var disabled *FieldCipher
+
+		value, err := disabled.Encrypt("+380501234567", "participant_1")
+		require.NoError(t, err)
+		assert.Equal(t, "+380501234567", value)
+
+		encrypted, err := c.Encrypt("+380501234567", "participant_1")
+		require.NoError(t, err)
+
+		_, err = disabled.Decrypt(encrypted, "participant_1")
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("invalid_keyring", func(t *testing.T) {
+		_, err := NewFieldCipher(context.Background(), testKeyring("b", "a"))
+		assert.ErrorIs(t, err, ErrInvalidKeyring)
+
+		short := testKeyring("a", "a")
+		short.Keys["a"] = []byte("short")
+		_, err = NewFieldCipher(context.Background(), short)
+		assert.ErrorIs(t, err, ErrInvalidKeyring)
+
+		noIndex := testKeyring("a", "a")
+		noIndex.IndexKey = nil
+		_, err = NewFieldCipher(context.Background(), noIndex)
+		assert.ErrorIs(t, err, ErrInvalidKeyring)
+	})
+}
+
+// reversingKMS "wraps" keys by reversing them.
+type reversingKMS struct{}
+
+func (reversingKMS) Decrypt(_ context.Context, ciphertext []byte) ([]byte, error) {
+	plain := make([]byte, len(ciphertext))
+	for i, b := range ciphertext {
+		plain[len(ciphertext)-1-i] = b
+	}
+
+	return plain, nil
+}
+
+func TestKeyProviders(t *testing.T) {
+	key := append(bytes.Repeat([]byte("k"), 31), 'x')
+	index := bytes.Repeat([]byte("i"), 32)
+
+	encode := base64.StdEncoding.EncodeToString
+
+	t.Run("local", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keyring.json")
+		keyring := fmt.Sprintf(`{"primary": "2024-05", "keys": {"2024-05": %q}, "indexKey": %q}`, encode(key), encode(index))
+		require.NoError(t, os.WriteFile(path, []byte(keyring), 0o600))
+
+		c, err := NewFieldCipher(context.Background(), NewLocalKeyProvider(path))
+		require.NoError(t, err)
+		assert.Equal(t, "2024-05", c.primary)
+
+		_, err = NewLocalKeyProvider(filepath.Join(t.TempDir(), "missing.json")).Keyring(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("kms", func(t *testing.T) {
+		wrapped, _ := reversingKMS{}.Decrypt(context.Background(), key)
+		keyring := fmt.Sprintf(`{"primary": "2024-05", "keys": {"2024-05": %q}, "indexKey": %q}`, encode(wrapped), encode(index))
+
+		got, err := NewKMSKeyProvider([]byte(keyring), reversingKMS{}).Keyring(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, key, got.Keys["2024-05"])
+		assert.Equal(t, index, got.IndexKey)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := NewKMSKeyProvider([]byte(`{"keys": {"1": "not base64"}}`), reversingKMS{}).Keyring(context.Background())
+		assert.ErrorIs(t, err, ErrInvalidKeyring)
+	})
+}
+
+func TestEncryptedParticipantStorage(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := fsemulator.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+	require.NoError(t, os.Create(&service.Organizer{ID: "organizer_id_1"}))
+
+	// The participant is created before the encryption is enabled.
+	plainRaffles := os.RaffleStorage("organizer_id_1")
+	require.NoError(t, plainRaffles.Create(&service.Raffle{ID: "raffle_id_1"}))
+
+	legacy := service.Participant{ID: "participant_id_1", Name: "Legacy", Phone: "+380501112233"}
+	require.NoError(t, plainRaffles.ParticipantStorage("raffle_id_1").Create(&legacy))
+
+	prize := service.Prize{ID: "prize_id_1", PlayResult: &service.PrizePlayResult{
+		Winners: []service.PlayParticipant{{Participant: legacy}},
+	}}
+	require.NoError(t, plainRaffles.PrizeStorage("raffle_id_1").Create(&prize))
+
+	// Events used to be published with contacts of winners.
+	payload, err := json.Marshal(service.Event{Type: service.EventPrizePlayed, Data: service.PrizePlayed{
+		Winner: service.PlayParticipant{Participant: legacy},
+	}})
+	require.NoError(t, err)
+
+	delivery := service.Delivery{ID: "delivery_id_1", EventType: service.EventPrizePlayed, Payload: string(payload)}
+	require.NoError(t, os.DeliveryStorage("organizer_id_1").Create(&delivery))
+
+	os.SetFieldCipher(newTestCipher(t, "a", "a"))
+
+	rs := os.raffleStorage("organizer_id_1")
+	ps := rs.participantStorage("raffle_id_1")
+
+	storedPhone := func(t *testing.T, id string) string {
+		doc, err := ps.collectionReference.Doc(id).Get(context.Background())
+		require.NoError(t, err)
+
+		phone, err := doc.DataAt("Phone")
+		require.NoError(t, err)
+
+		return phone.(string)
+	}
+
+	t.Run("legacy_phone_is_unique", func(t *testing.T) {
+		err := ps.Create(&service.Participant{ID: "participant_id_2", Phone: legacy.Phone})
+		require.ErrorIs(t, err, service.ErrAlreadyExists)
+
+		got, err := ps.Get(legacy.ID)
+		require.NoError(t, err)
+		assert.Equal(t, legacy.Phone, got.Phone)
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		p := service.Participant{ID: "participant_id_3", Phone: "+380504445566"}
+		require.NoError(t, ps.Create(&p))
+		assert.True(t, strings.HasPrefix(storedPhone(t, p.ID), "enc:a:"))
+
+		got, err := ps.Get(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, p.Phone, got.Phone)
+
+		err = ps.Create(&service.Participant{ID: "participant_id_4", Phone: "050 444 55 66"})
+		require.ErrorIs(t, err, service.ErrAlreadyExists)
+
+		_, err = ps.phoneReferences.Doc(p.Phone).Get(context.Background())
+		assert.True(t, isNotFound(err), "phone is not indexed in plaintext")
+	})
+
+	t.Run("rotate_keys", func(t *testing.T) {
+		os.SetFieldCipher(newTestCipher(t, "b", "a", "b"))
+
+		rotation, err := os.RotateKeys()
+		require.NoError(t, err)
+		assert.Equal(t, &service.KeyRotation{Key: "b", Participants: 2, Prizes: 1, Deliveries: 1}, rotation)
+
+		rs = os.raffleStorage("organizer_id_1")
+		ps = rs.participantStorage("raffle_id_1")
+
+		assert.True(t, strings.HasPrefix(storedPhone(t, legacy.ID), "enc:b:"))
+
+		_, err = ps.phoneReferences.Doc(legacy.Phone).Get(context.Background())
+		assert.True(t, isNotFound(err), "plaintext index is removed")
+
+		err = ps.Create(&service.Participant{ID: "participant_id_5", Phone: legacy.Phone})
+		require.ErrorIs(t, err, service.ErrAlreadyExists)
+
+		os.SetFieldCipher(newTestCipher(t, "b", "b"))
+
+		got, err := os.raffleStorage("organizer_id_1").prizeStorage("raffle_id_1").Get(prize.ID)
+		require.NoError(t, err)
+		assert.Equal(t, legacy.Phone, got.PlayResult.Winners[0].Participant.Phone)
+
+		redacted, err := os.DeliveryStorage("organizer_id_1").Get(delivery.ID)
+		require.NoError(t, err)
+		assert.NotContains(t, redacted.Payload, legacy.Phone)
+	})
+}
+
+var _ KeyProvider = (*staticKeys)(nil)
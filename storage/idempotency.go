@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+// FirestoreIdempotencyStorage is a storage for responses
+// to requests with idempotency keys based on Firestore.
+type FirestoreIdempotencyStorage struct {
+	*StorageBase[service.IdempotentResponse]
+}
+
+// NewFirestoreIdempotencyStorage creates a new FirestoreIdempotencyStorage.
+func NewFirestoreIdempotencyStorage(client *firestore.Client) *FirestoreIdempotencyStorage {
+	idExtractor := IDExtractor[service.IdempotentResponse](
+		func(r *service.IdempotentResponse) string {
+			return r.ID
+		},
+	)
+
+	return &FirestoreIdempotencyStorage{
+		StorageBase: NewStorageBase(client, client.Collection(idempotencyCollection), idExtractor),
+	}
+}
+
+// Claim stores the pending response in a transaction unless a response
+// which isn't expired is stored, so concurrent requests can't both claim it.
+func (is *FirestoreIdempotencyStorage) Claim(r *service.IdempotentResponse) (*service.IdempotentResponse, error) {
+	ref := is.collectionReference.Doc(r.ID)
+
+	var stored *service.IdempotentResponse
+
+	err := is.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		stored = nil
+
+		doc, err := tx.Get(ref)
+		switch {
+		case isNotFound(err):
+		case err != nil:
+			return err
+		default:
+			var existing service.IdempotentResponse
+			if err := doc.DataTo(&existing); err != nil {
+				return fmt.Errorf("decode response: %w", err)
+			}
+
+			if !existing.IsExpired(r.CreatedAt) {
+				stored = &existing
+				return nil
+			}
+		}
+
+		return tx.Set(ref, r)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claim response: %w", err)
+	}
+
+	return stored, nil
+}
+
+// Save creates or replaces a response.
+func (is *FirestoreIdempotencyStorage) Save(r *service.IdempotentResponse) error {
+	_, err := is.collectionReference.Doc(r.ID).Set(context.Background(), r)
+	if err != nil {
+		return fmt.Errorf("save response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/testinfra/firestore"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaznasho/yarmarok/testinfra"
+)
+
+func TestIdempotencyStorage(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := firestore.RunInstance(t)
+	require.NoError(t, err)
+
+	is := NewFirestoreIdempotencyStorage(firestoreInstance.Client())
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	response := &service.IdempotentResponse{
+		ID:          "key_1",
+		RequestHash: "hash_1",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	t.Run("save pending", func(t *testing.T) {
+		err := is.Save(response)
+		require.NoError(t, err)
+
+		stored, err := is.Get(response.ID)
+		require.NoError(t, err)
+		require.Equal(t, response, stored)
+	})
+
+	t.Run("save completed", func(t *testing.T) {
+		response.Completed = true
+		response.StatusCode = 200
+		response.Body = []byte(`{"id":"donation_id_1"}`)
+
+		err := is.Save(response)
+		require.NoError(t, err)
+
+		stored, err := is.Get(response.ID)
+		require.NoError(t, err)
+		require.Equal(t, response, stored)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := is.Delete(response.ID)
+		require.NoError(t, err)
+
+		_, err = is.Get(response.ID)
+		require.ErrorIs(t, err, service.ErrNotFound)
+	})
+
+	t.Run("claim", func(t *testing.T) {
+		claim := &service.IdempotentResponse{
+			ID:          "key_2",
+			RequestHash: "hash_2",
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(time.Hour),
+		}
+
+		stored, err := is.Claim(claim)
+		require.NoError(t, err)
+		require.Nil(t, stored)
+
+		retry := *claim
+		retry.RequestHash = "hash_3"
+
+		stored, err = is.Claim(&retry)
+		require.NoError(t, err)
+		require.Equal(t, claim, stored)
+
+		// Expired responses are claimed again.
+		retry.CreatedAt = now.Add(2 * time.Hour)
+		retry.ExpiresAt = now.Add(3 * time.Hour)
+
+		stored, err = is.Claim(&retry)
+		require.NoError(t, err)
+		require.Nil(t, stored)
+
+		stored, err = is.Get(claim.ID)
+		require.NoError(t, err)
+		require.Equal(t, &retry, stored)
+	})
+}
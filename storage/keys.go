@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"google.golang.org/api/cloudkms/v1"
+)
+
+// KeyProvider provides keys of encrypted fields.
+type KeyProvider interface {
+	Keyring(ctx context.Context) (*Keyring, error)
+}
+
+// Keyring is a set of keys of encrypted fields.
+type Keyring struct {
+	// Primary is the ID of the key new values are encrypted with.
+	Primary string
+	// Keys are AES-256 keys by their IDs. Keys replaced by a new primary one
+	// are kept until all values are re-encrypted, see FirestoreOrganizerStorage.RotateKeys.
+	Keys map[string][]byte
+	// IndexKey is the key of blind indexes.
+	IndexKey []byte
+}
+
+// keyringFile is the JSON representation of a keyring with base64 encoded keys, e.g.
+//
+//	{"primary": "2024-05", "keys": {"2024-05": "...", "2023-11": "..."}, "indexKey": "..."}
+type keyringFile struct {
+	Primary  string            `json:"primary"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"indexKey"`
+}
+
+// decodeKeyring decodes the keyring and unwraps its keys with the function.
+func decodeKeyring(data []byte, unwrap func(key []byte) ([]byte, error)) (*Keyring, error) {
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyring, err)
+	}
+
+	decode := func(name, encoded string) ([]byte, error) {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: decode %s: %s", ErrInvalidKeyring, name, err)
+		}
+
+		key, err = unwrap(key)
+		if err != nil {
+			return nil, fmt.Errorf("unwrap %s: %w", name, err)
+		}
+
+		return key, nil
+	}
+
+	keyring := &Keyring{
+		Primary: file.Primary,
+		Keys:    make(map[string][]byte, len(file.Keys)),
+	}
+
+	for id, encoded := range file.Keys {
+		key, err := decode(fmt.Sprintf("key %q", id), encoded)
+		if err != nil {
+			return nil, err
+		}
+
+		keyring.Keys[id] = key
+	}
+
+	var err error
+
+	keyring.IndexKey, err = decode("index key", file.IndexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyring, nil
+}
+
+// LocalKeyProvider reads plaintext keys from a keyring file.
+// It is meant for tests and local development.
+type LocalKeyProvider struct {
+	path string
+}
+
+// NewLocalKeyProvider creates a new LocalKeyProvider.
+func NewLocalKeyProvider(path string) *LocalKeyProvider {
+	return &LocalKeyProvider{path: path}
+}
+
+// Keyring reads the keyring file.
+func (p *LocalKeyProvider) Keyring(_ context.Context) (*Keyring, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("read keyring file: %w", err)
+	}
+
+	return decodeKeyring(data, func(key []byte) ([]byte, error) {
+		return key, nil
+	})
+}
+
+// KMS decrypts keys wrapped with a key of a key management service.
+type KMS interface {
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// KMSKeyProvider unwraps keys of a keyring encrypted with a KMS key,
+// so the keyring itself isn't secret.
+type KMSKeyProvider struct {
+	keyring []byte
+	kms     KMS
+}
+
+// NewKMSKeyProvider creates a new KMSKeyProvider of the keyring in JSON.
+func NewKMSKeyProvider(keyring []byte, kms KMS) *KMSKeyProvider {
+	return &KMSKeyProvider{keyring: keyring, kms: kms}
+}
+
+// Keyring decrypts the keys with the KMS.
+func (p *KMSKeyProvider) Keyring(ctx context.Context) (*Keyring, error) {
+	return decodeKeyring(p.keyring, func(key []byte) ([]byte, error) {
+		return p.kms.Decrypt(ctx, key)
+	})
+}
+
+// CloudKMS is a KMS based on a Google Cloud KMS key.
+type CloudKMS struct {
+	name string
+	keys *cloudkms.ProjectsLocationsKeyRingsCryptoKeysService
+}
+
+// NewCloudKMS creates a new CloudKMS of the key with the resource name, e.g.
+// "projects/p/locations/global/keyRings/r/cryptoKeys/k".
+// It uses the default credentials of the environment.
+func NewCloudKMS(ctx context.Context, name string) (*CloudKMS, error) {
+	svc, err := cloudkms.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create cloud kms service: %w", err)
+	}
+
+	return &CloudKMS{
+		name: name,
+		keys: cloudkms.NewProjectsLocationsKeyRingsCryptoKeysService(svc),
+	}, nil
+}
+
+// Decrypt decrypts the ciphertext with the key.
+func (k *CloudKMS) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	resp, err := k.keys.Decrypt(k.name, &cloudkms.DecryptRequest{
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("decrypt with cloud kms: %w", err)
+	}
+
+	plain, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("decode plaintext: %w", err)
+	}
+
+	return plain, nil
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+// FirestoreNotificationStorage is a storage for queued winner notifications based on Firestore.
+type FirestoreNotificationStorage struct {
+	*StorageBase[service.QueuedNotification]
+}
+
+// NewFirestoreNotificationStorage creates a new FirestoreNotificationStorage.
+func NewFirestoreNotificationStorage(firestoreClient *firestore.Client, collectionReference *firestore.CollectionRef) *FirestoreNotificationStorage {
+	notificationIDExtractor := IDExtractor[service.QueuedNotification](
+		func(n *service.QueuedNotification) string {
+			return n.ID
+		},
+	)
+
+	return &FirestoreNotificationStorage{
+		StorageBase: NewStorageBase(firestoreClient, collectionReference, notificationIDExtractor),
+	}
+}
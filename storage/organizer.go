@@ -1,24 +1,45 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/kaznasho/yarmarok/service"
 
 	"cloud.google.com/go/firestore"
+	"golang.org/x/exp/slices"
 )
 
 const (
 	organizerCollection   = "organizers"
 	raffleCollection      = "raffles"
 	participantCollection = "participants"
-	prizeCollection       = "prizes"
-	donationCollection    = "donations"
+	// participantPhoneCollection indexes participants of a raffle by normalized phone.
+	participantPhoneCollection = "participant_phones"
+	prizeCollection            = "prizes"
+	donationCollection         = "donations"
+	paymentCollection          = "payments"
+	shiftCollection            = "shifts"
+	idempotencyCollection      = "idempotency_keys"
+	webhookCollection          = "webhooks"
+	deliveryCollection         = "deliveries"
+	notificationCollection     = "notifications"
 )
 
+// ErrEncryptionDisabled is returned when keys are rotated without a field cipher.
+var ErrEncryptionDisabled = errors.New("encryption is not configured")
+
 // FirestoreOrganizerStorage is a storage for organizers based on Firestore.
 type FirestoreOrganizerStorage struct {
 	*StorageBase[service.Organizer]
+	// cipher encrypts phones of participants, see SetFieldCipher.
+	cipher *FieldCipher
 }
 
+var _ service.KeyRotator = (*FirestoreOrganizerStorage)(nil)
+
 // NewFirestoreOrganizerStorage creates a new FirestoreOrganizerStorage.
 func NewFirestoreOrganizerStorage(client *firestore.Client) *FirestoreOrganizerStorage {
 	idExtractor := IDExtractor[service.Organizer](
@@ -27,13 +48,209 @@ func NewFirestoreOrganizerStorage(client *firestore.Client) *FirestoreOrganizerS
 		},
 	)
 
-	base := NewStorageBase(client.Collection(organizerCollection), idExtractor)
+	base := NewStorageBase(client, client.Collection(organizerCollection), idExtractor)
 	return &FirestoreOrganizerStorage{
 		StorageBase: base,
 	}
 }
 
+// SetFieldCipher makes phones of participants encrypted with the cipher.
+// Phones written before are still read and get encrypted by RotateKeys.
+func (os *FirestoreOrganizerStorage) SetFieldCipher(c *FieldCipher) {
+	os.cipher = c
+}
+
 // RaffleStorage returns a storage for raffles.
 func (os *FirestoreOrganizerStorage) RaffleStorage(organizerID string) service.RaffleStorage {
-	return NewFirestoreRaffleStorage(os.collectionReference.Doc(organizerID).Collection(raffleCollection), organizerID)
+	return os.raffleStorage(organizerID)
+}
+
+func (os *FirestoreOrganizerStorage) raffleStorage(organizerID string) *FirestoreRaffleStorage {
+	rs := NewFirestoreRaffleStorage(os.firestoreClient, os.collectionReference.Doc(organizerID).Collection(raffleCollection), organizerID)
+	rs.cipher = os.cipher
+
+	return rs
+}
+
+// WebhookStorage returns a storage for webhooks.
+func (os *FirestoreOrganizerStorage) WebhookStorage(organizerID string) service.WebhookStorage {
+	return NewFirestoreWebhookStorage(os.firestoreClient, os.collectionReference.Doc(organizerID).Collection(webhookCollection))
+}
+
+// DeliveryStorage returns a storage for deliveries to webhooks.
+func (os *FirestoreOrganizerStorage) DeliveryStorage(organizerID string) service.DeliveryStorage {
+	return os.deliveryStorage(organizerID)
+}
+
+func (os *FirestoreOrganizerStorage) deliveryStorage(organizerID string) *FirestoreDeliveryStorage {
+	return NewFirestoreDeliveryStorage(os.firestoreClient, os.collectionReference.Doc(organizerID).Collection(deliveryCollection))
+}
+
+// NotificationStorage returns a storage for queued winner notifications.
+func (os *FirestoreOrganizerStorage) NotificationStorage(organizerID string) service.NotificationStorage {
+	return NewFirestoreNotificationStorage(os.firestoreClient, os.collectionReference.Doc(organizerID).Collection(notificationCollection))
+}
+
+// DuePrizes returns prizes of all organizers which are due by the moment,
+// see service.Prize.DueAt. Only references of the prizes are read.
+func (os *FirestoreOrganizerStorage) DuePrizes(now time.Time) ([]service.DuePrize, error) {
+	docs, err := os.firestoreClient.CollectionGroup(prizeCollection).
+		Where(dueAtField, "<=", now).
+		Select().
+		Documents(context.Background()).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get due prizes: %w", err)
+	}
+
+	due := make([]service.DuePrize, 0, len(docs))
+
+	for _, doc := range docs {
+		raffle := doc.Ref.Parent.Parent
+		due = append(due, service.DuePrize{
+			OrganizerID: raffle.Parent.Parent.ID,
+			RaffleID:    raffle.ID,
+			PrizeID:     doc.Ref.ID,
+		})
+	}
+
+	return due, nil
+}
+
+// ClosedRaffles returns raffles of all organizers which raffle.closed events
+// are due by the moment, see service.Raffle.CloseDueAt.
+func (os *FirestoreOrganizerStorage) ClosedRaffles(now time.Time) ([]service.Raffle, error) {
+	raffles, err := os.queryRaffles(closeDueAtField, now)
+	if err != nil {
+		return nil, fmt.Errorf("get closed raffles: %w", err)
+	}
+
+	return raffles, nil
+}
+
+// RetainedRaffles returns raffles of all organizers archived by the moment
+// which participants are not anonymized yet, see service.Raffle.RetainedSince.
+func (os *FirestoreOrganizerStorage) RetainedRaffles(archivedBy time.Time) ([]service.Raffle, error) {
+	raffles, err := os.queryRaffles(retainedSinceField, archivedBy)
+	if err != nil {
+		return nil, fmt.Errorf("get retained raffles: %w", err)
+	}
+
+	return raffles, nil
+}
+
+// queryRaffles returns raffles of all organizers which time field is set
+// and not after the moment.
+func (os *FirestoreOrganizerStorage) queryRaffles(field string, by time.Time) ([]service.Raffle, error) {
+	docs, err := os.firestoreClient.CollectionGroup(raffleCollection).
+		Where(field, "<=", by).
+		Documents(context.Background()).
+		GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	raffles, err := decodeAll[service.Raffle](docs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range raffles {
+		raffles[i].OrganizerID = docs[i].Ref.Parent.Parent.ID
+	}
+
+	return raffles, nil
+}
+
+// DueDeliveryOrganizers returns IDs of organizers which have pending
+// deliveries to webhooks due by the moment. Only references of the deliveries are read.
+func (os *FirestoreOrganizerStorage) DueDeliveryOrganizers(now time.Time) ([]string, error) {
+	docs, err := os.firestoreClient.CollectionGroup(deliveryCollection).
+		Where(nextAttemptAtField, "<=", now).
+		Select().
+		Documents(context.Background()).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get due deliveries: %w", err)
+	}
+
+	ids := make([]string, 0)
+
+	for _, doc := range docs {
+		id := doc.Ref.Parent.Parent.ID
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// QueuedNotifications returns queued winner notifications of all organizers.
+func (os *FirestoreOrganizerStorage) QueuedNotifications() ([]service.QueuedNotification, error) {
+	docs, err := os.firestoreClient.CollectionGroup(notificationCollection).
+		Documents(context.Background()).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get queued notifications: %w", err)
+	}
+
+	queued, err := decodeAll[service.QueuedNotification](docs)
+	if err != nil {
+		return nil, fmt.Errorf("get queued notifications: %w", err)
+	}
+
+	for i := range queued {
+		queued[i].OrganizerID = docs[i].Ref.Parent.Parent.ID
+	}
+
+	return queued, nil
+}
+
+// RotateKeys re-encrypts phones of participants of all raffles with the primary key
+// and removes contacts left in payloads of webhook deliveries.
+// It is meant to be run after a new primary key is added to the keyring.
+// The previous key can be removed from the keyring once it succeeds.
+func (os *FirestoreOrganizerStorage) RotateKeys() (*service.KeyRotation, error) {
+	if os.cipher == nil {
+		return nil, ErrEncryptionDisabled
+	}
+
+	organizers, err := os.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get all organizers: %w", err)
+	}
+
+	rotation := &service.KeyRotation{Key: os.cipher.primary}
+
+	for _, organizer := range organizers {
+		deliveries, err := os.deliveryStorage(organizer.ID).redactContacts()
+		rotation.Deliveries += deliveries
+		if err != nil {
+			return rotation, fmt.Errorf("redact deliveries of organizer %q: %w", organizer.ID, err)
+		}
+
+		rs := os.raffleStorage(organizer.ID)
+
+		raffles, err := rs.GetAll()
+		if err != nil {
+			return rotation, fmt.Errorf("get raffles of organizer %q: %w", organizer.ID, err)
+		}
+
+		for _, raffle := range raffles {
+			participants, err := rs.participantStorage(raffle.ID).reencrypt()
+			rotation.Participants += participants
+			if err != nil {
+				return rotation, fmt.Errorf("re-encrypt participants of raffle %q: %w", raffle.ID, err)
+			}
+
+			prizes, err := rs.prizeStorage(raffle.ID).reencrypt()
+			rotation.Prizes += prizes
+			if err != nil {
+				return rotation, fmt.Errorf("re-encrypt prizes of raffle %q: %w", raffle.ID, err)
+			}
+		}
+	}
+
+	return rotation, nil
 }
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"testing"
+	"time"
 
 	"github.com/kaznasho/yarmarok/service"
 	"github.com/kaznasho/yarmarok/testinfra/firestore"
@@ -45,3 +46,162 @@ func TestCreateOrganizer(t *testing.T) {
 }
 
 var _ service.OrganizerStorage = &FirestoreOrganizerStorage{}
+
+func TestDuePrizes(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := firestore.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	now := time.Now().UTC()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	raffleStorage := os.RaffleStorage("organizer_id_1")
+	require.NoError(t, raffleStorage.Create(&service.Raffle{ID: "raffle_id_1"}))
+
+	prizeStorage := raffleStorage.PrizeStorage("raffle_id_1")
+	prizes := []service.Prize{
+		{ID: "due", DrawAt: &past},
+		{ID: "not_yet", DrawAt: &future},
+		{ID: "unscheduled"},
+		{ID: "played", DrawAt: &past, PlayResult: &service.PrizePlayResult{}},
+	}
+
+	for i := range prizes {
+		require.NoError(t, prizeStorage.Create(&prizes[i]))
+	}
+
+	expected := service.DuePrize{OrganizerID: "organizer_id_1", RaffleID: "raffle_id_1", PrizeID: "due"}
+
+	t.Run("draw time", func(t *testing.T) {
+		due, err := os.DuePrizes(now)
+		require.NoError(t, err)
+		assert.Equal(t, []service.DuePrize{expected}, due)
+	})
+
+	t.Run("played", func(t *testing.T) {
+		prizes[0].PlayResult = &service.PrizePlayResult{}
+		require.NoError(t, prizeStorage.UpdateFields(&prizes[0], []string{"PlayResult"}))
+
+		due, err := os.DuePrizes(now)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+	})
+}
+
+func TestScheduledQueries(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := firestore.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	now := time.Now().UTC()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	t.Run("closed raffles", func(t *testing.T) {
+		raffleStorage := os.RaffleStorage("organizer_id_1")
+		raffles := []service.Raffle{
+			{ID: "ended", EndsAt: &past},
+			{ID: "archived", ArchivedAt: &past},
+			{ID: "open", EndsAt: &future},
+			{ID: "published", EndsAt: &past, ClosedPublishedAt: &past},
+		}
+
+		for i := range raffles {
+			require.NoError(t, raffleStorage.Create(&raffles[i]))
+		}
+
+		closed, err := os.ClosedRaffles(now)
+		require.NoError(t, err)
+
+		ids := make([]string, 0, len(closed))
+		for _, r := range closed {
+			assert.Equal(t, "organizer_id_1", r.OrganizerID)
+			ids = append(ids, r.ID)
+		}
+		assert.ElementsMatch(t, []string{"ended", "archived"}, ids)
+
+		raffles[0].ClosedPublishedAt = &now
+		require.NoError(t, raffleStorage.Update(&raffles[0]))
+
+		closed, err = os.ClosedRaffles(now)
+		require.NoError(t, err)
+		require.Len(t, closed, 1)
+		assert.Equal(t, "archived", closed[0].ID)
+	})
+
+	t.Run("retained raffles", func(t *testing.T) {
+		raffleStorage := os.RaffleStorage("organizer_id_2")
+		raffles := []service.Raffle{
+			{ID: "expired", ArchivedAt: &past},
+			{ID: "recent", ArchivedAt: &now},
+			{ID: "anonymized", ArchivedAt: &past, AnonymizedAt: &past},
+		}
+
+		for i := range raffles {
+			require.NoError(t, raffleStorage.Create(&raffles[i]))
+		}
+
+		retained, err := os.RetainedRaffles(past)
+		require.NoError(t, err)
+		require.Len(t, retained, 1)
+		assert.Equal(t, "expired", retained[0].ID)
+		assert.Equal(t, "organizer_id_2", retained[0].OrganizerID)
+	})
+
+	t.Run("due deliveries", func(t *testing.T) {
+		deliveries := map[string]service.Delivery{
+			"organizer_id_1": {ID: "due_1", NextAttemptAt: &past},
+			"organizer_id_2": {ID: "not_yet", NextAttemptAt: &future},
+			"organizer_id_3": {ID: "succeeded"},
+		}
+
+		for organizerID, d := range deliveries {
+			d := d
+			require.NoError(t, os.DeliveryStorage(organizerID).Create(&d))
+		}
+
+		ids, err := os.DueDeliveryOrganizers(now)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"organizer_id_1"}, ids)
+	})
+}
+
+func TestQueuedNotifications(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := firestore.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	queued := service.QueuedNotification{
+		ID:            "notification_id_1",
+		RaffleID:      "raffle_id_1",
+		PrizeID:       "prize_id_1",
+		PrizeName:     "Bicycle",
+		ParticipantID: "participant_id_1",
+		CreatedAt:     at,
+	}
+
+	require.NoError(t, os.NotificationStorage("organizer_id_1").Create(&queued))
+
+	notifications, err := os.QueuedNotifications()
+	require.NoError(t, err)
+
+	queued.OrganizerID = "organizer_id_1"
+	assert.Equal(t, []service.QueuedNotification{queued}, notifications)
+
+	require.NoError(t, os.NotificationStorage("organizer_id_1").Delete(queued.ID))
+
+	notifications, err = os.QueuedNotifications()
+	require.NoError(t, err)
+	assert.Empty(t, notifications)
+}
@@ -1,19 +1,33 @@
 package storage
 
 import (
+	"context"
+	"fmt"
+
 	"cloud.google.com/go/firestore"
 
 	"github.com/kaznasho/yarmarok/service"
 )
 
 // FirestoreParticipantStorage is a storage for raffles based on Firestore.
+// It keeps an index document per normalized phone,
+// so that a phone can't be used by several participants of the raffle.
+// If phones are encrypted, the index documents are named by blind indexes of the phones.
 type FirestoreParticipantStorage struct {
-	raffleID string
+	raffleID        string
+	phoneReferences *firestore.CollectionRef
+	// cipher encrypts phones. They are kept in plaintext if it's nil.
+	cipher *FieldCipher
 	*StorageBase[service.Participant]
 }
 
+// phoneIndex refers to the participant the phone belongs to.
+type phoneIndex struct {
+	ParticipantID string
+}
+
 // NewFirestoreParticipantStorage creates a new FirestoreParticipantStorage.
-func NewFirestoreParticipantStorage(client *firestore.CollectionRef, raffleID string) *FirestoreParticipantStorage {
+func NewFirestoreParticipantStorage(firestoreClient *firestore.Client, client *firestore.CollectionRef, raffleID string) *FirestoreParticipantStorage {
 	participantIDExtractor := IDExtractor[service.Participant](
 		func(p *service.Participant) string {
 			return p.ID
@@ -21,7 +35,513 @@ func NewFirestoreParticipantStorage(client *firestore.CollectionRef, raffleID st
 	)
 
 	return &FirestoreParticipantStorage{
-		raffleID:    raffleID,
-		StorageBase: NewStorageBase(client, participantIDExtractor),
+		raffleID:        raffleID,
+		phoneReferences: client.Parent.Collection(participantPhoneCollection),
+		StorageBase:     NewStorageBase(firestoreClient, client, participantIDExtractor),
+	}
+}
+
+// Create creates a new participant.
+// It returns service.AlreadyExistsError with the ID of the participant
+// if the phone is already used in the raffle.
+func (ps *FirestoreParticipantStorage) Create(p *service.Participant) error {
+	ref := ps.collectionReference.Doc(p.ID)
+	phoneRefs := ps.phoneRefs(p.Phone)
+
+	stored, err := ps.encrypt(p)
+	if err != nil {
+		return fmt.Errorf("create participant: %w", err)
+	}
+
+	err = ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		exists, err := docExists(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return service.ErrAlreadyExists
+		}
+
+		if err := ps.checkPhoneIsFree(tx, phoneRefs, p.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Create(ref, stored); err != nil {
+			return err
+		}
+
+		return ps.setPhone(tx, phoneRefs, p.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("create participant: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the participant with the decrypted phone.
+func (ps *FirestoreParticipantStorage) Get(id string) (*service.Participant, error) {
+	p, err := ps.StorageBase.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ps.decrypt(p); err != nil {
+		return nil, fmt.Errorf("get participant: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetAll returns all participants with the decrypted phones.
+func (ps *FirestoreParticipantStorage) GetAll() ([]service.Participant, error) {
+	participants, err := ps.StorageBase.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range participants {
+		if err := ps.decrypt(&participants[i]); err != nil {
+			return nil, fmt.Errorf("get all participants: %w", err)
+		}
+	}
+
+	return participants, nil
+}
+
+// Update replaces the participant and moves the phone index if the phone has changed.
+// Anonymized participants have no phone, so their index is just released.
+// It fails with service.ErrVersionMismatch if the participant was updated after its version was read.
+func (ps *FirestoreParticipantStorage) Update(p *service.Participant) error {
+	return ps.update(p, nil)
+}
+
+// UpdateFields updates only the given fields of the participant, see Update.
+func (ps *FirestoreParticipantStorage) UpdateFields(p *service.Participant, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return ps.update(p, fields)
+}
+
+// update updates the given fields of the participant or all of them if fields is nil.
+func (ps *FirestoreParticipantStorage) update(p *service.Participant, fields []string) error {
+	ref := ps.collectionReference.Doc(p.ID)
+	phoneRefs := ps.phoneRefs(p.Phone)
+
+	stored, err := ps.encrypt(p)
+	if err != nil {
+		return fmt.Errorf("update participant: %w", err)
+	}
+
+	err = ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		old, err := ps.getParticipant(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		oldPhoneRefs := ps.phoneRefs(old.Phone)
+
+		switch {
+		case service.NormalizePhone(old.Phone) != service.NormalizePhone(p.Phone):
+			if err := ps.checkPhoneIsFree(tx, phoneRefs, p.ID); err != nil {
+				return err
+			}
+
+			if err := ps.deletePhone(tx, oldPhoneRefs, p.ID); err != nil {
+				return err
+			}
+		case len(oldPhoneRefs) > 1:
+			// The phone may still be indexed in plaintext.
+			if err := ps.deletePhone(tx, oldPhoneRefs[1:], p.ID); err != nil {
+				return err
+			}
+		}
+
+		updates := fieldUpdates(stored)
+		if fields != nil {
+			updates = selectFields(updates, fields)
+		}
+
+		if err := tx.Update(ref, updates, versionPreconditions(stored)...); err != nil {
+			return err
+		}
+
+		return ps.setPhone(tx, phoneRefs, p.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("update participant: %w", updateError(err))
+	}
+
+	readVersion(ps.firestoreClient, ref, p)
+
+	return nil
+}
+
+// Delete deletes the participant and releases its phone.
+func (ps *FirestoreParticipantStorage) Delete(id string) error {
+	ref := ps.collectionReference.Doc(id)
+
+	err := ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		p, err := ps.getParticipant(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		if err := ps.deletePhone(tx, ps.phoneRefs(p.Phone), id); err != nil {
+			return err
+		}
+
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return fmt.Errorf("delete participant: %w", err)
 	}
+
+	return nil
+}
+
+// participantIDField is the field of donations and payments
+// referring to the participant who made them.
+const participantIDField = "ParticipantID"
+
+// Merge reassigns donations and payments of the duplicates to the participant
+// and deletes the duplicates along with their phones in a single transaction,
+// which moves the totals as well.
+// It fails with service.ErrShiftClosed if any of the donations or payments
+// is locked by a closed shift, and with service.ErrVersionMismatch
+// if any of them was updated after its version was read.
+func (ps *FirestoreParticipantStorage) Merge(m *service.ParticipantMerge) error {
+	raffleRef := ps.collectionReference.Parent
+
+	err := ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := getParticipant(tx, ps.collectionReference.Doc(m.ParticipantID)); err != nil {
+			return err
+		}
+
+		// Everything is read before anything is written,
+		// since transactions don't allow reads after writes.
+		phoneRefs := make([]*firestore.DocumentRef, 0, len(m.DuplicateIDs))
+		for _, id := range m.DuplicateIDs {
+			p, err := ps.getParticipant(tx, ps.collectionReference.Doc(id))
+			if err != nil {
+				return err
+			}
+
+			owned, err := ps.ownedPhones(tx, ps.phoneRefs(p.Phone), id)
+			if err != nil {
+				return err
+			}
+
+			phoneRefs = append(phoneRefs, owned...)
+		}
+
+		totals := newDonationTotals(raffleRef)
+		moves := make([]participantMove, 0)
+
+		for prizeID, donations := range m.Donations {
+			for i := range donations {
+				ref := ps.donationReference(prizeID, donations[i].ID)
+
+				old, err := getDonation(tx, ref)
+				if err != nil {
+					return err
+				}
+
+				if old.ShiftID != "" {
+					return service.ErrShiftClosed
+				}
+
+				moved := *old
+				moved.ParticipantID = m.ParticipantID
+
+				totals.remove(prizeID, old)
+				totals.add(prizeID, &moved)
+				moves = append(moves, participantMove{reference: ref, preconditions: versionPreconditions(&donations[i])})
+			}
+		}
+
+		for i := range m.Payments {
+			ref := raffleRef.Collection(paymentCollection).Doc(m.Payments[i].ID)
+
+			old, err := getPayment(tx, ref)
+			if err != nil {
+				return err
+			}
+
+			if old.ShiftID != "" {
+				return service.ErrShiftClosed
+			}
+
+			moved := *old
+			moved.ParticipantID = m.ParticipantID
+
+			for prizeID, donations := range old.Donations() {
+				for j := range donations {
+					totals.remove(prizeID, &donations[j])
+				}
+			}
+
+			for prizeID, donations := range moved.Donations() {
+				for j := range donations {
+					totals.add(prizeID, &donations[j])
+					moves = append(moves, participantMove{reference: ps.donationReference(prizeID, donations[j].ID)})
+				}
+			}
+
+			moves = append(moves, participantMove{reference: ref, preconditions: versionPreconditions(&m.Payments[i])})
+		}
+
+		if err := totals.read(tx); err != nil {
+			return err
+		}
+
+		for _, move := range moves {
+			updates := []firestore.Update{{Path: participantIDField, Value: m.ParticipantID}}
+			if err := tx.Update(move.reference, updates, move.preconditions...); err != nil {
+				return err
+			}
+		}
+
+		for _, phoneRef := range phoneRefs {
+			if err := tx.Delete(phoneRef); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range m.DuplicateIDs {
+			if err := tx.Delete(ps.collectionReference.Doc(id)); err != nil {
+				return err
+			}
+		}
+
+		return totals.write(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("merge participants: %w", updateError(err))
+	}
+
+	return nil
+}
+
+// participantMove is a document to be reassigned to another participant.
+type participantMove struct {
+	reference     *firestore.DocumentRef
+	preconditions []firestore.Precondition
+}
+
+// donationReference returns a reference to the donation made to the prize
+// or to the raffle if the prize ID is empty.
+func (ps *FirestoreParticipantStorage) donationReference(prizeID, donationID string) *firestore.DocumentRef {
+	raffleRef := ps.collectionReference.Parent
+	if prizeID == "" {
+		return raffleRef.Collection(donationCollection).Doc(donationID)
+	}
+
+	return raffleRef.Collection(prizeCollection).Doc(prizeID).Collection(donationCollection).Doc(donationID)
+}
+
+// reencrypt re-encrypts phones which are in plaintext or encrypted
+// with a key other than the primary one, and moves their indexes.
+// It returns the number of re-encrypted participants.
+func (ps *FirestoreParticipantStorage) reencrypt() (int, error) {
+	participants, err := ps.StorageBase.GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	reencrypted := 0
+
+	for i := range participants {
+		p := &participants[i]
+		if ps.cipher.IsCurrent(p.Phone) {
+			continue
+		}
+
+		if err := ps.decrypt(p); err != nil {
+			return reencrypted, fmt.Errorf("participant %q: %w", p.ID, err)
+		}
+
+		if err := ps.Update(p); err != nil {
+			return reencrypted, err
+		}
+
+		reencrypted++
+	}
+
+	return reencrypted, nil
+}
+
+// encrypt returns a copy of the participant with the encrypted phone.
+func (ps *FirestoreParticipantStorage) encrypt(p *service.Participant) (*service.Participant, error) {
+	stored := *p
+
+	var err error
+
+	stored.Phone, err = ps.cipher.Encrypt(p.Phone, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt phone: %w", err)
+	}
+
+	return &stored, nil
+}
+
+// decrypt decrypts the phone of the participant.
+func (ps *FirestoreParticipantStorage) decrypt(p *service.Participant) error {
+	var err error
+
+	p.Phone, err = ps.cipher.Decrypt(p.Phone, p.ID)
+	if err != nil {
+		return fmt.Errorf("decrypt phone: %w", err)
+	}
+
+	return nil
+}
+
+// phoneRefs returns references to the index documents of the phone:
+// the blind index if phones are encrypted followed by the plaintext one,
+// which was used before the encryption was enabled.
+// It returns nil for an empty phone.
+func (ps *FirestoreParticipantStorage) phoneRefs(phone string) []*firestore.DocumentRef {
+	phone = service.NormalizePhone(phone)
+	if phone == "" {
+		return nil
+	}
+
+	if ps.cipher == nil {
+		return []*firestore.DocumentRef{ps.phoneReferences.Doc(phone)}
+	}
+
+	return []*firestore.DocumentRef{
+		ps.phoneReferences.Doc(ps.cipher.BlindIndex(phone)),
+		ps.phoneReferences.Doc(phone),
+	}
+}
+
+// setPhone indexes the phone by the first of its references.
+func (ps *FirestoreParticipantStorage) setPhone(tx *firestore.Transaction, phoneRefs []*firestore.DocumentRef, participantID string) error {
+	if len(phoneRefs) == 0 {
+		return nil
+	}
+
+	return tx.Set(phoneRefs[0], phoneIndex{ParticipantID: participantID})
+}
+
+// checkPhoneIsFree returns service.AlreadyExistsError
+// if the phone belongs to another participant.
+func (ps *FirestoreParticipantStorage) checkPhoneIsFree(tx *firestore.Transaction, phoneRefs []*firestore.DocumentRef, participantID string) error {
+	for _, phoneRef := range phoneRefs {
+		index, err := getPhoneIndex(tx, phoneRef)
+		if err != nil {
+			return err
+		}
+
+		if index != nil && index.ParticipantID != participantID {
+			return &service.AlreadyExistsError{ID: index.ParticipantID}
+		}
+	}
+
+	return nil
+}
+
+// deletePhone deletes the phone indexes which belong to the participant.
+// Participants created before phones were indexed may have none.
+func (ps *FirestoreParticipantStorage) deletePhone(tx *firestore.Transaction, phoneRefs []*firestore.DocumentRef, participantID string) error {
+	// All the indexes are read before any of them is deleted,
+	// since transactions don't allow reads after writes.
+	owned, err := ps.ownedPhones(tx, phoneRefs, participantID)
+	if err != nil {
+		return err
+	}
+
+	for _, phoneRef := range owned {
+		if err := tx.Delete(phoneRef); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ownedPhones returns the phone indexes which belong to the participant.
+func (ps *FirestoreParticipantStorage) ownedPhones(tx *firestore.Transaction, phoneRefs []*firestore.DocumentRef, participantID string) ([]*firestore.DocumentRef, error) {
+	owned := make([]*firestore.DocumentRef, 0, len(phoneRefs))
+
+	for _, phoneRef := range phoneRefs {
+		index, err := getPhoneIndex(tx, phoneRef)
+		if err != nil {
+			return nil, err
+		}
+
+		if index != nil && index.ParticipantID == participantID {
+			owned = append(owned, phoneRef)
+		}
+	}
+
+	return owned, nil
+}
+
+// getParticipant reads the participant in the transaction and decrypts its phone.
+func (ps *FirestoreParticipantStorage) getParticipant(tx *firestore.Transaction, ref *firestore.DocumentRef) (*service.Participant, error) {
+	p, err := getParticipant(tx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ps.decrypt(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func getParticipant(tx *firestore.Transaction, ref *firestore.DocumentRef) (*service.Participant, error) {
+	doc, err := tx.Get(ref)
+	if isNotFound(err) {
+		return nil, service.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get participant: %w", err)
+	}
+
+	var p service.Participant
+	if err := doc.DataTo(&p); err != nil {
+		return nil, fmt.Errorf("decode participant: %w", err)
+	}
+
+	return &p, nil
+}
+
+func getPhoneIndex(tx *firestore.Transaction, ref *firestore.DocumentRef) (*phoneIndex, error) {
+	doc, err := tx.Get(ref)
+	if isNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get phone index: %w", err)
+	}
+
+	var index phoneIndex
+	if err := doc.DataTo(&index); err != nil {
+		return nil, fmt.Errorf("decode phone index: %w", err)
+	}
+
+	return &index, nil
+}
+
+func docExists(tx *firestore.Transaction, ref *firestore.DocumentRef) (bool, error) {
+	_, err := tx.Get(ref)
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("check item exists: %w", err)
+	}
+
+	return true, nil
 }
@@ -24,7 +24,7 @@ func TestParticipantStorage(t *testing.T) {
 	require.NoError(t, err)
 
 	raf := service.Raffle{ID: "raffle_id_1"}
-	rs := NewFirestoreRaffleStorage(os.collectionReference.Doc(org.ID).Collection(raffleCollection), raf.ID)
+	rs := NewFirestoreRaffleStorage(os.firestoreClient, os.collectionReference.Doc(org.ID).Collection(raffleCollection), raf.ID)
 
 	err = rs.Create(&raf)
 	require.NoError(t, err)
@@ -51,7 +51,7 @@ func TestParticipantStorage(t *testing.T) {
 			t.Run(fmt.Sprintf("Get participant %d", i), func(t *testing.T) {
 				p2, err := ps.Get(p.ID)
 				require.NoError(t, err)
-				require.Equal(t, &p, p2)
+				require.Equal(t, &p, unversioned(p2))
 			})
 
 			t.Run(fmt.Sprintf("Update participant %d", i), func(t *testing.T) {
@@ -61,7 +61,7 @@ func TestParticipantStorage(t *testing.T) {
 
 				p2, err := ps.Get(p.ID)
 				require.NoError(t, err)
-				require.Equal(t, &p, p2)
+				require.Equal(t, &p, unversioned(p2))
 
 				created[i-1] = p
 			})
@@ -69,7 +69,7 @@ func TestParticipantStorage(t *testing.T) {
 			t.Run("Get all participants", func(t *testing.T) {
 				participants, err := ps.GetAll()
 				require.NoError(t, err)
-				require.ElementsMatch(t, created, participants)
+				require.ElementsMatch(t, created, unversionedAll(participants))
 			})
 		}
 
@@ -79,6 +79,116 @@ func TestParticipantStorage(t *testing.T) {
 			require.Nil(t, resp)
 		})
 	})
+
+	t.Run("Unique phone", func(t *testing.T) {
+		p := service.Participant{ID: "participant_id_phone_1", Name: "Phone 1", Phone: "+380501112233"}
+		require.NoError(t, ps.Create(&p))
+
+		t.Run("Create with the same phone", func(t *testing.T) {
+			duplicate := service.Participant{ID: "participant_id_phone_2", Name: "Phone 2", Phone: "050 111 22 33"}
+
+			err := ps.Create(&duplicate)
+			require.ErrorIs(t, err, service.ErrAlreadyExists)
+
+			var alreadyExists *service.AlreadyExistsError
+			require.ErrorAs(t, err, &alreadyExists)
+			require.Equal(t, p.ID, alreadyExists.ID)
+
+			_, err = ps.Get(duplicate.ID)
+			require.ErrorIs(t, err, service.ErrNotFound)
+		})
+
+		t.Run("Update to the used phone", func(t *testing.T) {
+			other := service.Participant{ID: "participant_id_phone_3", Name: "Phone 3", Phone: "+380504445566"}
+			require.NoError(t, ps.Create(&other))
+
+			other.Phone = p.Phone
+			err := ps.Update(&other)
+			require.ErrorIs(t, err, service.ErrAlreadyExists)
+		})
+
+		t.Run("Phone is released on update", func(t *testing.T) {
+			p.Phone = "+380507778899"
+			require.NoError(t, ps.Update(&p))
+
+			reused := service.Participant{ID: "participant_id_phone_4", Name: "Phone 4", Phone: "+380501112233"}
+			require.NoError(t, ps.Create(&reused))
+		})
+
+		t.Run("Phone is released on delete", func(t *testing.T) {
+			require.NoError(t, ps.Delete(p.ID))
+
+			reused := service.Participant{ID: "participant_id_phone_5", Name: "Phone 5", Phone: p.Phone}
+			require.NoError(t, ps.Create(&reused))
+		})
+
+		t.Run("Phone is released on anonymization", func(t *testing.T) {
+			anonymized := service.Participant{ID: "participant_id_phone_6", Name: "Phone 6", Phone: "+380509990011"}
+			require.NoError(t, ps.Create(&anonymized))
+
+			anonymized.Name = ""
+			anonymized.Phone = ""
+			require.NoError(t, ps.Update(&anonymized))
+			require.NoError(t, ps.Delete(anonymized.ID))
+
+			reused := service.Participant{ID: "participant_id_phone_7", Name: "Phone 7", Phone: "+380509990011"}
+			require.NoError(t, ps.Create(&reused))
+		})
+
+		t.Run("Update non-existent participant", func(t *testing.T) {
+			err := ps.Update(&service.Participant{ID: "not-exists", Phone: "+380500000000"})
+			require.ErrorIs(t, err, service.ErrNotFound)
+		})
+	})
+
+	t.Run("Update with stale version", func(t *testing.T) {
+		p := service.Participant{ID: "participant_id_version_1", Name: "Version 1", Phone: "+380502223344"}
+		require.NoError(t, ps.Create(&p))
+
+		read, err := ps.Get(p.ID)
+		require.NoError(t, err)
+		require.False(t, read.Version().IsZero())
+
+		stale := *read
+
+		read.Note = "updated first"
+		require.NoError(t, ps.Update(read))
+
+		stale.Note = "updated second"
+		require.ErrorIs(t, ps.Update(&stale), service.ErrVersionMismatch)
+
+		got, err := ps.Get(p.ID)
+		require.NoError(t, err)
+		require.Equal(t, "updated first", got.Note)
+		require.True(t, got.Version().After(stale.Version()))
+		require.True(t, got.Version().Equal(read.Version()), "the version written by the transaction is read back")
+
+		read.Note = "updated again"
+		require.NoError(t, ps.Update(read), "the entity can be updated again with the version")
+	})
+
+	t.Run("Update fields", func(t *testing.T) {
+		p := service.Participant{ID: "participant_id_fields_1", Name: "Fields 1", Phone: "+380503334455", Note: "note"}
+		require.NoError(t, ps.Create(&p))
+
+		read, err := ps.Get(p.ID)
+		require.NoError(t, err)
+
+		// Fields which aren't given are kept as they're stored.
+		patched := *read
+		patched.Name = "Fields 2"
+		patched.Note = "ignored"
+		require.NoError(t, ps.UpdateFields(&patched, []string{"Name"}))
+
+		got, err := ps.Get(p.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Fields 2", got.Name)
+		require.Equal(t, "note", got.Note)
+		require.Equal(t, p.Phone, got.Phone)
+
+		read.Note = "stale"
+		require.ErrorIs(t, ps.UpdateFields(read, []string{"Note"}), service.ErrVersionMismatch)
+	})
 }
 
 var _ service.ParticipantStorage = (*FirestoreParticipantStorage)(nil)
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+// FirestorePaymentStorage is a storage for payments based on Firestore.
+// Payments are written in transactions along with donations of their allocations.
+type FirestorePaymentStorage struct {
+	prizeReferences *firestore.CollectionRef
+	*StorageBase[service.Payment]
+}
+
+// NewFirestorePaymentStorage creates a new FirestorePaymentStorage.
+func NewFirestorePaymentStorage(firestoreClient *firestore.Client, client *firestore.CollectionRef, prizes *firestore.CollectionRef) *FirestorePaymentStorage {
+	paymentIDExtractor := IDExtractor[service.Payment](
+		func(p *service.Payment) string {
+			return p.ID
+		},
+	)
+
+	return &FirestorePaymentStorage{
+		prizeReferences: prizes,
+		StorageBase:     NewStorageBase(firestoreClient, client, paymentIDExtractor),
+	}
+}
+
+// Create creates a new payment and donations of its allocations.
+func (ps *FirestorePaymentStorage) Create(p *service.Payment) error {
+	ref := ps.collectionReference.Doc(p.ID)
+
+	err := ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		exists, err := docExists(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return service.ErrAlreadyExists
+		}
+
+		totals, err := ps.readTotals(tx, nil, p)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(ref, p); err != nil {
+			return err
+		}
+
+		if err := ps.setDonations(tx, p); err != nil {
+			return err
+		}
+
+		return totals.write(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("create payment: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the payment and its donations.
+// Donations of allocations which are removed are deleted.
+// It fails with service.ErrVersionMismatch if the payment was updated after its version was read.
+func (ps *FirestorePaymentStorage) Update(p *service.Payment) error {
+	ref := ps.collectionReference.Doc(p.ID)
+
+	err := ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		old, err := getPayment(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		totals, err := ps.readTotals(tx, old, p)
+		if err != nil {
+			return err
+		}
+
+		kept := make(map[string]bool, len(p.Allocations))
+		for _, a := range p.Allocations {
+			kept[ps.donationReference(a).Path] = true
+		}
+
+		for _, a := range old.Allocations {
+			if donationRef := ps.donationReference(a); !kept[donationRef.Path] {
+				if err := tx.Delete(donationRef); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Update(ref, fieldUpdates(p), versionPreconditions(p)...); err != nil {
+			return err
+		}
+
+		if err := ps.setDonations(tx, p); err != nil {
+			return err
+		}
+
+		return totals.write(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("update payment: %w", updateError(err))
+	}
+
+	readVersion(ps.firestoreClient, ref, p)
+
+	return nil
+}
+
+// Delete deletes the payment and its donations.
+func (ps *FirestorePaymentStorage) Delete(id string) error {
+	ref := ps.collectionReference.Doc(id)
+
+	err := ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		p, err := getPayment(tx, ref)
+		if err != nil {
+			return err
+		}
+
+		totals, err := ps.readTotals(tx, p, nil)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range p.Allocations {
+			if err := tx.Delete(ps.donationReference(a)); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+
+		return totals.write(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("delete payment: %w", err)
+	}
+
+	return nil
+}
+
+// setDonations writes donations of the payment.
+func (ps *FirestorePaymentStorage) setDonations(tx *firestore.Transaction, p *service.Payment) error {
+	for prizeID, donations := range p.Donations() {
+		collection := ps.prizeReferences.Doc(prizeID).Collection(donationCollection)
+		for i := range donations {
+			if err := tx.Set(collection.Doc(donations[i].ID), &donations[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readTotals replaces donations of the old payment with donations
+// of the new one in the running totals and reads the ones to be changed.
+// Either of the payments may be nil.
+func (ps *FirestorePaymentStorage) readTotals(tx *firestore.Transaction, old, p *service.Payment) (*donationTotals, error) {
+	totals := newDonationTotals(ps.prizeReferences.Parent)
+
+	for _, payment := range []*service.Payment{old, p} {
+		if payment == nil {
+			continue
+		}
+
+		for prizeID, donations := range payment.Donations() {
+			for i := range donations {
+				if payment == old {
+					totals.remove(prizeID, &donations[i])
+				} else {
+					totals.add(prizeID, &donations[i])
+				}
+			}
+		}
+	}
+
+	if err := totals.read(tx); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func (ps *FirestorePaymentStorage) donationReference(a service.PaymentAllocation) *firestore.DocumentRef {
+	return ps.prizeReferences.Doc(a.PrizeID).Collection(donationCollection).Doc(a.DonationID)
+}
+
+func getPayment(tx *firestore.Transaction, ref *firestore.DocumentRef) (*service.Payment, error) {
+	doc, err := tx.Get(ref)
+	if isNotFound(err) {
+		return nil, service.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get payment: %w", err)
+	}
+
+	var p service.Payment
+	if err := doc.DataTo(&p); err != nil {
+		return nil, fmt.Errorf("decode payment: %w", err)
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/testinfra"
+	"github.com/kaznasho/yarmarok/testinfra/firestore"
+)
+
+func TestPaymentStorage(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := firestore.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	org := &service.Organizer{ID: "organizer_id_1"}
+	err = os.Create(org)
+	require.NoError(t, err)
+
+	raf := service.Raffle{ID: "raffle_id_1"}
+	rs := NewFirestoreRaffleStorage(os.firestoreClient, os.collectionReference.Doc(org.ID).Collection(raffleCollection), raf.ID)
+
+	err = rs.Create(&raf)
+	require.NoError(t, err)
+
+	prizes := rs.PrizeStorage(raf.ID)
+	for _, id := range []string{"prize_id_1", "prize_id_2", "prize_id_3"} {
+		require.NoError(t, prizes.Create(&service.Prize{ID: id}))
+	}
+
+	ps := rs.PaymentStorage(raf.ID)
+
+	payment := service.Payment{
+		ID:            "payment_id_1",
+		ParticipantID: "participant_id_1",
+		Amount:        300,
+		Method:        service.PaymentCash,
+		Allocations: []service.PaymentAllocation{
+			{PrizeID: "prize_id_1", DonationID: "donation_id_1", Amount: 100},
+			{PrizeID: "prize_id_2", DonationID: "donation_id_2", Amount: 200},
+		},
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	requireDonations := func(t *testing.T, p *service.Payment) {
+		t.Helper()
+
+		expected := p.Donations()
+		for _, prizeID := range []string{"prize_id_1", "prize_id_2", "prize_id_3"} {
+			donations, err := prizes.DonationStorage(prizeID).GetAll()
+			require.NoError(t, err)
+			require.ElementsMatch(t, expected[prizeID], unversionedAll(donations))
+		}
+	}
+
+	t.Run("Create payment", func(t *testing.T) {
+		require.NoError(t, ps.Create(&payment))
+
+		got, err := ps.Get(payment.ID)
+		require.NoError(t, err)
+		require.Equal(t, &payment, unversioned(got))
+
+		requireDonations(t, &payment)
+	})
+
+	t.Run("Create existing payment", func(t *testing.T) {
+		require.ErrorIs(t, ps.Create(&payment), service.ErrAlreadyExists)
+	})
+
+	t.Run("Update payment", func(t *testing.T) {
+		payment.Amount = 250
+		payment.Allocations = []service.PaymentAllocation{
+			{PrizeID: "prize_id_1", DonationID: "donation_id_1", Amount: 150},
+			{PrizeID: "prize_id_3", DonationID: "donation_id_3", Amount: 100},
+		}
+
+		require.NoError(t, ps.Update(&payment))
+
+		got, err := ps.Get(payment.ID)
+		require.NoError(t, err)
+		require.Equal(t, &payment, unversioned(got))
+
+		requireDonations(t, &payment)
+	})
+
+	t.Run("Update non-existent payment", func(t *testing.T) {
+		require.ErrorIs(t, ps.Update(&service.Payment{ID: "not-exists"}), service.ErrNotFound)
+	})
+
+	t.Run("Get all payments", func(t *testing.T) {
+		payments, err := ps.GetAll()
+		require.NoError(t, err)
+		require.Equal(t, []service.Payment{payment}, unversionedAll(payments))
+	})
+
+	t.Run("Delete payment", func(t *testing.T) {
+		require.NoError(t, ps.Delete(payment.ID))
+
+		_, err := ps.Get(payment.ID)
+		require.ErrorIs(t, err, service.ErrNotFound)
+
+		requireDonations(t, &service.Payment{})
+	})
+
+	t.Run("Delete non-existent payment", func(t *testing.T) {
+		require.ErrorIs(t, ps.Delete("not-exists"), service.ErrNotFound)
+	})
+}
+
+var _ service.PaymentStorage = (*FirestorePaymentStorage)(nil)
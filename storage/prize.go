@@ -1,19 +1,32 @@
 package storage
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"cloud.google.com/go/firestore"
+	"golang.org/x/exp/slices"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/kaznasho/yarmarok/service"
 )
 
+// dueAtField is the field of prizes queried for due ones, see service.Prize.DueAt.
+const dueAtField = "DueAt"
+
 // FirestorePrizeStorage is a storage for prizes based on Firestore.
 type FirestorePrizeStorage struct {
 	raffleID string
+	// cipher encrypts phones of participants kept in play results.
+	// They are kept in plaintext if it's nil.
+	cipher *FieldCipher
 	*StorageBase[service.Prize]
 }
 
 // NewFirestorePrizeStorage creates a new FirestorePrizeStorage.
-func NewFirestorePrizeStorage(client *firestore.CollectionRef, raffleID string) *FirestorePrizeStorage {
+func NewFirestorePrizeStorage(firestoreClient *firestore.Client, client *firestore.CollectionRef, raffleID string) *FirestorePrizeStorage {
 	prizeIDExtractor := IDExtractor[service.Prize](
 		func(p *service.Prize) string {
 			return p.ID
@@ -22,11 +35,329 @@ func NewFirestorePrizeStorage(client *firestore.CollectionRef, raffleID string)
 
 	return &FirestorePrizeStorage{
 		raffleID:    raffleID,
-		StorageBase: NewStorageBase(client, prizeIDExtractor),
+		StorageBase: NewStorageBase(firestoreClient, client, prizeIDExtractor),
 	}
 }
 
 // DonationStorage returns a donation storage.
 func (ps *FirestorePrizeStorage) DonationStorage(prizeID string) service.DonationStorage {
-	return NewFirestoreDonationStorage(ps.collectionReference.Doc(prizeID).Collection(donationCollection), ps, prizeID)
+	return NewFirestoreDonationStorage(ps.firestoreClient, ps.collectionReference.Doc(prizeID).Collection(donationCollection), ps, prizeID, ps.collectionReference.Parent)
+}
+
+// Create creates a new prize.
+func (ps *FirestorePrizeStorage) Create(p *service.Prize) error {
+	stored, err := ps.stored(p)
+	if err != nil {
+		return fmt.Errorf("create prize: %w", err)
+	}
+
+	return ps.StorageBase.Create(stored)
+}
+
+// Get returns the prize with decrypted phones of the participants.
+func (ps *FirestorePrizeStorage) Get(id string) (*service.Prize, error) {
+	p, err := ps.StorageBase.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ps.decrypt(p); err != nil {
+		return nil, fmt.Errorf("get prize: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetAll returns all prizes with decrypted phones of the participants.
+func (ps *FirestorePrizeStorage) GetAll() ([]service.Prize, error) {
+	prizes, err := ps.StorageBase.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range prizes {
+		if err := ps.decrypt(&prizes[i]); err != nil {
+			return nil, fmt.Errorf("get all prizes: %w", err)
+		}
+	}
+
+	return prizes, nil
+}
+
+// Update replaces the prize keeping its stored running totals.
+// It fails with service.ErrVersionMismatch if the prize was updated
+// after its version was read, e.g. by a donation.
+func (ps *FirestorePrizeStorage) Update(p *service.Prize) error {
+	stored, err := ps.stored(p)
+	if err != nil {
+		return fmt.Errorf("update prize: %w", err)
+	}
+
+	return ps.update(p, stored, fieldUpdates(stored, prizeTotalsFields...))
+}
+
+// UpdateFields updates only the given fields of the prize, see Update.
+// The due time of the prize is updated along with them.
+func (ps *FirestorePrizeStorage) UpdateFields(p *service.Prize, fields []string) error {
+	stored, err := ps.stored(p)
+	if err != nil {
+		return fmt.Errorf("update prize: %w", err)
+	}
+
+	fields = append(slices.Clone(fields), dueAtField)
+
+	return ps.update(p, stored, selectFields(fieldUpdates(stored, prizeTotalsFields...), fields))
+}
+
+// UpdateAll replaces the given prizes in a single transaction
+// keeping their stored running totals.
+// Nothing is updated if any of the prizes doesn't exist or has a stale version.
+// Versions of the prizes are read back, see readVersions.
+func (ps *FirestorePrizeStorage) UpdateAll(prizes []service.Prize) error {
+	refs := make([]*firestore.DocumentRef, 0, len(prizes))
+	for i := range prizes {
+		refs = append(refs, ps.collectionReference.Doc(prizes[i].ID))
+	}
+
+	err := ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		for i := range prizes {
+			stored, err := ps.stored(&prizes[i])
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Update(refs[i], fieldUpdates(stored, prizeTotalsFields...), versionPreconditions(stored)...); err != nil {
+				return fmt.Errorf("update prize: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("update prizes: %w", updateError(err))
+	}
+
+	readVersions(ps.firestoreClient, refs, func(i int, version time.Time) {
+		prizes[i].SetVersion(version)
+	})
+
+	return nil
+}
+
+// Delete deletes the prize along with its donations and donor sums
+// and subtracts the donations from the raffle counters in a single transaction.
+func (ps *FirestorePrizeStorage) Delete(id string) error {
+	ref := ps.collectionReference.Doc(id)
+	raffle := ps.collectionReference.Parent
+
+	err := ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err != nil {
+			if isNotFound(err) {
+				return service.ErrNotFound
+			}
+
+			return fmt.Errorf("get prize: %w", err)
+		}
+
+		donations, err := tx.Documents(ref.Collection(donationCollection)).GetAll()
+		if err != nil {
+			return fmt.Errorf("get donations: %w", err)
+		}
+
+		donors, err := tx.Documents(ref.Collection(donorCollection)).GetAll()
+		if err != nil {
+			return fmt.Errorf("get donor totals: %w", err)
+		}
+
+		shards, err := tx.GetAll(counterShardReferences(raffle))
+		if err != nil {
+			return fmt.Errorf("get counters: %w", err)
+		}
+
+		var delta service.RaffleCounters
+		for _, doc := range donations {
+			var d service.Donation
+			if err := doc.DataTo(&d); err != nil {
+				return fmt.Errorf("decode donation: %w", err)
+			}
+
+			delta.RemoveDonation(id, &d)
+		}
+
+		for _, doc := range append(donations, donors...) {
+			if err := tx.Delete(doc.Ref); err != nil {
+				return fmt.Errorf("delete %s: %w", doc.Ref.Parent.ID, err)
+			}
+		}
+
+		if err := removePrizeCounters(tx, shards, id, &delta); err != nil {
+			return err
+		}
+
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return fmt.Errorf("delete prize: %w", err)
+	}
+
+	return nil
+}
+
+// reencrypt re-encrypts phones of participants in play results
+// which are in plaintext or encrypted with a key other than the primary one.
+// It returns the number of updated prizes.
+func (ps *FirestorePrizeStorage) reencrypt() (int, error) {
+	prizes, err := ps.StorageBase.GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	stale := make([]service.Prize, 0)
+
+	for i := range prizes {
+		current := true
+		_ = forEachPlayer(prizes[i].PlayResult, func(p *service.Participant) error {
+			current = current && ps.cipher.IsCurrent(p.Phone)
+			return nil
+		})
+
+		if current {
+			continue
+		}
+
+		if err := ps.decrypt(&prizes[i]); err != nil {
+			return 0, fmt.Errorf("prize %q: %w", prizes[i].ID, err)
+		}
+
+		stale = append(stale, prizes[i])
+	}
+
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	if err := ps.UpdateAll(stale); err != nil {
+		return 0, err
+	}
+
+	return len(stale), nil
+}
+
+// stored sets the due time of the prize and returns it as it's stored, see encrypt.
+func (ps *FirestorePrizeStorage) stored(p *service.Prize) (*service.Prize, error) {
+	p.DueAt = p.NextDueAt()
+
+	return ps.encrypt(p)
+}
+
+// encrypt returns a copy of the prize with encrypted phones of the participants.
+func (ps *FirestorePrizeStorage) encrypt(p *service.Prize) (*service.Prize, error) {
+	if ps.cipher == nil || p.PlayResult == nil {
+		return p, nil
+	}
+
+	stored := *p
+	result := *p.PlayResult
+	result.Winners = slices.Clone(result.Winners)
+	result.PlayParticipants = slices.Clone(result.PlayParticipants)
+	result.Voided = slices.Clone(result.Voided)
+	stored.PlayResult = &result
+
+	err := forEachPlayer(&result, func(prt *service.Participant) error {
+		var err error
+
+		prt.Phone, err = ps.cipher.Encrypt(prt.Phone, prt.ID)
+		if err != nil {
+			return fmt.Errorf("encrypt phone: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stored, nil
+}
+
+// decrypt decrypts phones of the participants of the prize.
+func (ps *FirestorePrizeStorage) decrypt(p *service.Prize) error {
+	return forEachPlayer(p.PlayResult, func(prt *service.Participant) error {
+		var err error
+
+		prt.Phone, err = ps.cipher.Decrypt(prt.Phone, prt.ID)
+		if err != nil {
+			return fmt.Errorf("decrypt phone: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// forEachPlayer calls the function for copies of participants kept in the play result.
+func forEachPlayer(result *service.PrizePlayResult, f func(*service.Participant) error) error {
+	if result == nil {
+		return nil
+	}
+
+	players := make([]*service.PlayParticipant, 0, len(result.Winners)+len(result.PlayParticipants)+len(result.Voided))
+
+	for i := range result.Winners {
+		players = append(players, &result.Winners[i])
+	}
+
+	for i := range result.PlayParticipants {
+		players = append(players, &result.PlayParticipants[i])
+	}
+
+	for i := range result.Voided {
+		players = append(players, &result.Voided[i].PlayParticipant)
+	}
+
+	for _, player := range players {
+		if err := f(&player.Participant); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateDonations creates donations keyed by prize ID in a single transaction
+// along with the running totals.
+// Nothing is created if any of the donations already exists.
+func (ps *FirestorePrizeStorage) CreateDonations(donations map[string][]service.Donation) error {
+	totals := newDonationTotals(ps.collectionReference.Parent)
+
+	for prizeID, prizeDonations := range donations {
+		for i := range prizeDonations {
+			totals.add(prizeID, &prizeDonations[i])
+		}
+	}
+
+	err := ps.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := totals.read(tx); err != nil {
+			return err
+		}
+
+		for prizeID, prizeDonations := range donations {
+			collection := ps.collectionReference.Doc(prizeID).Collection(donationCollection)
+			for i := range prizeDonations {
+				if err := tx.Create(collection.Doc(donationIDExtractor(&prizeDonations[i])), &prizeDonations[i]); err != nil {
+					return err
+				}
+			}
+		}
+
+		return totals.write(tx)
+	})
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return service.ErrAlreadyExists
+		}
+
+		return fmt.Errorf("create donations: %w", err)
+	}
+
+	return nil
 }
@@ -25,7 +25,7 @@ func TestPrizeStorage(t *testing.T) {
 	require.NoError(t, err)
 
 	y := service.Raffle{ID: "raffle_id_1"}
-	ys := NewFirestoreRaffleStorage(os.collectionReference.Doc(org.ID).Collection(raffleCollection), y.ID)
+	ys := NewFirestoreRaffleStorage(os.firestoreClient, os.collectionReference.Doc(org.ID).Collection(raffleCollection), y.ID)
 
 	err = ys.Create(&y)
 	require.NoError(t, err)
@@ -75,6 +75,86 @@ func TestPrizeStorage(t *testing.T) {
 			})
 		}
 
+		t.Run("Update all prizes", func(t *testing.T) {
+			updated := make([]service.Prize, len(testPrizes))
+			copy(updated, testPrizes)
+			updated[0].Name = "updated_together_1"
+			updated[1].Name = "updated_together_2"
+
+			err := pz.UpdateAll(updated[:2])
+			require.NoError(t, err)
+
+			getPrizes, err := pz.GetAll()
+			require.NoError(t, err)
+			require.ElementsMatch(t, unversionedAll(updated), unversionedAll(getPrizes))
+
+			testPrizes = updated
+		})
+
+		t.Run("Update all prizes with non-existent one", func(t *testing.T) {
+			changed := testPrizes[0]
+			changed.Name = "not_saved"
+
+			err := pz.UpdateAll([]service.Prize{changed, {ID: "not-exists"}})
+			require.ErrorIs(t, err, service.ErrNotFound)
+
+			p, err := pz.Get(changed.ID)
+			require.NoError(t, err)
+			require.Equal(t, testPrizes[0].Name, p.Name)
+		})
+
+		t.Run("Update prize with stale version", func(t *testing.T) {
+			p, err := pz.Get(testPrizes[0].ID)
+			require.NoError(t, err)
+
+			stale := *p
+
+			p.Name = "updated_first"
+			require.NoError(t, pz.Update(p))
+
+			stale.Name = "updated_second"
+			require.ErrorIs(t, pz.Update(&stale), service.ErrVersionMismatch)
+			require.ErrorIs(t, pz.UpdateAll([]service.Prize{stale}), service.ErrVersionMismatch)
+
+			got, err := pz.Get(p.ID)
+			require.NoError(t, err)
+			require.Equal(t, p, got)
+
+			testPrizes[0] = *p
+		})
+
+		t.Run("Create donations to several prizes", func(t *testing.T) {
+			donations := map[string][]service.Donation{
+				testPrizes[0].ID: {
+					{ID: "bulk_donation_1", ParticipantID: "participant_id_1", Amount: 100},
+					{ID: "bulk_donation_2", ParticipantID: "participant_id_1", Amount: 50},
+				},
+				testPrizes[1].ID: {{ID: "bulk_donation_3", ParticipantID: "participant_id_1", Amount: 150}},
+			}
+
+			err := pz.CreateDonations(donations)
+			require.NoError(t, err)
+
+			for prizeID, prizeDonations := range donations {
+				got, err := pz.DonationStorage(prizeID).GetAll()
+				require.NoError(t, err)
+				require.ElementsMatch(t, prizeDonations, unversionedAll(got))
+			}
+
+			t.Run("already exists", func(t *testing.T) {
+				err := pz.CreateDonations(map[string][]service.Donation{
+					testPrizes[1].ID: {
+						{ID: "bulk_donation_4", ParticipantID: "participant_id_1", Amount: 10},
+						{ID: "bulk_donation_3", ParticipantID: "participant_id_1", Amount: 10},
+					},
+				})
+				require.ErrorIs(t, err, service.ErrAlreadyExists)
+
+				_, err = pz.DonationStorage(testPrizes[1].ID).Get("bulk_donation_4")
+				require.ErrorIs(t, err, service.ErrNotFound)
+			})
+		})
+
 		t.Run("Get non-existent prize", func(t *testing.T) {
 			resp, err := pz.Get("not-exists")
 			require.Error(t, err)
@@ -2,12 +2,21 @@ package storage
 
 import (
 	"cloud.google.com/go/firestore"
+	"golang.org/x/exp/slices"
 
 	"github.com/kaznasho/yarmarok/service"
 )
 
+const (
+	// closeDueAtField is the field of raffles queried for closed ones, see service.Raffle.CloseDueAt.
+	closeDueAtField = "CloseDueAt"
+	// retainedSinceField is the field of raffles queried for ones to be anonymized,
+	// see service.Raffle.RetainedSince.
+	retainedSinceField = "RetainedSince"
+)
+
 // NewFirestoreRaffleStorage creates a new FirestoreRaffleStorage.
-func NewFirestoreRaffleStorage(client *firestore.CollectionRef, organizerID string) *FirestoreRaffleStorage {
+func NewFirestoreRaffleStorage(firestoreClient *firestore.Client, client *firestore.CollectionRef, organizerID string) *FirestoreRaffleStorage {
 	raffleIDExtractor := IDExtractor[service.Raffle](
 		func(r *service.Raffle) string {
 			return r.ID
@@ -16,27 +25,87 @@ func NewFirestoreRaffleStorage(client *firestore.CollectionRef, organizerID stri
 
 	return &FirestoreRaffleStorage{
 		organizerID: organizerID,
-		StorageBase: NewStorageBase(client, raffleIDExtractor),
+		StorageBase: NewStorageBase(firestoreClient, client, raffleIDExtractor),
 	}
 }
 
 // FirestoreRaffleStorage is a storage for raffles based on Firestore.
 type FirestoreRaffleStorage struct {
 	organizerID string
+	// cipher encrypts phones of participants. They are kept in plaintext if it's nil.
+	cipher *FieldCipher
 	*StorageBase[service.Raffle]
 }
 
 func (rs *FirestoreRaffleStorage) Create(r *service.Raffle) error {
 	r.OrganizerID = rs.organizerID
+	scheduleRaffle(r)
+
 	return rs.StorageBase.Create(r)
 }
 
+// Update replaces the raffle along with its due times.
+func (rs *FirestoreRaffleStorage) Update(r *service.Raffle) error {
+	scheduleRaffle(r)
+
+	return rs.StorageBase.Update(r)
+}
+
+// UpdateFields updates only the given fields of the raffle along with its due times.
+func (rs *FirestoreRaffleStorage) UpdateFields(r *service.Raffle, fields []string) error {
+	scheduleRaffle(r)
+
+	return rs.StorageBase.UpdateFields(r, append(slices.Clone(fields), closeDueAtField, retainedSinceField))
+}
+
+// scheduleRaffle sets the times the scheduler has to act on the raffle.
+func scheduleRaffle(r *service.Raffle) {
+	r.CloseDueAt = r.NextCloseDueAt()
+	r.RetainedSince = r.NextRetainedSince()
+}
+
 // PrizeStorage returns a prize storage.
 func (rs *FirestoreRaffleStorage) PrizeStorage(raffleID string) service.PrizeStorage {
-	return NewFirestorePrizeStorage(rs.collectionReference.Doc(raffleID).Collection(prizeCollection), raffleID)
+	return rs.prizeStorage(raffleID)
+}
+
+func (rs *FirestoreRaffleStorage) prizeStorage(raffleID string) *FirestorePrizeStorage {
+	ps := NewFirestorePrizeStorage(rs.firestoreClient, rs.collectionReference.Doc(raffleID).Collection(prizeCollection), raffleID)
+	ps.cipher = rs.cipher
+
+	return ps
 }
 
 // ParticipantStorage returns a participant storage.
 func (rs *FirestoreRaffleStorage) ParticipantStorage(raffleID string) service.ParticipantStorage {
-	return NewFirestoreParticipantStorage(rs.collectionReference.Doc(raffleID).Collection(participantCollection), raffleID)
+	return rs.participantStorage(raffleID)
+}
+
+func (rs *FirestoreRaffleStorage) participantStorage(raffleID string) *FirestoreParticipantStorage {
+	ps := NewFirestoreParticipantStorage(rs.firestoreClient, rs.collectionReference.Doc(raffleID).Collection(participantCollection), raffleID)
+	ps.cipher = rs.cipher
+
+	return ps
+}
+
+// DonationStorage returns a storage for donations made to the whole raffle.
+func (rs *FirestoreRaffleStorage) DonationStorage(raffleID string) service.DonationStorage {
+	raffleRef := rs.collectionReference.Doc(raffleID)
+	return NewFirestoreRaffleDonationStorage(rs.firestoreClient, raffleRef.Collection(donationCollection), raffleRef)
+}
+
+// PaymentStorage returns a storage for payments split across prizes of the raffle.
+func (rs *FirestoreRaffleStorage) PaymentStorage(raffleID string) service.PaymentStorage {
+	raffleRef := rs.collectionReference.Doc(raffleID)
+	return NewFirestorePaymentStorage(rs.firestoreClient, raffleRef.Collection(paymentCollection), raffleRef.Collection(prizeCollection))
+}
+
+// ShiftStorage returns a storage for closed shifts of the raffle.
+func (rs *FirestoreRaffleStorage) ShiftStorage(raffleID string) service.ShiftStorage {
+	return NewFirestoreShiftStorage(rs.firestoreClient, rs.collectionReference.Doc(raffleID))
+}
+
+// StatsStorage returns a storage for counters of the raffle.
+func (rs *FirestoreRaffleStorage) StatsStorage(raffleID string) service.StatsStorage {
+	return NewFirestoreStatsStorage(rs.firestoreClient, rs.collectionReference.Doc(raffleID))
 }
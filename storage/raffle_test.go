@@ -89,4 +89,22 @@ func TestRaffle(t *testing.T) {
 		require.Len(t, raffles, 2)
 		require.Equal(t, created, raffles)
 	})
+
+	t.Run("raffle donations", func(t *testing.T) {
+		ds := rs.DonationStorage(raf.ID)
+
+		d := &service.Donation{
+			ID:            "donation_id_1",
+			ParticipantID: "participant_id_1",
+			Amount:        100,
+			CreatedAt:     time.Now().UTC().Truncate(time.Millisecond),
+		}
+
+		err := ds.Create(d)
+		require.NoError(t, err)
+
+		donations, err := ds.GetAll()
+		require.NoError(t, err)
+		require.Equal(t, []service.Donation{*d}, unversionedAll(donations))
+	})
 }
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+// shiftIDField is the name of the field which locks donations and payments.
+const shiftIDField = "ShiftID"
+
+// FirestoreShiftStorage is a storage for closed shifts based on Firestore.
+type FirestoreShiftStorage struct {
+	raffleReference *firestore.DocumentRef
+	*StorageBase[service.Shift]
+}
+
+// NewFirestoreShiftStorage creates a new FirestoreShiftStorage.
+func NewFirestoreShiftStorage(firestoreClient *firestore.Client, raffle *firestore.DocumentRef) *FirestoreShiftStorage {
+	shiftIDExtractor := IDExtractor[service.Shift](
+		func(s *service.Shift) string {
+			return s.ID
+		},
+	)
+
+	return &FirestoreShiftStorage{
+		raffleReference: raffle,
+		StorageBase:     NewStorageBase(firestoreClient, raffle.Collection(shiftCollection), shiftIDExtractor),
+	}
+}
+
+// Create creates the shift and sets its ID to the donations and payments it locks.
+// Nothing is written if any of them is already locked, deleted or has another amount.
+func (ss *FirestoreShiftStorage) Create(s *service.Shift) error {
+	ref := ss.collectionReference.Doc(s.ID)
+
+	donationRefs := make([]*firestore.DocumentRef, 0, len(s.Donations))
+	for _, d := range s.Donations {
+		donationRefs = append(donationRefs, ss.donationReference(d))
+	}
+
+	paymentRefs := make([]*firestore.DocumentRef, 0, len(s.PaymentIDs))
+	for _, id := range s.PaymentIDs {
+		paymentRefs = append(paymentRefs, ss.raffleReference.Collection(paymentCollection).Doc(id))
+	}
+
+	err := ss.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := checkUnlockedDonations(tx, donationRefs, s.Donations); err != nil {
+			return err
+		}
+
+		if err := checkUnlockedPayments(tx, paymentRefs); err != nil {
+			return err
+		}
+
+		if err := tx.Create(ref, s); err != nil {
+			return err
+		}
+
+		lock := []firestore.Update{{Path: shiftIDField, Value: s.ID}}
+		for _, r := range append(donationRefs, paymentRefs...) {
+			if err := tx.Update(r, lock); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("create shift: %w", err)
+	}
+
+	return nil
+}
+
+func (ss *FirestoreShiftStorage) donationReference(d service.ShiftDonation) *firestore.DocumentRef {
+	if d.PrizeID == "" {
+		return ss.raffleReference.Collection(donationCollection).Doc(d.DonationID)
+	}
+
+	return ss.raffleReference.Collection(prizeCollection).Doc(d.PrizeID).Collection(donationCollection).Doc(d.DonationID)
+}
+
+func checkUnlockedDonations(tx *firestore.Transaction, refs []*firestore.DocumentRef, expected []service.ShiftDonation) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	docs, err := tx.GetAll(refs)
+	if err != nil {
+		return fmt.Errorf("get donations: %w", err)
+	}
+
+	for i, doc := range docs {
+		if !doc.Exists() {
+			return service.ErrShiftOutdated
+		}
+
+		var d service.Donation
+		if err := doc.DataTo(&d); err != nil {
+			return fmt.Errorf("decode donation: %w", err)
+		}
+
+		if d.ShiftID != "" || d.Amount != expected[i].Amount {
+			return service.ErrShiftOutdated
+		}
+	}
+
+	return nil
+}
+
+func checkUnlockedPayments(tx *firestore.Transaction, refs []*firestore.DocumentRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	docs, err := tx.GetAll(refs)
+	if err != nil {
+		return fmt.Errorf("get payments: %w", err)
+	}
+
+	for _, doc := range docs {
+		if !doc.Exists() {
+			return service.ErrShiftOutdated
+		}
+
+		var p service.Payment
+		if err := doc.DataTo(&p); err != nil {
+			return fmt.Errorf("decode payment: %w", err)
+		}
+
+		if p.ShiftID != "" {
+			return service.ErrShiftOutdated
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/testinfra"
+	"github.com/kaznasho/yarmarok/testinfra/firestore"
+)
+
+func TestShiftStorage(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := firestore.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	org := &service.Organizer{ID: "organizer_id_1"}
+	err = os.Create(org)
+	require.NoError(t, err)
+
+	raf := service.Raffle{ID: "raffle_id_1"}
+	rs := NewFirestoreRaffleStorage(os.firestoreClient, os.collectionReference.Doc(org.ID).Collection(raffleCollection), raf.ID)
+
+	err = rs.Create(&raf)
+	require.NoError(t, err)
+
+	prizes := rs.PrizeStorage(raf.ID)
+	require.NoError(t, prizes.Create(&service.Prize{ID: "prize_id_1"}))
+
+	raffleDonations := rs.DonationStorage(raf.ID)
+	require.NoError(t, raffleDonations.Create(&service.Donation{ID: "donation_id_1", Amount: 100, EnteredBy: "volunteer_id_1"}))
+
+	payment := service.Payment{
+		ID:          "payment_id_1",
+		Amount:      50,
+		Method:      service.PaymentCard,
+		Allocations: []service.PaymentAllocation{{PrizeID: "prize_id_1", DonationID: "donation_id_2", Amount: 50}},
+		EnteredBy:   "volunteer_id_1",
+	}
+	require.NoError(t, rs.PaymentStorage(raf.ID).Create(&payment))
+
+	ss := rs.ShiftStorage(raf.ID)
+
+	shift := service.Shift{
+		ID:          "shift_id_1",
+		VolunteerID: "volunteer_id_1",
+		Totals: service.ReconciliationTotals{
+			Total:    150,
+			Count:    2,
+			ByMethod: map[service.PaymentMethod]int{service.PaymentCash: 100, service.PaymentCard: 50},
+		},
+		Donations: []service.ShiftDonation{
+			{DonationID: "donation_id_1", Amount: 100},
+			{PrizeID: "prize_id_1", DonationID: "donation_id_2", Amount: 50},
+		},
+		PaymentIDs: []string{payment.ID},
+		ClosedAt:   time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	t.Run("Outdated shift", func(t *testing.T) {
+		outdated := shift
+		outdated.ID = "shift_id_0"
+		outdated.Donations = []service.ShiftDonation{{DonationID: "donation_id_1", Amount: 90}}
+
+		require.ErrorIs(t, ss.Create(&outdated), service.ErrShiftOutdated)
+
+		d, err := raffleDonations.Get("donation_id_1")
+		require.NoError(t, err)
+		require.Empty(t, d.ShiftID)
+	})
+
+	t.Run("Create shift", func(t *testing.T) {
+		require.NoError(t, ss.Create(&shift))
+
+		d, err := raffleDonations.Get("donation_id_1")
+		require.NoError(t, err)
+		require.Equal(t, shift.ID, d.ShiftID)
+
+		d, err = prizes.DonationStorage("prize_id_1").Get("donation_id_2")
+		require.NoError(t, err)
+		require.Equal(t, shift.ID, d.ShiftID)
+
+		p, err := rs.PaymentStorage(raf.ID).Get(payment.ID)
+		require.NoError(t, err)
+		require.Equal(t, shift.ID, p.ShiftID)
+	})
+
+	t.Run("Donations are locked once", func(t *testing.T) {
+		again := shift
+		again.ID = "shift_id_2"
+
+		require.ErrorIs(t, ss.Create(&again), service.ErrShiftOutdated)
+	})
+
+	t.Run("Get all shifts", func(t *testing.T) {
+		shifts, err := ss.GetAll()
+		require.NoError(t, err)
+		require.Equal(t, []service.Shift{shift}, shifts)
+	})
+}
+
+var _ service.ShiftStorage = (*FirestoreShiftStorage)(nil)
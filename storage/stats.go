@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+const (
+	statsCollection = "stats"
+	// countersDocument is the first shard of counters of all donations of the raffle.
+	countersDocument = "counters"
+	// counterShards is the number of documents the counters are split into,
+	// so concurrent donations don't contend for a single document.
+	counterShards = 10
+)
+
+// FirestoreStatsStorage is a storage for raffle counters based on Firestore.
+// Counters are incremented by donation storages in the same transactions
+// which write donations. Each transaction increments a random shard
+// and the counters are the sum of all shards.
+type FirestoreStatsStorage struct {
+	firestoreClient *firestore.Client
+	raffleReference *firestore.DocumentRef
+	shardReferences []*firestore.DocumentRef
+}
+
+// NewFirestoreStatsStorage creates a new FirestoreStatsStorage.
+func NewFirestoreStatsStorage(firestoreClient *firestore.Client, raffle *firestore.DocumentRef) *FirestoreStatsStorage {
+	return &FirestoreStatsStorage{
+		firestoreClient: firestoreClient,
+		raffleReference: raffle,
+		shardReferences: counterShardReferences(raffle),
+	}
+}
+
+// Get returns the counters of the raffle summed over the shards.
+func (ss *FirestoreStatsStorage) Get() (*service.RaffleCounters, error) {
+	docs, err := ss.firestoreClient.GetAll(context.Background(), ss.shardReferences)
+	if err != nil {
+		return nil, fmt.Errorf("get counters: %w", err)
+	}
+
+	var sum service.RaffleCounters
+
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+
+		var shard service.RaffleCounters
+		if err := doc.DataTo(&shard); err != nil {
+			return nil, fmt.Errorf("decode counters: %w", err)
+		}
+
+		addCounters(&sum, &shard)
+	}
+
+	return &sum, nil
+}
+
+// Replace replaces the counters, running totals of the prizes
+// and donor sums of the prizes in a single transaction.
+func (ss *FirestoreStatsStorage) Replace(c *service.RaffleCounters) error {
+	err := ss.firestoreClient.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+		prizes, err := tx.Documents(ss.raffleReference.Collection(prizeCollection)).GetAll()
+		if err != nil {
+			return fmt.Errorf("get prizes: %w", err)
+		}
+
+		donors := make(map[string][]*firestore.DocumentSnapshot, len(prizes))
+		for _, prize := range prizes {
+			docs, err := tx.Documents(prize.Ref.Collection(donorCollection)).GetAll()
+			if err != nil {
+				return fmt.Errorf("get donor totals: %w", err)
+			}
+
+			donors[prize.Ref.ID] = docs
+		}
+
+		if err := tx.Set(ss.shardReferences[0], c); err != nil {
+			return fmt.Errorf("set counters: %w", err)
+		}
+
+		for _, ref := range ss.shardReferences[1:] {
+			if err := tx.Delete(ref); err != nil {
+				return fmt.Errorf("delete counter shard: %w", err)
+			}
+		}
+
+		for _, prize := range prizes {
+			counters := c.Prizes[prize.Ref.ID]
+			totals := counters.PrizeTotals()
+
+			err := tx.Update(prize.Ref, []firestore.Update{
+				{Path: totalDonatedField, Value: totals.TotalDonated},
+				{Path: donationCountField, Value: totals.DonationCount},
+				{Path: participantCountField, Value: totals.ParticipantCount},
+			})
+			if err != nil {
+				return fmt.Errorf("update prize totals: %w", err)
+			}
+
+			for _, doc := range donors[prize.Ref.ID] {
+				if counters.Participants[doc.Ref.ID] > 0 {
+					continue
+				}
+
+				if err := tx.Delete(doc.Ref); err != nil {
+					return fmt.Errorf("delete donor total: %w", err)
+				}
+			}
+
+			for participantID, amount := range counters.Participants {
+				if amount <= 0 {
+					continue
+				}
+
+				ref := prize.Ref.Collection(donorCollection).Doc(participantID)
+				if err := tx.Set(ref, donorTotal{Amount: amount}); err != nil {
+					return fmt.Errorf("set donor total: %w", err)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("replace counters: %w", err)
+	}
+
+	return nil
+}
+
+// counterShardReferences returns references to all counter shards of the raffle.
+// The first one is the document counters were kept in before sharding.
+func counterShardReferences(raffle *firestore.DocumentRef) []*firestore.DocumentRef {
+	refs := make([]*firestore.DocumentRef, 0, counterShards)
+	for shard := 0; shard < counterShards; shard++ {
+		refs = append(refs, counterShardReference(raffle, shard))
+	}
+
+	return refs
+}
+
+func counterShardReference(raffle *firestore.DocumentRef, shard int) *firestore.DocumentRef {
+	id := countersDocument
+	if shard > 0 {
+		id = fmt.Sprintf("%s_%d", countersDocument, shard)
+	}
+
+	return raffle.Collection(statsCollection).Doc(id)
+}
+
+// randomCounterShard returns a reference to a random counter shard of the raffle.
+func randomCounterShard(raffle *firestore.DocumentRef) *firestore.DocumentRef {
+	return counterShardReference(raffle, rand.Intn(counterShards))
+}
+
+// addCounters adds counters of a shard to the sum.
+// Keys with zero sums are kept, as they are in a single document.
+func addCounters(sum, shard *service.RaffleCounters) {
+	sum.Raised += shard.Raised
+	sum.Donations += shard.Donations
+	sum.Donors = addCounts(sum.Donors, shard.Donors)
+	sum.Hourly = addCounts(sum.Hourly, shard.Hourly)
+	addDonationCounters(&sum.Raffle, &shard.Raffle)
+
+	for prizeID, counters := range shard.Prizes {
+		if sum.Prizes == nil {
+			sum.Prizes = make(map[string]service.DonationCounters)
+		}
+
+		prize := sum.Prizes[prizeID]
+		addDonationCounters(&prize, &counters)
+		sum.Prizes[prizeID] = prize
+	}
+}
+
+func addDonationCounters(sum, shard *service.DonationCounters) {
+	sum.Raised += shard.Raised
+	sum.Donations += shard.Donations
+	sum.Participants = addCounts(sum.Participants, shard.Participants)
+}
+
+func addCounts(sum, shard map[string]int) map[string]int {
+	for key, value := range shard {
+		if sum == nil {
+			sum = make(map[string]int, len(shard))
+		}
+
+		sum[key] += value
+	}
+
+	return sum
+}
+
+// counterIncrements converts non-zero counters to increments to be merged
+// into a counter shard, so concurrent writes don't override each other.
+// Empty maps are omitted since merging them would replace stored ones.
+func counterIncrements(c *service.RaffleCounters) map[string]any {
+	increments := make(map[string]any)
+
+	addIncrement(increments, "Raised", c.Raised)
+	addIncrement(increments, "Donations", c.Donations)
+	addIncrements(increments, "Donors", c.Donors)
+	addIncrements(increments, "Hourly", c.Hourly)
+
+	if raffle := donationIncrements(c.Raffle); len(raffle) > 0 {
+		increments["Raffle"] = raffle
+	}
+
+	prizes := make(map[string]any)
+	for prizeID, counters := range c.Prizes {
+		if prize := donationIncrements(counters); len(prize) > 0 {
+			prizes[prizeID] = prize
+		}
+	}
+
+	if len(prizes) > 0 {
+		increments["Prizes"] = prizes
+	}
+
+	return increments
+}
+
+func donationIncrements(c service.DonationCounters) map[string]any {
+	increments := make(map[string]any)
+
+	addIncrement(increments, "Raised", c.Raised)
+	addIncrement(increments, "Donations", c.Donations)
+	addIncrements(increments, "Participants", c.Participants)
+
+	return increments
+}
+
+func addIncrement(increments map[string]any, field string, value int) {
+	if value != 0 {
+		increments[field] = firestore.Increment(value)
+	}
+}
+
+func addIncrements(increments map[string]any, field string, values map[string]int) {
+	nested := make(map[string]any)
+	for key, value := range values {
+		addIncrement(nested, key, value)
+	}
+
+	if len(nested) > 0 {
+		increments[field] = nested
+	}
+}
+
+// incrementCounters merges the delta into the counter shard within the transaction.
+func incrementCounters(tx *firestore.Transaction, ref *firestore.DocumentRef, delta *service.RaffleCounters) error {
+	increments := counterIncrements(delta)
+	if len(increments) == 0 {
+		return nil
+	}
+
+	if err := tx.Set(ref, increments, firestore.MergeAll); err != nil {
+		return fmt.Errorf("increment counters: %w", err)
+	}
+
+	return nil
+}
+
+// removePrizeCounters drops counters of the prize from every existing shard
+// and merges the rest of the delta into the first one within the transaction.
+func removePrizeCounters(tx *firestore.Transaction, shards []*firestore.DocumentSnapshot, prizeID string, delta *service.RaffleCounters) error {
+	delete(delta.Prizes, prizeID)
+
+	for i, doc := range shards {
+		if i == 0 {
+			increments := counterIncrements(delta)
+			increments["Prizes"] = map[string]any{prizeID: firestore.Delete}
+
+			if err := tx.Set(doc.Ref, increments, firestore.MergeAll); err != nil {
+				return fmt.Errorf("increment counters: %w", err)
+			}
+
+			continue
+		}
+
+		if !doc.Exists() {
+			continue
+		}
+
+		err := tx.Update(doc.Ref, []firestore.Update{{FieldPath: firestore.FieldPath{"Prizes", prizeID}, Value: firestore.Delete}})
+		if err != nil {
+			return fmt.Errorf("remove prize counters: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/testinfra"
+	fsemulator "github.com/kaznasho/yarmarok/testinfra/firestore"
+)
+
+func TestCounterIncrements(t *testing.T) {
+	var delta service.RaffleCounters
+	delta.RemoveDonation("prize_id_1", &service.Donation{ParticipantID: "participant_1", Amount: 100})
+	delta.AddDonation("prize_id_1", &service.Donation{ParticipantID: "participant_1", Amount: 100})
+
+	require.Empty(t, counterIncrements(&delta), "unchanged counters aren't written")
+
+	delta.AddDonation("", &service.Donation{ParticipantID: "participant_2", Amount: 50})
+
+	require.Equal(t, map[string]any{
+		"Raised":    firestore.Increment(50),
+		"Donations": firestore.Increment(1),
+		"Donors":    map[string]any{"participant_2": firestore.Increment(50)},
+		"Hourly":    map[string]any{"0001-01-01T00": firestore.Increment(50)},
+		"Raffle": map[string]any{
+			"Raised":       firestore.Increment(50),
+			"Donations":    firestore.Increment(1),
+			"Participants": map[string]any{"participant_2": firestore.Increment(50)},
+		},
+	}, counterIncrements(&delta))
+}
+
+func TestAddCounters(t *testing.T) {
+	var sum service.RaffleCounters
+
+	addCounters(&sum, &service.RaffleCounters{})
+	require.Equal(t, service.RaffleCounters{}, sum, "empty shards add no maps")
+
+	addCounters(&sum, &service.RaffleCounters{
+		Raised:    100,
+		Donations: 1,
+		Donors:    map[string]int{"participant_1": 100},
+		Prizes: map[string]service.DonationCounters{
+			"prize_id_1": {Raised: 100, Donations: 1, Participants: map[string]int{"participant_1": 100}},
+		},
+	})
+	addCounters(&sum, &service.RaffleCounters{
+		Raised:    -50,
+		Donations: 0,
+		Donors:    map[string]int{"participant_1": -100, "participant_2": 50},
+		Raffle:    service.DonationCounters{Raised: 50, Donations: 1, Participants: map[string]int{"participant_2": 50}},
+		Prizes: map[string]service.DonationCounters{
+			"prize_id_1": {Raised: -100, Donations: -1, Participants: map[string]int{"participant_1": -100}},
+		},
+	})
+
+	require.Equal(t, service.RaffleCounters{
+		Raised:    50,
+		Donations: 1,
+		Donors:    map[string]int{"participant_1": 0, "participant_2": 50},
+		Raffle:    service.DonationCounters{Raised: 50, Donations: 1, Participants: map[string]int{"participant_2": 50}},
+		Prizes: map[string]service.DonationCounters{
+			"prize_id_1": {Participants: map[string]int{"participant_1": 0}},
+		},
+	}, sum)
+}
+
+func TestCounterShardReferences(t *testing.T) {
+	raffle := (&firestore.Client{}).Doc("raffles/raffle_id_1")
+
+	refs := counterShardReferences(raffle)
+	require.Len(t, refs, counterShards)
+	require.Equal(t, countersDocument, refs[0].ID, "the first shard is the unsharded document")
+
+	ids := make(map[string]bool)
+	for _, ref := range refs {
+		ids[ref.ID] = true
+	}
+
+	require.Len(t, ids, counterShards)
+}
+
+func TestStatsStorage(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := fsemulator.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	org := &service.Organizer{ID: "organizer_id_1"}
+	require.NoError(t, os.Create(org))
+
+	raf := service.Raffle{ID: "raffle_id_1"}
+	rs := NewFirestoreRaffleStorage(os.firestoreClient, os.collectionReference.Doc(org.ID).Collection(raffleCollection), raf.ID)
+	require.NoError(t, rs.Create(&raf))
+
+	prizes := rs.PrizeStorage(raf.ID)
+	require.NoError(t, prizes.Create(&service.Prize{ID: "prize_id_1"}))
+
+	ss := rs.StatsStorage(raf.ID)
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	t.Run("No donations", func(t *testing.T) {
+		counters, err := ss.Get()
+		require.NoError(t, err)
+		require.Equal(t, &service.RaffleCounters{}, counters)
+	})
+
+	t.Run("Counters follow donations", func(t *testing.T) {
+		prizeDonations := prizes.DonationStorage("prize_id_1")
+
+		d := &service.Donation{ID: "donation_id_1", ParticipantID: "participant_1", Amount: 100, CreatedAt: at}
+		require.NoError(t, prizeDonations.Create(d))
+
+		d.Amount = 70
+		require.NoError(t, prizeDonations.Update(d))
+
+		require.NoError(t, rs.DonationStorage(raf.ID).Create(
+			&service.Donation{ID: "donation_id_2", ParticipantID: "participant_2", Amount: 50, CreatedAt: at},
+		))
+
+		require.NoError(t, prizes.CreateDonations(map[string][]service.Donation{
+			"prize_id_1": {{ID: "donation_id_3", ParticipantID: "participant_2", Amount: 30, CreatedAt: at}},
+		}))
+
+		require.NoError(t, rs.PaymentStorage(raf.ID).Create(&service.Payment{
+			ID:            "payment_id_1",
+			ParticipantID: "participant_1",
+			Amount:        20,
+			Allocations:   []service.PaymentAllocation{{PrizeID: "prize_id_1", DonationID: "donation_id_4", Amount: 20}},
+			CreatedAt:     at,
+		}))
+
+		require.NoError(t, prizeDonations.Delete("donation_id_3"))
+
+		counters, err := ss.Get()
+		require.NoError(t, err)
+		require.Equal(t, &service.RaffleCounters{
+			Raised:    140,
+			Donations: 3,
+			Donors:    map[string]int{"participant_1": 90, "participant_2": 50},
+			Hourly:    map[string]int{"2024-05-01T09": 140},
+			Raffle:    service.DonationCounters{Raised: 50, Donations: 1, Participants: map[string]int{"participant_2": 50}},
+			Prizes: map[string]service.DonationCounters{
+				"prize_id_1": {Raised: 90, Donations: 2, Participants: map[string]int{"participant_1": 90, "participant_2": 0}},
+			},
+		}, counters)
+	})
+
+	t.Run("Counters of deleted prize", func(t *testing.T) {
+		require.NoError(t, prizes.Create(&service.Prize{ID: "prize_id_2"}))
+		require.NoError(t, prizes.DonationStorage("prize_id_2").Create(
+			&service.Donation{ID: "donation_id_5", ParticipantID: "participant_2", Amount: 10, CreatedAt: at},
+		))
+
+		// Spread counters of the prize over several shards.
+		for shard := 1; shard < 3; shard++ {
+			_, err := counterShardReference(rs.collectionReference.Doc(raf.ID), shard).Set(context.Background(), map[string]any{
+				"Prizes": map[string]any{"prize_id_2": map[string]any{"Raised": 0}},
+			}, firestore.MergeAll)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, prizes.Delete("prize_id_2"))
+		require.ErrorIs(t, prizes.Delete("prize_id_2"), service.ErrNotFound)
+
+		counters, err := ss.Get()
+		require.NoError(t, err)
+		require.Equal(t, 140, counters.Raised)
+		require.Equal(t, 3, counters.Donations)
+		require.Equal(t, 50, counters.Donors["participant_2"])
+		require.NotContains(t, counters.Prizes, "prize_id_2")
+
+		donors, err := rs.collectionReference.Doc(raf.ID).Collection(prizeCollection).Doc("prize_id_2").
+			Collection(donorCollection).Documents(context.Background()).GetAll()
+		require.NoError(t, err)
+		require.Empty(t, donors)
+	})
+}
+
+var _ service.StatsStorage = (*FirestoreStatsStorage)(nil)
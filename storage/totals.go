@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+// donorCollection holds sums donated to a prize by each participant,
+// so the number of participants of the prize can be kept in transactions.
+const donorCollection = "donors"
+
+// Names of the running totals of service.Prize.
+const (
+	totalDonatedField     = "TotalDonated"
+	donationCountField    = "DonationCount"
+	participantCountField = "ParticipantCount"
+)
+
+// prizeTotalsFields are kept when a prize is written by the prize storage,
+// since they are only changed along with donations.
+var prizeTotalsFields = []string{totalDonatedField, donationCountField, participantCountField}
+
+// donorTotal is a sum donated to a prize by a participant.
+type donorTotal struct {
+	Amount int
+}
+
+// donationTotals keeps the raffle counters, the running totals of prizes
+// and the donor sums consistent with donations written in a transaction.
+// Donation changes are collected first, then read is called
+// before any write of the transaction and write is called last.
+type donationTotals struct {
+	raffleReference *firestore.DocumentRef
+	delta           service.RaffleCounters
+	donors          map[string]*donorChange
+}
+
+// donorChange is a change of the sum donated to a prize by a participant.
+type donorChange struct {
+	prizeID   string
+	reference *firestore.DocumentRef
+	current   int
+	delta     int
+}
+
+func newDonationTotals(raffle *firestore.DocumentRef) *donationTotals {
+	return &donationTotals{raffleReference: raffle}
+}
+
+func (t *donationTotals) add(prizeID string, d *service.Donation) {
+	t.delta.AddDonation(prizeID, d)
+}
+
+func (t *donationTotals) remove(prizeID string, d *service.Donation) {
+	t.delta.RemoveDonation(prizeID, d)
+}
+
+// read reads current donor sums of participants whose donations have changed.
+func (t *donationTotals) read(tx *firestore.Transaction) error {
+	t.donors = make(map[string]*donorChange)
+	refs := make([]*firestore.DocumentRef, 0)
+
+	for prizeID, counters := range t.delta.Prizes {
+		for participantID, amount := range counters.Participants {
+			if amount == 0 {
+				continue
+			}
+
+			ref := t.prizeReference(prizeID).Collection(donorCollection).Doc(participantID)
+			t.donors[ref.Path] = &donorChange{prizeID: prizeID, reference: ref, delta: amount}
+			refs = append(refs, ref)
+		}
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	docs, err := tx.GetAll(refs)
+	if err != nil {
+		return fmt.Errorf("get donor totals: %w", err)
+	}
+
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+
+		var total donorTotal
+		if err := doc.DataTo(&total); err != nil {
+			return fmt.Errorf("decode donor total: %w", err)
+		}
+
+		t.donors[doc.Ref.Path].current = total.Amount
+	}
+
+	return nil
+}
+
+// write writes the changed donor sums, prize totals and raffle counters.
+func (t *donationTotals) write(tx *firestore.Transaction) error {
+	participantDeltas := make(map[string]int)
+
+	for _, donor := range t.donors {
+		updated := donor.current + donor.delta
+
+		switch {
+		case donor.current <= 0 && updated > 0:
+			participantDeltas[donor.prizeID]++
+		case donor.current > 0 && updated <= 0:
+			participantDeltas[donor.prizeID]--
+		}
+
+		if updated <= 0 {
+			if err := tx.Delete(donor.reference); err != nil {
+				return fmt.Errorf("delete donor total: %w", err)
+			}
+
+			continue
+		}
+
+		if err := tx.Set(donor.reference, donorTotal{Amount: updated}); err != nil {
+			return fmt.Errorf("set donor total: %w", err)
+		}
+	}
+
+	for prizeID, counters := range t.delta.Prizes {
+		updates := make([]firestore.Update, 0, 3)
+
+		for field, value := range map[string]int{
+			totalDonatedField:     counters.Raised,
+			donationCountField:    counters.Donations,
+			participantCountField: participantDeltas[prizeID],
+		} {
+			if value != 0 {
+				updates = append(updates, firestore.Update{Path: field, Value: firestore.Increment(value)})
+			}
+		}
+
+		if len(updates) == 0 {
+			continue
+		}
+
+		if err := tx.Update(t.prizeReference(prizeID), updates); err != nil {
+			return fmt.Errorf("update prize totals: %w", err)
+		}
+	}
+
+	return incrementCounters(tx, randomCounterShard(t.raffleReference), &t.delta)
+}
+
+func (t *donationTotals) prizeReference(prizeID string) *firestore.DocumentRef {
+	return t.raffleReference.Collection(prizeCollection).Doc(prizeID)
+}
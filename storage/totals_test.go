@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/testinfra"
+	fsemulator "github.com/kaznasho/yarmarok/testinfra/firestore"
+)
+
+func TestPrizeTotals(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := fsemulator.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	org := &service.Organizer{ID: "organizer_id_1"}
+	require.NoError(t, os.Create(org))
+
+	raf := service.Raffle{ID: "raffle_id_1"}
+	rs := NewFirestoreRaffleStorage(os.firestoreClient, os.collectionReference.Doc(org.ID).Collection(raffleCollection), raf.ID)
+	require.NoError(t, rs.Create(&raf))
+
+	prizes := rs.PrizeStorage(raf.ID)
+	require.NoError(t, prizes.Create(&service.Prize{ID: "prize_id_1", Name: "Bike"}))
+
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	requireTotals := func(t *testing.T, expected service.PrizeTotals) {
+		prize, err := prizes.Get("prize_id_1")
+		require.NoError(t, err)
+		require.Equal(t, expected, prize.Totals())
+	}
+
+	t.Run("Totals follow donations", func(t *testing.T) {
+		prizeDonations := prizes.DonationStorage("prize_id_1")
+
+		d := &service.Donation{ID: "donation_id_1", ParticipantID: "participant_1", Amount: 100, CreatedAt: at}
+		require.NoError(t, prizeDonations.Create(d))
+		requireTotals(t, service.PrizeTotals{TotalDonated: 100, DonationCount: 1, ParticipantCount: 1})
+
+		d.Amount = 70
+		require.NoError(t, prizeDonations.Update(d))
+		requireTotals(t, service.PrizeTotals{TotalDonated: 70, DonationCount: 1, ParticipantCount: 1})
+
+		require.NoError(t, prizes.CreateDonations(map[string][]service.Donation{
+			"prize_id_1": {{ID: "donation_id_2", ParticipantID: "participant_2", Amount: 30, CreatedAt: at}},
+		}))
+		requireTotals(t, service.PrizeTotals{TotalDonated: 100, DonationCount: 2, ParticipantCount: 2})
+
+		require.NoError(t, rs.PaymentStorage(raf.ID).Create(&service.Payment{
+			ID:            "payment_id_1",
+			ParticipantID: "participant_1",
+			Amount:        20,
+			Allocations:   []service.PaymentAllocation{{PrizeID: "prize_id_1", DonationID: "donation_id_3", Amount: 20}},
+			CreatedAt:     at,
+		}))
+		requireTotals(t, service.PrizeTotals{TotalDonated: 120, DonationCount: 3, ParticipantCount: 2})
+
+		require.NoError(t, prizeDonations.Delete("donation_id_2"))
+		requireTotals(t, service.PrizeTotals{TotalDonated: 90, DonationCount: 2, ParticipantCount: 1})
+
+		require.NoError(t, rs.PaymentStorage(raf.ID).Delete("payment_id_1"))
+		requireTotals(t, service.PrizeTotals{TotalDonated: 70, DonationCount: 1, ParticipantCount: 1})
+	})
+
+	t.Run("Prize update keeps totals", func(t *testing.T) {
+		require.NoError(t, prizes.Update(&service.Prize{ID: "prize_id_1", Name: "Red bike"}))
+
+		prize, err := prizes.Get("prize_id_1")
+		require.NoError(t, err)
+		require.Equal(t, "Red bike", prize.Name)
+		require.Equal(t, service.PrizeTotals{TotalDonated: 70, DonationCount: 1, ParticipantCount: 1}, prize.Totals())
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		var c service.RaffleCounters
+		c.AddDonation("prize_id_1", &service.Donation{ParticipantID: "participant_1", Amount: 70, CreatedAt: at})
+		c.AddDonation("prize_id_1", &service.Donation{ParticipantID: "participant_3", Amount: 10, CreatedAt: at})
+
+		ss := rs.StatsStorage(raf.ID)
+		require.NoError(t, ss.Replace(&c))
+
+		counters, err := ss.Get()
+		require.NoError(t, err)
+		require.Equal(t, &c, counters)
+		requireTotals(t, service.PrizeTotals{TotalDonated: 80, DonationCount: 2, ParticipantCount: 2})
+
+		require.NoError(t, prizes.DonationStorage("prize_id_1").Delete("donation_id_1"))
+		requireTotals(t, service.PrizeTotals{TotalDonated: 10, DonationCount: 1, ParticipantCount: 1})
+	})
+}
+
+func TestMergeParticipantsTotals(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := fsemulator.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	org := &service.Organizer{ID: "organizer_id_1"}
+	require.NoError(t, os.Create(org))
+
+	raf := service.Raffle{ID: "raffle_id_1"}
+	rs := NewFirestoreRaffleStorage(os.firestoreClient, os.collectionReference.Doc(org.ID).Collection(raffleCollection), raf.ID)
+	require.NoError(t, rs.Create(&raf))
+
+	participants := rs.ParticipantStorage(raf.ID)
+	require.NoError(t, participants.Create(&service.Participant{ID: "participant_1", Name: "Target", Phone: "+380501112233"}))
+	require.NoError(t, participants.Create(&service.Participant{ID: "participant_2", Name: "Duplicate", Phone: "+380504445566"}))
+
+	prizes := rs.PrizeStorage(raf.ID)
+	require.NoError(t, prizes.Create(&service.Prize{ID: "prize_id_1", Name: "Bike", TicketCost: 10}))
+
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	require.NoError(t, prizes.DonationStorage("prize_id_1").Create(
+		&service.Donation{ID: "donation_id_1", ParticipantID: "participant_2", Amount: 10, CreatedAt: at},
+	))
+	require.NoError(t, rs.PaymentStorage(raf.ID).Create(&service.Payment{
+		ID:            "payment_id_1",
+		ParticipantID: "participant_2",
+		Amount:        30,
+		Allocations:   []service.PaymentAllocation{{PrizeID: "prize_id_1", DonationID: "donation_id_2", Amount: 30}},
+		CreatedAt:     at,
+	}))
+
+	svc := service.NewRaffleManager(rs).ParticipantService(raf.ID)
+
+	details, err := svc.Merge("participant_1", &service.MergeParticipantsRequest{DuplicateIDs: []string{"participant_2"}})
+	require.NoError(t, err)
+	require.Equal(t, 40, details.TotalDonation)
+
+	prize, err := prizes.Get("prize_id_1")
+	require.NoError(t, err)
+	require.Equal(t, service.PrizeTotals{TotalDonated: 40, DonationCount: 2, ParticipantCount: 1}, prize.Totals())
+
+	counters, err := rs.StatsStorage(raf.ID).Get()
+	require.NoError(t, err)
+	require.Equal(t, 40, counters.Raised)
+	require.Equal(t, 2, counters.Donations)
+	require.Equal(t, 40, counters.Donors["participant_1"])
+	require.Zero(t, counters.Donors["participant_2"])
+	require.Equal(t, 40, counters.Prizes["prize_id_1"].Raised)
+	t.Run("locked_donation", func(t *testing.T) {
+		require.NoError(t, participants.Create(&service.Participant{ID: "participant_3", Name: "Locked", Phone: "+380507778899"}))
+
+		locked := &service.Donation{ID: "donation_id_3", ParticipantID: "participant_3", Amount: 10, ShiftID: "shift_id_1", CreatedAt: at}
+		require.NoError(t, prizes.DonationStorage("prize_id_1").Create(locked))
+
+		err := participants.Merge(&service.ParticipantMerge{
+			ParticipantID: "participant_1",
+			DuplicateIDs:  []string{"participant_3"},
+			Donations:     map[string][]service.Donation{"prize_id_1": {*locked}},
+		})
+		require.ErrorIs(t, err, service.ErrShiftClosed)
+
+		_, err = participants.Get("participant_3")
+		require.NoError(t, err)
+
+		d, err := prizes.DonationStorage("prize_id_1").Get("donation_id_3")
+		require.NoError(t, err)
+		require.Equal(t, "participant_3", d.ParticipantID)
+	})
+}
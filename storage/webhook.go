@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+const (
+	webhookIDField     = "WebhookID"
+	eventTypeField     = "EventType"
+	nextAttemptAtField = "NextAttemptAt"
+)
+
+// FirestoreWebhookStorage is a storage for webhooks based on Firestore.
+type FirestoreWebhookStorage struct {
+	*StorageBase[service.Webhook]
+}
+
+// NewFirestoreWebhookStorage creates a new FirestoreWebhookStorage.
+func NewFirestoreWebhookStorage(firestoreClient *firestore.Client, collectionReference *firestore.CollectionRef) *FirestoreWebhookStorage {
+	webhookIDExtractor := IDExtractor[service.Webhook](
+		func(w *service.Webhook) string {
+			return w.ID
+		},
+	)
+
+	return &FirestoreWebhookStorage{
+		StorageBase: NewStorageBase(firestoreClient, collectionReference, webhookIDExtractor),
+	}
+}
+
+// FirestoreDeliveryStorage is a storage for deliveries to webhooks based on Firestore.
+type FirestoreDeliveryStorage struct {
+	*StorageBase[service.Delivery]
+}
+
+// NewFirestoreDeliveryStorage creates a new FirestoreDeliveryStorage.
+func NewFirestoreDeliveryStorage(firestoreClient *firestore.Client, collectionReference *firestore.CollectionRef) *FirestoreDeliveryStorage {
+	deliveryIDExtractor := IDExtractor[service.Delivery](
+		func(d *service.Delivery) string {
+			return d.ID
+		},
+	)
+
+	return &FirestoreDeliveryStorage{
+		StorageBase: NewStorageBase(firestoreClient, collectionReference, deliveryIDExtractor),
+	}
+}
+
+// GetByWebhook returns deliveries to the webhook, the latest first.
+func (ds *FirestoreDeliveryStorage) GetByWebhook(webhookID string) ([]service.Delivery, error) {
+	deliveries, err := ds.query(ds.collectionReference.Where(webhookIDField, "==", webhookID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Sorted here rather than in the query, so it needs no composite index.
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+
+	return deliveries, nil
+}
+
+// GetByEventType returns deliveries of events of the type.
+func (ds *FirestoreDeliveryStorage) GetByEventType(eventType service.EventType) ([]service.Delivery, error) {
+	return ds.query(ds.collectionReference.Where(eventTypeField, "==", eventType))
+}
+
+// GetDue returns pending deliveries which next attempt is due by the moment.
+// Only pending deliveries have the next attempt time set.
+func (ds *FirestoreDeliveryStorage) GetDue(now time.Time) ([]service.Delivery, error) {
+	return ds.query(ds.collectionReference.Where(nextAttemptAtField, "<=", now))
+}
+
+// redactContacts removes contacts of winners from payloads of prize.played deliveries.
+// It returns the number of updated deliveries.
+func (ds *FirestoreDeliveryStorage) redactContacts() (int, error) {
+	deliveries, err := ds.GetByEventType(service.EventPrizePlayed)
+	if err != nil {
+		return 0, err
+	}
+
+	redacted := 0
+
+	for i := range deliveries {
+		changed, err := deliveries[i].RedactContacts()
+		if err != nil {
+			return redacted, fmt.Errorf("delivery %q: %w", deliveries[i].ID, err)
+		}
+
+		if !changed {
+			continue
+		}
+
+		if err := ds.Update(&deliveries[i]); err != nil {
+			return redacted, err
+		}
+
+		redacted++
+	}
+
+	return redacted, nil
+}
+
+func (ds *FirestoreDeliveryStorage) query(q firestore.Query) ([]service.Delivery, error) {
+	docs, err := q.Documents(context.Background()).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("query deliveries: %w", err)
+	}
+
+	deliveries := make([]service.Delivery, 0, len(docs))
+	for _, doc := range docs {
+		var d service.Delivery
+		if err := doc.DataTo(&d); err != nil {
+			return nil, fmt.Errorf("decode delivery: %w", err)
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
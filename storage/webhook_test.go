@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/testinfra"
+	"github.com/kaznasho/yarmarok/testinfra/firestore"
+)
+
+func TestWebhookStorage(t *testing.T) {
+	testinfra.SkipIfNotIntegrationRun(t)
+
+	firestoreInstance, err := firestore.RunInstance(t)
+	require.NoError(t, err)
+
+	os := NewFirestoreOrganizerStorage(firestoreInstance.Client())
+
+	org := &service.Organizer{ID: "organizer_id_1"}
+	require.NoError(t, os.Create(org))
+
+	ws := os.WebhookStorage(org.ID)
+
+	webhook := service.Webhook{
+		ID:     "webhook_id_1",
+		URL:    "https://example.com/hooks",
+		Secret: "0123456789abcdef",
+		Events: []service.EventType{service.EventDonationCreated},
+	}
+	require.NoError(t, ws.Create(&webhook))
+
+	stored, err := ws.Get(webhook.ID)
+	require.NoError(t, err)
+	require.Equal(t, webhook.Secret, stored.Secret, "the secret is stored even though it's not encoded to JSON")
+
+	ds := os.DeliveryStorage(org.ID)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	later := now.Add(time.Hour)
+
+	deliveries := []service.Delivery{
+		{ID: "due", WebhookID: webhook.ID, Status: service.DeliveryPending, NextAttemptAt: &now, CreatedAt: now},
+		{ID: "not_yet", WebhookID: webhook.ID, Status: service.DeliveryPending, NextAttemptAt: &later, CreatedAt: now.Add(time.Second)},
+		{ID: "succeeded", WebhookID: webhook.ID, Status: service.DeliverySucceeded, CreatedAt: now.Add(2 * time.Second)},
+		{ID: "other", WebhookID: "webhook_id_2", EventType: service.EventPrizePlayed, Status: service.DeliveryFailed, CreatedAt: now},
+	}
+
+	for i := range deliveries {
+		require.NoError(t, ds.Create(&deliveries[i]))
+	}
+
+	due, err := ds.GetDue(now)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, "due", due[0].ID)
+
+	byWebhook, err := ds.GetByWebhook(webhook.ID)
+	require.NoError(t, err)
+	require.Len(t, byWebhook, 3)
+	require.Equal(t, "succeeded", byWebhook[0].ID, "the latest delivery goes first")
+
+	played, err := ds.GetByEventType(service.EventPrizePlayed)
+	require.NoError(t, err)
+	require.Len(t, played, 1)
+	require.Equal(t, "other", played[0].ID)
+}
@@ -4,6 +4,7 @@ package main
 
 import (
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -16,8 +17,12 @@ import (
 
 	"github.com/kaznasho/yarmarok/function"
 	"github.com/kaznasho/yarmarok/testinfra/firestore"
+	"github.com/kaznasho/yarmarok/web"
 )
 
+// schedulerInterval is how often due prizes are played.
+const schedulerInterval = time.Minute
+
 type testEnv struct {
 	cleanups []func()
 	tmpDirs  []string
@@ -98,6 +103,26 @@ func main() {
 		}
 	}()
 
+	go runScheduler(t, port)
+
 	<-sigs
 	t.runCleanup()
 }
+
+// runScheduler imitates Cloud Scheduler by periodically
+// triggering the endpoint that plays due prizes.
+func runScheduler(t *testEnv, port string) {
+	url := "http://localhost:" + port + web.ApiPath + web.SchedulerPath + web.PlayDuePath
+
+	for range time.Tick(schedulerInterval) {
+		resp, err := http.Post(url, "application/json", nil)
+		if err != nil {
+			t.Log("Failed to play due prizes:", err)
+			continue
+		}
+
+		if err := resp.Body.Close(); err != nil {
+			t.Log("Failed to close response body:", err)
+		}
+	}
+}
@@ -6,6 +6,8 @@ export const usePrizeStore = defineStore({
     state: () => ({
         prizes: <Prizes>[],
         selectedPrize: <Prize | null>null,
+        // Idempotency keys of plays not known to be done, by prize ID.
+        playKeys: <Record<string, string>>{},
     }),
     actions: {
         async getPrizes(raffleId: string) {
@@ -78,15 +80,26 @@ export const usePrizeStore = defineStore({
             this.selectLastPrize()
         },
         async playPrize(raffleId: string, id: string) {
+            // The key is kept until the play succeeds, so that retries
+            // of the same play, automatic or not, are not played twice.
+            const key = this.playKeys[id] ??= crypto.randomUUID()
+
             const { error } = await useApiFetch<{
                 isPlayed: boolean
             }>(
                 `/api/raffles/${ raffleId }/prizes/${ id }/play`, {
-                    method: "GET",
+                    method: "POST",
+                    headers: {
+                        "Content-Type": "application/json",
+                        "Idempotency-Key": key,
+                    },
+                    retry: 2,
                 })
             if (error.value) {
                 throw error.value
             }
+
+            delete this.playKeys[id]
         },
         selectFirstPrize() {
             this.selectedPrize = this.prizes.length === 0 ? null : this.prizes[0]
@@ -15,7 +15,7 @@ export const newParticipantSchema = object({
         .required("Ім'я обов'язкове"),
     phone: string()
         .required("Номер телефону обов'язковий")
-        .matches(/^\+380\d{9,10}$/, "Номер телефону повинен бути вигляду: +380112233444"),
+        .matches(/^\+?[\d\s()-]{7,20}$/, "Номер телефону повинен бути вигляду: +380112233444"),
     note: string(),
 })
 
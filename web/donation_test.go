@@ -44,6 +44,7 @@ func (s *DonationSuite) SetupTest() {
 	s.organizerService.EXPECT().RaffleService(s.organizerID).Return(s.raffleService).AnyTimes()
 	s.raffleService.EXPECT().PrizeService(s.raffleID).Return(s.prizeService).AnyTimes()
 	s.prizeService.EXPECT().DonationService(s.prizeID).Return(s.donationService, nil).AnyTimes()
+	s.raffleService.EXPECT().DonationService(s.raffleID).Return(s.donationService, nil).AnyTimes()
 
 	var err error
 	s.router, err = NewRouter(s.organizerService, logger.NewLogger(logger.LevelDebug))
@@ -58,6 +59,7 @@ func (s *DonationSuite) TestCreate() {
 		donationNew := &service.DonationRequest{
 			Amount:        100,
 			ParticipantID: "participant_id_1",
+			EnteredBy:     s.organizerID,
 		}
 
 		req, err := newRequestJSON(http.MethodPost, donationPath, s.organizerID, donationNew)
@@ -75,6 +77,7 @@ func (s *DonationSuite) TestCreate() {
 		donationNew := &service.DonationRequest{
 			Amount:        100,
 			ParticipantID: "participant_id_1",
+			EnteredBy:     s.organizerID,
 		}
 
 		req, err := newRequestJSON(http.MethodPost, donationPath, s.organizerID, donationNew)
@@ -97,7 +100,21 @@ func (s *DonationSuite) TestCreate() {
 		writer := httptest.NewRecorder()
 		s.router.ServeHTTP(writer, req)
 
-		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+		s.Require().Equal(http.StatusBadRequest, writer.Code)
+	})
+
+	s.Run("negative_amount", func() {
+		invalidPrizeID := "prize_id_2"
+		s.prizeService.EXPECT().DonationService(invalidPrizeID).Return(service.NewDonationManager(nil), nil)
+
+		path := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, invalidPrizeID, DonationsPath)
+		req, err := newRequestJSON(http.MethodPost, path, s.organizerID, &service.DonationRequest{Amount: -100, ParticipantID: "participant_id_1"})
+		s.Require().NoError(err)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusBadRequest, writer.Code)
 	})
 }
 
@@ -147,7 +164,21 @@ func (s *DonationSuite) TestEdit() {
 		writer := httptest.NewRecorder()
 		s.router.ServeHTTP(writer, req)
 
-		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+		s.Require().Equal(http.StatusBadRequest, writer.Code)
+	})
+
+	s.Run("negative_amount", func() {
+		invalidPrizeID := "prize_id_2"
+		s.prizeService.EXPECT().DonationService(invalidPrizeID).Return(service.NewDonationManager(nil), nil)
+
+		path := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, invalidPrizeID, DonationsPath, s.donationID)
+		req, err := newRequestJSON(http.MethodPut, path, s.organizerID, &service.DonationRequest{Amount: -100, ParticipantID: "participant_id_1"})
+		s.Require().NoError(err)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusBadRequest, writer.Code)
 	})
 }
 
@@ -221,3 +252,49 @@ func (s *DonationSuite) TestList() {
 		s.Require().Equal(http.StatusInternalServerError, writer.Code)
 	})
 }
+
+func (s *DonationSuite) TestRaffleDonations() {
+	donationsPath := joinPath(ApiPath, RafflesPath, s.raffleID, DonationsPath)
+
+	s.Run("create", func() {
+		donationNew := &service.DonationRequest{
+			Amount:        100,
+			ParticipantID: "participant_id_1",
+			EnteredBy:     s.organizerID,
+		}
+
+		req, err := newRequestJSON(http.MethodPost, donationsPath, s.organizerID, donationNew)
+		s.Require().NoError(err)
+
+		s.donationService.EXPECT().Create(donationNew).Return(s.donationID, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("list", func() {
+		req, err := newRequestJSON(http.MethodGet, donationsPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.donationService.EXPECT().List().Return([]service.Donation{{ID: s.donationID}}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("delete", func() {
+		req, err := newRequestJSON(http.MethodDelete, joinPath(donationsPath, s.donationID), s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.donationService.EXPECT().Delete(s.donationID).Return(nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+}
@@ -0,0 +1,27 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+// WithKeyRotator enables the scheduler endpoint re-encrypting
+// personal data with the current key.
+func WithKeyRotator(rotator service.KeyRotator) RouterOption {
+	return func(r *Router) {
+		r.keyRotator = rotator
+	}
+}
+
+// rotateKeys re-encrypts personal data encrypted with previous keys.
+// It is meant to be triggered by Cloud Scheduler.
+func (r *Router) rotateKeys(w http.ResponseWriter, _ *http.Request) {
+	rotation, err := r.keyRotator.RotateKeys()
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	r.respond(w, rotation)
+}
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/web/mocks"
+)
+
+// keyRotatorFunc is a service.KeyRotator of a function.
+type keyRotatorFunc func() (*service.KeyRotation, error)
+
+func (f keyRotatorFunc) RotateKeys() (*service.KeyRotation, error) {
+	return f()
+}
+
+func TestRotateKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	osMock := mocks.NewMockOrganizerService(ctrl)
+
+	rotateKeysPath := joinPath(ApiPath, SchedulerPath, RotateKeysPath)
+
+	t.Run("success", func(t *testing.T) {
+		rotator := keyRotatorFunc(func() (*service.KeyRotation, error) {
+			return &service.KeyRotation{Key: "2024-05", Participants: 3, Prizes: 1}, nil
+		})
+
+		router, err := NewRouter(osMock, logger.NewNoOpLogger(), WithKeyRotator(rotator), testSchedulerAuth())
+		require.NoError(t, err)
+
+		req, err := newSchedulerRequest(rotateKeysPath)
+		require.NoError(t, err)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, req)
+		require.Equal(t, http.StatusOK, writer.Code)
+		assertJSONResponse(t, service.KeyRotation{Key: "2024-05", Participants: 3, Prizes: 1}, writer.Body)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rotator := keyRotatorFunc(func() (*service.KeyRotation, error) {
+			return nil, assert.AnError
+		})
+
+		router, err := NewRouter(osMock, logger.NewNoOpLogger(), WithKeyRotator(rotator), testSchedulerAuth())
+		require.NoError(t, err)
+
+		req, err := newSchedulerRequest(rotateKeysPath)
+		require.NoError(t, err)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, req)
+		require.Equal(t, http.StatusInternalServerError, writer.Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router, err := NewRouter(osMock, logger.NewNoOpLogger(), testSchedulerAuth())
+		require.NoError(t, err)
+
+		req, err := newSchedulerRequest(rotateKeysPath)
+		require.NoError(t, err)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, req)
+		require.Equal(t, http.StatusNotFound, writer.Code)
+	})
+}
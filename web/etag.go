@@ -0,0 +1,92 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// versionExpecter is an edit request which can be made conditional
+// on the version of the entity, see service.Precondition.
+type versionExpecter interface {
+	ExpectVersion(time.Time)
+}
+
+// versionWriter is an edit request which tells the version
+// the entity is written with, see service.Precondition.
+type versionWriter interface {
+	WrittenVersion() time.Time
+}
+
+// setETag sets the ETag header to the version of the entity if it's versioned.
+func setETag(rw http.ResponseWriter, entity any) {
+	if v, ok := entity.(service.Versioned); ok {
+		setVersionETag(rw, v.Version())
+	}
+}
+
+// setWrittenETag sets the ETag header to the version the edit request
+// has written the entity with, so it can be edited again without reading it.
+func setWrittenETag(rw http.ResponseWriter, in any) {
+	if w, ok := in.(versionWriter); ok {
+		setVersionETag(rw, w.WrittenVersion())
+	}
+}
+
+// setVersionETag sets the ETag header unless the version is unknown.
+func setVersionETag(rw http.ResponseWriter, version time.Time) {
+	if version.IsZero() {
+		return
+	}
+
+	rw.Header().Set(etagHeader, formatETag(version))
+}
+
+// expectIfMatch makes the edit request conditional on the version
+// given in the If-Match header. Requests without the header
+// or with "*" edit the entity regardless of its version.
+func expectIfMatch(req *http.Request, in any) error {
+	tag := req.Header.Get(ifMatchHeader)
+	if tag == "" || tag == "*" {
+		return nil
+	}
+
+	e, ok := in.(versionExpecter)
+	if !ok {
+		return nil
+	}
+
+	version, err := parseETag(tag)
+	if err != nil {
+		return err
+	}
+
+	e.ExpectVersion(version)
+
+	return nil
+}
+
+// formatETag formats the version as a strong entity tag.
+func formatETag(version time.Time) string {
+	return strconv.Quote(strconv.FormatInt(version.UnixNano(), 10))
+}
+
+// parseETag parses an entity tag formatted by formatETag.
+// Tags which can't be parsed never match, so service.ErrVersionMismatch is returned.
+func parseETag(tag string) (time.Time, error) {
+	nanos, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+	if err != nil || nanos <= 0 {
+		return time.Time{}, fmt.Errorf("%w: unknown entity tag %s", service.ErrVersionMismatch, tag)
+	}
+
+	return time.Unix(0, nanos), nil
+}
@@ -2,6 +2,7 @@ package web
 
 import (
 	"net/http"
+	"reflect"
 
 	"github.com/go-chi/chi"
 )
@@ -14,6 +15,9 @@ type (
 	Edit[I any]   func(id string, upd I) error
 	Delete        func(id string) error
 	List[O any]   func() ([]O, error)
+
+	// Action performs an operation on an existing entity.
+	Action[I, O any] func(id string, in I) (O, error)
 )
 
 // CreateHandler is a wrapper around a service method
@@ -69,6 +73,7 @@ func NewGetHandler[O any](router *Router, fn Get[O]) GetHandler[O] {
 }
 
 // Handle handles a get request.
+// The ETag header is set to the version of versioned objects.
 func (h GetHandler[O]) Handle(rw http.ResponseWriter, req *http.Request) {
 	id := lastURLParam(req)
 
@@ -78,6 +83,7 @@ func (h GetHandler[O]) Handle(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	setETag(rw, out)
 	h.respond(rw, out)
 }
 
@@ -118,6 +124,8 @@ func NewEditHandler[I any](router *Router, fn Edit[I]) EditHandler[I] {
 }
 
 // Handle handles an edit request.
+// The request is conditional on the version given in the If-Match header.
+// The ETag header is set to the new version if it's known.
 func (h EditHandler[I]) Handle(rw http.ResponseWriter, req *http.Request) {
 	var in I
 	if err := h.decodeBody(req.Body, &in); err != nil {
@@ -125,11 +133,18 @@ func (h EditHandler[I]) Handle(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := expectIfMatch(req, in); err != nil {
+		h.respondErr(rw, err)
+		return
+	}
+
 	id := lastURLParam(req)
 	if err := h.Edit(id, in); err != nil {
 		h.respondErr(rw, err)
 		return
 	}
+
+	setWrittenETag(rw, in)
 }
 
 // DeleteHandler is a wrapper around a service method
@@ -186,3 +201,51 @@ func (h ListHandler[O]) Handle(rw http.ResponseWriter, _ *http.Request) {
 type ListResponse[O any] struct {
 	Items []O `json:"items"`
 }
+
+// ActionHandler is a wrapper around a service method
+// that performs an operation on an object by ID.
+type ActionHandler[I, O any] struct {
+	Action[I, O]
+	*Router
+}
+
+// NewActionHandler creates a new ActionHandler.
+func NewActionHandler[I, O any](router *Router, fn Action[I, O]) ActionHandler[I, O] {
+	return ActionHandler[I, O]{
+		Action: fn,
+		Router: router,
+	}
+}
+
+// Handle handles an action request.
+// Requests without a body perform the action with the zero request.
+func (h ActionHandler[I, O]) Handle(rw http.ResponseWriter, req *http.Request) {
+	in := zeroRequest[I]()
+	if err := h.decodeOptionalBody(req.Body, &in); err != nil {
+		h.respondErr(rw, err)
+		return
+	}
+
+	id := lastURLParam(req)
+
+	out, err := h.Action(id, in)
+	if err != nil {
+		h.respondErr(rw, err)
+		return
+	}
+
+	h.respond(rw, out)
+}
+
+// zeroRequest returns the zero value of the request type.
+// Pointer requests point to the zero value, so they can be used without decoding a body.
+func zeroRequest[I any]() I {
+	var in I
+
+	t := reflect.TypeOf(&in).Elem()
+	if t.Kind() == reflect.Pointer {
+		in = reflect.New(t.Elem()).Interface().(I)
+	}
+
+	return in
+}
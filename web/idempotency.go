@@ -0,0 +1,199 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kaznasho/yarmarok/service"
+)
+
+const (
+	// IdempotencyKeyHeader is the header with a client generated key
+	// identifying a request, so that its retries aren't processed twice.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader marks responses replayed from the storage.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL is the default time window
+	// during which stored responses are replayed.
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// IdempotencyClaimLease is the time window during which a claimed key
+	// is pending. It outlives the function timeout, so a claim left behind
+	// by a crashed request is taken over by a later retry.
+	IdempotencyClaimLease = time.Minute
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key is already used for another request")
+	ErrIdempotentRequestPending = errors.New("request with the same idempotency key is in progress")
+)
+
+// RouterOption configures optional features of the Router.
+type RouterOption func(*Router)
+
+// WithIdempotencyStorage enables idempotency keys support.
+// Responses are stored in the given storage for the given time window.
+func WithIdempotencyStorage(storage service.IdempotencyStorage, ttl time.Duration) RouterOption {
+	return func(r *Router) {
+		r.idempotencyStorage = storage
+		r.idempotencyTTL = ttl
+	}
+}
+
+// idempotencyMiddleware replays the stored response for retried requests
+// with the same Idempotency-Key header instead of processing them again.
+// The key is claimed for a short lease before the request is processed,
+// so concurrent retries get a conflict until the first one completes.
+// The claim is released when the request fails or panics.
+// Requests without the header and routers without the storage are not affected.
+func (r *Router) idempotencyMiddleware(next http.Handler) http.Handler {
+	return r.idempotent(next, func(_ *http.Request, body []byte) ([]byte, error) {
+		return body, nil
+	})
+}
+
+// resourceIdempotencyMiddleware works like idempotencyMiddleware for routes
+// responding with participants, whose contacts must not be kept in the storage.
+// Only the status and the ID of the resource from the given path param are kept,
+// so retries get the ID to read the result with.
+func (r *Router) resourceIdempotencyMiddleware(param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return r.idempotent(next, func(req *http.Request, _ []byte) ([]byte, error) {
+			id, err := extractParam(req, param)
+			if err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(CreateResponse{ID: id})
+		})
+	}
+}
+
+// idempotent implements the idempotency middleware,
+// storing the response bodies returned by the given function.
+func (r *Router) idempotent(
+	next http.Handler,
+	storedBody func(req *http.Request, body []byte) ([]byte, error),
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(IdempotencyKeyHeader)
+		if key == "" || r.idempotencyStorage == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			r.respondErr(w, err)
+			return
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		organizerID, _ := extractOrganizerID(req)
+		id := hashOf(organizerID, key)
+		requestHash := hashOf(req.Method, req.URL.Path, string(body))
+		now := time.Now()
+
+		response := &service.IdempotentResponse{
+			ID:          id,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(IdempotencyClaimLease),
+		}
+
+		stored, err := r.idempotencyStorage.Claim(response)
+		switch {
+		case err != nil:
+			r.respondErr(w, err)
+			return
+		case stored == nil:
+		case stored.RequestHash != requestHash:
+			http.Error(w, ErrIdempotencyKeyReused.Error(), http.StatusUnprocessableEntity)
+			return
+		case !stored.Completed:
+			http.Error(w, ErrIdempotentRequestPending.Error(), http.StatusConflict)
+			return
+		default:
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(stored.StatusCode)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		// Panicked requests are forgotten, so that they can be retried.
+		defer func() {
+			if p := recover(); p != nil {
+				r.releaseClaim(id)
+				panic(p)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		// Failed requests are forgotten, so that they can be retried.
+		if rec.status >= http.StatusBadRequest {
+			r.releaseClaim(id)
+			return
+		}
+
+		response.Body, err = storedBody(req, rec.body.Bytes())
+		if err != nil {
+			r.logger.WithError(err).Warn("failed to prepare idempotent response")
+			r.releaseClaim(id)
+			return
+		}
+
+		response.Completed = true
+		response.StatusCode = rec.status
+		response.ExpiresAt = time.Now().Add(r.idempotencyTTL)
+
+		if err := r.idempotencyStorage.Save(response); err != nil {
+			r.logger.WithError(err).Warn("failed to save idempotent response")
+		}
+	})
+}
+
+// releaseClaim deletes the claimed key, so that the request can be retried.
+func (r *Router) releaseClaim(id string) {
+	if err := r.idempotencyStorage.Delete(id); err != nil {
+		r.logger.WithError(err).Warn("failed to delete idempotent response")
+	}
+}
+
+// responseRecorder writes the response through
+// keeping a copy of the status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
+
+func hashOf(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
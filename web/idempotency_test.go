@@ -0,0 +1,347 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/web/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type IdempotencySuite struct {
+	suite.Suite
+	organizerService *mocks.MockOrganizerService
+	raffleService    *mocks.MockRaffleService
+	prizeService     *mocks.MockPrizeService
+	donationService  *mocks.MockDonationService
+	storage          *memoryIdempotencyStorage
+	router           *Router
+	organizerID      string
+	raffleID         string
+	prizeID          string
+}
+
+func TestIdempotency(t *testing.T) {
+	suite.Run(t, &IdempotencySuite{})
+}
+
+func (s *IdempotencySuite) SetupTest() {
+	ctrl := gomock.NewController(s.T())
+	s.organizerService = mocks.NewMockOrganizerService(ctrl)
+	s.raffleService = mocks.NewMockRaffleService(ctrl)
+	s.prizeService = mocks.NewMockPrizeService(ctrl)
+	s.donationService = mocks.NewMockDonationService(ctrl)
+	s.storage = newMemoryIdempotencyStorage()
+	s.organizerID = "organizer_id_1"
+	s.raffleID = "raffle_id_1"
+	s.prizeID = "prize_id_1"
+
+	s.organizerService.EXPECT().CreateOrganizerIfNotExists(gomock.Any()).Return(nil).AnyTimes()
+	s.organizerService.EXPECT().RaffleService(gomock.Any()).Return(s.raffleService).AnyTimes()
+	s.raffleService.EXPECT().PrizeService(s.raffleID).Return(s.prizeService).AnyTimes()
+	s.prizeService.EXPECT().DonationService(s.prizeID).Return(s.donationService, nil).AnyTimes()
+
+	var err error
+	s.router, err = NewRouter(
+		s.organizerService,
+		logger.NewLogger(logger.LevelDebug),
+		WithIdempotencyStorage(s.storage, time.Hour),
+	)
+	s.Require().NoError(err)
+}
+
+func (s *IdempotencySuite) TestCreateDonation() {
+	donationPath := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, s.prizeID, DonationsPath)
+	donation := &service.DonationRequest{Amount: 100, ParticipantID: "participant_id_1", EnteredBy: s.organizerID}
+
+	s.Run("retry_is_replayed", func() {
+		s.donationService.EXPECT().Create(donation).Return("donation_id_1", nil).Times(1)
+
+		first := s.serve(http.MethodPost, donationPath, "key_1", donation)
+		s.Require().Equal(http.StatusOK, first.Code)
+		s.Empty(first.Header().Get(IdempotentReplayedHeader))
+
+		retry := s.serve(http.MethodPost, donationPath, "key_1", donation)
+		s.Require().Equal(http.StatusOK, retry.Code)
+		s.Equal("true", retry.Header().Get(IdempotentReplayedHeader))
+		s.Equal(first.Body.String(), retry.Body.String())
+	})
+
+	s.Run("key_reused_for_another_request", func() {
+		other := &service.DonationRequest{Amount: 200, ParticipantID: "participant_id_1"}
+
+		res := s.serve(http.MethodPost, donationPath, "key_1", other)
+		s.Equal(http.StatusUnprocessableEntity, res.Code)
+	})
+
+	s.Run("failure_is_not_stored", func() {
+		s.donationService.EXPECT().Create(donation).Return("", assert.AnError)
+		s.donationService.EXPECT().Create(donation).Return("donation_id_2", nil)
+
+		res := s.serve(http.MethodPost, donationPath, "key_2", donation)
+		s.Equal(http.StatusInternalServerError, res.Code)
+
+		res = s.serve(http.MethodPost, donationPath, "key_2", donation)
+		s.Equal(http.StatusOK, res.Code)
+		s.Empty(res.Header().Get(IdempotentReplayedHeader))
+	})
+
+	s.Run("expired", func() {
+		s.donationService.EXPECT().Create(donation).Return("donation_id_3", nil).Times(2)
+
+		res := s.serve(http.MethodPost, donationPath, "key_3", donation)
+		s.Equal(http.StatusOK, res.Code)
+
+		for _, r := range s.storage.responses {
+			r.ExpiresAt = time.Now().Add(-time.Minute)
+		}
+
+		res = s.serve(http.MethodPost, donationPath, "key_3", donation)
+		s.Equal(http.StatusOK, res.Code)
+		s.Empty(res.Header().Get(IdempotentReplayedHeader))
+	})
+
+	s.Run("concurrent_retry", func() {
+		processing := make(chan struct{})
+		release := make(chan struct{})
+
+		s.donationService.EXPECT().Create(donation).DoAndReturn(func(*service.DonationRequest) (string, error) {
+			close(processing)
+			<-release
+			return "donation_id_5", nil
+		}).Times(1)
+
+		first := make(chan *httptest.ResponseRecorder)
+		go func() {
+			first <- s.serve(http.MethodPost, donationPath, "key_5", donation)
+		}()
+
+		<-processing
+
+		retry := s.serve(http.MethodPost, donationPath, "key_5", donation)
+		s.Equal(http.StatusConflict, retry.Code)
+
+		close(release)
+		s.Equal(http.StatusOK, (<-first).Code)
+
+		retry = s.serve(http.MethodPost, donationPath, "key_5", donation)
+		s.Equal(http.StatusOK, retry.Code)
+		s.Equal("true", retry.Header().Get(IdempotentReplayedHeader))
+	})
+
+	s.Run("panic_releases_claim", func() {
+		s.donationService.EXPECT().Create(donation).DoAndReturn(func(*service.DonationRequest) (string, error) {
+			panic("boom")
+		})
+		s.donationService.EXPECT().Create(donation).Return("donation_id_6", nil)
+
+		res := s.serve(http.MethodPost, donationPath, "key_6", donation)
+		s.Equal(http.StatusInternalServerError, res.Code)
+
+		res = s.serve(http.MethodPost, donationPath, "key_6", donation)
+		s.Equal(http.StatusOK, res.Code)
+		s.Empty(res.Header().Get(IdempotentReplayedHeader))
+	})
+
+	s.Run("abandoned_claim_is_taken_over", func() {
+		s.donationService.EXPECT().Create(donation).Return("donation_id_7", nil)
+
+		abandoned := time.Now().Add(-IdempotencyClaimLease - time.Second)
+		id := hashOf(s.organizerID, "key_7")
+		s.storage.responses[id] = &service.IdempotentResponse{
+			ID:        id,
+			CreatedAt: abandoned,
+			ExpiresAt: abandoned.Add(IdempotencyClaimLease),
+		}
+
+		res := s.serve(http.MethodPost, donationPath, "key_7", donation)
+		s.Equal(http.StatusOK, res.Code)
+		s.Empty(res.Header().Get(IdempotentReplayedHeader))
+	})
+
+	s.Run("pending_claim_is_leased", func() {
+		processing := make(chan struct{})
+		release := make(chan struct{})
+
+		s.donationService.EXPECT().Create(donation).DoAndReturn(func(*service.DonationRequest) (string, error) {
+			close(processing)
+			<-release
+			return "donation_id_8", nil
+		})
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			done <- s.serve(http.MethodPost, donationPath, "key_8", donation)
+		}()
+
+		<-processing
+
+		pending, err := s.storage.Get(hashOf(s.organizerID, "key_8"))
+		s.Require().NoError(err)
+		s.False(pending.Completed)
+		s.WithinDuration(pending.CreatedAt.Add(IdempotencyClaimLease), pending.ExpiresAt, 0)
+
+		close(release)
+		s.Equal(http.StatusOK, (<-done).Code)
+
+		saved, err := s.storage.Get(hashOf(s.organizerID, "key_8"))
+		s.Require().NoError(err)
+		s.True(saved.Completed)
+		s.WithinDuration(time.Now().Add(time.Hour), saved.ExpiresAt, time.Minute)
+	})
+
+	s.Run("without_key", func() {
+		s.donationService.EXPECT().Create(donation).Return("donation_id_4", nil).Times(2)
+
+		s.Equal(http.StatusOK, s.serve(http.MethodPost, donationPath, "", donation).Code)
+		s.Equal(http.StatusOK, s.serve(http.MethodPost, donationPath, "", donation).Code)
+	})
+}
+
+func (s *IdempotencySuite) TestPlay() {
+	playPath := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, s.prizeID, PlayPath)
+	result := &service.PrizePlayResult{
+		Winners: []service.PlayParticipant{
+			{Participant: service.Participant{ID: "participant_id_1", Phone: "+380930000000"}},
+		},
+	}
+
+	s.prizeService.EXPECT().Play(s.prizeID).Return(result, nil).Times(1)
+
+	first := s.serve(http.MethodPost, playPath, "key_1", nil)
+	s.Require().Equal(http.StatusOK, first.Code)
+	s.Contains(first.Body.String(), "+380930000000")
+
+	retry := s.serve(http.MethodPost, playPath, "key_1", nil)
+	s.Require().Equal(http.StatusOK, retry.Code)
+	s.Equal("true", retry.Header().Get(IdempotentReplayedHeader))
+	s.JSONEq(`{"id":"prize_id_1"}`, retry.Body.String())
+
+	s.Run("participants_are_not_stored", func() {
+		for _, r := range s.storage.responses {
+			s.NotContains(string(r.Body), "+380930000000")
+		}
+	})
+
+	s.Run("pending", func() {
+		for _, r := range s.storage.responses {
+			r.Completed = false
+		}
+
+		res := s.serve(http.MethodPost, playPath, "key_1", nil)
+		s.Equal(http.StatusConflict, res.Code)
+	})
+
+	s.Run("keys_are_scoped_by_organizer", func() {
+		s.prizeService.EXPECT().Play(s.prizeID).Return(result, nil)
+
+		req, err := newRequestJSON(http.MethodPost, playPath, "organizer_id_2", nil)
+		s.Require().NoError(err)
+		req.Header.Set(IdempotencyKeyHeader, "key_1")
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+	})
+}
+
+func (s *IdempotencySuite) TestPlayAll() {
+	playAllPath := joinPath(ApiPath, RafflesPath, s.raffleID, PlayAllPath)
+	playAll := &service.PlayAllRequest{Order: service.PlayByTicketCost}
+	result := &service.RafflePlayResult{
+		RaffleID: s.raffleID,
+		Prizes: []service.PrizeDrawResult{{
+			PrizeID: s.prizeID,
+			Winner:  &service.PlayParticipant{Participant: service.Participant{ID: "participant_id_1", Phone: "+380930000000"}},
+		}},
+	}
+
+	s.raffleService.EXPECT().PlayAll(s.raffleID, playAll).Return(result, nil).Times(1)
+
+	first := s.serve(http.MethodPost, playAllPath, "key_1", playAll)
+	s.Require().Equal(http.StatusOK, first.Code)
+	s.Contains(first.Body.String(), "+380930000000")
+
+	retry := s.serve(http.MethodPost, playAllPath, "key_1", playAll)
+	s.Require().Equal(http.StatusOK, retry.Code)
+	s.Equal("true", retry.Header().Get(IdempotentReplayedHeader))
+	s.JSONEq(`{"id":"raffle_id_1"}`, retry.Body.String())
+
+	for _, r := range s.storage.responses {
+		s.NotContains(string(r.Body), "+380930000000")
+	}
+}
+
+func (s *IdempotencySuite) serve(method, path, key string, body any) *httptest.ResponseRecorder {
+	req, err := newRequestJSON(method, path, s.organizerID, body)
+	s.Require().NoError(err)
+
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+
+	return writer
+}
+
+type memoryIdempotencyStorage struct {
+	mu        sync.Mutex
+	responses map[string]*service.IdempotentResponse
+}
+
+func newMemoryIdempotencyStorage() *memoryIdempotencyStorage {
+	return &memoryIdempotencyStorage{responses: make(map[string]*service.IdempotentResponse)}
+}
+
+func (m *memoryIdempotencyStorage) Get(id string) (*service.IdempotentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.responses[id]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+
+	stored := *r
+	return &stored, nil
+}
+
+func (m *memoryIdempotencyStorage) Claim(r *service.IdempotentResponse) (*service.IdempotentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.responses[r.ID]; ok && !existing.IsExpired(r.CreatedAt) {
+		stored := *existing
+		return &stored, nil
+	}
+
+	stored := *r
+	m.responses[r.ID] = &stored
+	return nil, nil
+}
+
+func (m *memoryIdempotencyStorage) Save(r *service.IdempotentResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *r
+	m.responses[r.ID] = &stored
+	return nil
+}
+
+func (m *memoryIdempotencyStorage) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.responses, id)
+	return nil
+}
@@ -93,6 +93,7 @@ func (r *Router) corsMiddleware(next http.Handler) http.Handler {
 				http.MethodGet,
 				http.MethodPost,
 				http.MethodPut,
+				http.MethodPatch,
 				http.MethodDelete,
 			},
 
@@ -102,8 +103,10 @@ func (r *Router) corsMiddleware(next http.Handler) http.Handler {
 				"Content-Type",
 				"X-CSRF-Token",
 				"X-Goog-Authenticated-User-Id",
+				IdempotencyKeyHeader,
+				ifMatchHeader,
 			},
-			ExposedHeaders:       []string{},
+			ExposedHeaders:       []string{IdempotentReplayedHeader, etagHeader},
 			MaxAge:               0,
 			AllowPrivateNetwork:  false,
 			OptionsPassthrough:   false,
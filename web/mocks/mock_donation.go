@@ -110,3 +110,17 @@ func (mr *MockDonationServiceMockRecorder) List() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDonationService)(nil).List))
 }
+
+// Patch mocks base method.
+func (m *MockDonationService) Patch(arg0 string, arg1 *service.MergePatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockDonationServiceMockRecorder) Patch(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockDonationService)(nil).Patch), arg0, arg1)
+}
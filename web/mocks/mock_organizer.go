@@ -38,6 +38,21 @@ func (m *MockOrganizerService) EXPECT() *MockOrganizerServiceMockRecorder {
 	return m.recorder
 }
 
+// AnonymizeExpired mocks base method.
+func (m *MockOrganizerService) AnonymizeExpired() ([]service.AnonymizedRaffle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeExpired")
+	ret0, _ := ret[0].([]service.AnonymizedRaffle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeExpired indicates an expected call of AnonymizeExpired.
+func (mr *MockOrganizerServiceMockRecorder) AnonymizeExpired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeExpired", reflect.TypeOf((*MockOrganizerService)(nil).AnonymizeExpired))
+}
+
 // CreateOrganizerIfNotExists mocks base method.
 func (m *MockOrganizerService) CreateOrganizerIfNotExists(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -52,6 +67,36 @@ func (mr *MockOrganizerServiceMockRecorder) CreateOrganizerIfNotExists(arg0 any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganizerIfNotExists", reflect.TypeOf((*MockOrganizerService)(nil).CreateOrganizerIfNotExists), arg0)
 }
 
+// DeliverWebhooks mocks base method.
+func (m *MockOrganizerService) DeliverWebhooks() ([]service.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverWebhooks")
+	ret0, _ := ret[0].([]service.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverWebhooks indicates an expected call of DeliverWebhooks.
+func (mr *MockOrganizerServiceMockRecorder) DeliverWebhooks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverWebhooks", reflect.TypeOf((*MockOrganizerService)(nil).DeliverWebhooks))
+}
+
+// PlayDuePrizes mocks base method.
+func (m *MockOrganizerService) PlayDuePrizes() ([]service.ScheduledPlay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlayDuePrizes")
+	ret0, _ := ret[0].([]service.ScheduledPlay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlayDuePrizes indicates an expected call of PlayDuePrizes.
+func (mr *MockOrganizerServiceMockRecorder) PlayDuePrizes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlayDuePrizes", reflect.TypeOf((*MockOrganizerService)(nil).PlayDuePrizes))
+}
+
 // RaffleService mocks base method.
 func (m *MockOrganizerService) RaffleService(arg0 string) service.RaffleService {
 	m.ctrl.T.Helper()
@@ -65,3 +110,32 @@ func (mr *MockOrganizerServiceMockRecorder) RaffleService(arg0 any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RaffleService", reflect.TypeOf((*MockOrganizerService)(nil).RaffleService), arg0)
 }
+
+// SendNotifications mocks base method.
+func (m *MockOrganizerService) SendNotifications() ([]service.QueuedNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendNotifications")
+	ret0, _ := ret[0].([]service.QueuedNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendNotifications indicates an expected call of SendNotifications.
+func (mr *MockOrganizerServiceMockRecorder) SendNotifications() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendNotifications", reflect.TypeOf((*MockOrganizerService)(nil).SendNotifications))
+}
+
+// WebhookService mocks base method.
+func (m *MockOrganizerService) WebhookService(arg0 string) service.WebhookService {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebhookService", arg0)
+	ret0, _ := ret[0].(service.WebhookService)
+	return ret0
+}
+
+// WebhookService indicates an expected call of WebhookService.
+func (mr *MockOrganizerServiceMockRecorder) WebhookService(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookService", reflect.TypeOf((*MockOrganizerService)(nil).WebhookService), arg0)
+}
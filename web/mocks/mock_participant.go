@@ -38,6 +38,21 @@ func (m *MockParticipantService) EXPECT() *MockParticipantServiceMockRecorder {
 	return m.recorder
 }
 
+// Anonymize mocks base method.
+func (m *MockParticipantService) Anonymize(arg0 string, arg1 *service.AnonymizeRequest) (*service.Participant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", arg0, arg1)
+	ret0, _ := ret[0].(*service.Participant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Anonymize indicates an expected call of Anonymize.
+func (mr *MockParticipantServiceMockRecorder) Anonymize(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockParticipantService)(nil).Anonymize), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockParticipantService) Create(arg0 *service.ParticipantRequest) (string, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +82,21 @@ func (mr *MockParticipantServiceMockRecorder) Delete(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockParticipantService)(nil).Delete), arg0)
 }
 
+// Duplicates mocks base method.
+func (m *MockParticipantService) Duplicates() ([]service.DuplicateParticipants, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Duplicates")
+	ret0, _ := ret[0].([]service.DuplicateParticipants)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Duplicates indicates an expected call of Duplicates.
+func (mr *MockParticipantServiceMockRecorder) Duplicates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Duplicates", reflect.TypeOf((*MockParticipantService)(nil).Duplicates))
+}
+
 // Edit mocks base method.
 func (m *MockParticipantService) Edit(arg0 string, arg1 *service.ParticipantRequest) error {
 	m.ctrl.T.Helper()
@@ -81,6 +111,36 @@ func (mr *MockParticipantServiceMockRecorder) Edit(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Edit", reflect.TypeOf((*MockParticipantService)(nil).Edit), arg0, arg1)
 }
 
+// Export mocks base method.
+func (m *MockParticipantService) Export(arg0 string) (*service.ParticipantExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0)
+	ret0, _ := ret[0].(*service.ParticipantExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockParticipantServiceMockRecorder) Export(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockParticipantService)(nil).Export), arg0)
+}
+
+// Get mocks base method.
+func (m *MockParticipantService) Get(arg0 string) (*service.ParticipantDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*service.ParticipantDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockParticipantServiceMockRecorder) Get(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockParticipantService)(nil).Get), arg0)
+}
+
 // List mocks base method.
 func (m *MockParticipantService) List() ([]service.Participant, error) {
 	m.ctrl.T.Helper()
@@ -95,3 +155,47 @@ func (mr *MockParticipantServiceMockRecorder) List() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockParticipantService)(nil).List))
 }
+
+// Merge mocks base method.
+func (m *MockParticipantService) Merge(arg0 string, arg1 *service.MergeParticipantsRequest) (*service.ParticipantDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", arg0, arg1)
+	ret0, _ := ret[0].(*service.ParticipantDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockParticipantServiceMockRecorder) Merge(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockParticipantService)(nil).Merge), arg0, arg1)
+}
+
+// Patch mocks base method.
+func (m *MockParticipantService) Patch(arg0 string, arg1 *service.MergePatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockParticipantServiceMockRecorder) Patch(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockParticipantService)(nil).Patch), arg0, arg1)
+}
+
+// Search mocks base method.
+func (m *MockParticipantService) Search(arg0 string) ([]service.Participant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0)
+	ret0, _ := ret[0].([]service.Participant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockParticipantServiceMockRecorder) Search(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockParticipantService)(nil).Search), arg0)
+}
@@ -38,6 +38,21 @@ func (m *MockPrizeService) EXPECT() *MockPrizeServiceMockRecorder {
 	return m.recorder
 }
 
+// Claim mocks base method.
+func (m *MockPrizeService) Claim(arg0 string, arg1 *service.ClaimRequest) (*service.PrizePlayResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", arg0, arg1)
+	ret0, _ := ret[0].(*service.PrizePlayResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockPrizeServiceMockRecorder) Claim(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockPrizeService)(nil).Claim), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockPrizeService) Create(arg0 *service.PrizeRequest) (string, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +111,21 @@ func (mr *MockPrizeServiceMockRecorder) Edit(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Edit", reflect.TypeOf((*MockPrizeService)(nil).Edit), arg0, arg1)
 }
 
+// Forfeit mocks base method.
+func (m *MockPrizeService) Forfeit(arg0 string, arg1 *service.ForfeitRequest) (*service.PrizePlayResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Forfeit", arg0, arg1)
+	ret0, _ := ret[0].(*service.PrizePlayResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Forfeit indicates an expected call of Forfeit.
+func (mr *MockPrizeServiceMockRecorder) Forfeit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forfeit", reflect.TypeOf((*MockPrizeService)(nil).Forfeit), arg0, arg1)
+}
+
 // Get mocks base method.
 func (m *MockPrizeService) Get(arg0 string) (*service.Prize, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +156,35 @@ func (mr *MockPrizeServiceMockRecorder) List() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPrizeService)(nil).List))
 }
 
+// ListUnclaimed mocks base method.
+func (m *MockPrizeService) ListUnclaimed() ([]service.UnclaimedPrize, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnclaimed")
+	ret0, _ := ret[0].([]service.UnclaimedPrize)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnclaimed indicates an expected call of ListUnclaimed.
+func (mr *MockPrizeServiceMockRecorder) ListUnclaimed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnclaimed", reflect.TypeOf((*MockPrizeService)(nil).ListUnclaimed))
+}
+
+// Patch mocks base method.
+func (m *MockPrizeService) Patch(arg0 string, arg1 *service.MergePatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockPrizeServiceMockRecorder) Patch(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockPrizeService)(nil).Patch), arg0, arg1)
+}
+
 // Play mocks base method.
 func (m *MockPrizeService) Play(arg0 string) (*service.PrizePlayResult, error) {
 	m.ctrl.T.Helper()
@@ -140,3 +199,33 @@ func (mr *MockPrizeServiceMockRecorder) Play(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Play", reflect.TypeOf((*MockPrizeService)(nil).Play), arg0)
 }
+
+// PlayResult mocks base method.
+func (m *MockPrizeService) PlayResult(arg0 string) (*service.PrizePlayResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlayResult", arg0)
+	ret0, _ := ret[0].(*service.PrizePlayResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlayResult indicates an expected call of PlayResult.
+func (mr *MockPrizeServiceMockRecorder) PlayResult(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlayResult", reflect.TypeOf((*MockPrizeService)(nil).PlayResult), arg0)
+}
+
+// VoidDraw mocks base method.
+func (m *MockPrizeService) VoidDraw(arg0 string, arg1 *service.VoidDrawRequest) (*service.PrizePlayResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidDraw", arg0, arg1)
+	ret0, _ := ret[0].(*service.PrizePlayResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidDraw indicates an expected call of VoidDraw.
+func (mr *MockPrizeServiceMockRecorder) VoidDraw(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidDraw", reflect.TypeOf((*MockPrizeService)(nil).VoidDraw), arg0, arg1)
+}
@@ -38,6 +38,21 @@ func (m *MockRaffleService) EXPECT() *MockRaffleServiceMockRecorder {
 	return m.recorder
 }
 
+// Archive mocks base method.
+func (m *MockRaffleService) Archive(arg0 string, arg1 *service.ArchiveRequest) (*service.Raffle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", arg0, arg1)
+	ret0, _ := ret[0].(*service.Raffle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockRaffleServiceMockRecorder) Archive(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockRaffleService)(nil).Archive), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockRaffleService) Create(arg0 *service.RaffleRequest) (string, error) {
 	m.ctrl.T.Helper()
@@ -53,6 +68,21 @@ func (mr *MockRaffleServiceMockRecorder) Create(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRaffleService)(nil).Create), arg0)
 }
 
+// CreateDonations mocks base method.
+func (m *MockRaffleService) CreateDonations(arg0 string, arg1 *service.BulkDonationRequest) (*service.BulkDonationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDonations", arg0, arg1)
+	ret0, _ := ret[0].(*service.BulkDonationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDonations indicates an expected call of CreateDonations.
+func (mr *MockRaffleServiceMockRecorder) CreateDonations(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDonations", reflect.TypeOf((*MockRaffleService)(nil).CreateDonations), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockRaffleService) Delete(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -67,6 +97,21 @@ func (mr *MockRaffleServiceMockRecorder) Delete(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRaffleService)(nil).Delete), arg0)
 }
 
+// DonationService mocks base method.
+func (m *MockRaffleService) DonationService(arg0 string) (service.DonationService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DonationService", arg0)
+	ret0, _ := ret[0].(service.DonationService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DonationService indicates an expected call of DonationService.
+func (mr *MockRaffleServiceMockRecorder) DonationService(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DonationService", reflect.TypeOf((*MockRaffleService)(nil).DonationService), arg0)
+}
+
 // Edit mocks base method.
 func (m *MockRaffleService) Edit(arg0 string, arg1 *service.RaffleRequest) error {
 	m.ctrl.T.Helper()
@@ -140,6 +185,50 @@ func (mr *MockRaffleServiceMockRecorder) ParticipantService(arg0 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParticipantService", reflect.TypeOf((*MockRaffleService)(nil).ParticipantService), arg0)
 }
 
+// Patch mocks base method.
+func (m *MockRaffleService) Patch(arg0 string, arg1 *service.MergePatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockRaffleServiceMockRecorder) Patch(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockRaffleService)(nil).Patch), arg0, arg1)
+}
+
+// PaymentService mocks base method.
+func (m *MockRaffleService) PaymentService(arg0 string) (service.PaymentService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaymentService", arg0)
+	ret0, _ := ret[0].(service.PaymentService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PaymentService indicates an expected call of PaymentService.
+func (mr *MockRaffleServiceMockRecorder) PaymentService(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaymentService", reflect.TypeOf((*MockRaffleService)(nil).PaymentService), arg0)
+}
+
+// PlayAll mocks base method.
+func (m *MockRaffleService) PlayAll(arg0 string, arg1 *service.PlayAllRequest) (*service.RafflePlayResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlayAll", arg0, arg1)
+	ret0, _ := ret[0].(*service.RafflePlayResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlayAll indicates an expected call of PlayAll.
+func (mr *MockRaffleServiceMockRecorder) PlayAll(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlayAll", reflect.TypeOf((*MockRaffleService)(nil).PlayAll), arg0, arg1)
+}
+
 // PrizeService mocks base method.
 func (m *MockRaffleService) PrizeService(arg0 string) service.PrizeService {
 	m.ctrl.T.Helper()
@@ -153,3 +242,47 @@ func (mr *MockRaffleServiceMockRecorder) PrizeService(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrizeService", reflect.TypeOf((*MockRaffleService)(nil).PrizeService), arg0)
 }
+
+// ReconciliationService mocks base method.
+func (m *MockRaffleService) ReconciliationService(arg0 string) service.ReconciliationService {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconciliationService", arg0)
+	ret0, _ := ret[0].(service.ReconciliationService)
+	return ret0
+}
+
+// ReconciliationService indicates an expected call of ReconciliationService.
+func (mr *MockRaffleServiceMockRecorder) ReconciliationService(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconciliationService", reflect.TypeOf((*MockRaffleService)(nil).ReconciliationService), arg0)
+}
+
+// RepairTotals mocks base method.
+func (m *MockRaffleService) RepairTotals(arg0 string, arg1 *service.RepairTotalsRequest) (*service.RepairTotalsResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairTotals", arg0, arg1)
+	ret0, _ := ret[0].(*service.RepairTotalsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepairTotals indicates an expected call of RepairTotals.
+func (mr *MockRaffleServiceMockRecorder) RepairTotals(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairTotals", reflect.TypeOf((*MockRaffleService)(nil).RepairTotals), arg0, arg1)
+}
+
+// Stats mocks base method.
+func (m *MockRaffleService) Stats(arg0 string) (*service.RaffleStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", arg0)
+	ret0, _ := ret[0].(*service.RaffleStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockRaffleServiceMockRecorder) Stats(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockRaffleService)(nil).Stats), arg0)
+}
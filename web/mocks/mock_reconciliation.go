@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/kaznasho/yarmarok/service (interfaces: ReconciliationService)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mocks/mock_reconciliation.go -package=mocks github.com/kaznasho/yarmarok/service ReconciliationService
+//
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	service "github.com/kaznasho/yarmarok/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReconciliationService is a mock of ReconciliationService interface.
+type MockReconciliationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReconciliationServiceMockRecorder
+}
+
+// MockReconciliationServiceMockRecorder is the mock recorder for MockReconciliationService.
+type MockReconciliationServiceMockRecorder struct {
+	mock *MockReconciliationService
+}
+
+// NewMockReconciliationService creates a new mock instance.
+func NewMockReconciliationService(ctrl *gomock.Controller) *MockReconciliationService {
+	mock := &MockReconciliationService{ctrl: ctrl}
+	mock.recorder = &MockReconciliationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconciliationService) EXPECT() *MockReconciliationServiceMockRecorder {
+	return m.recorder
+}
+
+// CloseShift mocks base method.
+func (m *MockReconciliationService) CloseShift(arg0 *service.CloseShiftRequest) (*service.Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseShift", arg0)
+	ret0, _ := ret[0].(*service.Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseShift indicates an expected call of CloseShift.
+func (mr *MockReconciliationServiceMockRecorder) CloseShift(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseShift", reflect.TypeOf((*MockReconciliationService)(nil).CloseShift), arg0)
+}
+
+// ListShifts mocks base method.
+func (m *MockReconciliationService) ListShifts() ([]service.Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShifts")
+	ret0, _ := ret[0].([]service.Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShifts indicates an expected call of ListShifts.
+func (mr *MockReconciliationServiceMockRecorder) ListShifts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShifts", reflect.TypeOf((*MockReconciliationService)(nil).ListShifts))
+}
+
+// Report mocks base method.
+func (m *MockReconciliationService) Report(arg0 *service.ReconciliationRequest) (*service.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", arg0)
+	ret0, _ := ret[0].(*service.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockReconciliationServiceMockRecorder) Report(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockReconciliationService)(nil).Report), arg0)
+}
@@ -1,10 +1,13 @@
 package web
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kaznasho/yarmarok/logger"
 	"github.com/kaznasho/yarmarok/service"
@@ -77,6 +80,23 @@ func (s *ParticipantSuite) TestCreate() {
 		s.Equal(http.StatusInternalServerError, writer.Code)
 	})
 
+	s.Run("phone_already_exists", func() {
+		participantCreateRequest := &service.ParticipantRequest{Name: "participant_1", Phone: "phone_1", Note: "note_1"}
+
+		req, err := newRequestJSON(http.MethodPost, participantPath, s.organizerID, participantCreateRequest)
+		s.Require().NoError(err)
+		s.participantService.EXPECT().Create(participantCreateRequest).
+			Return("", fmt.Errorf("creating participant: %w", &service.AlreadyExistsError{ID: "participant_id_2"}))
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusConflict, writer.Code)
+
+		var body map[string]string
+		s.Require().NoError(json.Unmarshal(writer.Body.Bytes(), &body))
+		s.Equal("participant_id_2", body["id"])
+	})
+
 	s.Run("empty_body", func() {
 		req, err := newRequestWithOrigin(http.MethodPost, participantPath, emptyBody())
 		s.Require().NoError(err)
@@ -85,7 +105,7 @@ func (s *ParticipantSuite) TestCreate() {
 
 		writer := httptest.NewRecorder()
 		s.router.ServeHTTP(writer, req)
-		s.Equal(http.StatusInternalServerError, writer.Code)
+		s.Equal(http.StatusBadRequest, writer.Code)
 	})
 }
 
@@ -108,6 +128,49 @@ func (s *ParticipantSuite) TestEdit() {
 		s.Equal(http.StatusOK, writer.Code)
 	})
 
+	s.Run("if_match", func() {
+		participantEditRequest := &service.ParticipantRequest{Name: "participant_1", Phone: "phone_1"}
+
+		req, err := newRequestJSON(http.MethodPut, participantPath, s.organizerID, participantEditRequest)
+		s.Require().NoError(err)
+		req.Header.Set("If-Match", `"1700000000123456000"`)
+
+		expected := *participantEditRequest
+		expected.IfVersion = time.Unix(0, 1700000000123456000)
+		s.participantService.EXPECT().Edit(s.participantID, &expected).Return(nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("version_mismatch", func() {
+		participantEditRequest := &service.ParticipantRequest{Name: "participant_1", Phone: "phone_1"}
+
+		req, err := newRequestJSON(http.MethodPut, participantPath, s.organizerID, participantEditRequest)
+		s.Require().NoError(err)
+		req.Header.Set("If-Match", `"1700000000123456000"`)
+
+		s.participantService.EXPECT().Edit(s.participantID, gomock.Any()).
+			Return(fmt.Errorf("updating participant: %w", service.ErrVersionMismatch))
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusPreconditionFailed, writer.Code)
+	})
+
+	s.Run("unknown_if_match", func() {
+		participantEditRequest := &service.ParticipantRequest{Name: "participant_1", Phone: "phone_1"}
+
+		req, err := newRequestJSON(http.MethodPut, participantPath, s.organizerID, participantEditRequest)
+		s.Require().NoError(err)
+		req.Header.Set("If-Match", `W/"abc"`)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusPreconditionFailed, writer.Code)
+	})
+
 	s.Run("error", func() {
 		participantEditRequest := &service.ParticipantRequest{Name: "participant_1", Phone: "phone_1", Note: "note_1"}
 
@@ -126,6 +189,59 @@ func (s *ParticipantSuite) TestEdit() {
 
 		req.Header.Set(GoogleUserIDHeader, s.organizerID)
 
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusBadRequest, writer.Code)
+	})
+}
+
+func (s *ParticipantSuite) TestPatch() {
+	participantPath := joinPath(ApiPath, RafflesPath, s.raffleID, ParticipantsPath, s.participantID)
+	patch := json.RawMessage(`{"note":null,"publishName":true}`)
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodPatch, participantPath, s.organizerID, patch)
+		s.Require().NoError(err)
+
+		s.participantService.EXPECT().Patch(s.participantID, &service.MergePatch{Document: patch}).Return(nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("if_match", func() {
+		req, err := newRequestJSON(http.MethodPatch, participantPath, s.organizerID, patch)
+		s.Require().NoError(err)
+		req.Header.Set("If-Match", `"1700000000123456000"`)
+
+		expected := &service.MergePatch{Document: patch}
+		expected.IfVersion = time.Unix(0, 1700000000123456000)
+		s.participantService.EXPECT().Patch(s.participantID, expected).Return(nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("version_mismatch", func() {
+		req, err := newRequestJSON(http.MethodPatch, participantPath, s.organizerID, patch)
+		s.Require().NoError(err)
+
+		s.participantService.EXPECT().Patch(s.participantID, gomock.Any()).
+			Return(fmt.Errorf("updating participant: %w", service.ErrVersionMismatch))
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusPreconditionFailed, writer.Code)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodPatch, participantPath, s.organizerID, patch)
+		s.Require().NoError(err)
+
+		s.participantService.EXPECT().Patch(s.participantID, gomock.Any()).Return(service.ErrInvalidPatch)
+
 		writer := httptest.NewRecorder()
 		s.router.ServeHTTP(writer, req)
 		s.Equal(http.StatusInternalServerError, writer.Code)
@@ -203,3 +319,138 @@ func (s *ParticipantSuite) TestList() {
 		s.Equal(http.StatusInternalServerError, writer.Code)
 	})
 }
+
+func (s *ParticipantSuite) TestGet() {
+	participantPath := joinPath(ApiPath, RafflesPath, s.raffleID, ParticipantsPath, s.participantID)
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodGet, participantPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		details := &service.ParticipantDetails{
+			Participant:   service.Participant{ID: s.participantID},
+			TotalDonation: 100,
+			TotalTickets:  1,
+		}
+		s.participantService.EXPECT().Get(s.participantID).Return(details, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), `"totalTickets":1`)
+		s.Empty(writer.Header().Get("ETag"))
+	})
+
+	s.Run("etag", func() {
+		req, err := newRequestJSON(http.MethodGet, participantPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		details := &service.ParticipantDetails{
+			Participant: service.Participant{ID: s.participantID, UpdatedAt: time.Unix(0, 1700000000123456000)},
+		}
+		s.participantService.EXPECT().Get(s.participantID).Return(details, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+		s.Equal(`"1700000000123456000"`, writer.Header().Get("ETag"))
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodGet, participantPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.participantService.EXPECT().Get(s.participantID).Return(nil, service.ErrNotFound)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *ParticipantSuite) TestSearch() {
+	searchPath := joinPath(ApiPath, RafflesPath, s.raffleID, ParticipantsPath, SearchPath) + "?q=067"
+
+	req, err := newRequestJSON(http.MethodGet, searchPath, s.organizerID, nil)
+	s.Require().NoError(err)
+
+	s.participantService.EXPECT().Search("067").Return([]service.Participant{{ID: s.participantID}}, nil)
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+	s.Equal(http.StatusOK, writer.Code)
+}
+
+func (s *ParticipantSuite) TestDuplicates() {
+	duplicatesPath := joinPath(ApiPath, RafflesPath, s.raffleID, ParticipantsPath, DuplicatesPath)
+
+	req, err := newRequestJSON(http.MethodGet, duplicatesPath, s.organizerID, nil)
+	s.Require().NoError(err)
+
+	duplicates := []service.DuplicateParticipants{
+		{Phone: "380671234567", Participants: []service.Participant{{ID: "p1"}, {ID: "p2"}}},
+	}
+	s.participantService.EXPECT().Duplicates().Return(duplicates, nil)
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+	s.Equal(http.StatusOK, writer.Code)
+}
+
+func (s *ParticipantSuite) TestMerge() {
+	mergePath := joinPath(ApiPath, RafflesPath, s.raffleID, ParticipantsPath, s.participantID, MergePath)
+	merge := &service.MergeParticipantsRequest{DuplicateIDs: []string{"participant_id_2"}}
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodPost, mergePath, s.organizerID, merge)
+		s.Require().NoError(err)
+
+		details := &service.ParticipantDetails{Participant: service.Participant{ID: s.participantID}}
+		s.participantService.EXPECT().Merge(s.participantID, merge).Return(details, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodPost, mergePath, s.organizerID, merge)
+		s.Require().NoError(err)
+
+		s.participantService.EXPECT().Merge(s.participantID, merge).Return(nil, service.ErrEditPlayedPrizeDonations)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *ParticipantSuite) TestExport() {
+	req, err := newRequestJSON(http.MethodGet, joinPath(ApiPath, RafflesPath, s.raffleID, ParticipantsPath, s.participantID, ExportPath), s.organizerID, nil)
+	s.Require().NoError(err)
+
+	export := &service.ParticipantExport{
+		ParticipantDetails: service.ParticipantDetails{Participant: service.Participant{ID: s.participantID, Name: "Olena"}},
+		Wins:               []service.ParticipantWin{{PrizeID: "prize_id_1", PrizeName: "Bicycle"}},
+	}
+	s.participantService.EXPECT().Export(s.participantID).Return(export, nil)
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+	s.Require().Equal(http.StatusOK, writer.Code)
+	s.Contains(writer.Body.String(), `"prizeName":"Bicycle"`)
+}
+
+func (s *ParticipantSuite) TestAnonymize() {
+	req, err := newRequestJSON(http.MethodPost, joinPath(ApiPath, RafflesPath, s.raffleID, ParticipantsPath, s.participantID, AnonymizePath), s.organizerID, &service.AnonymizeRequest{})
+	s.Require().NoError(err)
+
+	now := time.Now()
+	s.participantService.EXPECT().Anonymize(s.participantID, &service.AnonymizeRequest{}).
+		Return(&service.Participant{ID: s.participantID, AnonymizedAt: &now}, nil)
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+	s.Require().Equal(http.StatusOK, writer.Code)
+	s.Contains(writer.Body.String(), `"anonymizedAt"`)
+}
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/web/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type PaymentSuite struct {
+	suite.Suite
+	organizerService *mocks.MockOrganizerService
+	raffleService    *mocks.MockRaffleService
+	paymentService   *mocks.MockPaymentService
+	router           *Router
+	organizerID      string
+	raffleID         string
+	paymentID        string
+}
+
+func TestPayment(t *testing.T) {
+	suite.Run(t, &PaymentSuite{})
+}
+
+func (s *PaymentSuite) SetupTest() {
+	ctrl := gomock.NewController(s.T())
+	s.organizerService = mocks.NewMockOrganizerService(ctrl)
+	s.raffleService = mocks.NewMockRaffleService(ctrl)
+	s.paymentService = mocks.NewMockPaymentService(ctrl)
+	s.organizerID = "organizer_id_1"
+	s.raffleID = "raffle_id_1"
+	s.paymentID = "payment_id_1"
+
+	s.organizerService.EXPECT().CreateOrganizerIfNotExists(s.organizerID).Return(nil).AnyTimes()
+	s.organizerService.EXPECT().RaffleService(s.organizerID).Return(s.raffleService).AnyTimes()
+	s.raffleService.EXPECT().PaymentService(s.raffleID).Return(s.paymentService, nil).AnyTimes()
+
+	var err error
+	s.router, err = NewRouter(s.organizerService, logger.NewLogger(logger.LevelDebug))
+	s.Require().NoError(err)
+}
+
+func (s *PaymentSuite) TestPayments() {
+	paymentsPath := joinPath(ApiPath, RafflesPath, s.raffleID, PaymentsPath)
+	paymentRequest := &service.PaymentRequest{
+		ParticipantID: "participant_id_1",
+		Amount:        300,
+		Method:        service.PaymentCash,
+		Allocations: []service.AllocationRequest{
+			{PrizeID: "prize_id_1", Amount: 100},
+			{PrizeID: "prize_id_2", Amount: 200},
+		},
+	}
+
+	s.Run("create", func() {
+		req, err := newRequestJSON(http.MethodPost, paymentsPath, s.organizerID, paymentRequest)
+		s.Require().NoError(err)
+
+		created := *paymentRequest
+		created.EnteredBy = s.organizerID
+
+		s.paymentService.EXPECT().Create(&created).Return(s.paymentID, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), s.paymentID)
+	})
+
+	s.Run("create_error", func() {
+		req, err := newRequestJSON(http.MethodPost, paymentsPath, s.organizerID, paymentRequest)
+		s.Require().NoError(err)
+
+		s.paymentService.EXPECT().Create(gomock.Any()).Return("", service.ErrPaymentNotReconciled)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+	})
+
+	s.Run("get", func() {
+		req, err := newRequestJSON(http.MethodGet, joinPath(paymentsPath, s.paymentID), s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.paymentService.EXPECT().Get(s.paymentID).Return(&service.Payment{ID: s.paymentID}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("list", func() {
+		req, err := newRequestJSON(http.MethodGet, paymentsPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.paymentService.EXPECT().List().Return([]service.Payment{{ID: s.paymentID}}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("edit", func() {
+		req, err := newRequestJSON(http.MethodPut, joinPath(paymentsPath, s.paymentID), s.organizerID, paymentRequest)
+		s.Require().NoError(err)
+
+		s.paymentService.EXPECT().Edit(s.paymentID, paymentRequest).Return(nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("delete", func() {
+		req, err := newRequestJSON(http.MethodDelete, joinPath(paymentsPath, s.paymentID), s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.paymentService.EXPECT().Delete(s.paymentID).Return(nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+}
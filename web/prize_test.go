@@ -94,7 +94,7 @@ func (s *PrizeSuite) TestCreate() {
 
 		writer := httptest.NewRecorder()
 		s.router.ServeHTTP(writer, req)
-		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+		s.Require().Equal(http.StatusBadRequest, writer.Code)
 	})
 }
 
@@ -143,7 +143,7 @@ func (s *PrizeSuite) TestEdit() {
 
 		writer := httptest.NewRecorder()
 		s.router.ServeHTTP(writer, req)
-		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+		s.Require().Equal(http.StatusBadRequest, writer.Code)
 	})
 }
 
@@ -262,7 +262,7 @@ func (s *PrizeSuite) TestPlay() {
 	playPath := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, s.prizeID, PlayPath)
 
 	s.Run("success", func() {
-		req, err := newRequestJSON(http.MethodGet, playPath, s.organizerID, nil)
+		req, err := newRequestJSON(http.MethodPost, playPath, s.organizerID, nil)
 		s.NoError(err)
 
 		mockedTime := time.Now().UTC()
@@ -340,7 +340,7 @@ func (s *PrizeSuite) TestPlay() {
 	})
 
 	s.Run("error", func() {
-		req, err := newRequestJSON(http.MethodGet, playPath, s.organizerID, nil)
+		req, err := newRequestJSON(http.MethodPost, playPath, s.organizerID, nil)
 		s.NoError(err)
 
 		mockedErr := assert.AnError
@@ -351,3 +351,194 @@ func (s *PrizeSuite) TestPlay() {
 		s.Equal(http.StatusInternalServerError, writer.Code)
 	})
 }
+
+func (s *PrizeSuite) TestPlayResult() {
+	playPath := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, s.prizeID, PlayPath)
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodGet, playPath, s.organizerID, nil)
+		s.NoError(err)
+
+		mockedResponse := &service.PrizePlayResult{
+			Winners: []service.PlayParticipant{{Participant: service.Participant{ID: "ID1"}}},
+		}
+
+		s.prizeService.EXPECT().PlayResult(s.prizeID).Return(mockedResponse, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("not_played", func() {
+		req, err := newRequestJSON(http.MethodGet, playPath, s.organizerID, nil)
+		s.NoError(err)
+
+		s.prizeService.EXPECT().PlayResult(s.prizeID).Return(nil, service.ErrPrizeNotPlayed)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *PrizeSuite) TestVoidDraw() {
+	voidPath := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, s.prizeID, PlayPath, VoidPath)
+
+	voidRequest := &service.VoidDrawRequest{
+		Reason:       "winner is absent",
+		ReturnToPool: true,
+	}
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodPost, voidPath, s.organizerID, voidRequest)
+		s.NoError(err)
+
+		mockedResponse := &service.PrizePlayResult{
+			Winners: []service.PlayParticipant{},
+			Voided: []service.VoidedWinner{
+				{
+					PlayParticipant: service.PlayParticipant{
+						Participant: service.Participant{ID: "ID1"},
+					},
+					Reason:         voidRequest.Reason,
+					ReturnedToPool: true,
+				},
+			},
+		}
+
+		s.prizeService.EXPECT().VoidDraw(s.prizeID, voidRequest).Return(mockedResponse, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+		assertJSONResponse(s.T(), mockedResponse, writer.Body)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodPost, voidPath, s.organizerID, voidRequest)
+		s.NoError(err)
+
+		s.prizeService.EXPECT().VoidDraw(s.prizeID, voidRequest).Return(nil, service.ErrNoWinners)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *PrizeSuite) TestClaim() {
+	claimPath := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, s.prizeID, PlayPath, ClaimPath)
+	claimRequest := &service.ClaimRequest{ParticipantID: "ID1"}
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodPost, claimPath, s.organizerID, claimRequest)
+		s.NoError(err)
+
+		mockedResponse := &service.PrizePlayResult{
+			Winners: []service.PlayParticipant{
+				{
+					Participant: service.Participant{ID: "ID1"},
+					Claim:       &service.WinnerClaim{Status: service.ClaimClaimed},
+				},
+			},
+		}
+
+		s.prizeService.EXPECT().Claim(s.prizeID, claimRequest).Return(mockedResponse, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+		assertJSONResponse(s.T(), mockedResponse, writer.Body)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodPost, claimPath, s.organizerID, claimRequest)
+		s.NoError(err)
+
+		s.prizeService.EXPECT().Claim(s.prizeID, claimRequest).Return(nil, service.ErrClaimNotPending)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *PrizeSuite) TestForfeit() {
+	forfeitPath := joinPath(ApiPath, RafflesPath, s.raffleID, PrizesPath, s.prizeID, PlayPath, ForfeitPath)
+	forfeitRequest := &service.ForfeitRequest{ParticipantID: "ID1", Reason: "did not show up"}
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodPost, forfeitPath, s.organizerID, forfeitRequest)
+		s.NoError(err)
+
+		mockedResponse := &service.PrizePlayResult{
+			Winners: []service.PlayParticipant{
+				{
+					Participant: service.Participant{ID: "ID1"},
+					Claim:       &service.WinnerClaim{Status: service.ClaimForfeited},
+				},
+				{
+					Participant: service.Participant{ID: "ID2"},
+					Claim:       &service.WinnerClaim{Status: service.ClaimPending},
+				},
+			},
+		}
+
+		s.prizeService.EXPECT().Forfeit(s.prizeID, forfeitRequest).Return(mockedResponse, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+		assertJSONResponse(s.T(), mockedResponse, writer.Body)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodPost, forfeitPath, s.organizerID, forfeitRequest)
+		s.NoError(err)
+
+		s.prizeService.EXPECT().Forfeit(s.prizeID, forfeitRequest).Return(nil, assert.AnError)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *PrizeSuite) TestListUnclaimed() {
+	unclaimedPath := joinPath(ApiPath, RafflesPath, s.raffleID, UnclaimedPath)
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodGet, unclaimedPath, s.organizerID, nil)
+		s.NoError(err)
+
+		unclaimed := []service.UnclaimedPrize{
+			{
+				PrizeID:   s.prizeID,
+				PrizeName: "prize_1",
+				Winner: service.PlayParticipant{
+					Participant: service.Participant{ID: "ID1"},
+					Claim:       &service.WinnerClaim{Status: service.ClaimPending},
+				},
+			},
+		}
+
+		s.prizeService.EXPECT().ListUnclaimed().Return(unclaimed, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+		assertJSONResponse(s.T(), ListResponse[service.UnclaimedPrize]{Items: unclaimed}, writer.Body)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodGet, unclaimedPath, s.organizerID, nil)
+		s.NoError(err)
+
+		s.prizeService.EXPECT().ListUnclaimed().Return(nil, assert.AnError)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
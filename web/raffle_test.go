@@ -85,6 +85,36 @@ func (s *RaffleSuite) TestCreate() {
 		s.Require().NoError(err)
 		req.Header.Set(GoogleUserIDHeader, s.organizerID)
 
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusBadRequest, writer.Code)
+	})
+}
+
+func (s *RaffleSuite) TestGet() {
+	raffleID := "raffle_id_1"
+	rafflePath := joinPath(ApiPath, RafflesPath, raffleID)
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodGet, rafflePath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		raffle := &service.Raffle{ID: raffleID, Name: "raffle_1", UpdatedAt: time.Unix(0, 1700000000123456000)}
+		s.raffleService.EXPECT().Get(raffleID).Return(raffle, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusOK, writer.Code)
+		s.Equal(`"1700000000123456000"`, writer.Header().Get("ETag"))
+		s.Contains(writer.Body.String(), `"name":"raffle_1"`)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodGet, rafflePath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().Get(raffleID).Return(nil, service.ErrNotFound)
+
 		writer := httptest.NewRecorder()
 		s.router.ServeHTTP(writer, req)
 		s.Equal(http.StatusInternalServerError, writer.Code)
@@ -111,6 +141,22 @@ func (s *RaffleSuite) TestEdit() {
 		s.Equal(http.StatusOK, writer.Code)
 	})
 
+	s.Run("version_mismatch", func() {
+		upd := &service.RaffleRequest{Name: "raffle_1"}
+
+		req, err := newRequestJSON(http.MethodPut, rafflePath, s.organizerID, upd)
+		s.Require().NoError(err)
+		req.Header.Set("If-Match", `"1700000000123456000"`)
+
+		expected := *upd
+		expected.IfVersion = time.Unix(0, 1700000000123456000)
+		s.raffleService.EXPECT().Edit(raffleID, &expected).Return(service.ErrVersionMismatch)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+		s.Equal(http.StatusPreconditionFailed, writer.Code)
+	})
+
 	s.Run("error", func() {
 		upd := &service.RaffleRequest{
 			Name: "raffle_1",
@@ -136,7 +182,7 @@ func (s *RaffleSuite) TestEdit() {
 
 		writer := httptest.NewRecorder()
 		s.router.ServeHTTP(writer, req)
-		s.Equal(http.StatusInternalServerError, writer.Code)
+		s.Equal(http.StatusBadRequest, writer.Code)
 	})
 }
 
@@ -262,3 +308,193 @@ func (s *RaffleSuite) TestDownloadXLSX() {
 		s.Equal(http.StatusInternalServerError, writer.Code)
 	})
 }
+
+func (s *RaffleSuite) TestPlayAll() {
+	raffleID := "raffle_id_1"
+	playAllPath := joinPath(ApiPath, RafflesPath, raffleID, PlayAllPath)
+
+	s.Run("success", func() {
+		playAll := &service.PlayAllRequest{Order: service.PlayByTicketCost}
+		result := &service.RafflePlayResult{
+			RaffleID: raffleID,
+			Prizes: []service.PrizeDrawResult{
+				{PrizeID: "prize_id_1", Winner: &service.PlayParticipant{Participant: service.Participant{ID: "participant_id_1"}}},
+				{PrizeID: "prize_id_2", Error: "no donations"},
+			},
+		}
+
+		req, err := newRequestJSON(http.MethodPost, playAllPath, s.organizerID, playAll)
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().PlayAll(raffleID, playAll).Return(result, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), `"prizeId":"prize_id_2"`)
+	})
+
+	s.Run("error", func() {
+		playAll := &service.PlayAllRequest{Atomic: true}
+
+		req, err := newRequestJSON(http.MethodPost, playAllPath, s.organizerID, playAll)
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().PlayAll(raffleID, playAll).Return(nil, service.ErrNoDonations)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+	})
+
+	s.Run("empty_body", func() {
+		req, err := newRequestWithOrigin(http.MethodPost, playAllPath, emptyBody())
+		s.Require().NoError(err)
+
+		req.Header.Set(GoogleUserIDHeader, s.organizerID)
+
+		s.raffleService.EXPECT().PlayAll(raffleID, &service.PlayAllRequest{}).Return(&service.RafflePlayResult{RaffleID: raffleID}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+
+	s.Run("invalid_body", func() {
+		req, err := newRequestWithOrigin(http.MethodPost, playAllPath, bytes.NewBufferString(`{"atomic":`))
+		s.Require().NoError(err)
+
+		req.Header.Set(GoogleUserIDHeader, s.organizerID)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusBadRequest, writer.Code)
+	})
+}
+
+func (s *RaffleSuite) TestBulkDonations() {
+	raffleID := "raffle_id_1"
+	bulkPath := joinPath(ApiPath, RafflesPath, raffleID, DonationsPath, BulkPath)
+
+	bulk := &service.BulkDonationRequest{
+		Donations: []service.PrizeDonationRequest{
+			{PrizeID: "prize_id_1", DonationRequest: service.DonationRequest{Amount: 100, ParticipantID: "participant_id_1", EnteredBy: s.organizerID}},
+			{PrizeID: "prize_id_2", DonationRequest: service.DonationRequest{Amount: 100, ParticipantID: "participant_id_1", EnteredBy: s.organizerID}},
+		},
+	}
+
+	s.Run("success", func() {
+		result := &service.BulkDonationResult{
+			Donations: []service.BulkDonationItemResult{
+				{PrizeID: "prize_id_1", ID: "donation_id_1"},
+				{PrizeID: "prize_id_2", Error: "can't edit played prize donations"},
+			},
+		}
+
+		req, err := newRequestJSON(http.MethodPost, bulkPath, s.organizerID, bulk)
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().CreateDonations(raffleID, bulk).Return(result, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), `"id":"donation_id_1"`)
+		s.Contains(writer.Body.String(), `"error":"can't edit played prize donations"`)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodPost, bulkPath, s.organizerID, bulk)
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().CreateDonations(raffleID, bulk).Return(nil, service.ErrRaffleClosed)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *RaffleSuite) TestStats() {
+	raffleID := "raffle_id_1"
+	statsPath := joinPath(ApiPath, RafflesPath, raffleID, StatsPath)
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodGet, statsPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().Stats(raffleID).Return(&service.RaffleStats{TotalRaised: 400, DonationCount: 5}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), `"totalRaised":400`)
+	})
+
+	s.Run("not_found", func() {
+		req, err := newRequestJSON(http.MethodGet, statsPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().Stats(raffleID).Return(nil, service.ErrNotFound)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *RaffleSuite) TestRepairTotals() {
+	raffleID := "raffle_id_1"
+	repairPath := joinPath(ApiPath, RafflesPath, raffleID, RepairTotalsPath)
+
+	s.Run("success", func() {
+		req, err := newRequestJSON(http.MethodPost, repairPath, s.organizerID, &service.RepairTotalsRequest{DryRun: true})
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().RepairTotals(raffleID, &service.RepairTotalsRequest{DryRun: true}).
+			Return(&service.RepairTotalsResult{Prizes: []service.PrizeTotalsDrift{}, CountersDrifted: true}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), `"countersDrifted":true`)
+	})
+
+	s.Run("error", func() {
+		req, err := newRequestJSON(http.MethodPost, repairPath, s.organizerID, &service.RepairTotalsRequest{})
+		s.Require().NoError(err)
+
+		s.raffleService.EXPECT().RepairTotals(raffleID, &service.RepairTotalsRequest{}).Return(nil, assert.AnError)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *RaffleSuite) TestArchive() {
+	raffleID := "raffle_id_1"
+
+	req, err := newRequestJSON(http.MethodPost, joinPath(ApiPath, RafflesPath, raffleID, ArchivePath), s.organizerID, &service.ArchiveRequest{})
+	s.Require().NoError(err)
+
+	now := time.Now()
+	s.raffleService.EXPECT().Archive(raffleID, &service.ArchiveRequest{}).
+		Return(&service.Raffle{ID: raffleID, ArchivedAt: &now}, nil)
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+
+	s.Require().Equal(http.StatusOK, writer.Code)
+	s.Contains(writer.Body.String(), `"archivedAt"`)
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/web/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ReconciliationSuite struct {
+	suite.Suite
+	organizerService      *mocks.MockOrganizerService
+	raffleService         *mocks.MockRaffleService
+	reconciliationService *mocks.MockReconciliationService
+	router                *Router
+	organizerID           string
+	raffleID              string
+}
+
+func TestReconciliation(t *testing.T) {
+	suite.Run(t, &ReconciliationSuite{})
+}
+
+func (s *ReconciliationSuite) SetupTest() {
+	ctrl := gomock.NewController(s.T())
+	s.organizerService = mocks.NewMockOrganizerService(ctrl)
+	s.raffleService = mocks.NewMockRaffleService(ctrl)
+	s.reconciliationService = mocks.NewMockReconciliationService(ctrl)
+	s.organizerID = "organizer_id_1"
+	s.raffleID = "raffle_id_1"
+
+	s.organizerService.EXPECT().CreateOrganizerIfNotExists(s.organizerID).Return(nil).AnyTimes()
+	s.organizerService.EXPECT().RaffleService(s.organizerID).Return(s.raffleService).AnyTimes()
+	s.raffleService.EXPECT().ReconciliationService(s.raffleID).Return(s.reconciliationService).AnyTimes()
+
+	var err error
+	s.router, err = NewRouter(s.organizerService, logger.NewLogger(logger.LevelDebug))
+	s.Require().NoError(err)
+}
+
+func (s *ReconciliationSuite) TestReport() {
+	reportPath := joinPath(ApiPath, RafflesPath, s.raffleID, ReconcilePath)
+
+	s.Run("time_window", func() {
+		from := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+		to := from.Add(8 * time.Hour)
+
+		req, err := newRequestJSON(http.MethodGet, reportPath+"?from=2024-05-01T09:00:00Z&to=2024-05-01T17:00:00Z", s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.reconciliationService.EXPECT().Report(&service.ReconciliationRequest{From: &from, To: &to}).
+			Return(&service.ReconciliationReport{Totals: service.ReconciliationTotals{Total: 300}}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), `"total":300`)
+	})
+
+	s.Run("invalid_time", func() {
+		req, err := newRequestJSON(http.MethodGet, reportPath+"?from=yesterday", s.organizerID, nil)
+		s.Require().NoError(err)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func (s *ReconciliationSuite) TestShifts() {
+	shiftsPath := joinPath(ApiPath, RafflesPath, s.raffleID, ShiftsPath)
+
+	s.Run("close_own_shift", func() {
+		req, err := newRequestJSON(http.MethodPost, joinPath(shiftsPath, ClosePath), s.organizerID, &service.CloseShiftRequest{})
+		s.Require().NoError(err)
+
+		s.reconciliationService.EXPECT().CloseShift(&service.CloseShiftRequest{VolunteerID: s.organizerID}).
+			Return(&service.Shift{ID: "shift_id_1", VolunteerID: s.organizerID}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), "shift_id_1")
+	})
+
+	s.Run("close_volunteer_shift", func() {
+		closeShift := &service.CloseShiftRequest{VolunteerID: "volunteer_id_1"}
+
+		req, err := newRequestJSON(http.MethodPost, joinPath(shiftsPath, ClosePath), s.organizerID, closeShift)
+		s.Require().NoError(err)
+
+		s.reconciliationService.EXPECT().CloseShift(closeShift).Return(nil, service.ErrShiftOutdated)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusInternalServerError, writer.Code)
+	})
+
+	s.Run("list", func() {
+		req, err := newRequestJSON(http.MethodGet, shiftsPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.reconciliationService.EXPECT().ListShifts().Return([]service.Shift{{ID: "shift_id_1"}}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+	})
+}
@@ -3,11 +3,23 @@ package web
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/kaznasho/yarmarok/service"
 )
 
+// ErrInvalidBody is returned when the request body can't be decoded.
+var ErrInvalidBody = errors.New("invalid request body")
+
+// alreadyExistsResponse tells the client which item conflicts with the request.
+type alreadyExistsResponse struct {
+	Error string `json:"error"`
+	ID    string `json:"id"`
+}
+
 // respond writes minimalistic response.
 // function signature and error/status handling may be different.
 func (r *Router) respond(rw http.ResponseWriter, data any) {
@@ -31,18 +43,47 @@ func (r *Router) respond(rw http.ResponseWriter, data any) {
 
 func (r *Router) respondErr(rw http.ResponseWriter, err error) {
 	r.logger.WithError(err).Warn("responding with error")
+
+	var alreadyExists *service.AlreadyExistsError
+	if errors.As(err, &alreadyExists) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusConflict)
+		_ = r.encodeBody(rw, alreadyExistsResponse{Error: err.Error(), ID: alreadyExists.ID})
+		return
+	}
+
+	if errors.Is(err, service.ErrVersionMismatch) {
+		http.Error(rw, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+
+	if errors.Is(err, ErrInvalidBody) || errors.Is(err, service.ErrInvalidRequest) {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	http.Error(rw, err.Error(), http.StatusInternalServerError)
 }
 
 // decodeBody reads data from a body and converts it to any.
 func (r *Router) decodeBody(body io.Reader, data any) error {
 	if err := json.NewDecoder(body).Decode(data); err != nil {
-		return fmt.Errorf("decoding body: %w", err)
+		return fmt.Errorf("%w: decoding body: %w", ErrInvalidBody, err)
 	}
 
 	return nil
 }
 
+// decodeOptionalBody works like decodeBody, but leaves data as is if the body is empty.
+func (r *Router) decodeOptionalBody(body io.Reader, data any) error {
+	err := r.decodeBody(body, data)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
+
 // encodeBody writes data to a writer after converting it to JSON.
 func (r *Router) encodeBody(rw io.Writer, data any) error {
 	if err := json.NewEncoder(rw).Encode(data); err != nil {
@@ -3,6 +3,7 @@ package web
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
@@ -17,7 +18,32 @@ const (
 	ParticipantsPath = "/participants"
 	PrizesPath       = "/prizes"
 	DonationsPath    = "/donations"
+	PaymentsPath     = "/payments"
 	PlayPath         = "/play"
+	PlayAllPath      = "/play-all"
+	BulkPath         = "/bulk"
+	VoidPath         = "/void"
+	ClaimPath        = "/claim"
+	ForfeitPath      = "/forfeit"
+	UnclaimedPath    = "/unclaimed"
+	SearchPath       = "/search"
+	DuplicatesPath   = "/duplicates"
+	MergePath        = "/merge"
+	SchedulerPath    = "/scheduler"
+	PlayDuePath      = "/play-due"
+	ReconcilePath    = "/reconciliation"
+	ShiftsPath       = "/shifts"
+	ClosePath        = "/close"
+	StatsPath        = "/stats"
+	RepairTotalsPath = "/repair-totals"
+	WebhooksPath     = "/webhooks"
+	DeliveriesPath   = "/deliveries"
+	ReplayPath       = "/replay"
+	ArchivePath      = "/archive"
+	ExportPath       = "/export"
+	AnonymizePath    = "/anonymize"
+	RotateKeysPath   = "/rotate-keys"
+	NotifyPath       = "/notifications"
 )
 
 const (
@@ -25,6 +51,13 @@ const (
 	participantIDParam = "participant_id"
 	prizeIDParam       = "prize_id"
 	donationIDParam    = "donation_id"
+	paymentIDParam     = "payment_id"
+	webhookIDParam     = "webhook_id"
+	deliveryIDParam    = "delivery_id"
+
+	searchQueryParam = "q"
+	fromQueryParam   = "from"
+	toQueryParam     = "to"
 )
 
 const (
@@ -32,6 +65,9 @@ const (
 	participantIDPlaceholder = "/{" + participantIDParam + "}"
 	prizeIDPlaceholder       = "/{" + prizeIDParam + "}"
 	donationIDPlaceholder    = "/{" + donationIDParam + "}"
+	paymentIDPlaceholder     = "/{" + paymentIDParam + "}"
+	webhookIDPlaceholder     = "/{" + webhookIDParam + "}"
+	deliveryIDPlaceholder    = "/{" + deliveryIDParam + "}"
 )
 
 // localRun is true if app is build for local run
@@ -52,10 +88,17 @@ type Router struct {
 	chi.Router
 	organizerService service.OrganizerService
 	logger           *logger.Entry
+
+	idempotencyStorage service.IdempotencyStorage
+	idempotencyTTL     time.Duration
+
+	keyRotator service.KeyRotator
+
+	schedulerAuth *schedulerAuth
 }
 
 // NewRouter creates a new Router
-func NewRouter(os service.OrganizerService, log *logger.Logger) (*Router, error) {
+func NewRouter(os service.OrganizerService, log *logger.Logger, opts ...RouterOption) (*Router, error) {
 	router := &Router{
 		Router:           chi.NewRouter(),
 		organizerService: os,
@@ -65,18 +108,54 @@ func NewRouter(os service.OrganizerService, log *logger.Logger) (*Router, error)
 				"trace_id":  uuid.New().String(),
 			},
 		),
+		idempotencyTTL: DefaultIdempotencyTTL,
+	}
+
+	for _, opt := range opts {
+		opt(router)
 	}
 
 	router.Use(router.corsMiddleware)
 	router.Use(router.loggingMiddleware)
 	router.Use(router.recoverMiddleware)
 	router.Use(router.headerMiddleware)
-	router.Use(router.organizerMiddleware)
+
+	// "/api/scheduler"
+	router.Route(ApiPath+SchedulerPath, func(r chi.Router) {
+		r.Use(router.schedulerMiddleware)
+
+		r.Post(PlayDuePath, router.playDuePrizes)
+		r.Post(WebhooksPath, router.deliverWebhooks)
+		r.Post(NotifyPath, router.sendNotifications)
+		r.Post(AnonymizePath, router.anonymizeExpired)
+
+		if router.keyRotator != nil {
+			r.Post(RotateKeysPath, router.rotateKeys)
+		}
+	})
 
 	// "/api"
-	router.Route(ApiPath, func(r chi.Router) {
+	router.With(router.organizerMiddleware).Route(ApiPath, func(r chi.Router) {
 		r.Handle("/login", http.RedirectHandler("/", http.StatusSeeOther))
 
+		// "/api/webhooks"
+		r.Route(WebhooksPath, func(r chi.Router) {
+			r.Post("/", router.createWebhook)
+			r.Get("/", router.listWebhooks)
+
+			// "/api/webhooks/{webhook_id}"
+			r.Route(webhookIDPlaceholder, func(r chi.Router) {
+				r.Get("/", router.getWebhook)
+				r.Delete("/", router.deleteWebhook)
+
+				// "/api/webhooks/{webhook_id}/deliveries"
+				r.Route(DeliveriesPath, func(r chi.Router) {
+					r.Get("/", router.listWebhookDeliveries)
+					r.Post(deliveryIDPlaceholder+ReplayPath, router.replayWebhookDelivery)
+				})
+			})
+		})
+
 		// "/api/raffles"
 		r.Route(RafflesPath, func(r chi.Router) {
 			r.Post("/", router.createRaffle)
@@ -84,22 +163,73 @@ func NewRouter(os service.OrganizerService, log *logger.Logger) (*Router, error)
 
 			// "/api/raffles/{raffle_id}"
 			r.Route(raffleIDPlaceholder, func(r chi.Router) {
+				r.Get("/", router.getRaffle)
 				r.Put("/", router.editRaffle)
+				r.Patch("/", router.patchRaffle)
 				r.Delete("/", router.deleteRaffle)
 				r.Get("/download-xlsx", router.downloadRaffleXLSX)
+				r.Get(UnclaimedPath, router.listUnclaimedPrizes)
+				r.Get(StatsPath, router.getRaffleStats)
+				r.Post(RepairTotalsPath, router.repairRaffleTotals)
+				r.Post(ArchivePath, router.archiveRaffle)
+				r.With(router.resourceIdempotencyMiddleware(raffleIDParam)).Post(PlayAllPath, router.playAllPrizes)
 
 				// "/api/raffles/{raffle_id}/participants"
 				r.Route(ParticipantsPath, func(r chi.Router) {
 					r.Post("/", router.createParticipant)
 					r.Get("/", router.listParticipants)
+					r.Get(SearchPath, router.searchParticipants)
+					r.Get(DuplicatesPath, router.listDuplicateParticipants)
 
 					// "/api/raffles/{raffle_id}/participants/{participant_id}"
 					r.Route(participantIDPlaceholder, func(r chi.Router) {
+						r.Get("/", router.getParticipant)
 						r.Put("/", router.editParticipant)
+						r.Patch("/", router.patchParticipant)
 						r.Delete("/", router.deleteParticipant)
+						r.Post(MergePath, router.mergeParticipants)
+						r.Get(ExportPath, router.exportParticipant)
+						r.Post(AnonymizePath, router.anonymizeParticipant)
 					})
 				})
 
+				// "/api/raffles/{raffle_id}/donations"
+				r.Route(DonationsPath, func(r chi.Router) {
+					r.With(router.idempotencyMiddleware).Post("/", router.createRaffleDonation)
+					r.Get("/", router.listRaffleDonations)
+					r.With(router.idempotencyMiddleware).Post(BulkPath, router.createBulkDonations)
+
+					// "/api/raffles/{raffle_id}/donations/{donation_id}"
+					r.Route(donationIDPlaceholder, func(r chi.Router) {
+						r.Get("/", router.getRaffleDonation)
+						r.Put("/", router.editRaffleDonation)
+						r.Patch("/", router.patchRaffleDonation)
+						r.Delete("/", router.deleteRaffleDonation)
+					})
+				})
+
+				// "/api/raffles/{raffle_id}/payments"
+				r.Route(PaymentsPath, func(r chi.Router) {
+					r.With(router.idempotencyMiddleware).Post("/", router.createPayment)
+					r.Get("/", router.listPayments)
+
+					// "/api/raffles/{raffle_id}/payments/{payment_id}"
+					r.Route(paymentIDPlaceholder, func(r chi.Router) {
+						r.Get("/", router.getPayment)
+						r.Put("/", router.editPayment)
+						r.Delete("/", router.deletePayment)
+					})
+				})
+
+				// "/api/raffles/{raffle_id}/reconciliation"
+				r.Get(ReconcilePath, router.reconciliationReport)
+
+				// "/api/raffles/{raffle_id}/shifts"
+				r.Route(ShiftsPath, func(r chi.Router) {
+					r.Get("/", router.listShifts)
+					r.Post(ClosePath, router.closeShift)
+				})
+
 				// "/api/raffles/{raffle_id}/prizes"
 				r.Route(PrizesPath, func(r chi.Router) {
 					r.Post("/", router.createPrize)
@@ -109,22 +239,28 @@ func NewRouter(os service.OrganizerService, log *logger.Logger) (*Router, error)
 					r.Route(prizeIDPlaceholder, func(r chi.Router) {
 						r.Get("/", router.getPrize)
 						r.Put("/", router.editPrize)
+						r.Patch("/", router.patchPrize)
 						r.Delete("/", router.deletePrize)
 
 						// "/api/raffles/{raffle_id}/prizes/{prize_id}/play"
 						r.Route(PlayPath, func(r chi.Router) {
-							r.Get("/", router.playPrize)
+							r.Get("/", router.getPlayResult)
+							r.With(router.resourceIdempotencyMiddleware(prizeIDParam)).Post("/", router.playPrize)
+							r.Post(VoidPath, router.voidPrizeDraw)
+							r.Post(ClaimPath, router.claimPrize)
+							r.Post(ForfeitPath, router.forfeitPrize)
 						})
 
 						// "/api/raffles/{raffle_id}/prizes/{prize_id}/donations"
 						r.Route(DonationsPath, func(r chi.Router) {
-							r.Post("/", router.createDonation)
+							r.With(router.idempotencyMiddleware).Post("/", router.createDonation)
 							r.Get("/", router.listDonations)
 
 							// "/api/raffles/{raffle_id}/prizes/{prize_id}/donations/{donation_id}"
 							r.Route(donationIDPlaceholder, func(r chi.Router) {
 								r.Get("/", router.getDonation)
 								r.Put("/", router.editDonation)
+								r.Patch("/", router.patchDonation)
 								r.Delete("/", router.deleteDonation)
 							})
 						})
@@ -148,6 +284,16 @@ func (r *Router) createRaffle(w http.ResponseWriter, req *http.Request) {
 	NewCreateHandler(r, svc.Create).Handle(w, req)
 }
 
+func (r *Router) getRaffle(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewGetHandler(r, svc.Get).Handle(w, req)
+}
+
 func (r *Router) editRaffle(w http.ResponseWriter, req *http.Request) {
 	svc, err := r.getRaffleService(req)
 	if err != nil {
@@ -158,6 +304,16 @@ func (r *Router) editRaffle(w http.ResponseWriter, req *http.Request) {
 	NewEditHandler(r, svc.Edit).Handle(w, req)
 }
 
+func (r *Router) patchRaffle(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewEditHandler(r, svc.Patch).Handle(w, req)
+}
+
 func (r *Router) deleteRaffle(w http.ResponseWriter, req *http.Request) {
 	svc, err := r.getRaffleService(req)
 	if err != nil {
@@ -205,6 +361,36 @@ func (r *Router) downloadRaffleXLSX(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+func (r *Router) getRaffleStats(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewGetHandler(r, svc.Stats).Handle(w, req)
+}
+
+func (r *Router) repairRaffleTotals(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.RepairTotals).Handle(w, req)
+}
+
+func (r *Router) archiveRaffle(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.Archive).Handle(w, req)
+}
+
 func (r *Router) createParticipant(w http.ResponseWriter, req *http.Request) {
 	svc, err := r.getParticipantService(req)
 	if err != nil {
@@ -215,6 +401,70 @@ func (r *Router) createParticipant(w http.ResponseWriter, req *http.Request) {
 	NewCreateHandler(r, svc.Create).Handle(w, req)
 }
 
+func (r *Router) getParticipant(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getParticipantService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewGetHandler(r, svc.Get).Handle(w, req)
+}
+
+func (r *Router) searchParticipants(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getParticipantService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	search := func() ([]service.Participant, error) {
+		return svc.Search(req.URL.Query().Get(searchQueryParam))
+	}
+
+	NewListHandler(r, search).Handle(w, req)
+}
+
+func (r *Router) listDuplicateParticipants(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getParticipantService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewListHandler(r, svc.Duplicates).Handle(w, req)
+}
+
+func (r *Router) mergeParticipants(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getParticipantService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.Merge).Handle(w, req)
+}
+
+func (r *Router) exportParticipant(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getParticipantService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewGetHandler(r, svc.Export).Handle(w, req)
+}
+
+func (r *Router) anonymizeParticipant(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getParticipantService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.Anonymize).Handle(w, req)
+}
+
 func (r *Router) editParticipant(w http.ResponseWriter, req *http.Request) {
 	svc, err := r.getParticipantService(req)
 	if err != nil {
@@ -225,6 +475,16 @@ func (r *Router) editParticipant(w http.ResponseWriter, req *http.Request) {
 	NewEditHandler(r, svc.Edit).Handle(w, req)
 }
 
+func (r *Router) patchParticipant(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getParticipantService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewEditHandler(r, svc.Patch).Handle(w, req)
+}
+
 func (r *Router) deleteParticipant(w http.ResponseWriter, req *http.Request) {
 	svc, err := r.getParticipantService(req)
 	if err != nil {
@@ -275,6 +535,16 @@ func (r *Router) editPrize(w http.ResponseWriter, req *http.Request) {
 	NewEditHandler(r, svc.Edit).Handle(w, req)
 }
 
+func (r *Router) patchPrize(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPrizeService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewEditHandler(r, svc.Patch).Handle(w, req)
+}
+
 func (r *Router) deletePrize(w http.ResponseWriter, req *http.Request) {
 	svc, err := r.getPrizeService(req)
 	if err != nil {
@@ -305,6 +575,114 @@ func (r *Router) playPrize(w http.ResponseWriter, req *http.Request) {
 	NewGetHandler(r, svc.Play).Handle(w, req)
 }
 
+func (r *Router) getPlayResult(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPrizeService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewGetHandler(r, svc.PlayResult).Handle(w, req)
+}
+
+func (r *Router) voidPrizeDraw(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPrizeService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.VoidDraw).Handle(w, req)
+}
+
+func (r *Router) playAllPrizes(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.PlayAll).Handle(w, req)
+}
+
+func (r *Router) createBulkDonations(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	userID, err := extractOrganizerID(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	create := func(id string, b *service.BulkDonationRequest) (*service.BulkDonationResult, error) {
+		for i := range b.Donations {
+			b.Donations[i].EnteredBy = userID
+		}
+
+		return svc.CreateDonations(id, b)
+	}
+
+	NewActionHandler(r, create).Handle(w, req)
+}
+
+func (r *Router) claimPrize(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPrizeService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.Claim).Handle(w, req)
+}
+
+func (r *Router) forfeitPrize(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPrizeService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.Forfeit).Handle(w, req)
+}
+
+func (r *Router) listUnclaimedPrizes(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPrizeService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewListHandler(r, svc.ListUnclaimed).Handle(w, req)
+}
+
+// playDuePrizes plays prizes which draw time has come.
+// It is meant to be triggered by Cloud Scheduler.
+func (r *Router) playDuePrizes(w http.ResponseWriter, req *http.Request) {
+	NewListHandler(r, r.organizerService.PlayDuePrizes).Handle(w, req)
+}
+
+// deliverWebhooks publishes closed raffles and attempts pending webhook deliveries.
+// It is meant to be triggered by Cloud Scheduler.
+func (r *Router) deliverWebhooks(w http.ResponseWriter, req *http.Request) {
+	NewListHandler(r, r.organizerService.DeliverWebhooks).Handle(w, req)
+}
+
+// sendNotifications notifies winners queued when prizes were played.
+// It is meant to be triggered by Cloud Scheduler.
+func (r *Router) sendNotifications(w http.ResponseWriter, req *http.Request) {
+	NewListHandler(r, r.organizerService.SendNotifications).Handle(w, req)
+}
+
+// anonymizeExpired anonymizes participants of raffles archived longer than the retention period.
+// It is meant to be triggered by Cloud Scheduler.
+func (r *Router) anonymizeExpired(w http.ResponseWriter, req *http.Request) {
+	NewListHandler(r, r.organizerService.AnonymizeExpired).Handle(w, req)
+}
+
 func (r *Router) createDonation(w http.ResponseWriter, req *http.Request) {
 	svc, err := r.getDonationService(req)
 	if err != nil {
@@ -312,7 +690,18 @@ func (r *Router) createDonation(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	NewCreateHandler(r, svc.Create).Handle(w, req)
+	userID, err := extractOrganizerID(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	create := func(d *service.DonationRequest) (string, error) {
+		d.EnteredBy = userID
+		return svc.Create(d)
+	}
+
+	NewCreateHandler(r, create).Handle(w, req)
 }
 
 func (r *Router) getDonation(w http.ResponseWriter, req *http.Request) {
@@ -345,6 +734,16 @@ func (r *Router) editDonation(w http.ResponseWriter, req *http.Request) {
 	NewEditHandler(r, svc.Edit).Handle(w, req)
 }
 
+func (r *Router) patchDonation(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getDonationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewEditHandler(r, svc.Patch).Handle(w, req)
+}
+
 func (r *Router) deleteDonation(w http.ResponseWriter, req *http.Request) {
 	svc, err := r.getDonationService(req)
 	if err != nil {
@@ -354,3 +753,261 @@ func (r *Router) deleteDonation(w http.ResponseWriter, req *http.Request) {
 
 	NewDeleteHandler(r, svc.Delete).Handle(w, req)
 }
+
+func (r *Router) createRaffleDonation(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleDonationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	userID, err := extractOrganizerID(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	create := func(d *service.DonationRequest) (string, error) {
+		d.EnteredBy = userID
+		return svc.Create(d)
+	}
+
+	NewCreateHandler(r, create).Handle(w, req)
+}
+
+func (r *Router) getRaffleDonation(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleDonationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewGetHandler(r, svc.Get).Handle(w, req)
+}
+
+func (r *Router) listRaffleDonations(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleDonationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewListHandler(r, svc.List).Handle(w, req)
+}
+
+func (r *Router) editRaffleDonation(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleDonationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewEditHandler(r, svc.Edit).Handle(w, req)
+}
+
+func (r *Router) patchRaffleDonation(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleDonationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewEditHandler(r, svc.Patch).Handle(w, req)
+}
+
+func (r *Router) deleteRaffleDonation(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getRaffleDonationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewDeleteHandler(r, svc.Delete).Handle(w, req)
+}
+
+func (r *Router) createPayment(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPaymentService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	userID, err := extractOrganizerID(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	create := func(p *service.PaymentRequest) (string, error) {
+		p.EnteredBy = userID
+		return svc.Create(p)
+	}
+
+	NewCreateHandler(r, create).Handle(w, req)
+}
+
+func (r *Router) getPayment(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPaymentService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewGetHandler(r, svc.Get).Handle(w, req)
+}
+
+func (r *Router) listPayments(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPaymentService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewListHandler(r, svc.List).Handle(w, req)
+}
+
+func (r *Router) editPayment(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPaymentService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewEditHandler(r, svc.Edit).Handle(w, req)
+}
+
+func (r *Router) deletePayment(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getPaymentService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewDeleteHandler(r, svc.Delete).Handle(w, req)
+}
+
+func (r *Router) reconciliationReport(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getReconciliationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	window, err := extractTimeWindow(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	report := func(string) (*service.ReconciliationReport, error) {
+		return svc.Report(window)
+	}
+
+	NewGetHandler(r, report).Handle(w, req)
+}
+
+func (r *Router) listShifts(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getReconciliationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewListHandler(r, svc.ListShifts).Handle(w, req)
+}
+
+// closeShift closes a shift of the volunteer from the request
+// or of the authenticated user if the volunteer is not set.
+func (r *Router) closeShift(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getReconciliationService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	userID, err := extractOrganizerID(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	closeShift := func(_ string, c *service.CloseShiftRequest) (*service.Shift, error) {
+		if c.VolunteerID == "" {
+			c.VolunteerID = userID
+		}
+
+		return svc.CloseShift(c)
+	}
+
+	NewActionHandler(r, closeShift).Handle(w, req)
+}
+
+func (r *Router) createWebhook(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getWebhookService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewCreateHandler(r, svc.Create).Handle(w, req)
+}
+
+func (r *Router) getWebhook(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getWebhookService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewGetHandler(r, svc.Get).Handle(w, req)
+}
+
+func (r *Router) listWebhooks(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getWebhookService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewListHandler(r, svc.List).Handle(w, req)
+}
+
+func (r *Router) deleteWebhook(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getWebhookService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewDeleteHandler(r, svc.Delete).Handle(w, req)
+}
+
+func (r *Router) listWebhookDeliveries(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getWebhookService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	webhookID, err := extractParam(req, webhookIDParam)
+	if err != nil {
+		r.respondErr(w, errors.Join(ErrMissingID, err))
+		return
+	}
+
+	deliveries := func() ([]service.Delivery, error) {
+		return svc.Deliveries(webhookID)
+	}
+
+	NewListHandler(r, deliveries).Handle(w, req)
+}
+
+func (r *Router) replayWebhookDelivery(w http.ResponseWriter, req *http.Request) {
+	svc, err := r.getWebhookService(req)
+	if err != nil {
+		r.respondErr(w, err)
+		return
+	}
+
+	NewActionHandler(r, svc.Replay).Handle(w, req)
+}
@@ -10,10 +10,12 @@ import (
 	"path"
 	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
 
 	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/service"
 	"github.com/kaznasho/yarmarok/web/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -155,6 +157,35 @@ func TestCORSMiddleware(t *testing.T) {
 		require.Equal(t, defaultOrigin, writer.Header().Get("Access-Control-Allow-Origin"))
 	})
 
+	t.Run("etag_exposed", func(t *testing.T) {
+		req, err := newRequestWithOrigin(http.MethodGet, RafflesPath, nil)
+		require.NoError(t, err)
+
+		writer := httptest.NewRecorder()
+		router.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(etagHeader, `"1"`)
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(writer, req)
+		require.Equal(t, http.StatusOK, writer.Code)
+		require.Contains(t, writer.Header().Get("Access-Control-Expose-Headers"), http.CanonicalHeaderKey(etagHeader))
+	})
+
+	t.Run("preflight_if_match", func(t *testing.T) {
+		req, err := newRequestWithOrigin(http.MethodOptions, RafflesPath, nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		req.Header.Set("Access-Control-Request-Headers", ifMatchHeader)
+
+		writer := httptest.NewRecorder()
+		router.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(writer, req)
+		require.Equal(t, defaultOrigin, writer.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, http.MethodPut, writer.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, ifMatchHeader, writer.Header().Get("Access-Control-Allow-Headers"))
+	})
+
 	t.Run("no_origin", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, RafflesPath, emptyBody())
 		require.NoError(t, err)
@@ -182,6 +213,134 @@ func TestCORSMiddleware(t *testing.T) {
 	})
 }
 
+// writtenEdit is an edit request the edit function writes with the given version.
+type writtenEdit struct {
+	Version int64 `json:"version"`
+	written time.Time
+}
+
+func (e *writtenEdit) WrittenVersion() time.Time {
+	return e.written
+}
+
+func TestEditHandlerETag(t *testing.T) {
+	router, err := NewRouter(nil, logger.NewNoOpLogger())
+	require.NoError(t, err)
+
+	handler := NewEditHandler(router, func(_ string, in *writtenEdit) error {
+		if in.Version == 0 {
+			return nil
+		}
+
+		in.written = time.Unix(0, in.Version)
+		return nil
+	})
+
+	t.Run("written_version", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPatch, RafflesPath, bytes.NewBufferString(`{"version":1700000000123456000}`))
+		require.NoError(t, err)
+
+		writer := httptest.NewRecorder()
+		handler.Handle(writer, req)
+		require.Equal(t, http.StatusOK, writer.Code)
+		require.Equal(t, `"1700000000123456000"`, writer.Header().Get(etagHeader))
+	})
+
+	t.Run("unknown_version", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPatch, RafflesPath, bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+
+		writer := httptest.NewRecorder()
+		handler.Handle(writer, req)
+		require.Equal(t, http.StatusOK, writer.Code)
+		require.Empty(t, writer.Header().Get(etagHeader))
+	})
+}
+
+func TestPlayDuePrizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	osMock := mocks.NewMockOrganizerService(ctrl)
+
+	router, err := NewRouter(osMock, logger.NewNoOpLogger(), testSchedulerAuth())
+	require.NoError(t, err)
+
+	playDuePath := joinPath(ApiPath, SchedulerPath, PlayDuePath)
+
+	t.Run("success", func(t *testing.T) {
+		plays := []service.ScheduledPlay{
+			{OrganizerID: "organizer_id_2", RaffleID: "raffle_id_1", PrizeID: "prize_id_1"},
+		}
+
+		req, err := newSchedulerRequest(playDuePath)
+		require.NoError(t, err)
+
+		osMock.EXPECT().PlayDuePrizes().Return(plays, nil)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, req)
+		require.Equal(t, http.StatusOK, writer.Code)
+		assertJSONResponse(t, ListResponse[service.ScheduledPlay]{Items: plays}, writer.Body)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		req, err := newSchedulerRequest(playDuePath)
+		require.NoError(t, err)
+
+		osMock.EXPECT().PlayDuePrizes().Return(nil, assert.AnError)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, req)
+		require.Equal(t, http.StatusInternalServerError, writer.Code)
+	})
+}
+
+func TestAnonymizeExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	osMock := mocks.NewMockOrganizerService(ctrl)
+
+	router, err := NewRouter(osMock, logger.NewNoOpLogger(), testSchedulerAuth())
+	require.NoError(t, err)
+
+	anonymized := []service.AnonymizedRaffle{
+		{OrganizerID: "organizer_id_2", RaffleID: "raffle_id_1", Participants: 3},
+	}
+
+	req, err := newSchedulerRequest(joinPath(ApiPath, SchedulerPath, AnonymizePath))
+	require.NoError(t, err)
+
+	osMock.EXPECT().AnonymizeExpired().Return(anonymized, nil)
+
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, req)
+	require.Equal(t, http.StatusOK, writer.Code)
+	assertJSONResponse(t, ListResponse[service.AnonymizedRaffle]{Items: anonymized}, writer.Body)
+}
+
+func TestSendNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	osMock := mocks.NewMockOrganizerService(ctrl)
+
+	router, err := NewRouter(osMock, logger.NewNoOpLogger(), testSchedulerAuth())
+	require.NoError(t, err)
+
+	sent := []service.QueuedNotification{
+		{ID: "notification_id_1", OrganizerID: "organizer_id_1", PrizeID: "prize_id_1", ParticipantID: "participant_id_1"},
+	}
+
+	req, err := newSchedulerRequest(joinPath(ApiPath, SchedulerPath, NotifyPath))
+	require.NoError(t, err)
+
+	osMock.EXPECT().SendNotifications().Return(sent, nil)
+
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, req)
+	require.Equal(t, http.StatusOK, writer.Code)
+	assertJSONResponse(t, ListResponse[service.QueuedNotification]{Items: sent}, writer.Body)
+}
+
 func TestJoinPath(t *testing.T) {
 	testCases := []struct {
 		input    []string
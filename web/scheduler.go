@@ -0,0 +1,94 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/idtoken"
+)
+
+var (
+	// ErrMissingSchedulerToken is returned when a scheduler request
+	// has no OIDC token or scheduler authentication isn't configured.
+	ErrMissingSchedulerToken = errors.New("missing scheduler token")
+
+	// ErrForeignSchedulerToken is returned when the OIDC token of a scheduler request
+	// doesn't belong to the scheduler service account.
+	ErrForeignSchedulerToken = errors.New("scheduler token of another account")
+)
+
+// TokenValidator validates an OIDC token issued for the audience and returns its payload.
+type TokenValidator func(ctx context.Context, token, audience string) (*idtoken.Payload, error)
+
+// schedulerAuth authenticates Cloud Scheduler jobs by the OIDC tokens they are sent with.
+type schedulerAuth struct {
+	audience       string
+	serviceAccount string
+	validate       TokenValidator
+}
+
+// WithSchedulerAuth enables the scheduler endpoints for requests with Google-signed
+// OIDC tokens of the service account issued for the audience.
+// Scheduler endpoints reject all requests without it.
+func WithSchedulerAuth(audience, serviceAccount string) RouterOption {
+	return withSchedulerAuth(audience, serviceAccount, idtoken.Validate)
+}
+
+func withSchedulerAuth(audience, serviceAccount string, validate TokenValidator) RouterOption {
+	return func(r *Router) {
+		r.schedulerAuth = &schedulerAuth{
+			audience:       audience,
+			serviceAccount: serviceAccount,
+			validate:       validate,
+		}
+	}
+}
+
+// schedulerMiddleware lets through only requests of Cloud Scheduler jobs.
+// Scheduler endpoints act on behalf of no organizer, so they are not behind organizerMiddleware.
+func (r *Router) schedulerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := r.authenticateScheduler(req); err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrForeignSchedulerToken) {
+				status = http.StatusForbidden
+			}
+
+			http.Error(w, err.Error(), status)
+			r.logger.WithError(err).Warn("failed to authenticate scheduler")
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (r *Router) authenticateScheduler(req *http.Request) error {
+	if r.schedulerAuth == nil {
+		if localRun {
+			return nil
+		}
+
+		return ErrMissingSchedulerToken
+	}
+
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return ErrMissingSchedulerToken
+	}
+
+	payload, err := r.schedulerAuth.validate(req.Context(), token, r.schedulerAuth.audience)
+	if err != nil {
+		return errors.Join(ErrMissingSchedulerToken, err)
+	}
+
+	email, _ := payload.Claims["email"].(string)
+	verified, _ := payload.Claims["email_verified"].(bool)
+	if !verified || email != r.schedulerAuth.serviceAccount {
+		return ErrForeignSchedulerToken
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/api/idtoken"
+
+	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/web/mocks"
+)
+
+const (
+	schedulerAudience = "scheduler_audience"
+	schedulerAccount  = "scheduler@project.iam.gserviceaccount.com"
+	schedulerToken    = "scheduler_token"
+)
+
+// testSchedulerAuth authenticates requests with schedulerToken
+// as requests of schedulerAccount, see newSchedulerRequest.
+func testSchedulerAuth() RouterOption {
+	tokens := map[string]string{
+		schedulerToken: schedulerAccount,
+		"other_token":  "other@project.iam.gserviceaccount.com",
+	}
+
+	return withSchedulerAuth(schedulerAudience, schedulerAccount, func(_ context.Context, token, audience string) (*idtoken.Payload, error) {
+		email, ok := tokens[token]
+		if !ok || audience != schedulerAudience {
+			return nil, errors.New("invalid token")
+		}
+
+		return &idtoken.Payload{
+			Audience: audience,
+			Claims:   map[string]any{"email": email, "email_verified": true},
+		}, nil
+	})
+}
+
+// newSchedulerRequest creates a request of a Cloud Scheduler job.
+func newSchedulerRequest(path string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+schedulerToken)
+
+	return req, nil
+}
+
+func TestSchedulerMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	// No organizer is created for scheduler requests.
+	osMock := mocks.NewMockOrganizerService(ctrl)
+	osMock.EXPECT().PlayDuePrizes().Return(nil, nil)
+
+	playDuePath := joinPath(ApiPath, SchedulerPath, PlayDuePath)
+
+	router, err := NewRouter(osMock, logger.NewNoOpLogger(), testSchedulerAuth())
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		req, err := newSchedulerRequest(playDuePath)
+		require.NoError(t, err)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, req)
+		require.Equal(t, http.StatusOK, writer.Code)
+	})
+
+	for name, tt := range map[string]struct {
+		authorization string
+		status        int
+	}{
+		"no_token":      {"", http.StatusUnauthorized},
+		"not_bearer":    {"Basic " + schedulerToken, http.StatusUnauthorized},
+		"invalid_token": {"Bearer invalid_token", http.StatusUnauthorized},
+		"other_account": {"Bearer other_token", http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, err := newRequestJSON(http.MethodPost, playDuePath, "scheduler", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", tt.authorization)
+
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, req)
+			require.Equal(t, tt.status, writer.Code)
+		})
+	}
+
+	t.Run("not_configured", func(t *testing.T) {
+		router, err := NewRouter(osMock, logger.NewNoOpLogger())
+		require.NoError(t, err)
+
+		req, err := newSchedulerRequest(playDuePath)
+		require.NoError(t, err)
+
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, req)
+		require.Equal(t, http.StatusUnauthorized, writer.Code)
+	})
+}
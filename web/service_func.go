@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
 
@@ -38,6 +39,48 @@ func (r *Router) getParticipantService(req *http.Request) (service.ParticipantSe
 	return raffleService.ParticipantService(raffleID), nil
 }
 
+func (r *Router) getRaffleDonationService(req *http.Request) (service.DonationService, error) {
+	raffleService, err := r.getRaffleService(req)
+	if err != nil {
+		return nil, err
+	}
+
+	raffleID, err := extractParam(req, raffleIDParam)
+	if err != nil {
+		return nil, errors.Join(ErrMissingID, err)
+	}
+
+	return raffleService.DonationService(raffleID)
+}
+
+func (r *Router) getPaymentService(req *http.Request) (service.PaymentService, error) {
+	raffleService, err := r.getRaffleService(req)
+	if err != nil {
+		return nil, err
+	}
+
+	raffleID, err := extractParam(req, raffleIDParam)
+	if err != nil {
+		return nil, errors.Join(ErrMissingID, err)
+	}
+
+	return raffleService.PaymentService(raffleID)
+}
+
+func (r *Router) getReconciliationService(req *http.Request) (service.ReconciliationService, error) {
+	raffleService, err := r.getRaffleService(req)
+	if err != nil {
+		return nil, err
+	}
+
+	raffleID, err := extractParam(req, raffleIDParam)
+	if err != nil {
+		return nil, errors.Join(ErrMissingID, err)
+	}
+
+	return raffleService.ReconciliationService(raffleID), nil
+}
+
 func (r *Router) getRaffleService(req *http.Request) (service.RaffleService, error) {
 	organizerID, err := extractOrganizerID(req)
 	if err != nil {
@@ -61,6 +104,15 @@ func (r *Router) getDonationService(req *http.Request) (service.DonationService,
 	return prizeService.DonationService(prizeID)
 }
 
+func (r *Router) getWebhookService(req *http.Request) (service.WebhookService, error) {
+	organizerID, err := extractOrganizerID(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.organizerService.WebhookService(organizerID), nil
+}
+
 func extractOrganizerID(r *http.Request) (id string, err error) {
 	defer func() {
 		if localRun && err != nil {
@@ -91,3 +143,27 @@ func extractParam(req *http.Request, param string) (string, error) {
 
 	return val, nil
 }
+
+// extractTimeWindow reads optional RFC 3339 bounds of a time window from the query.
+func extractTimeWindow(req *http.Request) (*service.ReconciliationRequest, error) {
+	window := &service.ReconciliationRequest{}
+
+	for param, bound := range map[string]**time.Time{
+		fromQueryParam: &window.From,
+		toQueryParam:   &window.To,
+	} {
+		val := req.URL.Query().Get(param)
+		if val == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", param, err)
+		}
+
+		*bound = &t
+	}
+
+	return window, nil
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaznasho/yarmarok/logger"
+	"github.com/kaznasho/yarmarok/service"
+	"github.com/kaznasho/yarmarok/web/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type WebhookSuite struct {
+	suite.Suite
+	organizerService *mocks.MockOrganizerService
+	webhookService   *mocks.MockWebhookService
+	router           *Router
+	organizerID      string
+	webhookID        string
+}
+
+func TestWebhook(t *testing.T) {
+	suite.Run(t, &WebhookSuite{})
+}
+
+func (s *WebhookSuite) SetupTest() {
+	ctrl := gomock.NewController(s.T())
+	s.organizerService = mocks.NewMockOrganizerService(ctrl)
+	s.webhookService = mocks.NewMockWebhookService(ctrl)
+	s.organizerID = "organizer_id_1"
+	s.webhookID = "webhook_id_1"
+
+	s.organizerService.EXPECT().CreateOrganizerIfNotExists(s.organizerID).Return(nil).AnyTimes()
+	s.organizerService.EXPECT().WebhookService(s.organizerID).Return(s.webhookService).AnyTimes()
+
+	var err error
+	s.router, err = NewRouter(s.organizerService, logger.NewLogger(logger.LevelDebug), testSchedulerAuth())
+	s.Require().NoError(err)
+}
+
+func (s *WebhookSuite) TestCreate() {
+	webhook := &service.WebhookRequest{
+		URL:    "https://example.com/hooks",
+		Secret: "0123456789abcdef",
+		Events: []service.EventType{service.EventDonationCreated},
+	}
+
+	req, err := newRequestJSON(http.MethodPost, joinPath(ApiPath, WebhooksPath), s.organizerID, webhook)
+	s.Require().NoError(err)
+
+	s.webhookService.EXPECT().Create(webhook).Return(s.webhookID, nil)
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+
+	s.Require().Equal(http.StatusOK, writer.Code)
+	s.Contains(writer.Body.String(), s.webhookID)
+}
+
+func (s *WebhookSuite) TestGet() {
+	req, err := newRequestJSON(http.MethodGet, joinPath(ApiPath, WebhooksPath, s.webhookID), s.organizerID, nil)
+	s.Require().NoError(err)
+
+	s.webhookService.EXPECT().Get(s.webhookID).Return(&service.Webhook{ID: s.webhookID, Secret: "0123456789abcdef"}, nil)
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+
+	s.Require().Equal(http.StatusOK, writer.Code)
+	s.NotContains(writer.Body.String(), "0123456789abcdef")
+}
+
+func (s *WebhookSuite) TestDeliveries() {
+	deliveriesPath := joinPath(ApiPath, WebhooksPath, s.webhookID, DeliveriesPath)
+
+	s.Run("list", func() {
+		req, err := newRequestJSON(http.MethodGet, deliveriesPath, s.organizerID, nil)
+		s.Require().NoError(err)
+
+		s.webhookService.EXPECT().Deliveries(s.webhookID).
+			Return([]service.Delivery{{ID: "delivery_id_1", Status: service.DeliveryFailed}}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), `"status":"failed"`)
+	})
+
+	s.Run("replay", func() {
+		req, err := newRequestJSON(http.MethodPost, joinPath(deliveriesPath, "delivery_id_1", ReplayPath), s.organizerID, &service.ReplayRequest{})
+		s.Require().NoError(err)
+
+		s.webhookService.EXPECT().Replay("delivery_id_1", &service.ReplayRequest{}).
+			Return(&service.Delivery{ID: "delivery_id_2", ReplayOf: "delivery_id_1", Status: service.DeliverySucceeded}, nil)
+
+		writer := httptest.NewRecorder()
+		s.router.ServeHTTP(writer, req)
+
+		s.Require().Equal(http.StatusOK, writer.Code)
+		s.Contains(writer.Body.String(), `"replayOf":"delivery_id_1"`)
+	})
+}
+
+func (s *WebhookSuite) TestDeliverWebhooks() {
+	req, err := newSchedulerRequest(joinPath(ApiPath, SchedulerPath, WebhooksPath))
+	s.Require().NoError(err)
+
+	s.organizerService.EXPECT().DeliverWebhooks().Return([]service.Delivery{{ID: "delivery_id_1"}}, nil)
+
+	writer := httptest.NewRecorder()
+	s.router.ServeHTTP(writer, req)
+
+	s.Require().Equal(http.StatusOK, writer.Code)
+	s.Contains(writer.Body.String(), "delivery_id_1")
+}